@@ -36,6 +36,7 @@ export interface PluginMeta<T extends {} = KeyValue> {
   hasUpdate?: boolean;
   latestVersion?: string;
   pinned?: boolean;
+  readOnly?: boolean;
 }
 
 interface PluginDependencyInfo {
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/models"
+)
+
+// maxAuditEntriesPerPage bounds how many audit entries a search returns at once
+const maxAuditEntriesPerPage = 5000
+
+// SearchAuditEntries returns recorded audit entries, newest first.
+// GET /api/admin/audit?orgId=1&login=admin&action=delete&resource=dashboards&from=<epoch ms>&to=<epoch ms>&page=1&perpage=100
+func SearchAuditEntries(c *models.ReqContext) Response {
+	perPage := c.QueryInt("perpage")
+	if perPage <= 0 {
+		perPage = 1000
+	}
+	if perPage > maxAuditEntriesPerPage {
+		perPage = maxAuditEntriesPerPage
+	}
+
+	page := c.QueryInt("page")
+	if page < 1 {
+		page = 1
+	}
+
+	query := &models.SearchAuditEntriesQuery{
+		OrgId:    c.QueryInt64("orgId"),
+		UserId:   c.QueryInt64("userId"),
+		Login:    c.Query("login"),
+		Action:   c.Query("action"),
+		Resource: c.Query("resource"),
+		Page:     page,
+		Limit:    perPage,
+	}
+
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+
+	if err := bus.Dispatch(query); err != nil {
+		return Error(500, "Failed to search audit entries", err)
+	}
+
+	return JSON(200, query.Result)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSearchAuditEntries(t *testing.T) {
+	Convey("When searching audit entries", t, func() {
+		var query *m.SearchAuditEntriesQuery
+
+		search := func(sc *scenarioContext, params map[string]string) {
+			bus.AddHandler("test", func(q *m.SearchAuditEntriesQuery) error {
+				query = q
+				return nil
+			})
+
+			sc.handlerFunc = SearchAuditEntries
+			sc.fakeReqWithParams("GET", sc.url, params).exec()
+		}
+
+		loggedInUserScenarioWithRole("Should return 1000 entries per page by default", "GET", "/api/admin/audit", "/api/admin/audit", m.ROLE_ADMIN, func(sc *scenarioContext) {
+			search(sc, map[string]string{})
+
+			So(sc.resp.Code, ShouldEqual, 200)
+			So(query.Limit, ShouldEqual, 1000)
+			So(query.Page, ShouldEqual, 1)
+		})
+
+		loggedInUserScenarioWithRole("Should not return more than 5000 entries per page", "GET", "/api/admin/audit", "/api/admin/audit", m.ROLE_ADMIN, func(sc *scenarioContext) {
+			search(sc, map[string]string{"perpage": "1000000"})
+
+			So(sc.resp.Code, ShouldEqual, 200)
+			So(query.Limit, ShouldEqual, maxAuditEntriesPerPage)
+		})
+	})
+}
@@ -0,0 +1,11 @@
+package api
+
+import (
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/plugins"
+)
+
+// GET /api/admin/plugins/backend
+func AdminGetBackendPlugins(c *m.ReqContext) Response {
+	return JSON(200, plugins.GetBackendPluginStatuses())
+}
@@ -21,6 +21,14 @@ func (server *HTTPServer) AdminProvisioningReloadDatasources(c *models.ReqContex
 	return Success("Datasources config reloaded")
 }
 
+func (server *HTTPServer) AdminProvisioningReloadPlugins(c *models.ReqContext) Response {
+	err := server.ProvisioningService.ProvisionPlugins()
+	if err != nil {
+		return Error(500, "", err)
+	}
+	return Success("Plugins config reloaded")
+}
+
 func (server *HTTPServer) AdminProvisioningReloadNotifications(c *models.ReqContext) Response {
 	err := server.ProvisioningService.ProvisionNotifications()
 	if err != nil {
@@ -5,10 +5,11 @@ import (
 	"github.com/Seasheller/grafana/pkg/bus"
 	"github.com/Seasheller/grafana/pkg/infra/metrics"
 	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/passwordpolicy"
 	"github.com/Seasheller/grafana/pkg/util"
 )
 
-func AdminCreateUser(c *models.ReqContext, form dtos.AdminCreateUserForm) {
+func (hs *HTTPServer) AdminCreateUser(c *models.ReqContext, form dtos.AdminCreateUserForm) {
 	cmd := models.CreateUserCommand{
 		Login:    form.Login,
 		Email:    form.Email,
@@ -24,8 +25,13 @@ func AdminCreateUser(c *models.ReqContext, form dtos.AdminCreateUserForm) {
 		}
 	}
 
-	if len(cmd.Password) < 4 {
-		c.JsonApiErr(400, "Password is missing or too short", nil)
+	if len(cmd.Password) == 0 {
+		c.JsonApiErr(400, "Password is missing", nil)
+		return
+	}
+
+	if err := passwordpolicy.Validate(hs.Cfg.PasswordPolicy, &models.User{Login: cmd.Login, Email: cmd.Email}, cmd.Password); err != nil {
+		hs.passwordPolicyApiErr(c, err)
 		return
 	}
 
@@ -46,14 +52,9 @@ func AdminCreateUser(c *models.ReqContext, form dtos.AdminCreateUserForm) {
 	c.JSON(200, result)
 }
 
-func AdminUpdateUserPassword(c *models.ReqContext, form dtos.AdminUpdateUserPasswordForm) {
+func (hs *HTTPServer) AdminUpdateUserPassword(c *models.ReqContext, form dtos.AdminUpdateUserPasswordForm) {
 	userID := c.ParamsInt64(":id")
 
-	if len(form.Password) < 4 {
-		c.JsonApiErr(400, "New password too short", nil)
-		return
-	}
-
 	userQuery := models.GetUserByIdQuery{Id: userID}
 
 	if err := bus.Dispatch(&userQuery); err != nil {
@@ -61,6 +62,11 @@ func AdminUpdateUserPassword(c *models.ReqContext, form dtos.AdminUpdateUserPass
 		return
 	}
 
+	if err := passwordpolicy.Validate(hs.Cfg.PasswordPolicy, userQuery.Result, form.Password); err != nil {
+		hs.passwordPolicyApiErr(c, err)
+		return
+	}
+
 	passwordHashed := util.EncodePassword(form.Password, userQuery.Result.Salt)
 
 	cmd := models.ChangeUserPasswordCommand{
@@ -174,3 +180,12 @@ func (server *HTTPServer) AdminRevokeUserAuthToken(c *models.ReqContext, cmd mod
 	userID := c.ParamsInt64(":id")
 	return server.revokeUserAuthTokenInternal(c, userID, cmd)
 }
+
+func (hs *HTTPServer) passwordPolicyApiErr(c *models.ReqContext, err error) {
+	if _, ok := err.(*passwordpolicy.PolicyError); ok {
+		c.JsonApiErr(400, err.Error(), nil)
+		return
+	}
+
+	c.JsonApiErr(500, "Failed to validate password", err)
+}
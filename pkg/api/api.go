@@ -6,6 +6,7 @@ import (
 	"github.com/Seasheller/grafana/pkg/api/routing"
 	"github.com/Seasheller/grafana/pkg/middleware"
 	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/setting"
 	"github.com/go-macaron/binding"
 )
 
@@ -26,8 +27,12 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/", reqSignedIn, hs.Index)
 	r.Get("/logout", hs.Logout)
 	r.Post("/login", quota("session"), bind(dtos.LoginCommand{}), Wrap(hs.LoginPost))
+	r.Get("/login/saml", quota("session"), hs.SAMLLogin)
 	r.Get("/login/:name", quota("session"), hs.OAuthLogin)
 	r.Get("/login", hs.LoginView)
+	r.Get("/saml/metadata", hs.SAMLMetadata)
+	r.Post("/saml/acs", quota("session"), hs.SAMLACS)
+	r.Get("/saml/slo", hs.SAMLSLO)
 	r.Get("/invite/:code", hs.Index)
 
 	// authed views
@@ -98,7 +103,7 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/user/password/reset", hs.Index)
 
 	r.Post("/api/user/password/send-reset-email", bind(dtos.SendResetPasswordEmailForm{}), Wrap(SendResetPasswordEmail))
-	r.Post("/api/user/password/reset", bind(dtos.ResetUserPasswordForm{}), Wrap(ResetPassword))
+	r.Post("/api/user/password/reset", bind(dtos.ResetUserPasswordForm{}), Wrap(hs.ResetPassword))
 
 	// dashboard snapshots
 	r.Get("/dashboard/snapshot/*", hs.Index)
@@ -111,6 +116,12 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/api/snapshots-delete/:deleteKey", Wrap(DeleteDashboardSnapshotByDeleteKey))
 	r.Delete("/api/snapshots/:key", reqEditorRole, Wrap(DeleteDashboardSnapshot))
 
+	// public dashboards, which can also run the queries of their panels
+	if setting.PublicDashboardsEnabled {
+		r.Get("/api/public/dashboards/:accessToken", Wrap(hs.GetPublicDashboard))
+	}
+	r.Post("/api/tsdb/query", middleware.PublicDashboardAuth, reqSignedIn, bind(dtos.MetricRequest{}), Wrap(hs.QueryMetrics))
+
 	// api renew session based on cookie
 	r.Get("/api/login/ping", quota("session"), Wrap(hs.LoginAPIPing))
 
@@ -128,7 +139,7 @@ func (hs *HTTPServer) registerRoutes() {
 			userRoute.Post("/stars/dashboard/:id", Wrap(StarDashboard))
 			userRoute.Delete("/stars/dashboard/:id", Wrap(UnstarDashboard))
 
-			userRoute.Put("/password", bind(models.ChangeUserPasswordCommand{}), Wrap(ChangeUserPassword))
+			userRoute.Put("/password", bind(models.ChangeUserPasswordCommand{}), Wrap(hs.ChangeUserPassword))
 			userRoute.Get("/quotas", Wrap(GetUserQuotas))
 			userRoute.Put("/helpflags/:id", Wrap(SetHelpFlag))
 			// For dev purpose
@@ -240,21 +251,14 @@ func (hs *HTTPServer) registerRoutes() {
 		})
 
 		// Data sources
-		apiRoute.Group("/datasources", func(datasourceRoute routing.RouteRegister) {
-			datasourceRoute.Get("/", Wrap(GetDataSources))
-			datasourceRoute.Post("/", quota("data_source"), bind(models.AddDataSourceCommand{}), Wrap(AddDataSource))
-			datasourceRoute.Put("/:id", bind(models.UpdateDataSourceCommand{}), Wrap(UpdateDataSource))
-			datasourceRoute.Delete("/:id", Wrap(DeleteDataSourceById))
-			datasourceRoute.Delete("/name/:name", Wrap(DeleteDataSourceByName))
-			datasourceRoute.Get("/:id", Wrap(GetDataSourceById))
-			datasourceRoute.Get("/name/:name", Wrap(GetDataSourceByName))
-		}, reqOrgAdmin)
+		apiRoute.Group("/datasources", hs.registerDataSourceRoutes, reqSignedIn)
 
 		apiRoute.Get("/datasources/id/:name", Wrap(GetDataSourceIdByName), reqSignedIn)
 
 		apiRoute.Get("/plugins", Wrap(hs.GetPluginList))
 		apiRoute.Get("/plugins/:pluginId/settings", Wrap(GetPluginSettingByID))
 		apiRoute.Get("/plugins/:pluginId/markdown/:name", Wrap(GetPluginMarkdown))
+		apiRoute.Any("/plugins/:pluginId/resources/*", Wrap(CallPluginResource))
 
 		apiRoute.Group("/plugins", func(pluginRoute routing.RouteRegister) {
 			pluginRoute.Get("/:pluginId/dashboards/", Wrap(GetPluginDashboards))
@@ -275,6 +279,10 @@ func (hs *HTTPServer) registerRoutes() {
 				folderUidRoute.Get("/", Wrap(GetFolderByUID))
 				folderUidRoute.Put("/", bind(models.UpdateFolderCommand{}), Wrap(UpdateFolder))
 				folderUidRoute.Delete("/", Wrap(DeleteFolder))
+				folderUidRoute.Post("/move", bind(models.MoveFolderCommand{}), Wrap(MoveFolder))
+				folderUidRoute.Get("/version-retention", Wrap(GetFolderVersionRetention))
+				folderUidRoute.Put("/version-retention", bind(models.SaveDashboardVersionRetentionCommand{}), Wrap(UpdateFolderVersionRetention))
+				folderUidRoute.Delete("/version-retention", Wrap(DeleteFolderVersionRetention))
 
 				folderUidRoute.Group("/permissions", func(folderPermissionRoute routing.RouteRegister) {
 					folderPermissionRoute.Get("/", Wrap(GetFolderPermissionList))
@@ -283,24 +291,52 @@ func (hs *HTTPServer) registerRoutes() {
 			})
 		})
 
+		// Library panels
+		apiRoute.Group("/library-panels", func(libraryPanelRoute routing.RouteRegister) {
+			libraryPanelRoute.Get("/", Wrap(GetLibraryPanels))
+			libraryPanelRoute.Post("/", bind(models.CreateLibraryPanelCommand{}), Wrap(CreateLibraryPanel))
+
+			libraryPanelRoute.Group("/:uid", func(libraryPanelUidRoute routing.RouteRegister) {
+				libraryPanelUidRoute.Get("/", Wrap(GetLibraryPanelByUID))
+				libraryPanelUidRoute.Put("/", bind(models.UpdateLibraryPanelCommand{}), Wrap(UpdateLibraryPanel))
+				libraryPanelUidRoute.Delete("/", Wrap(DeleteLibraryPanel))
+				libraryPanelUidRoute.Get("/versions", Wrap(GetLibraryPanelVersions))
+				libraryPanelUidRoute.Get("/dashboards", Wrap(GetLibraryPanelDashboards))
+			})
+		})
+
 		// Dashboard
 		apiRoute.Group("/dashboards", func(dashboardRoute routing.RouteRegister) {
 			dashboardRoute.Get("/uid/:uid", Wrap(hs.GetDashboard))
 			dashboardRoute.Delete("/uid/:uid", Wrap(DeleteDashboardByUID))
+			dashboardRoute.Post("/uid/:uid/snapshot", bind(models.CreateServerSideSnapshotCommand{}), Wrap(CreateServerSideSnapshot))
+			dashboardRoute.Get("/uid/:uid/export", Wrap(ExportDashboardBundle))
+
+			if setting.PublicDashboardsEnabled {
+				dashboardRoute.Group("/uid/:uid/public", func(publicRoute routing.RouteRegister) {
+					publicRoute.Get("/", Wrap(GetPublicDashboards))
+					publicRoute.Post("/", bind(models.CreatePublicDashboardCommand{}), Wrap(CreatePublicDashboard))
+					publicRoute.Delete("/:id", Wrap(RevokePublicDashboard))
+				}, reqEditorRole)
+			}
 
 			dashboardRoute.Get("/db/:slug", Wrap(hs.GetDashboard))
 			dashboardRoute.Delete("/db/:slug", Wrap(DeleteDashboardBySlug))
 
 			dashboardRoute.Post("/calculate-diff", bind(dtos.CalculateDiffOptions{}), Wrap(CalculateDashboardDiff))
+			dashboardRoute.Post("/merge", bind(dtos.MergeDashboardCommand{}), Wrap(MergeDashboard))
 
 			dashboardRoute.Post("/db", bind(models.SaveDashboardCommand{}), Wrap(hs.PostDashboard))
 			dashboardRoute.Get("/home", Wrap(GetHomeDashboard))
 			dashboardRoute.Get("/tags", GetDashboardTags)
 			dashboardRoute.Post("/import", bind(dtos.ImportDashboardCommand{}), Wrap(ImportDashboard))
+			dashboardRoute.Post("/import/bundle", reqEditorRole, bind(dtos.ImportDashboardBundleForm{}), Wrap(ImportDashboardBundle))
 
 			dashboardRoute.Group("/id/:dashboardId", func(dashIdRoute routing.RouteRegister) {
 				dashIdRoute.Get("/versions", Wrap(GetDashboardVersions))
 				dashIdRoute.Get("/versions/:id", Wrap(GetDashboardVersion))
+				dashIdRoute.Put("/versions/:id/label", bind(models.SetDashboardVersionLabelCommand{}), Wrap(SetDashboardVersionLabel))
+				dashIdRoute.Delete("/versions/:id/label", Wrap(DeleteDashboardVersionLabel))
 				dashIdRoute.Post("/restore", bind(dtos.RestoreDashboardVersionCommand{}), Wrap(hs.RestoreDashboardVersion))
 
 				dashIdRoute.Group("/permissions", func(dashboardPermissionRoute routing.RouteRegister) {
@@ -310,6 +346,26 @@ func (hs *HTTPServer) registerRoutes() {
 			})
 		})
 
+		// Deleted dashboards
+		apiRoute.Group("/trash", func(trashRoute routing.RouteRegister) {
+			trashRoute.Get("/", Wrap(GetDashboardTrash))
+			trashRoute.Post("/:id/restore", Wrap(RestoreDeletedDashboard))
+			trashRoute.Delete("/:id", Wrap(PurgeDeletedDashboard))
+		}, reqOrgAdmin)
+
+		// Scheduled reports
+		if setting.ReportsEnabled {
+			apiRoute.Group("/reports", func(reportRoute routing.RouteRegister) {
+				reportRoute.Get("/", Wrap(GetReports))
+				reportRoute.Post("/", bind(models.CreateReportCommand{}), Wrap(CreateReport))
+				reportRoute.Get("/:id", Wrap(GetReport))
+				reportRoute.Put("/:id", bind(models.UpdateReportCommand{}), Wrap(UpdateReport))
+				reportRoute.Delete("/:id", Wrap(DeleteReport))
+				reportRoute.Post("/:id/send", Wrap(SendReport))
+				reportRoute.Get("/:id/deliveries", Wrap(GetReportDeliveries))
+			}, reqEditorRole)
+		}
+
 		// Dashboard snapshots
 		apiRoute.Group("/dashboard/snapshots", func(dashboardRoute routing.RouteRegister) {
 			dashboardRoute.Get("/", Wrap(SearchDashboardSnapshots))
@@ -330,11 +386,13 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Get("/search/", Wrap(Search))
 
 		// metrics
-		apiRoute.Post("/tsdb/query", bind(dtos.MetricRequest{}), Wrap(hs.QueryMetrics))
 		apiRoute.Get("/tsdb/testdata/scenarios", Wrap(GetTestDataScenarios))
 		apiRoute.Get("/tsdb/testdata/gensql", reqGrafanaAdmin, Wrap(GenerateSQLTestData))
 		apiRoute.Get("/tsdb/testdata/random-walk", Wrap(GetTestDataRandomWalk))
 
+		// live streams
+		apiRoute.Post("/streams/push", bind(dtos.StreamMessage{}), Wrap(hs.PushToStream))
+
 		apiRoute.Group("/alerts", func(alertsRoute routing.RouteRegister) {
 			alertsRoute.Post("/test", bind(dtos.AlertTestCommand{}), Wrap(AlertTest))
 			alertsRoute.Post("/:alertId/pause", reqEditorRole, bind(dtos.PauseAlertCommand{}), Wrap(PauseAlert))
@@ -377,8 +435,8 @@ func (hs *HTTPServer) registerRoutes() {
 	// admin api
 	r.Group("/api/admin", func(adminRoute routing.RouteRegister) {
 		adminRoute.Get("/settings", AdminGetSettings)
-		adminRoute.Post("/users", bind(dtos.AdminCreateUserForm{}), AdminCreateUser)
-		adminRoute.Put("/users/:id/password", bind(dtos.AdminUpdateUserPasswordForm{}), AdminUpdateUserPassword)
+		adminRoute.Post("/users", bind(dtos.AdminCreateUserForm{}), hs.AdminCreateUser)
+		adminRoute.Put("/users/:id/password", bind(dtos.AdminUpdateUserPasswordForm{}), hs.AdminUpdateUserPassword)
 		adminRoute.Put("/users/:id/permissions", bind(dtos.AdminUpdateUserPermissionsForm{}), AdminUpdateUserPermissions)
 		adminRoute.Delete("/users/:id", AdminDeleteUser)
 		adminRoute.Post("/users/:id/disable", Wrap(hs.AdminDisableUser))
@@ -394,8 +452,11 @@ func (hs *HTTPServer) registerRoutes() {
 
 		adminRoute.Post("/provisioning/dashboards/reload", Wrap(hs.AdminProvisioningReloadDasboards))
 		adminRoute.Post("/provisioning/datasources/reload", Wrap(hs.AdminProvisioningReloadDatasources))
+		adminRoute.Post("/provisioning/plugins/reload", Wrap(hs.AdminProvisioningReloadPlugins))
 		adminRoute.Post("/provisioning/notifications/reload", Wrap(hs.AdminProvisioningReloadNotifications))
 		adminRoute.Post("/ldap/reload", Wrap(hs.ReloadLDAPCfg))
+		adminRoute.Get("/audit", Wrap(SearchAuditEntries))
+		adminRoute.Get("/plugins/backend", Wrap(AdminGetBackendPlugins))
 	}, reqGrafanaAdmin)
 
 	// rendering
@@ -409,8 +470,30 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/avatar/:hash", avatarCacheServer.Handler)
 
 	// Websocket
-	r.Any("/ws", hs.streamManager.Serve)
+	r.Any("/ws", reqSignedIn, hs.streamManager.Serve)
+}
+
+// registerDataSourceRoutes registers the routes of /api/datasources. Only org
+// admins can add data sources and turn permissions on and off, users with
+// admin permission on a data source can manage it.
+func (hs *HTTPServer) registerDataSourceRoutes(datasourceRoute routing.RouteRegister) {
+	reqOrgAdmin := middleware.ReqOrgAdmin
+	quota := middleware.Quota(hs.QuotaService)
+	bind := binding.Bind
 
-	// streams
-	//r.Post("/api/streams/push", reqSignedIn, bind(dtos.StreamMessage{}), liveConn.PushToStream)
+	datasourceRoute.Get("/", reqAnyDataSourceAdmin, Wrap(GetDataSources))
+	datasourceRoute.Post("/", reqOrgAdmin, quota("data_source"), bind(models.AddDataSourceCommand{}), Wrap(AddDataSource))
+	datasourceRoute.Put("/:id", reqDataSourceAdmin, bind(models.UpdateDataSourceCommand{}), Wrap(UpdateDataSource))
+	datasourceRoute.Delete("/:id", reqDataSourceAdmin, Wrap(DeleteDataSourceById))
+	datasourceRoute.Delete("/name/:name", reqOrgAdmin, Wrap(DeleteDataSourceByName))
+	datasourceRoute.Get("/:id", reqDataSourceAdmin, Wrap(GetDataSourceById))
+	datasourceRoute.Get("/name/:name", reqOrgAdmin, Wrap(GetDataSourceByName))
+	datasourceRoute.Get("/:id/health", Wrap(hs.CheckDataSourceHealth))
+
+	// permissions
+	datasourceRoute.Post("/:id/enable-permissions", reqOrgAdmin, Wrap(EnableDataSourcePermissions))
+	datasourceRoute.Post("/:id/disable-permissions", reqOrgAdmin, Wrap(DisableDataSourcePermissions))
+	datasourceRoute.Get("/:id/permissions", reqDataSourceAdmin, Wrap(GetDataSourcePermissions))
+	datasourceRoute.Post("/:id/permissions", reqDataSourceAdmin, bind(models.AddDataSourcePermissionCommand{}), Wrap(AddDataSourcePermission))
+	datasourceRoute.Delete("/:id/permissions/:permissionId", reqDataSourceAdmin, Wrap(RemoveDataSourcePermission))
 }
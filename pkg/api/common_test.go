@@ -37,6 +37,8 @@ func loggedInUserScenarioWithRole(desc string, method string, url string, routeP
 		switch method {
 		case "GET":
 			sc.m.Get(routePattern, sc.defaultHandler)
+		case "POST":
+			sc.m.Post(routePattern, sc.defaultHandler)
 		case "DELETE":
 			sc.m.Delete(routePattern, sc.defaultHandler)
 		}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/Seasheller/grafana/pkg/services/alerting"
 	"github.com/Seasheller/grafana/pkg/services/dashboards"
@@ -194,6 +195,8 @@ func deleteDashboard(c *m.ReqContext) Response {
 	err := dashboards.NewService().DeleteDashboard(dash.Id, c.OrgId)
 	if err == m.ErrDashboardCannotDeleteProvisionedDashboard {
 		return Error(400, "Dashboard cannot be deleted because it was provisioned", err)
+	} else if err == m.ErrFolderContainsLibraryPanelsInUse {
+		return Error(409, err.Error(), nil)
 	} else if err != nil {
 		return Error(500, "Failed to delete dashboard", err)
 	}
@@ -239,9 +242,12 @@ func (hs *HTTPServer) PostDashboard(c *m.ReqContext, cmd m.SaveDashboardCommand)
 		err == m.ErrDashboardUidToLong ||
 		err == m.ErrDashboardWithSameUIDExists ||
 		err == m.ErrFolderNotFound ||
-		err == m.ErrDashboardFolderCannotHaveParent ||
+		err == m.ErrDashboardFolderCannotBeOwnParent ||
+		err == m.ErrDashboardFolderMaxDepthExceeded ||
 		err == m.ErrDashboardFolderNameExists ||
-		err == m.ErrDashboardCannotSaveProvisionedDashboard {
+		err == m.ErrDashboardCannotSaveProvisionedDashboard ||
+		err == m.ErrDashboardLibraryPanelNotFound ||
+		err == m.ErrLibraryPanelReferenceUidMissing {
 		return Error(400, err.Error(), nil)
 	}
 
@@ -425,11 +431,51 @@ func GetDashboardVersion(c *m.ReqContext) Response {
 		Created:       query.Result.Created,
 		Message:       query.Result.Message,
 		CreatedBy:     creator,
+		Label:         query.Result.Label,
 	}
 
 	return JSON(200, dashVersionMeta)
 }
 
+// PUT /api/dashboards/id/:dashboardId/versions/:id/label labels the version,
+// which keeps it from being deleted by the clean up of old versions.
+func SetDashboardVersionLabel(c *m.ReqContext, cmd m.SetDashboardVersionLabelCommand) Response {
+	cmd.Label = strings.TrimSpace(cmd.Label)
+	if cmd.Label == "" {
+		return Error(400, "Label cannot be empty", nil)
+	}
+	if len(cmd.Label) > 190 {
+		return Error(400, m.ErrDashboardVersionLabelTooLong.Error(), nil)
+	}
+
+	return saveDashboardVersionLabel(c, cmd)
+}
+
+// DELETE /api/dashboards/id/:dashboardId/versions/:id/label
+func DeleteDashboardVersionLabel(c *m.ReqContext) Response {
+	return saveDashboardVersionLabel(c, m.SetDashboardVersionLabelCommand{})
+}
+
+func saveDashboardVersionLabel(c *m.ReqContext, cmd m.SetDashboardVersionLabelCommand) Response {
+	cmd.DashboardId = c.ParamsInt64(":dashboardId")
+	cmd.OrgId = c.OrgId
+	cmd.Version = c.ParamsInt(":id")
+
+	guardian := guardian.New(cmd.DashboardId, c.OrgId, c.SignedInUser)
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == m.ErrDashboardVersionNotFound {
+			return Error(404, fmt.Sprintf("Dashboard version %d not found for dashboardId %d", cmd.Version, cmd.DashboardId), err)
+		}
+		return Error(500, "Failed to save dashboard version label", err)
+	}
+
+	return Success("Dashboard version label saved")
+}
+
 // POST /api/dashboards/calculate-diff performs diffs on two dashboards
 func CalculateDashboardDiff(c *m.ReqContext, apiOptions dtos.CalculateDiffOptions) Response {
 
@@ -468,13 +514,43 @@ func CalculateDashboardDiff(c *m.ReqContext, apiOptions dtos.CalculateDiffOption
 		return Error(500, "Unable to compute diff", err)
 	}
 
-	if options.DiffType == dashdiffs.DiffDelta {
+	if options.DiffType == dashdiffs.DiffDelta || options.DiffType == dashdiffs.DiffSemantic {
 		return Respond(200, result.Delta).Header("Content-Type", "application/json")
 	}
 
 	return Respond(200, result.Delta).Header("Content-Type", "text/html")
 }
 
+// POST /api/dashboards/merge merges an unsaved dashboard with the changes
+// saved since the version it is based on
+func MergeDashboard(c *m.ReqContext, apiCmd dtos.MergeDashboardCommand) Response {
+	id := apiCmd.Dashboard.Get("id").MustInt64()
+	uid := apiCmd.Dashboard.Get("uid").MustString()
+	if id == 0 && uid == "" {
+		return Error(400, "Dashboard id or uid is required", nil)
+	}
+
+	dash, rsp := getDashboardHelper(c.OrgId, "", id, uid)
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(dash.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	result, err := dashdiffs.MergeWithLatestVersion(dash, apiCmd.Dashboard)
+	if err != nil {
+		if err == m.ErrDashboardVersionNotFound {
+			return Error(404, "Dashboard version not found", err)
+		}
+		return Error(500, "Unable to merge dashboard", err)
+	}
+
+	return JSON(200, result)
+}
+
 // RestoreDashboardVersion restores a dashboard to the given version.
 func (hs *HTTPServer) RestoreDashboardVersion(c *m.ReqContext, apiCmd dtos.RestoreDashboardVersionCommand) Response {
 	dash, rsp := getDashboardHelper(c.OrgId, "", c.ParamsInt64(":dashboardId"), "")
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/Seasheller/grafana/pkg/api/dtos"
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/alerting"
+	"github.com/Seasheller/grafana/pkg/services/bundles"
+	"github.com/Seasheller/grafana/pkg/util"
+)
+
+// maxBundleSize limits the size of uploaded dashboard bundles.
+const maxBundleSize = 50 << 20
+
+func ExportDashboardBundle(c *m.ReqContext) Response {
+	cmd := bundles.ExportDashboardCommand{OrgId: c.OrgId, Uid: c.Params(":uid"), User: c.SignedInUser}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		switch err {
+		case m.ErrDashboardNotFound:
+			return Error(404, "Dashboard not found", err)
+		case bundles.ErrBundleAccessDenied:
+			return Error(403, err.Error(), err)
+		case bundles.ErrBundleFolderExport:
+			return Error(400, err.Error(), err)
+		}
+		return Error(500, "Failed to export dashboard", err)
+	}
+
+	var buf bytes.Buffer
+	if err := cmd.Result.Write(&buf); err != nil {
+		return Error(500, "Failed to write dashboard bundle", err)
+	}
+
+	return Respond(200, buf.Bytes()).
+		Header("Content-Type", "application/zip").
+		Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, cmd.Uid))
+}
+
+func ImportDashboardBundle(c *m.ReqContext, form dtos.ImportDashboardBundleForm) Response {
+	file, err := form.Bundle.Open()
+	if err != nil {
+		return Error(400, "Failed to read dashboard bundle", err)
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(file, maxBundleSize+1))
+	if err != nil {
+		return Error(400, "Failed to read dashboard bundle", err)
+	}
+	if len(data) > maxBundleSize {
+		return Error(400, "Dashboard bundle is too large", nil)
+	}
+
+	bundle, err := bundles.Read(data)
+	if err != nil {
+		return Error(400, err.Error(), err)
+	}
+
+	cmd := bundles.ImportBundleCommand{Bundle: bundle, OrgId: c.OrgId, User: c.SignedInUser}
+	if form.Options != "" {
+		if err := json.Unmarshal([]byte(form.Options), &cmd.Options); err != nil {
+			return Error(400, "Invalid import options", err)
+		}
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return importDashboardBundleError(err)
+	}
+
+	return JSON(200, cmd.Result)
+}
+
+func importDashboardBundleError(err error) Response {
+	if missing, ok := err.(*bundles.MissingDependenciesError); ok {
+		return JSON(412, util.DynMap{
+			"status":      "missing-dependencies",
+			"message":     missing.Error(),
+			"datasources": missing.Datasources,
+			"notifiers":   missing.Notifiers,
+		})
+	}
+
+	if validationErr, ok := err.(alerting.ValidationError); ok {
+		return Error(422, validationErr.Error(), nil)
+	}
+
+	switch err {
+	case bundles.ErrBundleCreateDatasourceDenied,
+		m.ErrFolderAccessDenied,
+		m.ErrLibraryPanelAccessDenied,
+		m.ErrDashboardUpdateAccessDenied:
+		return Error(403, err.Error(), err)
+	case m.ErrFolderNotFound,
+		m.ErrDashboardTitleEmpty,
+		m.ErrDashboardWithSameUIDExists,
+		m.ErrDashboardWithSameNameAsFolder,
+		m.ErrDashboardFolderMaxDepthExceeded,
+		m.ErrDashboardCannotSaveProvisionedDashboard,
+		m.ErrLibraryPanelWithSameNameExists,
+		m.ErrDataSourceNameExists:
+		return Error(400, err.Error(), nil)
+	case m.ErrDashboardWithSameNameInFolderExists:
+		return JSON(412, util.DynMap{"status": "name-exists", "message": err.Error()})
+	}
+
+	return Error(500, "Failed to import dashboard bundle", err)
+}
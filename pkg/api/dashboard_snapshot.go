@@ -125,6 +125,35 @@ func CreateDashboardSnapshot(c *m.ReqContext, cmd m.CreateDashboardSnapshotComma
 	})
 }
 
+// POST /api/dashboards/uid/:uid/snapshot
+func CreateServerSideSnapshot(c *m.ReqContext, cmd m.CreateServerSideSnapshotCommand) Response {
+	cmd.DashboardUid = c.Params(":uid")
+	cmd.OrgId = c.OrgId
+	cmd.User = c.SignedInUser
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		if err == m.ErrDashboardNotFound {
+			return Error(404, "Dashboard not found", err)
+		}
+		if err == m.ErrDashboardSnapshotAccessDenied || err == m.ErrDataSourceAccessDenied {
+			return Error(403, err.Error(), err)
+		}
+		if err == m.ErrDataSourceNotFound {
+			return Error(400, "Data source used by dashboard not found", err)
+		}
+		return Error(500, "Failed to create snapshot", err)
+	}
+
+	metrics.MApiDashboardSnapshotCreate.Inc()
+
+	return JSON(200, util.DynMap{
+		"key":       cmd.Result.Key,
+		"deleteKey": cmd.Result.DeleteKey,
+		"url":       setting.ToAbsUrl("dashboard/snapshot/" + cmd.Result.Key),
+		"deleteUrl": setting.ToAbsUrl("api/snapshots-delete/" + cmd.Result.DeleteKey),
+	})
+}
+
 // GET /api/snapshots/:key
 func GetDashboardSnapshot(c *m.ReqContext) {
 	key := c.Params(":key")
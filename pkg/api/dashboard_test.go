@@ -730,7 +730,8 @@ func TestDashboardApiEndpoint(t *testing.T) {
 				{SaveError: m.ErrDashboardWithSameNameInFolderExists, ExpectedStatusCode: 412},
 				{SaveError: m.ErrDashboardVersionMismatch, ExpectedStatusCode: 412},
 				{SaveError: m.ErrDashboardTitleEmpty, ExpectedStatusCode: 400},
-				{SaveError: m.ErrDashboardFolderCannotHaveParent, ExpectedStatusCode: 400},
+				{SaveError: m.ErrDashboardFolderCannotBeOwnParent, ExpectedStatusCode: 400},
+				{SaveError: m.ErrDashboardFolderMaxDepthExceeded, ExpectedStatusCode: 400},
 				{SaveError: alerting.ValidationError{Reason: "Mu"}, ExpectedStatusCode: 422},
 				{SaveError: m.ErrDashboardFailedGenerateUniqueUid, ExpectedStatusCode: 500},
 				{SaveError: m.ErrDashboardTypeMismatch, ExpectedStatusCode: 400},
@@ -816,6 +817,128 @@ func TestDashboardApiEndpoint(t *testing.T) {
 		})
 	})
 
+	Convey("Given a dashboard being merged with the latest version", t, func() {
+		latest := m.NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{
+			"id":      1,
+			"uid":     "abc",
+			"title":   "Dash",
+			"version": 2,
+			"panels": []interface{}{
+				map[string]interface{}{"id": 1, "title": "Theirs"},
+			},
+		}))
+		latest.Id = 1
+		latest.OrgId = TestOrgID
+
+		bus.AddHandler("test", func(query *m.GetDashboardQuery) error {
+			query.Result = latest
+			return nil
+		})
+
+		bus.AddHandler("test", func(query *m.GetDashboardAclInfoListQuery) error {
+			query.Result = []*m.DashboardAclInfoDTO{}
+			return nil
+		})
+
+		var baseVersionQuery *m.GetDashboardVersionQuery
+		bus.AddHandler("test", func(query *m.GetDashboardVersionQuery) error {
+			baseVersionQuery = query
+			query.Result = &m.DashboardVersion{
+				Data: simplejson.NewFromAny(map[string]interface{}{
+					"id":      1,
+					"uid":     "abc",
+					"title":   "Dash",
+					"version": 1,
+					"panels": []interface{}{
+						map[string]interface{}{"id": 1, "title": "Base"},
+					},
+				}),
+			}
+			return nil
+		})
+
+		Convey("when user does not have permission", func() {
+			cmd := dtos.MergeDashboardCommand{
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{"uid": "abc", "version": 1}),
+			}
+
+			postMergeScenario("When calling POST on", "/api/dashboards/merge", "/api/dashboards/merge", cmd, m.ROLE_VIEWER, func(sc *scenarioContext) {
+				CallPostDashboard(sc)
+				So(sc.resp.Code, ShouldEqual, 403)
+			})
+		})
+
+		Convey("when the changes do not conflict", func() {
+			cmd := dtos.MergeDashboardCommand{
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"id":      1,
+					"uid":     "abc",
+					"title":   "Ours",
+					"version": 1,
+					"panels": []interface{}{
+						map[string]interface{}{"id": 1, "title": "Base"},
+					},
+				}),
+			}
+
+			postMergeScenario("When calling POST on", "/api/dashboards/merge", "/api/dashboards/merge", cmd, m.ROLE_ADMIN, func(sc *scenarioContext) {
+				CallPostDashboard(sc)
+				So(sc.resp.Code, ShouldEqual, 200)
+
+				So(baseVersionQuery.DashboardId, ShouldEqual, 1)
+				So(baseVersionQuery.Version, ShouldEqual, 1)
+
+				result := sc.ToJSON()
+				So(result.Get("conflicts").MustArray(), ShouldBeEmpty)
+
+				dash := result.Get("dashboard")
+				So(dash.Get("title").MustString(), ShouldEqual, "Ours")
+				So(dash.Get("version").MustInt(), ShouldEqual, 2)
+				So(dash.Get("panels").GetIndex(0).Get("title").MustString(), ShouldEqual, "Theirs")
+			})
+		})
+
+		Convey("when the changes conflict", func() {
+			cmd := dtos.MergeDashboardCommand{
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"id":      1,
+					"uid":     "abc",
+					"title":   "Dash",
+					"version": 1,
+					"panels": []interface{}{
+						map[string]interface{}{"id": 1, "title": "Ours"},
+					},
+				}),
+			}
+
+			postMergeScenario("When calling POST on", "/api/dashboards/merge", "/api/dashboards/merge", cmd, m.ROLE_ADMIN, func(sc *scenarioContext) {
+				CallPostDashboard(sc)
+				So(sc.resp.Code, ShouldEqual, 200)
+
+				result := sc.ToJSON()
+				conflicts := result.Get("conflicts")
+				So(conflicts.MustArray(), ShouldHaveLength, 1)
+				So(conflicts.GetIndex(0).Get("target").MustString(), ShouldEqual, "panel")
+				So(conflicts.GetIndex(0).Get("panelId").MustInt64(), ShouldEqual, 1)
+				So(conflicts.GetIndex(0).Get("ours").Get("title").MustString(), ShouldEqual, "Ours")
+				So(conflicts.GetIndex(0).Get("theirs").Get("title").MustString(), ShouldEqual, "Theirs")
+
+				So(result.Get("dashboard").Get("panels").GetIndex(0).Get("title").MustString(), ShouldEqual, "Theirs")
+			})
+		})
+
+		Convey("when the dashboard has no id or uid", func() {
+			cmd := dtos.MergeDashboardCommand{
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{"version": 1}),
+			}
+
+			postMergeScenario("When calling POST on", "/api/dashboards/merge", "/api/dashboards/merge", cmd, m.ROLE_ADMIN, func(sc *scenarioContext) {
+				CallPostDashboard(sc)
+				So(sc.resp.Code, ShouldEqual, 400)
+			})
+		})
+	})
+
 	Convey("Given dashboard in folder being restored should restore to folder", t, func() {
 		fakeDash := m.NewDashboard("Child dash")
 		fakeDash.Id = 2
@@ -1095,6 +1218,28 @@ func postDiffScenario(desc string, url string, routePattern string, cmd dtos.Cal
 	})
 }
 
+func postMergeScenario(desc string, url string, routePattern string, cmd dtos.MergeDashboardCommand, role m.RoleType, fn scenarioFunc) {
+	Convey(desc+" "+url, func() {
+		defer bus.ClearBusHandlers()
+
+		sc := setupScenarioContext(url)
+		sc.defaultHandler = Wrap(func(c *m.ReqContext) Response {
+			sc.context = c
+			sc.context.SignedInUser = &m.SignedInUser{
+				OrgId:  TestOrgID,
+				UserId: TestUserID,
+			}
+			sc.context.OrgRole = role
+
+			return MergeDashboard(c, cmd)
+		})
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
 func restoreDashboardVersionScenario(desc string, url string, routePattern string, mock *dashboards.FakeDashboardService, cmd dtos.RestoreDashboardVersionCommand, fn scenarioFunc) {
 	Convey(desc+" "+url, func() {
 		defer bus.ClearBusHandlers()
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/dashboards"
+	"github.com/Seasheller/grafana/pkg/util"
+)
+
+func GetDashboardTrash(c *m.ReqContext) Response {
+	query := m.GetDashboardTrashQuery{OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to get deleted dashboards", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+func RestoreDeletedDashboard(c *m.ReqContext) Response {
+	dash, err := dashboards.NewService().RestoreDashboard(c.ParamsInt64(":id"), c.OrgId, c.SignedInUser)
+
+	if err == m.ErrDashboardTrashItemNotFound {
+		return JSON(404, util.DynMap{"status": "not-found", "message": err.Error()})
+	}
+
+	if err == m.ErrDashboardWithSameUIDExists ||
+		err == m.ErrDashboardWithSameNameAsFolder ||
+		err == m.ErrDashboardFolderWithSameNameAsDashboard ||
+		err == m.ErrDashboardFolderMaxDepthExceeded {
+		return Error(400, err.Error(), nil)
+	}
+
+	if err == m.ErrDashboardWithSameNameInFolderExists {
+		return JSON(412, util.DynMap{"status": "name-exists", "message": err.Error()})
+	}
+
+	if err != nil {
+		return Error(500, "Failed to restore dashboard", err)
+	}
+
+	return JSON(200, util.DynMap{
+		"title":   dash.Title,
+		"id":      dash.Id,
+		"uid":     dash.Uid,
+		"url":     dash.GetUrl(),
+		"message": fmt.Sprintf("Dashboard %s restored", dash.Title),
+	})
+}
+
+func PurgeDeletedDashboard(c *m.ReqContext) Response {
+	cmd := m.PurgeDashboardTrashCommand{OrgId: c.OrgId, TrashId: c.ParamsInt64(":id")}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == m.ErrDashboardTrashItemNotFound {
+			return JSON(404, util.DynMap{"status": "not-found", "message": err.Error()})
+		}
+		return Error(500, "Failed to permanently delete dashboard", err)
+	}
+
+	return JSON(200, util.DynMap{"message": "Dashboard permanently deleted"})
+}
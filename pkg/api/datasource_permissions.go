@@ -0,0 +1,185 @@
+package api
+
+import (
+	"github.com/Seasheller/grafana/pkg/api/dtos"
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+// reqDataSourceAdmin allows org admins and users with admin permission on
+// the data source in the :id route parameter.
+func reqDataSourceAdmin(c *m.ReqContext) {
+	if c.OrgRole == m.ROLE_ADMIN {
+		return
+	}
+
+	query := m.GetDataSourceByIdQuery{Id: c.ParamsInt64(":id"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		if err == m.ErrDataSourceNotFound {
+			c.JsonApiErr(404, "Data source not found", nil)
+			return
+		}
+		c.JsonApiErr(500, "Failed to query datasources", err)
+		return
+	}
+
+	filterQuery := m.DatasourcesPermissionFilterQuery{
+		User:        c.SignedInUser,
+		Datasources: []*m.DataSource{query.Result},
+		Permission:  m.DsPermissionAdmin,
+	}
+
+	if err := bus.Dispatch(&filterQuery); err != nil {
+		c.JsonApiErr(500, "Failed to check data source permissions", err)
+		return
+	}
+
+	if len(filterQuery.Result) == 0 {
+		c.JsonApiErr(403, "Permission denied", nil)
+	}
+}
+
+// reqAnyDataSourceAdmin allows org admins and users with admin permission on
+// at least one data source of the org.
+func reqAnyDataSourceAdmin(c *m.ReqContext) {
+	if c.OrgRole == m.ROLE_ADMIN {
+		return
+	}
+
+	query := m.GetDataSourcesQuery{OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		c.JsonApiErr(500, "Failed to query datasources", err)
+		return
+	}
+
+	filterQuery := m.DatasourcesPermissionFilterQuery{
+		User:        c.SignedInUser,
+		Datasources: query.Result,
+		Permission:  m.DsPermissionAdmin,
+	}
+
+	if err := bus.Dispatch(&filterQuery); err != nil {
+		c.JsonApiErr(500, "Failed to check data source permissions", err)
+		return
+	}
+
+	if len(filterQuery.Result) == 0 {
+		c.JsonApiErr(403, "Permission denied", nil)
+	}
+}
+
+// GET /api/datasources/:id/permissions
+func GetDataSourcePermissions(c *m.ReqContext) Response {
+	dsQuery := m.GetDataSourceByIdQuery{Id: c.ParamsInt64(":id"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&dsQuery); err != nil {
+		if err == m.ErrDataSourceNotFound {
+			return Error(404, "Data source not found", nil)
+		}
+		return Error(500, "Failed to query datasources", err)
+	}
+
+	query := m.GetDataSourcePermissionsQuery{DatasourceId: dsQuery.Result.Id, OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to get data source permissions", err)
+	}
+
+	for _, perm := range query.Result {
+		if perm.UserId > 0 {
+			perm.UserAvatarUrl = dtos.GetGravatarUrl(perm.UserEmail)
+		}
+		if perm.TeamId > 0 {
+			perm.TeamAvatarUrl = dtos.GetGravatarUrlWithDefault(perm.TeamEmail, perm.Team)
+		}
+	}
+
+	return JSON(200, dtos.DataSourcePermissionsDTO{
+		DatasourceId: dsQuery.Result.Id,
+		Enabled:      dsQuery.Result.PermissionsEnabled,
+		Permissions:  query.Result,
+	})
+}
+
+// POST /api/datasources/:id/enable-permissions
+func EnableDataSourcePermissions(c *m.ReqContext) Response {
+	cmd := m.EnableDataSourcePermissionsCommand{DatasourceId: c.ParamsInt64(":id"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == m.ErrDataSourceNotFound {
+			return Error(404, "Data source not found", nil)
+		}
+		return Error(500, "Failed to enable data source permissions", err)
+	}
+
+	return Success("Datasource permissions enabled")
+}
+
+// POST /api/datasources/:id/disable-permissions
+func DisableDataSourcePermissions(c *m.ReqContext) Response {
+	cmd := m.DisableDataSourcePermissionsCommand{DatasourceId: c.ParamsInt64(":id"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == m.ErrDataSourceNotFound {
+			return Error(404, "Data source not found", nil)
+		}
+		return Error(500, "Failed to disable data source permissions", err)
+	}
+
+	return Success("Datasource permissions disabled")
+}
+
+// POST /api/datasources/:id/permissions
+func AddDataSourcePermission(c *m.ReqContext, cmd m.AddDataSourcePermissionCommand) Response {
+	cmd.DatasourceId = c.ParamsInt64(":id")
+	cmd.OrgId = c.OrgId
+
+	if cmd.TeamId > 0 {
+		teamQuery := m.GetTeamByIdQuery{OrgId: c.OrgId, Id: cmd.TeamId}
+		if err := bus.Dispatch(&teamQuery); err != nil {
+			if err == m.ErrTeamNotFound {
+				return Error(400, "Team not found", nil)
+			}
+			return Error(500, "Failed to get team", err)
+		}
+	}
+
+	if cmd.UserId > 0 {
+		userQuery := m.GetUserByIdQuery{Id: cmd.UserId}
+		if err := bus.Dispatch(&userQuery); err != nil {
+			if err == m.ErrUserNotFound {
+				return Error(400, "User not found", nil)
+			}
+			return Error(500, "Failed to get user", err)
+		}
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		switch err {
+		case m.ErrDataSourceNotFound:
+			return Error(404, "Data source not found", nil)
+		case m.ErrDataSourcePermissionsDisabled,
+			m.ErrDataSourcePermissionExists,
+			m.ErrDataSourcePermissionInfoMissing,
+			m.ErrDataSourcePermissionInvalid:
+			return Error(400, err.Error(), err)
+		}
+		return Error(500, "Failed to add data source permission", err)
+	}
+
+	return Success("Datasource permission added")
+}
+
+// DELETE /api/datasources/:id/permissions/:permissionId
+func RemoveDataSourcePermission(c *m.ReqContext) Response {
+	cmd := m.RemoveDataSourcePermissionCommand{
+		OrgId:        c.OrgId,
+		DatasourceId: c.ParamsInt64(":id"),
+		Id:           c.ParamsInt64(":permissionId"),
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == m.ErrDataSourcePermissionNotFound {
+			return Error(404, "Data source permission not found", nil)
+		}
+		return Error(500, "Failed to remove data source permission", err)
+	}
+
+	return Success("Datasource permission removed")
+}
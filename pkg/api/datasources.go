@@ -1,12 +1,15 @@
 package api
 
 import (
+	"context"
 	"sort"
+	"time"
 
 	"github.com/Seasheller/grafana/pkg/api/dtos"
 	"github.com/Seasheller/grafana/pkg/bus"
 	m "github.com/Seasheller/grafana/pkg/models"
 	"github.com/Seasheller/grafana/pkg/plugins"
+	"github.com/Seasheller/grafana/pkg/tsdb"
 	"github.com/Seasheller/grafana/pkg/util"
 )
 
@@ -17,8 +20,19 @@ func GetDataSources(c *m.ReqContext) Response {
 		return Error(500, "Failed to query datasources", err)
 	}
 
+	// only list the data sources the user can administer
+	filterQuery := m.DatasourcesPermissionFilterQuery{
+		User:        c.SignedInUser,
+		Datasources: query.Result,
+		Permission:  m.DsPermissionAdmin,
+	}
+
+	if err := bus.Dispatch(&filterQuery); err != nil {
+		return Error(500, "Failed to query datasources", err)
+	}
+
 	result := make(dtos.DataSourceList, 0)
-	for _, ds := range query.Result {
+	for _, ds := range filterQuery.Result {
 		dsItem := dtos.DataSourceListItemDTO{
 			OrgId:     ds.OrgId,
 			Id:        ds.Id,
@@ -254,26 +268,64 @@ func GetDataSourceIdByName(c *m.ReqContext) Response {
 	return JSON(200, &dtos)
 }
 
+// GET /api/datasources/:id/health
+func (hs *HTTPServer) CheckDataSourceHealth(c *m.ReqContext) Response {
+	ds, err := hs.DatasourceCache.GetDatasource(c.ParamsInt64(":id"), c.SignedInUser, c.SkipCache)
+	if err != nil {
+		if err == m.ErrDataSourceNotFound {
+			return Error(404, "Data source not found", nil)
+		}
+		if err == m.ErrDataSourceAccessDenied {
+			return Error(403, "Access denied to datasource", err)
+		}
+		return Error(500, "Unable to load datasource meta data", err)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Req.Context(), hs.Cfg.DataSourceHealth.Timeout)
+	defer cancel()
+
+	result, err := tsdb.HandleHealthCheck(ctx, ds)
+	if err != nil {
+		if err == tsdb.ErrHealthCheckNotSupported {
+			return Error(400, err.Error(), nil)
+		}
+		return Error(500, "Failed to check datasource health", err)
+	}
+
+	dto := dtos.DataSourceHealth{
+		Status:    "ok",
+		Message:   result.Message,
+		LatencyMs: int64(result.Latency / time.Millisecond),
+	}
+	if !result.Healthy {
+		dto.Status = "failing"
+		return JSON(503, &dto)
+	}
+
+	return JSON(200, &dto)
+}
+
 func convertModelToDtos(ds *m.DataSource) dtos.DataSource {
 	dto := dtos.DataSource{
-		Id:                ds.Id,
-		OrgId:             ds.OrgId,
-		Name:              ds.Name,
-		Url:               ds.Url,
-		Type:              ds.Type,
-		Access:            ds.Access,
-		Password:          ds.Password,
-		Database:          ds.Database,
-		User:              ds.User,
-		BasicAuth:         ds.BasicAuth,
-		BasicAuthUser:     ds.BasicAuthUser,
-		BasicAuthPassword: ds.BasicAuthPassword,
-		WithCredentials:   ds.WithCredentials,
-		IsDefault:         ds.IsDefault,
-		JsonData:          ds.JsonData,
-		SecureJsonFields:  map[string]bool{},
-		Version:           ds.Version,
-		ReadOnly:          ds.ReadOnly,
+		Id:                 ds.Id,
+		OrgId:              ds.OrgId,
+		Name:               ds.Name,
+		Url:                ds.Url,
+		Type:               ds.Type,
+		Access:             ds.Access,
+		Password:           ds.Password,
+		Database:           ds.Database,
+		User:               ds.User,
+		BasicAuth:          ds.BasicAuth,
+		BasicAuthUser:      ds.BasicAuthUser,
+		BasicAuthPassword:  ds.BasicAuthPassword,
+		WithCredentials:    ds.WithCredentials,
+		IsDefault:          ds.IsDefault,
+		JsonData:           ds.JsonData,
+		SecureJsonFields:   map[string]bool{},
+		Version:            ds.Version,
+		ReadOnly:           ds.ReadOnly,
+		PermissionsEnabled: ds.PermissionsEnabled,
 	}
 
 	for k, v := range ds.SecureJsonData {
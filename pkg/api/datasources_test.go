@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/Seasheller/grafana/pkg/api/routing"
+	"github.com/Seasheller/grafana/pkg/middleware"
 	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/quota"
 
 	"github.com/Seasheller/grafana/pkg/bus"
 	. "github.com/smartystreets/goconvey/convey"
@@ -31,6 +34,12 @@ func TestDataSourcesProxy(t *testing.T) {
 				return nil
 			})
 
+			bus.AddHandler("test", func(query *models.DatasourcesPermissionFilterQuery) error {
+				So(query.Permission, ShouldEqual, models.DsPermissionAdmin)
+				query.Result = query.Datasources
+				return nil
+			})
+
 			// handler func being tested
 			sc.handlerFunc = GetDataSources
 			sc.fakeReq("GET", "/api/datasources").exec()
@@ -47,6 +56,30 @@ func TestDataSourcesProxy(t *testing.T) {
 			})
 		})
 
+		loggedInUserScenario("When calling GET with datasource permissions on", "/api/datasources/", func(sc *scenarioContext) {
+			bus.AddHandler("test", func(query *models.GetDataSourcesQuery) error {
+				query.Result = []*models.DataSource{{Id: 1, Name: "admin"}, {Id: 2, Name: "query only"}}
+				return nil
+			})
+
+			bus.AddHandler("test", func(query *models.DatasourcesPermissionFilterQuery) error {
+				query.Result = query.Datasources[:1]
+				return nil
+			})
+
+			sc.handlerFunc = GetDataSources
+			sc.fakeReq("GET", "/api/datasources").exec()
+
+			respJSON := []map[string]interface{}{}
+			err := json.NewDecoder(sc.resp.Body).Decode(&respJSON)
+			So(err, ShouldBeNil)
+
+			Convey("should only list datasources the user can administer", func() {
+				So(len(respJSON), ShouldEqual, 1)
+				So(respJSON[0]["name"], ShouldEqual, "admin")
+			})
+		})
+
 		Convey("Should be able to save a data source", func() {
 			loggedInUserScenario("When calling DELETE on non-existing", "/api/datasources/name/12345", func(sc *scenarioContext) {
 				sc.handlerFunc = DeleteDataSourceByName
@@ -56,3 +89,80 @@ func TestDataSourcesProxy(t *testing.T) {
 		})
 	})
 }
+
+func TestDataSourceRouteAccess(t *testing.T) {
+	routes := []struct {
+		method       string
+		url          string
+		dsAdminCanDo bool
+	}{
+		{"GET", "/api/datasources", true},
+		{"POST", "/api/datasources", false},
+		{"PUT", "/api/datasources/1", true},
+		{"DELETE", "/api/datasources/1", true},
+		{"DELETE", "/api/datasources/name/prom", false},
+		{"GET", "/api/datasources/1", true},
+		{"GET", "/api/datasources/name/prom", false},
+		{"POST", "/api/datasources/1/enable-permissions", false},
+		{"POST", "/api/datasources/1/disable-permissions", false},
+		{"GET", "/api/datasources/1/permissions", true},
+		{"POST", "/api/datasources/1/permissions", true},
+		{"DELETE", "/api/datasources/1/permissions/1", true},
+	}
+
+	for _, role := range []models.RoleType{models.ROLE_VIEWER, models.ROLE_EDITOR} {
+		Convey("Given a user with the "+string(role)+" role", t, func() {
+			defer bus.ClearBusHandlers()
+
+			ds := &models.DataSource{Id: 1, OrgId: TestOrgID, Name: "prom", Type: "prometheus"}
+			bus.AddHandler("test", func(query *models.GetDataSourcesQuery) error {
+				query.Result = []*models.DataSource{ds}
+				return nil
+			})
+			bus.AddHandler("test", func(query *models.GetDataSourceByIdQuery) error {
+				query.Result = ds
+				return nil
+			})
+
+			var dsAdmin bool
+			bus.AddHandler("test", func(query *models.DatasourcesPermissionFilterQuery) error {
+				query.Result = []*models.DataSource{}
+				if dsAdmin {
+					query.Result = query.Datasources
+				}
+				return nil
+			})
+
+			sc := setupScenarioContext("/api/datasources")
+			sc.m.Use(func(c *models.ReqContext) {
+				c.IsSignedIn = true
+				c.SignedInUser = &models.SignedInUser{UserId: TestUserID, OrgId: TestOrgID, OrgRole: role}
+			})
+
+			hs := &HTTPServer{QuotaService: &quota.QuotaService{}}
+			rr := routing.NewRouteRegister()
+			rr.Group("/api/datasources", hs.registerDataSourceRoutes, middleware.ReqSignedIn)
+			rr.Register(sc.m.Router)
+
+			Convey("Should not be allowed to call any route without permission on the data source", func() {
+				for _, route := range routes {
+					sc.fakeReq(route.method, route.url).exec()
+					So(sc.resp.Code, ShouldEqual, 403)
+				}
+			})
+
+			Convey("Should only be allowed to call the routes of data source admins with admin permission", func() {
+				dsAdmin = true
+
+				for _, route := range routes {
+					sc.fakeReq(route.method, route.url).exec()
+					if route.dsAdminCanDo {
+						So(sc.resp.Code, ShouldNotEqual, 403)
+					} else {
+						So(sc.resp.Code, ShouldEqual, 403)
+					}
+				}
+			})
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package dtos
 
 import (
+	"mime/multipart"
 	"time"
 
 	"github.com/Seasheller/grafana/pkg/components/simplejson"
@@ -10,6 +11,7 @@ type DashboardMeta struct {
 	IsStarred             bool      `json:"isStarred,omitempty"`
 	IsHome                bool      `json:"isHome,omitempty"`
 	IsSnapshot            bool      `json:"isSnapshot,omitempty"`
+	IsPublic              bool      `json:"isPublic,omitempty"`
 	Type                  string    `json:"type,omitempty"`
 	CanSave               bool      `json:"canSave"`
 	CanEdit               bool      `json:"canEdit"`
@@ -53,6 +55,19 @@ type CalculateDiffTarget struct {
 	UnsavedDashboard *simplejson.Json `json:"unsavedDashboard"`
 }
 
+// MergeDashboardCommand holds an unsaved dashboard, with the id or uid of
+// the dashboard and the version its changes are based on.
+type MergeDashboardCommand struct {
+	Dashboard *simplejson.Json `json:"dashboard" binding:"Required"`
+}
+
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
 }
+
+// ImportDashboardBundleForm is the multipart form of a dashboard bundle
+// import. Options holds the bundles.ImportOptions as JSON.
+type ImportDashboardBundleForm struct {
+	Bundle  *multipart.FileHeader `form:"bundle" binding:"Required"`
+	Options string                `form:"options"`
+}
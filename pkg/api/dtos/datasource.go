@@ -8,25 +8,26 @@ import (
 )
 
 type DataSource struct {
-	Id                int64            `json:"id"`
-	OrgId             int64            `json:"orgId"`
-	Name              string           `json:"name"`
-	Type              string           `json:"type"`
-	TypeLogoUrl       string           `json:"typeLogoUrl"`
-	Access            m.DsAccess       `json:"access"`
-	Url               string           `json:"url"`
-	Password          string           `json:"password"`
-	User              string           `json:"user"`
-	Database          string           `json:"database"`
-	BasicAuth         bool             `json:"basicAuth"`
-	BasicAuthUser     string           `json:"basicAuthUser"`
-	BasicAuthPassword string           `json:"basicAuthPassword"`
-	WithCredentials   bool             `json:"withCredentials"`
-	IsDefault         bool             `json:"isDefault"`
-	JsonData          *simplejson.Json `json:"jsonData,omitempty"`
-	SecureJsonFields  map[string]bool  `json:"secureJsonFields"`
-	Version           int              `json:"version"`
-	ReadOnly          bool             `json:"readOnly"`
+	Id                 int64            `json:"id"`
+	OrgId              int64            `json:"orgId"`
+	Name               string           `json:"name"`
+	Type               string           `json:"type"`
+	TypeLogoUrl        string           `json:"typeLogoUrl"`
+	Access             m.DsAccess       `json:"access"`
+	Url                string           `json:"url"`
+	Password           string           `json:"password"`
+	User               string           `json:"user"`
+	Database           string           `json:"database"`
+	BasicAuth          bool             `json:"basicAuth"`
+	BasicAuthUser      string           `json:"basicAuthUser"`
+	BasicAuthPassword  string           `json:"basicAuthPassword"`
+	WithCredentials    bool             `json:"withCredentials"`
+	IsDefault          bool             `json:"isDefault"`
+	JsonData           *simplejson.Json `json:"jsonData,omitempty"`
+	SecureJsonFields   map[string]bool  `json:"secureJsonFields"`
+	Version            int              `json:"version"`
+	ReadOnly           bool             `json:"readOnly"`
+	PermissionsEnabled bool             `json:"permissionsEnabled"`
 }
 
 type DataSourceListItemDTO struct {
@@ -46,6 +47,18 @@ type DataSourceListItemDTO struct {
 	ReadOnly    bool             `json:"readOnly"`
 }
 
+type DataSourceHealth struct {
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+	LatencyMs int64  `json:"latencyMs"`
+}
+
+type DataSourcePermissionsDTO struct {
+	DatasourceId int64                     `json:"datasourceId"`
+	Enabled      bool                      `json:"enabled"`
+	Permissions  []*m.DataSourceAclInfoDTO `json:"permissions"`
+}
+
 type DataSourceList []DataSourceListItemDTO
 
 func (slice DataSourceList) Len() int {
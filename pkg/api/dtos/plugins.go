@@ -3,6 +3,7 @@ package dtos
 import (
 	"github.com/Seasheller/grafana/pkg/components/simplejson"
 	"github.com/Seasheller/grafana/pkg/plugins"
+	"github.com/Seasheller/grafana/pkg/plugins/signature"
 )
 
 type PluginSetting struct {
@@ -18,10 +19,12 @@ type PluginSetting struct {
 	Dependencies  *plugins.PluginDependencies `json:"dependencies"`
 	JsonData      map[string]interface{}      `json:"jsonData"`
 	DefaultNavUrl string                      `json:"defaultNavUrl"`
+	ReadOnly      bool                        `json:"readOnly"`
 
 	LatestVersion string              `json:"latestVersion"`
 	HasUpdate     bool                `json:"hasUpdate"`
 	State         plugins.PluginState `json:"state"`
+	Signature     signature.Status    `json:"signature"`
 }
 
 type PluginListItem struct {
@@ -36,6 +39,7 @@ type PluginListItem struct {
 	DefaultNavUrl string              `json:"defaultNavUrl"`
 	Category      string              `json:"category"`
 	State         plugins.PluginState `json:"state"`
+	Signature     signature.Status    `json:"signature"`
 }
 
 type PluginList []PluginListItem
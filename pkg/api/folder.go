@@ -22,9 +22,11 @@ func GetFolders(c *m.ReqContext) Response {
 
 	for _, f := range folders {
 		result = append(result, dtos.FolderSearchHit{
-			Id:    f.Id,
-			Uid:   f.Uid,
-			Title: f.Title,
+			Id:        f.Id,
+			Uid:       f.Uid,
+			Title:     f.Title,
+			ParentUid: f.ParentUid,
+			Parents:   f.Parents,
 		})
 	}
 
@@ -82,6 +84,17 @@ func UpdateFolder(c *m.ReqContext, cmd m.UpdateFolderCommand) Response {
 	return JSON(200, toFolderDto(g, cmd.Result))
 }
 
+func MoveFolder(c *m.ReqContext, cmd m.MoveFolderCommand) Response {
+	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser)
+	err := s.MoveFolder(c.Params(":uid"), &cmd)
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	g := guardian.New(cmd.Result.Id, c.OrgId, c.SignedInUser)
+	return JSON(200, toFolderDto(g, cmd.Result))
+}
+
 func DeleteFolder(c *m.ReqContext) Response {
 	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser)
 	f, err := s.DeleteFolder(c.Params(":uid"))
@@ -123,6 +136,8 @@ func toFolderDto(g guardian.DashboardGuardian, folder *m.Folder) dtos.Folder {
 		UpdatedBy: updater,
 		Updated:   folder.Updated,
 		Version:   folder.Version,
+		ParentUid: folder.ParentUid,
+		Parents:   folder.Parents,
 	}
 }
 
@@ -130,6 +145,8 @@ func toFolderError(err error) Response {
 	if err == m.ErrFolderTitleEmpty ||
 		err == m.ErrFolderSameNameExists ||
 		err == m.ErrFolderWithSameUIDExists ||
+		err == m.ErrFolderCannotBeOwnParent ||
+		err == m.ErrFolderMaxDepthExceeded ||
 		err == m.ErrDashboardTypeMismatch ||
 		err == m.ErrDashboardInvalidUid ||
 		err == m.ErrDashboardUidToLong {
@@ -148,5 +165,9 @@ func toFolderError(err error) Response {
 		return JSON(412, util.DynMap{"status": "version-mismatch", "message": m.ErrFolderVersionMismatch.Error()})
 	}
 
+	if err == m.ErrFolderContainsLibraryPanelsInUse {
+		return Error(409, err.Error(), nil)
+	}
+
 	return Error(500, "Folder API error", err)
 }
@@ -131,6 +131,67 @@ func TestFoldersApiEndpoint(t *testing.T) {
 				})
 			}
 		})
+
+		Convey("Given a correct request for moving a folder", func() {
+			cmd := m.MoveFolderCommand{
+				ParentUid: "parent",
+			}
+
+			mock := &fakeFolderService{
+				MoveFolderResult: &m.Folder{
+					Id:        2,
+					Uid:       "uid",
+					Title:     "Folder",
+					ParentId:  1,
+					ParentUid: "parent",
+					Parents:   []*m.FolderRef{{Id: 1, Uid: "parent", Title: "Parent"}},
+				},
+			}
+
+			moveFolderScenario("When calling POST on", "/api/folders/uid/move", "/api/folders/:uid/move", mock, cmd, func(sc *scenarioContext) {
+				callMoveFolder(sc)
+
+				Convey("It should return the folder with its parents", func() {
+					folder := dtos.Folder{}
+					err := json.NewDecoder(sc.resp.Body).Decode(&folder)
+					So(err, ShouldBeNil)
+					So(folder.Id, ShouldEqual, 2)
+					So(folder.ParentUid, ShouldEqual, "parent")
+					So(len(folder.Parents), ShouldEqual, 1)
+					So(folder.Parents[0].Title, ShouldEqual, "Parent")
+				})
+			})
+		})
+
+		Convey("Given incorrect requests for moving a folder", func() {
+			testCases := []struct {
+				Error              error
+				ExpectedStatusCode int
+			}{
+				{Error: m.ErrFolderCannotBeOwnParent, ExpectedStatusCode: 400},
+				{Error: m.ErrFolderMaxDepthExceeded, ExpectedStatusCode: 400},
+				{Error: m.ErrFolderSameNameExists, ExpectedStatusCode: 400},
+				{Error: m.ErrFolderAccessDenied, ExpectedStatusCode: 403},
+				{Error: m.ErrFolderNotFound, ExpectedStatusCode: 404},
+			}
+
+			cmd := m.MoveFolderCommand{
+				ParentUid: "parent",
+			}
+
+			for _, tc := range testCases {
+				mock := &fakeFolderService{
+					MoveFolderError: tc.Error,
+				}
+
+				moveFolderScenario(fmt.Sprintf("Expect '%s' error when calling POST on", tc.Error.Error()), "/api/folders/uid/move", "/api/folders/:uid/move", mock, cmd, func(sc *scenarioContext) {
+					callMoveFolder(sc)
+					if sc.resp.Code != tc.ExpectedStatusCode {
+						t.Errorf("For error '%s' expected status code %d, actual %d", tc.Error, tc.ExpectedStatusCode, sc.resp.Code)
+					}
+				})
+			}
+		})
 	})
 }
 
@@ -197,6 +258,35 @@ func updateFolderScenario(desc string, url string, routePattern string, mock *fa
 	})
 }
 
+func callMoveFolder(sc *scenarioContext) {
+	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+}
+
+func moveFolderScenario(desc string, url string, routePattern string, mock *fakeFolderService, cmd m.MoveFolderCommand, fn scenarioFunc) {
+	Convey(desc+" "+url, func() {
+		defer bus.ClearBusHandlers()
+
+		sc := setupScenarioContext(url)
+		sc.defaultHandler = Wrap(func(c *m.ReqContext) Response {
+			sc.context = c
+			sc.context.SignedInUser = &m.SignedInUser{OrgId: TestOrgID, UserId: TestUserID}
+
+			return MoveFolder(c, cmd)
+		})
+
+		origNewFolderService := dashboards.NewFolderService
+		mockFolderService(mock)
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		defer func() {
+			dashboards.NewFolderService = origNewFolderService
+		}()
+
+		fn(sc)
+	})
+}
+
 type fakeFolderService struct {
 	GetFoldersResult     []*m.Folder
 	GetFoldersError      error
@@ -208,6 +298,8 @@ type fakeFolderService struct {
 	CreateFolderError    error
 	UpdateFolderResult   *m.Folder
 	UpdateFolderError    error
+	MoveFolderResult     *m.Folder
+	MoveFolderError      error
 	DeleteFolderResult   *m.Folder
 	DeleteFolderError    error
 	DeletedFolderUids    []string
@@ -235,6 +327,11 @@ func (s *fakeFolderService) UpdateFolder(existingUID string, cmd *m.UpdateFolder
 	return s.UpdateFolderError
 }
 
+func (s *fakeFolderService) MoveFolder(uid string, cmd *m.MoveFolderCommand) error {
+	cmd.Result = s.MoveFolderResult
+	return s.MoveFolderError
+}
+
 func (s *fakeFolderService) DeleteFolder(uid string) (*m.Folder, error) {
 	s.DeletedFolderUids = append(s.DeletedFolderUids, uid)
 	return s.DeleteFolderResult, s.DeleteFolderError
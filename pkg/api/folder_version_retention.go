@@ -0,0 +1,84 @@
+package api
+
+import (
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/dashboards"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+)
+
+// GET /api/folders/:uid/version-retention
+func GetFolderVersionRetention(c *m.ReqContext) Response {
+	folder, rsp := getVersionRetentionFolder(c, false)
+	if rsp != nil {
+		return rsp
+	}
+
+	query := m.GetDashboardVersionRetentionQuery{OrgId: c.OrgId, FolderId: folder.Id}
+	if err := bus.Dispatch(&query); err != nil {
+		if err == m.ErrDashboardVersionRetentionNotFound {
+			return Error(404, err.Error(), nil)
+		}
+		return Error(500, "Failed to get dashboard version retention", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+// PUT /api/folders/:uid/version-retention
+func UpdateFolderVersionRetention(c *m.ReqContext, cmd m.SaveDashboardVersionRetentionCommand) Response {
+	folder, rsp := getVersionRetentionFolder(c, true)
+	if rsp != nil {
+		return rsp
+	}
+
+	if err := cmd.Validate(); err != nil {
+		return Error(400, err.Error(), nil)
+	}
+
+	cmd.OrgId = c.OrgId
+	cmd.FolderId = folder.Id
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return Error(500, "Failed to save dashboard version retention", err)
+	}
+
+	return JSON(200, cmd.Result)
+}
+
+// DELETE /api/folders/:uid/version-retention
+func DeleteFolderVersionRetention(c *m.ReqContext) Response {
+	folder, rsp := getVersionRetentionFolder(c, true)
+	if rsp != nil {
+		return rsp
+	}
+
+	cmd := m.DeleteDashboardVersionRetentionCommand{OrgId: c.OrgId, FolderId: folder.Id}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == m.ErrDashboardVersionRetentionNotFound {
+			return Error(404, err.Error(), nil)
+		}
+		return Error(500, "Failed to delete dashboard version retention", err)
+	}
+
+	return Success("Dashboard version retention deleted")
+}
+
+// getVersionRetentionFolder returns the folder of the request. Changing the
+// retention of a folder requires the admin permission on it.
+func getVersionRetentionFolder(c *m.ReqContext, admin bool) (*m.Folder, Response) {
+	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser)
+	folder, err := s.GetFolderByUID(c.Params(":uid"))
+	if err != nil {
+		return nil, toFolderError(err)
+	}
+
+	if admin {
+		g := guardian.New(folder.Id, c.OrgId, c.SignedInUser)
+		if canAdmin, err := g.CanAdmin(); err != nil || !canAdmin {
+			return nil, toFolderError(m.ErrFolderAccessDenied)
+		}
+	}
+
+	return folder, nil
+}
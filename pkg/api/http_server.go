@@ -23,11 +23,13 @@ import (
 	"github.com/Seasheller/grafana/pkg/models"
 	"github.com/Seasheller/grafana/pkg/plugins"
 	"github.com/Seasheller/grafana/pkg/registry"
+	"github.com/Seasheller/grafana/pkg/services/audit"
 	"github.com/Seasheller/grafana/pkg/services/datasources"
 	"github.com/Seasheller/grafana/pkg/services/hooks"
 	"github.com/Seasheller/grafana/pkg/services/login"
 	"github.com/Seasheller/grafana/pkg/services/quota"
 	"github.com/Seasheller/grafana/pkg/services/rendering"
+	"github.com/Seasheller/grafana/pkg/services/saml"
 	"github.com/Seasheller/grafana/pkg/setting"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -44,6 +46,7 @@ func init() {
 
 type ProvisioningService interface {
 	ProvisionDatasources() error
+	ProvisionPlugins() error
 	ProvisionNotifications() error
 	ProvisionDashboards() error
 	GetDashboardProvisionerResolvedPath(name string) string
@@ -68,12 +71,20 @@ type HTTPServer struct {
 	RemoteCacheService  *remotecache.RemoteCache `inject:""`
 	ProvisioningService ProvisioningService      `inject:""`
 	Login               *login.LoginService      `inject:""`
+	AuditService        *audit.AuditService      `inject:""`
+	SAMLService         *saml.SAMLService        `inject:""`
 }
 
 func (hs *HTTPServer) Init() error {
 	hs.log = log.New("http.server")
 
-	hs.streamManager = live.NewStreamManager()
+	broker, err := live.NewBroker(hs.Cfg)
+	if err != nil {
+		return err
+	}
+
+	hs.streamManager = live.NewStreamManager(hs.DatasourceCache, broker)
+	hs.streamManager.ListenToDashboardEvents(hs.Bus)
 	hs.macaron = hs.newMacaron()
 	hs.registerRoutes()
 
@@ -246,6 +257,7 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 		hs.RemoteCacheService,
 	))
 	m.Use(middleware.OrgRedirect())
+	m.Use(middleware.Audit(hs.AuditService))
 
 	// needs to be after context handler
 	if setting.EnforceDomain {
@@ -270,7 +282,7 @@ func (hs *HTTPServer) metricsEndpoint(ctx *macaron.Context) {
 	}
 
 	promhttp.
-		HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{}).
+		HandlerFor(prometheus.Gatherers{prometheus.DefaultGatherer, plugins.BackendPluginGatherer()}, promhttp.HandlerOpts{}).
 		ServeHTTP(ctx.Resp, ctx.Req.Request)
 }
 
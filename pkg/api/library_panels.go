@@ -0,0 +1,231 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+	"github.com/Seasheller/grafana/pkg/services/search"
+	"github.com/Seasheller/grafana/pkg/util"
+)
+
+func GetLibraryPanels(c *m.ReqContext) Response {
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	folderIds := make([]int64, 0)
+	for _, id := range c.QueryStrings("folderId") {
+		folderId, err := strconv.ParseInt(id, 10, 64)
+		if err == nil {
+			folderIds = append(folderIds, folderId)
+		}
+	}
+
+	query := m.GetLibraryPanelsQuery{
+		OrgId:     c.OrgId,
+		FolderIds: folderIds,
+		Name:      c.Query("query"),
+		Limit:     limit,
+	}
+
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to get library panels", err)
+	}
+
+	canViewFolder := make(map[int64]bool)
+	result := make([]*m.LibraryPanel, 0)
+	for _, lp := range query.Result {
+		canView, checked := canViewFolder[lp.FolderId]
+		if !checked {
+			var err error
+			canView, err = guardian.New(lp.FolderId, c.OrgId, c.SignedInUser).CanView()
+			if err != nil {
+				return Error(500, "Failed to check library panel permissions", err)
+			}
+			canViewFolder[lp.FolderId] = canView
+		}
+
+		if canView {
+			result = append(result, lp)
+		}
+	}
+
+	return JSON(200, result)
+}
+
+func GetLibraryPanelByUID(c *m.ReqContext) Response {
+	lp, rsp := getLibraryPanelHelper(c, false)
+	if rsp != nil {
+		return rsp
+	}
+
+	return JSON(200, lp)
+}
+
+func CreateLibraryPanel(c *m.ReqContext, cmd m.CreateLibraryPanelCommand) Response {
+	if rsp := checkLibraryPanelFolderAccess(c, cmd.FolderId, true); rsp != nil {
+		return rsp
+	}
+
+	cmd.OrgId = c.OrgId
+	cmd.UserId = c.UserId
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return toLibraryPanelError(err)
+	}
+
+	return JSON(200, cmd.Result)
+}
+
+func UpdateLibraryPanel(c *m.ReqContext, cmd m.UpdateLibraryPanelCommand) Response {
+	lp, rsp := getLibraryPanelHelper(c, true)
+	if rsp != nil {
+		return rsp
+	}
+
+	if cmd.FolderId != lp.FolderId {
+		if rsp := checkLibraryPanelFolderAccess(c, cmd.FolderId, true); rsp != nil {
+			return rsp
+		}
+	}
+
+	cmd.Uid = lp.Uid
+	cmd.OrgId = c.OrgId
+	cmd.UserId = c.UserId
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return toLibraryPanelError(err)
+	}
+
+	return JSON(200, cmd.Result)
+}
+
+func DeleteLibraryPanel(c *m.ReqContext) Response {
+	lp, rsp := getLibraryPanelHelper(c, true)
+	if rsp != nil {
+		return rsp
+	}
+
+	cmd := m.DeleteLibraryPanelCommand{OrgId: c.OrgId, Uid: lp.Uid}
+	if err := bus.Dispatch(&cmd); err != nil {
+		return toLibraryPanelError(err)
+	}
+
+	return JSON(200, util.DynMap{"message": "Library panel deleted"})
+}
+
+func GetLibraryPanelVersions(c *m.ReqContext) Response {
+	lp, rsp := getLibraryPanelHelper(c, false)
+	if rsp != nil {
+		return rsp
+	}
+
+	query := m.GetLibraryPanelVersionsQuery{OrgId: c.OrgId, Uid: lp.Uid}
+	if err := bus.Dispatch(&query); err != nil {
+		return toLibraryPanelError(err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+// GetLibraryPanelDashboards lists the dashboards using a library panel that
+// the user can view.
+func GetLibraryPanelDashboards(c *m.ReqContext) Response {
+	lp, rsp := getLibraryPanelHelper(c, false)
+	if rsp != nil {
+		return rsp
+	}
+
+	idsQuery := m.GetLibraryPanelDashboardIdsQuery{OrgId: c.OrgId, Uid: lp.Uid}
+	if err := bus.Dispatch(&idsQuery); err != nil {
+		return toLibraryPanelError(err)
+	}
+
+	if len(idsQuery.Result) == 0 {
+		return JSON(200, search.HitList{})
+	}
+
+	searchQuery := search.Query{
+		SignedInUser: c.SignedInUser,
+		OrgId:        c.OrgId,
+		DashboardIds: idsQuery.Result,
+		Limit:        5000,
+		Permission:   m.PERMISSION_VIEW,
+	}
+
+	if err := bus.Dispatch(&searchQuery); err != nil {
+		return Error(500, "Failed to get dashboards using library panel", err)
+	}
+
+	return JSON(200, searchQuery.Result)
+}
+
+func getLibraryPanelHelper(c *m.ReqContext, edit bool) (*m.LibraryPanel, Response) {
+	query := m.GetLibraryPanelQuery{OrgId: c.OrgId, Uid: c.Params(":uid")}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, toLibraryPanelError(err)
+	}
+
+	if rsp := checkLibraryPanelFolderAccess(c, query.Result.FolderId, edit); rsp != nil {
+		return nil, rsp
+	}
+
+	return query.Result, nil
+}
+
+// checkLibraryPanelFolderAccess checks that the user can view, or with edit
+// save, dashboards in the folder of a library panel.
+func checkLibraryPanelFolderAccess(c *m.ReqContext, folderId int64, edit bool) Response {
+	g := guardian.New(folderId, c.OrgId, c.SignedInUser)
+
+	var hasAccess bool
+	var err error
+	if edit {
+		hasAccess, err = g.CanSave()
+	} else {
+		hasAccess, err = g.CanView()
+	}
+
+	if err != nil {
+		return Error(500, "Failed to check library panel permissions", err)
+	}
+
+	if !hasAccess {
+		return Error(403, m.ErrLibraryPanelAccessDenied.Error(), nil)
+	}
+
+	return nil
+}
+
+func toLibraryPanelError(err error) Response {
+	if err == m.ErrLibraryPanelNameEmpty ||
+		err == m.ErrLibraryPanelModelEmpty ||
+		err == m.ErrLibraryPanelInvalidUid ||
+		err == m.ErrLibraryPanelUidTooLong ||
+		err == m.ErrLibraryPanelWithSameUIDExists ||
+		err == m.ErrLibraryPanelFolderMustBeAFolder ||
+		err == m.ErrFolderNotFound {
+		return Error(400, err.Error(), nil)
+	}
+
+	if err == m.ErrLibraryPanelNotFound {
+		return JSON(404, util.DynMap{"status": "not-found", "message": err.Error()})
+	}
+
+	if err == m.ErrLibraryPanelInUse {
+		return Error(409, err.Error(), nil)
+	}
+
+	if err == m.ErrLibraryPanelWithSameNameExists {
+		return JSON(412, util.DynMap{"status": "name-exists", "message": err.Error()})
+	}
+
+	if err == m.ErrLibraryPanelVersionMismatch {
+		return JSON(412, util.DynMap{"status": "version-mismatch", "message": err.Error()})
+	}
+
+	return Error(500, "Library panel API error", err)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLibraryPanelsApiEndpoint(t *testing.T) {
+	Convey("Given a library panel in a folder", t, func() {
+		libraryPanel := &m.LibraryPanel{Id: 1, OrgId: TestOrgID, FolderId: 2, Uid: "lp1", Name: "Service health", Model: simplejson.New()}
+
+		bus.AddHandler("test", func(query *m.GetLibraryPanelQuery) error {
+			query.Result = libraryPanel
+			return nil
+		})
+
+		bus.AddHandler("test", func(query *m.GetLibraryPanelsQuery) error {
+			query.Result = []*m.LibraryPanel{libraryPanel}
+			return nil
+		})
+
+		var deleteError error
+		bus.AddHandler("test", func(cmd *m.DeleteLibraryPanelCommand) error {
+			return deleteError
+		})
+
+		origNewGuardian := guardian.New
+		defer func() {
+			guardian.New = origNewGuardian
+		}()
+
+		Convey("When the user cannot view the folder", func() {
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: false})
+
+			loggedInUserScenarioWithRole("When calling GET on", "GET", "/api/library-panels", "/api/library-panels", m.ROLE_VIEWER, func(sc *scenarioContext) {
+				sc.handlerFunc = GetLibraryPanels
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+
+				So(sc.resp.Code, ShouldEqual, 200)
+				result, err := simplejson.NewJson(sc.resp.Body.Bytes())
+				So(err, ShouldBeNil)
+				So(len(result.MustArray()), ShouldEqual, 0)
+			})
+
+			loggedInUserScenarioWithRole("When calling GET on", "GET", "/api/library-panels/lp1", "/api/library-panels/:uid", m.ROLE_VIEWER, func(sc *scenarioContext) {
+				sc.handlerFunc = GetLibraryPanelByUID
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+
+				So(sc.resp.Code, ShouldEqual, 403)
+			})
+		})
+
+		Convey("When the user can view but not save in the folder", func() {
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true, CanSaveValue: false})
+
+			loggedInUserScenarioWithRole("When calling GET on", "GET", "/api/library-panels", "/api/library-panels", m.ROLE_VIEWER, func(sc *scenarioContext) {
+				sc.handlerFunc = GetLibraryPanels
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+
+				So(sc.resp.Code, ShouldEqual, 200)
+				result, err := simplejson.NewJson(sc.resp.Body.Bytes())
+				So(err, ShouldBeNil)
+				So(len(result.MustArray()), ShouldEqual, 1)
+			})
+
+			loggedInUserScenarioWithRole("When calling DELETE on", "DELETE", "/api/library-panels/lp1", "/api/library-panels/:uid", m.ROLE_VIEWER, func(sc *scenarioContext) {
+				sc.handlerFunc = DeleteLibraryPanel
+				sc.fakeReqWithParams("DELETE", sc.url, map[string]string{}).exec()
+
+				So(sc.resp.Code, ShouldEqual, 403)
+			})
+		})
+
+		Convey("When the user can save in the folder", func() {
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true, CanSaveValue: true})
+
+			loggedInUserScenarioWithRole("When calling DELETE on", "DELETE", "/api/library-panels/lp1", "/api/library-panels/:uid", m.ROLE_EDITOR, func(sc *scenarioContext) {
+				sc.handlerFunc = DeleteLibraryPanel
+				sc.fakeReqWithParams("DELETE", sc.url, map[string]string{}).exec()
+
+				So(sc.resp.Code, ShouldEqual, 200)
+			})
+
+			Convey("And the library panel is used by a dashboard", func() {
+				deleteError = m.ErrLibraryPanelInUse
+
+				loggedInUserScenarioWithRole("When calling DELETE on", "DELETE", "/api/library-panels/lp1", "/api/library-panels/:uid", m.ROLE_EDITOR, func(sc *scenarioContext) {
+					sc.handlerFunc = DeleteLibraryPanel
+					sc.fakeReqWithParams("DELETE", sc.url, map[string]string{}).exec()
+
+					So(sc.resp.Code, ShouldEqual, 409)
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"github.com/Seasheller/grafana/pkg/api/dtos"
+	"github.com/Seasheller/grafana/pkg/api/live"
+	"github.com/Seasheller/grafana/pkg/components/null"
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+// PushToStream publishes a packet of series to the subscribers of a stream in
+// the org of the user, if the namespace of the stream lets the user publish
+// to it.
+func (hs *HTTPServer) PushToStream(c *m.ReqContext, message dtos.StreamMessage) Response {
+	packet := &m.StreamPacket{Stream: message.Stream, Series: make([]m.StreamSeries, 0, len(message.Series))}
+
+	for _, series := range message.Series {
+		points := make(m.TimeSeriesPoints, 0, len(series.Datapoints))
+		for _, datapoint := range series.Datapoints {
+			if len(datapoint) != 2 {
+				return Error(400, "Datapoints should be [value, timestamp]", nil)
+			}
+
+			value, err := datapoint[0].Float64()
+			if err != nil {
+				return Error(400, "Datapoint value should be a number", err)
+			}
+			timestamp, err := datapoint[1].Float64()
+			if err != nil {
+				return Error(400, "Datapoint timestamp should be a number", err)
+			}
+
+			points = append(points, m.TimePoint{null.FloatFrom(value), null.FloatFrom(timestamp)})
+		}
+
+		packet.Series = append(packet.Series, m.StreamSeries{Name: series.Name, Points: points})
+	}
+
+	if err := hs.streamManager.Publish(c.SignedInUser, message.Stream, packet); err != nil {
+		switch err {
+		case live.ErrStreamNamespaceNotFound:
+			return Error(404, err.Error(), nil)
+		case live.ErrStreamPublishDenied:
+			return Error(403, err.Error(), nil)
+		}
+		return Error(500, "Failed to publish to stream", err)
+	}
+
+	return Success("Published to stream")
+}
@@ -0,0 +1,68 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Seasheller/grafana/pkg/setting"
+)
+
+const (
+	localBrokerType = "local"
+	redisBrokerType = "redis"
+)
+
+// BrokerMessage is an encoded message published to a stream of an org.
+type BrokerMessage struct {
+	OrgId  int64           `json:"orgId"`
+	Stream string          `json:"stream"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// Broker fans out the messages published to streams to the stream managers
+// of all Grafana instances, including the one that published them.
+type Broker interface {
+	Publish(msg *BrokerMessage) error
+	// Run passes the messages published by any instance to the handler until
+	// the context is done.
+	Run(ctx context.Context, handler func(*BrokerMessage)) error
+}
+
+// NewBroker returns the broker of the ha_engine in the [live] section.
+func NewBroker(cfg *setting.Cfg) (Broker, error) {
+	switch cfg.LiveHAEngine {
+	case "", localBrokerType:
+		return newLocalBroker(), nil
+	case redisBrokerType:
+		return newRedisBroker(cfg.LiveHAEngineConnStr)
+	}
+
+	return nil, fmt.Errorf("Invalid live ha_engine %q, should be %s or %s", cfg.LiveHAEngine, localBrokerType, redisBrokerType)
+}
+
+// localBroker passes the messages to the stream manager of the instance, for
+// Grafana running as a single instance.
+type localBroker struct {
+	messages chan *BrokerMessage
+}
+
+func newLocalBroker() *localBroker {
+	return &localBroker{messages: make(chan *BrokerMessage, 100)}
+}
+
+func (b *localBroker) Publish(msg *BrokerMessage) error {
+	b.messages <- msg
+	return nil
+}
+
+func (b *localBroker) Run(ctx context.Context, handler func(*BrokerMessage)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg := <-b.messages:
+			handler(msg)
+		}
+	}
+}
@@ -2,10 +2,14 @@ package live
 
 import (
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/Seasheller/grafana/pkg/components/simplejson"
 	"github.com/Seasheller/grafana/pkg/infra/log"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/setting"
 	"github.com/gorilla/websocket"
 )
 
@@ -26,22 +30,47 @@ const (
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
+	CheckOrigin:     checkOrigin,
+}
+
+// checkOrigin only lets the pages of Grafana open websockets, as they are
+// authenticated with the session cookie, which browsers send for any site.
+// Clients that are not browsers don't send an Origin.
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	if strings.EqualFold(u.Host, r.Host) {
 		return true
-	},
+	}
+
+	if appUrl, err := url.Parse(setting.AppUrl); err == nil && strings.EqualFold(u.Host, appUrl.Host) {
+		return true
+	}
+
+	return setting.Domain != "" && strings.EqualFold(u.Hostname(), setting.Domain)
 }
 
 type connection struct {
 	hub  *hub
 	ws   *websocket.Conn
 	send chan []byte
+	user *m.SignedInUser
 }
 
-func newConnection(ws *websocket.Conn, hub *hub) *connection {
+func newConnection(ws *websocket.Conn, hub *hub, user *m.SignedInUser) *connection {
 	return &connection{
 		hub:  hub,
 		send: make(chan []byte, 256),
 		ws:   ws,
+		user: user,
 	}
 }
 
@@ -71,6 +100,7 @@ func (c *connection) handleMessage(message []byte) {
 	json, err := simplejson.NewJson(message)
 	if err != nil {
 		log.Error(3, "Unreadable message on websocket channel. error: %v", err)
+		return
 	}
 
 	msgType := json.Get("action").MustString()
@@ -81,11 +111,14 @@ func (c *connection) handleMessage(message []byte) {
 		return
 	}
 
+	key := orgStreamKey(c.user.OrgId, streamName)
+
 	switch msgType {
 	case "subscribe":
-		c.hub.subChannel <- &streamSubscription{name: streamName, conn: c}
+		err := c.hub.handler.authorize(c.user, streamName)
+		c.hub.subChannel <- &streamSubscription{name: streamName, key: key, conn: c, err: err}
 	case "unsubscribe":
-		c.hub.subChannel <- &streamSubscription{name: streamName, conn: c, remove: true}
+		c.hub.subChannel <- &streamSubscription{name: streamName, key: key, conn: c, remove: true}
 	}
 
 }
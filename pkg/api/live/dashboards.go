@@ -0,0 +1,122 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/events"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+)
+
+const (
+	dashboardActionSaved   = "saved"
+	dashboardActionDeleted = "deleted"
+)
+
+var errDashboardStreamAccessDenied = errors.New("Access denied to dashboard")
+
+// dashboardNamespace holds the streams of dashboards, named dashboard/<uid>,
+// which users who can view the dashboard can subscribe to. Only Grafana
+// publishes to them, when the dashboard is saved or deleted.
+type dashboardNamespace struct{}
+
+func (ns *dashboardNamespace) CanSubscribe(user *m.SignedInUser, path string) error {
+	query := m.GetDashboardQuery{Uid: path, OrgId: user.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return err
+	}
+
+	canView, err := guardian.New(query.Result.Id, user.OrgId, user).CanView()
+	if err != nil {
+		return err
+	}
+	if !canView {
+		return errDashboardStreamAccessDenied
+	}
+
+	return nil
+}
+
+func (ns *dashboardNamespace) CanPublish(user *m.SignedInUser, path string) error {
+	return ErrStreamPublishDenied
+}
+
+// DashboardEvent is the message published to the stream of a dashboard when
+// it is saved or deleted.
+type DashboardEvent struct {
+	Action    string    `json:"action"`
+	Uid       string    `json:"uid"`
+	Title     string    `json:"title,omitempty"`
+	Version   int       `json:"version,omitempty"`
+	UserId    int64     `json:"userId,omitempty"`
+	Login     string    `json:"login,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type dashboardNotification struct {
+	orgId int64
+	event *DashboardEvent
+}
+
+// ListenToDashboardEvents publishes the saves and deletes of dashboards to
+// their streams. The events are queued, so saving a dashboard never waits on
+// the broker.
+func (sm *StreamManager) ListenToDashboardEvents(b bus.Bus) {
+	b.AddEventListener(sm.onDashboardSaved)
+	b.AddEventListener(sm.onDashboardDeleted)
+}
+
+func (sm *StreamManager) onDashboardSaved(e *events.DashboardSaved) error {
+	sm.queueDashboardNotification(e.OrgId, &DashboardEvent{
+		Action:    dashboardActionSaved,
+		Uid:       e.Uid,
+		Title:     e.Title,
+		Version:   e.Version,
+		UserId:    e.UserId,
+		Timestamp: e.Timestamp,
+	})
+	return nil
+}
+
+func (sm *StreamManager) onDashboardDeleted(e *events.DashboardDeleted) error {
+	sm.queueDashboardNotification(e.OrgId, &DashboardEvent{
+		Action:    dashboardActionDeleted,
+		Uid:       e.Uid,
+		Timestamp: e.Timestamp,
+	})
+	return nil
+}
+
+func (sm *StreamManager) queueDashboardNotification(orgId int64, event *DashboardEvent) {
+	select {
+	case sm.dashboardNotifications <- &dashboardNotification{orgId: orgId, event: event}:
+	default:
+		sm.log.Warn("Dropped dashboard notification, queue is full", "uid", event.Uid, "action", event.Action)
+	}
+}
+
+// publishDashboardNotifications publishes the queued events to the streams
+// of the dashboards until the context is done.
+func (sm *StreamManager) publishDashboardNotifications(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-sm.dashboardNotifications:
+			if n.event.UserId > 0 {
+				query := m.GetUserByIdQuery{Id: n.event.UserId}
+				if err := bus.Dispatch(&query); err == nil {
+					n.event.Login = query.Result.Login
+				}
+			}
+
+			stream := dashboardNamespaceName + "/" + n.event.Uid
+			if err := sm.PublishToOrg(n.orgId, stream, n.event); err != nil {
+				sm.log.Error("Failed to publish dashboard notification", "stream", stream, "error", err)
+			}
+		}
+	}
+}
@@ -2,36 +2,57 @@ package live
 
 import (
 	"context"
+	"fmt"
 
-	"github.com/Seasheller/grafana/pkg/api/dtos"
 	"github.com/Seasheller/grafana/pkg/components/simplejson"
 	"github.com/Seasheller/grafana/pkg/infra/log"
+	m "github.com/Seasheller/grafana/pkg/models"
 )
 
+// streamHandler authorizes the subscriptions to streams, and runs the streams
+// Grafana produces while they have subscribers.
+type streamHandler interface {
+	authorize(user *m.SignedInUser, stream string) error
+	start(sub *streamSubscription)
+	stop(key string)
+}
+
 type hub struct {
 	log         log.Logger
 	connections map[*connection]bool
-	streams     map[string]map[*connection]bool
+	// subscribers of the streams by org stream key
+	streams map[string]map[*connection]bool
+	handler streamHandler
 
 	register      chan *connection
 	unregister    chan *connection
-	streamChannel chan *dtos.StreamMessage
+	streamChannel chan *streamMessage
 	subChannel    chan *streamSubscription
 }
 
 type streamSubscription struct {
 	conn   *connection
 	name   string
+	key    string
 	remove bool
+	err    error
+}
+
+// streamMessage is an encoded message to the subscribers of the stream with
+// the org stream key.
+type streamMessage struct {
+	key  string
+	body []byte
 }
 
-func newHub() *hub {
+func newHub(handler streamHandler) *hub {
 	return &hub{
 		connections:   make(map[*connection]bool),
 		streams:       make(map[string]map[*connection]bool),
+		handler:       handler,
 		register:      make(chan *connection),
 		unregister:    make(chan *connection),
-		streamChannel: make(chan *dtos.StreamMessage),
+		streamChannel: make(chan *streamMessage),
 		subChannel:    make(chan *streamSubscription),
 		log:           log.New("stream.hub"),
 	}
@@ -49,51 +70,102 @@ func (h *hub) run(ctx context.Context) {
 		case c := <-h.unregister:
 			if _, ok := h.connections[c]; ok {
 				h.log.Info("Closing connection", "total", len(h.connections))
-				delete(h.connections, c)
-				close(c.send)
+				h.removeConnection(c)
 			}
 			// hand stream subscriptions
 		case sub := <-h.subChannel:
 			h.log.Info("Subscribing", "channel", sub.name, "remove", sub.remove)
-			subscribers, exists := h.streams[sub.name]
+
+			if sub.err != nil {
+				h.log.Info("Subscription denied", "channel", sub.name, "error", sub.err)
+				h.sendTo(sub.conn, encodeStreamError(sub.name, sub.err))
+				continue
+			}
 
 			// handle unsubscribe
-			if exists && sub.remove {
-				delete(subscribers, sub.conn)
+			if sub.remove {
+				h.removeSubscriber(sub.key, sub.conn)
+				continue
+			}
+
+			if _, ok := h.connections[sub.conn]; !ok {
 				continue
 			}
 
+			subscribers, exists := h.streams[sub.key]
 			if !exists {
 				subscribers = make(map[*connection]bool)
-				h.streams[sub.name] = subscribers
+				h.streams[sub.key] = subscribers
 			}
 
 			subscribers[sub.conn] = true
+			if !exists {
+				h.handler.start(sub)
+			}
 
 			// handle stream messages
 		case message := <-h.streamChannel:
-			subscribers, exists := h.streams[message.Stream]
+			subscribers, exists := h.streams[message.key]
 			if !exists || len(subscribers) == 0 {
-				h.log.Info("Message to stream without subscribers", "stream", message.Stream)
+				h.log.Debug("Message to stream without subscribers", "stream", message.key)
 				continue
 			}
 
-			messageBytes, _ := simplejson.NewFromAny(message).Encode()
 			for sub := range subscribers {
-				// check if channel is open
-				if _, ok := h.connections[sub]; !ok {
-					delete(subscribers, sub)
-					continue
-				}
-
-				select {
-				case sub.send <- messageBytes:
-				default:
-					close(sub.send)
-					delete(h.connections, sub)
-					delete(subscribers, sub)
-				}
+				h.sendTo(sub, message.body)
 			}
 		}
 	}
 }
+
+// sendTo sends the message to the connection, which is closed when it can't
+// keep up.
+func (h *hub) sendTo(c *connection, body []byte) {
+	if _, ok := h.connections[c]; !ok {
+		return
+	}
+
+	select {
+	case c.send <- body:
+	default:
+		h.removeConnection(c)
+	}
+}
+
+func (h *hub) removeConnection(c *connection) {
+	delete(h.connections, c)
+	close(c.send)
+
+	for key := range h.streams {
+		h.removeSubscriber(key, c)
+	}
+}
+
+// removeSubscriber removes the connection from the subscribers of the stream,
+// and stops the stream when it was the last one.
+func (h *hub) removeSubscriber(key string, c *connection) {
+	subscribers, exists := h.streams[key]
+	if !exists {
+		return
+	}
+
+	delete(subscribers, c)
+	if len(subscribers) == 0 {
+		delete(h.streams, key)
+		h.handler.stop(key)
+	}
+}
+
+// orgStreamKey scopes the name of a stream to the org.
+func orgStreamKey(orgId int64, stream string) string {
+	return fmt.Sprintf("%d/%s", orgId, stream)
+}
+
+func encodeStreamError(stream string, err error) []byte {
+	body, _ := simplejson.NewFromAny(map[string]interface{}{
+		"stream": stream,
+		"error":  err.Error(),
+	}).Encode()
+
+	return body
+}
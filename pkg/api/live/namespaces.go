@@ -0,0 +1,89 @@
+package live
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/datasources"
+)
+
+var (
+	ErrStreamNamespaceNotFound = errors.New("Stream namespace not found")
+	ErrStreamPublishDenied     = errors.New("Not allowed to publish to stream")
+	ErrStreamSubscribeDenied   = errors.New("Not allowed to subscribe to stream")
+	errInvalidDatasourceStream = errors.New("Stream name should be ds/<datasource id>/<path>")
+)
+
+// StreamNamespace decides who can subscribe and publish to the streams named
+// <namespace>/<path> in the org of the user.
+type StreamNamespace interface {
+	CanSubscribe(user *m.SignedInUser, path string) error
+	CanPublish(user *m.SignedInUser, path string) error
+}
+
+// datasourceNamespace holds the streams of datasources, named
+// ds/<datasource id>/<path>, which users who can query the datasource can
+// subscribe to. Only the datasource publishes to them.
+type datasourceNamespace struct {
+	datasourceCache datasources.CacheService
+}
+
+func (ns *datasourceNamespace) CanSubscribe(user *m.SignedInUser, path string) error {
+	datasourceId, _, err := parseDatasourceStreamPath(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = ns.datasourceCache.GetDatasource(datasourceId, user, false)
+	return err
+}
+
+func (ns *datasourceNamespace) CanPublish(user *m.SignedInUser, path string) error {
+	return ErrStreamPublishDenied
+}
+
+// messageNamespace holds the streams the members of the org can subscribe
+// to, and editors and admins can publish to. Anonymous users are not members.
+type messageNamespace struct{}
+
+func (ns *messageNamespace) CanSubscribe(user *m.SignedInUser, path string) error {
+	if user.IsAnonymous || !user.OrgRole.IsValid() {
+		return ErrStreamSubscribeDenied
+	}
+	return nil
+}
+
+func (ns *messageNamespace) CanPublish(user *m.SignedInUser, path string) error {
+	if !user.HasRole(m.ROLE_EDITOR) {
+		return ErrStreamPublishDenied
+	}
+	return nil
+}
+
+// parseStreamName splits the name of a stream into its namespace and path.
+func parseStreamName(stream string) (string, string) {
+	parts := strings.SplitN(stream, "/", 2)
+	if len(parts) != 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// parseDatasourceStreamPath splits the path of a datasource stream into the
+// id of the datasource and the path that tells the datasource what to
+// stream.
+func parseDatasourceStreamPath(path string) (int64, string, error) {
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return 0, "", errInvalidDatasourceStream
+	}
+
+	datasourceId, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", errInvalidDatasourceStream
+	}
+
+	return datasourceId, parts[1], nil
+}
@@ -0,0 +1,95 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/infra/remotecache"
+	redis "gopkg.in/redis.v2"
+)
+
+const (
+	redisBrokerChannel = "grafana.live"
+	// how long to wait for a message before checking if the broker stopped
+	redisBrokerReceiveTimeout = time.Second
+	redisBrokerRetryInterval  = time.Second * 5
+)
+
+// redisBroker fans out the messages through the pub/sub of a redis server.
+type redisBroker struct {
+	log    log.Logger
+	client *redis.Client
+}
+
+func newRedisBroker(connStr string) (*redisBroker, error) {
+	client, err := remotecache.NewRedisClient(connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisBroker{log: log.New("live.broker.redis"), client: client}, nil
+}
+
+func (b *redisBroker) Publish(msg *BrokerMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return b.client.Publish(redisBrokerChannel, string(data)).Err()
+}
+
+// Run subscribes to the channel again when the connection to the redis server
+// fails. Messages published while it is not subscribed are lost.
+func (b *redisBroker) Run(ctx context.Context, handler func(*BrokerMessage)) error {
+	for {
+		err := b.receive(ctx, handler)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		b.log.Error("Failed to receive messages, subscribing again", "error", err, "retryIn", redisBrokerRetryInterval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(redisBrokerRetryInterval):
+		}
+	}
+}
+
+func (b *redisBroker) receive(ctx context.Context, handler func(*BrokerMessage)) error {
+	pubSub := b.client.PubSub()
+	defer pubSub.Close()
+
+	if err := pubSub.Subscribe(redisBrokerChannel); err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		reply, err := pubSub.ReceiveTimeout(redisBrokerReceiveTimeout)
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		message, ok := reply.(*redis.Message)
+		if !ok {
+			continue
+		}
+
+		msg := &BrokerMessage{}
+		if err := json.Unmarshal([]byte(message.Payload), msg); err != nil {
+			b.log.Error("Failed to decode message", "error", err)
+			continue
+		}
+
+		handler(msg)
+	}
+
+	return nil
+}
@@ -2,102 +2,307 @@ package live
 
 import (
 	"context"
-	"net/http"
+	"encoding/json"
+	"sort"
 	"sync"
 
 	"github.com/Seasheller/grafana/pkg/components/simplejson"
 	"github.com/Seasheller/grafana/pkg/infra/log"
 	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/datasources"
+	"github.com/Seasheller/grafana/pkg/tsdb"
 )
 
+const (
+	// datasourceNamespaceName is the namespace of the streams of datasources,
+	// named ds/<datasource id>/<path>. The path tells the datasource what to
+	// stream.
+	datasourceNamespaceName = "ds"
+	// messageNamespaceName is the namespace of the streams messages are
+	// published to through the HTTP API.
+	messageNamespaceName = "stream"
+	// dashboardNamespaceName is the namespace of the streams of dashboards,
+	// named dashboard/<uid>, that notify their viewers of saves and deletes.
+	dashboardNamespaceName = "dashboard"
+
+	dashboardNotificationQueueSize = 100
+)
+
+// StreamManager serves the Grafana Live websocket. The streams are scoped to
+// the org of the subscribers, and named <namespace>/<path>, where the
+// namespace decides who can subscribe and publish to them.
+//
+// It runs the streams of datasources from the moment the first client of an
+// org subscribes to them until the last client left, and pushes their packets
+// to the subscribers connected to this instance. Messages published to
+// streams go through the broker, so they reach the subscribers connected to
+// any instance.
 type StreamManager struct {
-	log           log.Logger
-	streams       map[string]*Stream
-	streamRWMutex *sync.RWMutex
-	hub           *hub
+	log             log.Logger
+	hub             *hub
+	broker          Broker
+	datasourceCache datasources.CacheService
+	handleStream    tsdb.HandleStreamFunc
+	ctx             context.Context
+
+	dashboardNotifications chan *dashboardNotification
+
+	namespacesMu sync.RWMutex
+	namespaces   map[string]StreamNamespace
+
+	producersMu sync.Mutex
+	producers   map[string]*streamProducer
+}
+
+type streamProducer struct {
+	cancel context.CancelFunc
 }
 
-func NewStreamManager() *StreamManager {
-	return &StreamManager{
-		hub:           newHub(),
-		log:           log.New("stream.manager"),
-		streams:       make(map[string]*Stream),
-		streamRWMutex: &sync.RWMutex{},
+func NewStreamManager(datasourceCache datasources.CacheService, broker Broker) *StreamManager {
+	sm := &StreamManager{
+		log:             log.New("stream.manager"),
+		broker:          broker,
+		datasourceCache: datasourceCache,
+		handleStream:    tsdb.HandleStream,
+		ctx:             context.Background(),
+		namespaces:      make(map[string]StreamNamespace),
+		producers:       make(map[string]*streamProducer),
+
+		dashboardNotifications: make(chan *dashboardNotification, dashboardNotificationQueueSize),
 	}
+	sm.hub = newHub(sm)
+
+	sm.RegisterNamespace(datasourceNamespaceName, &datasourceNamespace{datasourceCache: datasourceCache})
+	sm.RegisterNamespace(messageNamespaceName, &messageNamespace{})
+	sm.RegisterNamespace(dashboardNamespaceName, &dashboardNamespace{})
+
+	return sm
 }
 
 func (sm *StreamManager) Run(context context.Context) {
 	log.Info("Initializing Stream Manager")
+	sm.ctx = context
 
 	go func() {
 		sm.hub.run(context)
 		log.Info("Stopped Stream Manager")
 	}()
+
+	go func() {
+		if err := sm.broker.Run(context, sm.deliver); err != nil && context.Err() == nil {
+			sm.log.Error("Stream broker stopped", "error", err)
+		}
+	}()
+
+	go sm.publishDashboardNotifications(context)
+}
+
+// RegisterNamespace adds the namespace of streams named <name>/<path>.
+func (sm *StreamManager) RegisterNamespace(name string, ns StreamNamespace) {
+	sm.namespacesMu.Lock()
+	defer sm.namespacesMu.Unlock()
+
+	sm.namespaces[name] = ns
+}
+
+func (sm *StreamManager) getNamespace(stream string) (StreamNamespace, string, error) {
+	name, path := parseStreamName(stream)
+
+	sm.namespacesMu.RLock()
+	defer sm.namespacesMu.RUnlock()
+
+	ns, exists := sm.namespaces[name]
+	if !exists || path == "" {
+		return nil, "", ErrStreamNamespaceNotFound
+	}
+
+	return ns, path, nil
 }
 
-func (sm *StreamManager) Serve(w http.ResponseWriter, r *http.Request) {
+func (sm *StreamManager) Serve(c *m.ReqContext) {
 	sm.log.Info("Upgrading to WebSocket")
 
-	ws, err := upgrader.Upgrade(w, r, nil)
+	ws, err := upgrader.Upgrade(c.Resp, c.Req.Request, nil)
 	if err != nil {
 		sm.log.Error("Failed to upgrade connection to WebSocket", "error", err)
 		return
 	}
 
-	c := newConnection(ws, sm.hub)
-	sm.hub.register <- c
+	conn := newConnection(ws, sm.hub, c.SignedInUser)
+	sm.hub.register <- conn
 
-	go c.writePump()
-	c.readPump()
+	go conn.writePump()
+	conn.readPump()
 }
 
-func (s *StreamManager) GetStreamList() m.StreamList {
-	list := make(m.StreamList, 0)
+// GetStreamList returns the streams of datasources that are running, by
+// their name scoped to the org, <org id>/<stream>.
+func (sm *StreamManager) GetStreamList() m.StreamList {
+	sm.producersMu.Lock()
+	defer sm.producersMu.Unlock()
 
-	for _, stream := range s.streams {
+	list := make(m.StreamList, 0, len(sm.producers))
+	for key := range sm.producers {
 		list = append(list, &m.StreamInfo{
-			Name: stream.name,
+			Name: key,
 		})
 	}
 
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
 	return list
 }
 
-func (s *StreamManager) Push(packet *m.StreamPacket) {
-	stream, exist := s.streams[packet.Stream]
+// Publish publishes the message, which is encoded as a JSON object with the
+// stream set, to the subscribers of the stream in the org on all instances,
+// if the user can publish to the stream.
+func (sm *StreamManager) Publish(user *m.SignedInUser, stream string, message interface{}) error {
+	ns, path, err := sm.getNamespace(stream)
+	if err != nil {
+		return err
+	}
 
-	if !exist {
-		s.log.Info("Creating metric stream", "name", packet.Stream)
-		stream = NewStream(packet.Stream)
-		s.streams[stream.name] = stream
+	if err := ns.CanPublish(user, path); err != nil {
+		return err
 	}
 
-	stream.Push(packet)
+	return sm.PublishToOrg(user.OrgId, stream, message)
 }
 
-type Stream struct {
-	subscribers []*connection
-	name        string
+// PublishToOrg publishes the message to the subscribers of the stream in the
+// org on all instances, for messages Grafana publishes itself.
+func (sm *StreamManager) PublishToOrg(orgId int64, stream string, message interface{}) error {
+	body, err := encodeStreamMessage(stream, message)
+	if err != nil {
+		return err
+	}
+
+	return sm.broker.Publish(&BrokerMessage{OrgId: orgId, Stream: stream, Body: body})
+}
+
+// deliver passes a message from the broker to the subscribers connected to
+// this instance.
+func (sm *StreamManager) deliver(msg *BrokerMessage) {
+	sm.send(sm.ctx, &streamMessage{key: orgStreamKey(msg.OrgId, msg.Stream), body: msg.Body})
 }
 
-func NewStream(name string) *Stream {
-	return &Stream{
-		subscribers: make([]*connection, 0),
-		name:        name,
+func (sm *StreamManager) push(ctx context.Context, key string, packet *m.StreamPacket) error {
+	body, err := simplejson.NewFromAny(packet).Encode()
+	if err != nil {
+		return err
 	}
+
+	return sm.send(ctx, &streamMessage{key: key, body: body})
 }
 
-func (s *Stream) Push(packet *m.StreamPacket) {
+func (sm *StreamManager) send(ctx context.Context, message *streamMessage) error {
+	select {
+	case sm.hub.streamChannel <- message:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
-	messageBytes, _ := simplejson.NewFromAny(packet).Encode()
+// authorize asks the namespace of the stream if the user can subscribe to it.
+func (sm *StreamManager) authorize(user *m.SignedInUser, stream string) error {
+	ns, path, err := sm.getNamespace(stream)
+	if err != nil {
+		return err
+	}
 
-	for _, sub := range s.subscribers {
-		// check if channel is open
-		// if _, ok := h.connections[sub]; !ok {
-		// 	delete(s.subscribers, sub)
-		// 	continue
-		// }
+	return ns.CanSubscribe(user, path)
+}
+
+// start starts the stream of a datasource on behalf of its first subscriber
+// in the org.
+func (sm *StreamManager) start(sub *streamSubscription) {
+	name, path := parseStreamName(sub.name)
+	if name != datasourceNamespaceName {
+		return
+	}
+
+	datasourceId, path, err := parseDatasourceStreamPath(path)
+	if err != nil {
+		return
+	}
 
-		sub.send <- messageBytes
+	sm.producersMu.Lock()
+	defer sm.producersMu.Unlock()
+
+	if _, exists := sm.producers[sub.key]; exists {
+		return
 	}
+
+	ctx, cancel := context.WithCancel(sm.ctx)
+	producer := &streamProducer{cancel: cancel}
+	sm.producers[sub.key] = producer
+
+	sm.log.Info("Starting stream", "stream", sub.key)
+	req := &tsdb.StreamRequest{Stream: sub.name, Path: path, User: sub.conn.user}
+	go sm.runStream(ctx, producer, sub.key, req, datasourceId)
+}
+
+// stop stops the stream of a datasource once its last subscriber in the org
+// left.
+func (sm *StreamManager) stop(key string) {
+	sm.producersMu.Lock()
+	defer sm.producersMu.Unlock()
+
+	if producer, exists := sm.producers[key]; exists {
+		sm.log.Info("Stopping stream", "stream", key)
+		producer.cancel()
+		delete(sm.producers, key)
+	}
+}
+
+func (sm *StreamManager) runStream(ctx context.Context, producer *streamProducer, key string, req *tsdb.StreamRequest, datasourceId int64) {
+	defer func() {
+		producer.cancel()
+
+		sm.producersMu.Lock()
+		if sm.producers[key] == producer {
+			delete(sm.producers, key)
+		}
+		sm.producersMu.Unlock()
+	}()
+
+	ds, err := sm.datasourceCache.GetDatasource(datasourceId, req.User, false)
+	if err == nil {
+		err = sm.handleStream(ctx, ds, req, func(packet *m.StreamPacket) error {
+			packet.Stream = req.Stream
+			return sm.push(ctx, key, packet)
+		})
+	}
+
+	// streams that end are not restarted until all clients unsubscribed and
+	// one subscribes again
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		sm.log.Error("Stream failed", "stream", key, "error", err)
+		sm.send(ctx, &streamMessage{key: key, body: encodeStreamError(req.Stream, err)})
+		return
+	}
+	sm.log.Info("Stream ended", "stream", key)
+}
+
+// encodeStreamMessage encodes the message, which should encode to a JSON
+// object, with the stream set.
+func encodeStreamMessage(stream string, message interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := simplejson.NewJson(data)
+	if err != nil {
+		return nil, err
+	}
+
+	js.Set("stream", stream)
+	return js.Encode()
 }
@@ -0,0 +1,290 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/events"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+	"github.com/Seasheller/grafana/pkg/setting"
+	"github.com/Seasheller/grafana/pkg/tsdb"
+	. "github.com/smartystreets/goconvey/convey"
+	macaron "gopkg.in/macaron.v1"
+)
+
+type fakeDatasourceCache struct{}
+
+func (c *fakeDatasourceCache) GetDatasource(datasourceID int64, user *m.SignedInUser, skipCache bool) (*m.DataSource, error) {
+	if datasourceID != 1 {
+		return nil, m.ErrDataSourceAccessDenied
+	}
+	return &m.DataSource{Id: 1, Type: "testdata"}, nil
+}
+
+func TestStreamManager(t *testing.T) {
+	Convey("Given a running stream manager", t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		started := make(chan *tsdb.StreamRequest, 10)
+		stopped := make(chan string, 10)
+
+		sm := NewStreamManager(&fakeDatasourceCache{}, newLocalBroker())
+		sm.handleStream = func(ctx context.Context, ds *m.DataSource, req *tsdb.StreamRequest, send func(*m.StreamPacket) error) error {
+			started <- req
+			defer func() { stopped <- req.Stream }()
+
+			for {
+				if err := send(&m.StreamPacket{Series: []m.StreamSeries{{Name: req.Path}}}); err != nil {
+					return err
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(time.Millisecond * 5):
+				}
+			}
+		}
+		sm.Run(ctx)
+
+		user := &m.SignedInUser{UserId: 1, OrgId: 1, OrgRole: m.ROLE_VIEWER}
+		conn := newConnection(nil, sm.hub, user)
+		sm.hub.register <- conn
+
+		subscribe := func(c *connection, stream string) {
+			key := orgStreamKey(c.user.OrgId, stream)
+			sm.hub.subChannel <- &streamSubscription{name: stream, key: key, conn: c, err: sm.authorize(c.user, stream)}
+		}
+
+		Convey("When subscribing to the stream of a datasource", func() {
+			subscribe(conn, "ds/1/random-walk")
+
+			Convey("Should start the stream and push its packets", func() {
+				req := <-started
+				So(req.Path, ShouldEqual, "random-walk")
+				So(req.User, ShouldEqual, user)
+
+				packet := &m.StreamPacket{}
+				So(json.Unmarshal(<-conn.send, packet), ShouldBeNil)
+				So(packet.Stream, ShouldEqual, "ds/1/random-walk")
+				So(packet.Series[0].Name, ShouldEqual, "random-walk")
+				So(sm.GetStreamList(), ShouldHaveLength, 1)
+			})
+
+			Convey("Should start it once for all subscribers", func() {
+				other := newConnection(nil, sm.hub, user)
+				sm.hub.register <- other
+				subscribe(other, "ds/1/random-walk")
+
+				<-started
+				<-other.send
+				So(started, ShouldHaveLength, 0)
+
+				Convey("And stop it when the last one left", func() {
+					sm.hub.subChannel <- &streamSubscription{name: "ds/1/random-walk", key: "1/ds/1/random-walk", conn: conn, remove: true}
+					sm.hub.unregister <- other
+
+					So(<-stopped, ShouldEqual, "ds/1/random-walk")
+					So(sm.GetStreamList(), ShouldHaveLength, 0)
+				})
+			})
+		})
+
+		Convey("When subscribing to the stream of a datasource the user can't query", func() {
+			subscribe(conn, "ds/2/random-walk")
+
+			Convey("Should send an error and not start the stream", func() {
+				message := map[string]string{}
+				So(json.Unmarshal(<-conn.send, &message), ShouldBeNil)
+				So(message["stream"], ShouldEqual, "ds/2/random-walk")
+				So(message["error"], ShouldEqual, m.ErrDataSourceAccessDenied.Error())
+				So(started, ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When subscribing to a stream messages are published to", func() {
+			subscribe(conn, "stream/chat")
+
+			otherOrg := newConnection(nil, sm.hub, &m.SignedInUser{UserId: 2, OrgId: 2, OrgRole: m.ROLE_ADMIN})
+			sm.hub.register <- otherOrg
+			subscribe(otherOrg, "stream/chat")
+
+			Convey("Should only deliver the messages published in the org of the subscriber", func() {
+				editor := &m.SignedInUser{UserId: 3, OrgId: 1, OrgRole: m.ROLE_EDITOR}
+				err := sm.Publish(editor, "stream/chat", map[string]string{"text": "hello"})
+				So(err, ShouldBeNil)
+
+				message := map[string]string{}
+				So(json.Unmarshal(<-conn.send, &message), ShouldBeNil)
+				So(message["stream"], ShouldEqual, "stream/chat")
+				So(message["text"], ShouldEqual, "hello")
+				So(otherOrg.send, ShouldHaveLength, 0)
+			})
+
+			Convey("Should not let viewers publish", func() {
+				viewer := &m.SignedInUser{UserId: 4, OrgId: 1, OrgRole: m.ROLE_VIEWER}
+				err := sm.Publish(viewer, "stream/chat", map[string]string{"text": "hello"})
+				So(err, ShouldEqual, ErrStreamPublishDenied)
+			})
+
+			Convey("Should not let anyone publish to the streams of datasources", func() {
+				admin := &m.SignedInUser{UserId: 5, OrgId: 1, OrgRole: m.ROLE_ADMIN}
+				err := sm.Publish(admin, "ds/1/random-walk", map[string]string{"text": "hello"})
+				So(err, ShouldEqual, ErrStreamPublishDenied)
+			})
+		})
+
+		Convey("When an anonymous user subscribes to a stream messages are published to", func() {
+			anonymous := newConnection(nil, sm.hub, &m.SignedInUser{OrgId: 1, OrgRole: m.ROLE_VIEWER, IsAnonymous: true})
+			sm.hub.register <- anonymous
+			subscribe(anonymous, "stream/chat")
+
+			Convey("Should send an error", func() {
+				message := map[string]string{}
+				So(json.Unmarshal(<-anonymous.send, &message), ShouldBeNil)
+				So(message["stream"], ShouldEqual, "stream/chat")
+				So(message["error"], ShouldEqual, ErrStreamSubscribeDenied.Error())
+			})
+		})
+
+		Convey("When subscribing to the stream of a dashboard", func() {
+			bus.AddHandler("test", func(query *m.GetDashboardQuery) error {
+				if query.Uid != "abc" || query.OrgId != 1 {
+					return m.ErrDashboardNotFound
+				}
+				query.Result = &m.Dashboard{Id: 1, Uid: "abc", OrgId: 1}
+				return nil
+			})
+			bus.AddHandler("test", func(query *m.GetUserByIdQuery) error {
+				query.Result = &m.User{Id: query.Id, Login: "editor"}
+				return nil
+			})
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+
+			subscribe(conn, "dashboard/abc")
+
+			Convey("Should notify the subscriber when the dashboard is saved", func() {
+				err := sm.onDashboardSaved(&events.DashboardSaved{Id: 1, Uid: "abc", OrgId: 1, Version: 3, UserId: 2})
+				So(err, ShouldBeNil)
+
+				event := &DashboardEvent{}
+				So(json.Unmarshal(<-conn.send, event), ShouldBeNil)
+				So(event.Action, ShouldEqual, "saved")
+				So(event.Uid, ShouldEqual, "abc")
+				So(event.Version, ShouldEqual, 3)
+				So(event.UserId, ShouldEqual, 2)
+				So(event.Login, ShouldEqual, "editor")
+			})
+
+			Convey("Should notify the subscriber when the dashboard is deleted", func() {
+				err := sm.onDashboardDeleted(&events.DashboardDeleted{Id: 1, Uid: "abc", OrgId: 1})
+				So(err, ShouldBeNil)
+
+				message := map[string]string{}
+				So(json.Unmarshal(<-conn.send, &message), ShouldBeNil)
+				So(message["stream"], ShouldEqual, "dashboard/abc")
+				So(message["action"], ShouldEqual, "deleted")
+			})
+
+			Convey("Should not let anyone publish to it", func() {
+				admin := &m.SignedInUser{UserId: 5, OrgId: 1, OrgRole: m.ROLE_ADMIN}
+				err := sm.Publish(admin, "dashboard/abc", map[string]string{"text": "hello"})
+				So(err, ShouldEqual, ErrStreamPublishDenied)
+			})
+		})
+
+		Convey("When subscribing to the stream of a dashboard the user can't view", func() {
+			bus.AddHandler("test", func(query *m.GetDashboardQuery) error {
+				query.Result = &m.Dashboard{Id: 1, Uid: "abc", OrgId: 1}
+				return nil
+			})
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: false})
+
+			subscribe(conn, "dashboard/abc")
+
+			Convey("Should send an error", func() {
+				message := map[string]string{}
+				So(json.Unmarshal(<-conn.send, &message), ShouldBeNil)
+				So(message["stream"], ShouldEqual, "dashboard/abc")
+				So(message["error"], ShouldEqual, errDashboardStreamAccessDenied.Error())
+			})
+		})
+
+		Convey("When subscribing to a stream of an unknown namespace", func() {
+			subscribe(conn, "unknown/chat")
+
+			Convey("Should send an error", func() {
+				message := map[string]string{}
+				So(json.Unmarshal(<-conn.send, &message), ShouldBeNil)
+				So(message["error"], ShouldEqual, ErrStreamNamespaceNotFound.Error())
+			})
+		})
+
+		Convey("When subscribing to an invalid datasource stream", func() {
+			subscribe(conn, "ds/random-walk")
+
+			Convey("Should send an error", func() {
+				message := map[string]string{}
+				So(json.Unmarshal(<-conn.send, &message), ShouldBeNil)
+				So(message["error"], ShouldEqual, errInvalidDatasourceStream.Error())
+			})
+		})
+	})
+}
+
+func TestWebsocketOrigin(t *testing.T) {
+	Convey("When opening a websocket", t, func() {
+		setting.AppUrl = "https://grafana.example.com/"
+		setting.Domain = "grafana.example.com"
+
+		newRequest := func(origin string) *http.Request {
+			req := httptest.NewRequest("GET", "http://localhost:3000/ws", nil)
+			req.Header.Set("Connection", "upgrade")
+			req.Header.Set("Upgrade", "websocket")
+			req.Header.Set("Sec-Websocket-Version", "13")
+			req.Header.Set("Sec-Websocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+			if origin != "" {
+				req.Header.Set("Origin", origin)
+			}
+			return req
+		}
+
+		Convey("Should refuse the pages of other sites", func() {
+			sm := NewStreamManager(&fakeDatasourceCache{}, newLocalBroker())
+			user := &m.SignedInUser{UserId: 1, OrgId: 1, OrgRole: m.ROLE_VIEWER}
+
+			mac := macaron.New()
+			mac.Get("/ws", func(c *macaron.Context) {
+				sm.Serve(&m.ReqContext{Context: c, SignedInUser: user})
+			})
+
+			rec := httptest.NewRecorder()
+			mac.ServeHTTP(rec, newRequest("https://evil.example.com"))
+
+			So(rec.Code, ShouldEqual, 403)
+			So(sm.hub.connections, ShouldHaveLength, 0)
+		})
+
+		Convey("Should accept the pages of Grafana", func() {
+			So(checkOrigin(newRequest("http://localhost:3000")), ShouldBeTrue)
+			So(checkOrigin(newRequest("https://grafana.example.com")), ShouldBeTrue)
+			So(checkOrigin(newRequest("http://grafana.example.com:8080")), ShouldBeTrue)
+		})
+
+		Convey("Should accept clients that are not browsers", func() {
+			So(checkOrigin(newRequest("")), ShouldBeTrue)
+		})
+
+		Convey("Should refuse invalid origins", func() {
+			So(checkOrigin(newRequest("null")), ShouldBeFalse)
+			So(checkOrigin(newRequest("https://grafana.example.com.evil.example")), ShouldBeFalse)
+		})
+	})
+}
@@ -113,7 +113,25 @@ func (hs *HTTPServer) LoginPost(c *models.ReqContext, cmd dtos.LoginCommand) Res
 		IpAddress:  c.Req.RemoteAddr,
 	}
 
-	if err := bus.Dispatch(authQuery); err != nil {
+	err := bus.Dispatch(authQuery)
+
+	// an expired password can only be used to set a new one
+	if err == login.ErrPasswordExpired {
+		user := authQuery.User
+		codeQuery := models.CreateResetPasswordCodeQuery{User: user}
+		if err := bus.Dispatch(&codeQuery); err != nil {
+			return Error(500, "Failed to create password reset code", err)
+		}
+
+		hs.log.Info("Password expired", "user", user.Login)
+		return JSON(200, map[string]interface{}{
+			"message":         "Password expired",
+			"passwordExpired": true,
+			"redirectUrl":     setting.AppSubUrl + "/user/password/reset?code=" + url.QueryEscape(codeQuery.Result),
+		})
+	}
+
+	if err != nil {
 		e401 := Error(401, "Invalid username or password", err)
 		if err == login.ErrInvalidCredentials || err == login.ErrTooManyLoginAttempts {
 			return e401
@@ -166,10 +184,25 @@ func (hs *HTTPServer) Logout(c *models.ReqContext) {
 
 	middleware.WriteSessionCookie(c, "", -1)
 
+	if c.IsSignedIn && hs.SAMLService.IsEnabled() {
+		idpURL, err := hs.SAMLService.LogoutRedirectURL(c.UserId)
+		if err != nil {
+			hs.log.Error("failed to start SAML logout", "error", err)
+		} else if idpURL != "" {
+			hs.log.Info("Successful Logout", "User", c.Email)
+			c.Redirect(idpURL)
+			return
+		}
+	}
+
+	hs.log.Info("Successful Logout", "User", c.Email)
+	hs.redirectAfterLogout(c)
+}
+
+func (hs *HTTPServer) redirectAfterLogout(c *models.ReqContext) {
 	if setting.SignoutRedirectUrl != "" {
 		c.Redirect(setting.SignoutRedirectUrl)
 	} else {
-		hs.log.Info("Successful Logout", "User", c.Email)
 		c.Redirect(setting.AppSubUrl + "/login")
 	}
 }
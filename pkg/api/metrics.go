@@ -34,6 +34,13 @@ func (hs *HTTPServer) QueryMetrics(c *m.ReqContext, reqDto dtos.MetricRequest) R
 		return Error(500, "Unable to load datasource meta data", err)
 	}
 
+	if c.PublicDashboard != nil {
+		if rsp := checkPublicDashboardQueries(c, ds, reqDto.Queries); rsp != nil {
+			return rsp
+		}
+		reqDto.Debug = false
+	}
+
 	request := &tsdb.TsdbQuery{TimeRange: timeRange, Debug: reqDto.Debug}
 
 	for _, query := range reqDto.Queries {
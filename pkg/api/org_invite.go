@@ -163,6 +163,10 @@ func (hs *HTTPServer) CompleteInvite(c *m.ReqContext, completeInvite dtos.Comple
 		return Error(412, fmt.Sprintf("Invite cannot be used in status %s", invite.Status), nil)
 	}
 
+	if rsp := hs.validatePassword(&m.User{Login: completeInvite.Username, Email: completeInvite.Email}, completeInvite.Password); rsp != nil {
+		return rsp
+	}
+
 	cmd := m.CreateUserCommand{
 		Email:        completeInvite.Email,
 		Name:         completeInvite.Name,
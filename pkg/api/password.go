@@ -4,6 +4,7 @@ import (
 	"github.com/Seasheller/grafana/pkg/api/dtos"
 	"github.com/Seasheller/grafana/pkg/bus"
 	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/passwordpolicy"
 	"github.com/Seasheller/grafana/pkg/setting"
 	"github.com/Seasheller/grafana/pkg/util"
 )
@@ -31,7 +32,7 @@ func SendResetPasswordEmail(c *m.ReqContext, form dtos.SendResetPasswordEmailFor
 	return Success("Email sent")
 }
 
-func ResetPassword(c *m.ReqContext, form dtos.ResetUserPasswordForm) Response {
+func (hs *HTTPServer) ResetPassword(c *m.ReqContext, form dtos.ResetUserPasswordForm) Response {
 	query := m.ValidateResetPasswordCodeQuery{Code: form.Code}
 
 	if err := bus.Dispatch(&query); err != nil {
@@ -45,6 +46,10 @@ func ResetPassword(c *m.ReqContext, form dtos.ResetUserPasswordForm) Response {
 		return Error(400, "Passwords do not match", nil)
 	}
 
+	if rsp := hs.validatePassword(query.Result, form.NewPassword); rsp != nil {
+		return rsp
+	}
+
 	cmd := m.ChangeUserPasswordCommand{}
 	cmd.UserId = query.Result.Id
 	cmd.NewPassword = util.EncodePassword(form.NewPassword, query.Result.Salt)
@@ -55,3 +60,18 @@ func ResetPassword(c *m.ReqContext, form dtos.ResetUserPasswordForm) Response {
 
 	return Success("User password changed")
 }
+
+// validatePassword returns an error response when password breaks the
+// password policy. user only needs Login and Email for new accounts.
+func (hs *HTTPServer) validatePassword(user *m.User, password string) Response {
+	err := passwordpolicy.Validate(hs.Cfg.PasswordPolicy, user, password)
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(*passwordpolicy.PolicyError); ok {
+		return Error(400, err.Error(), nil)
+	}
+
+	return Error(500, "Failed to validate password", err)
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/plugins"
+	"github.com/Seasheller/grafana/pkg/plugins/backendplugin"
+	"github.com/Seasheller/grafana/pkg/setting"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// pluginResourceRequestHeaders are not passed to the backend of plugins, which
+// gets the identity of the caller in the request instead.
+var pluginResourceRequestHeaders = []string{"Cookie", "Authorization", "X-Forwarded-Host", "X-Forwarded-Port", "X-Forwarded-Proto"}
+
+// /api/plugins/:pluginId/resources/*
+func CallPluginResource(c *m.ReqContext) Response {
+	pluginID := c.Params(":pluginId")
+
+	if _, exists := plugins.Plugins[pluginID]; !exists {
+		return Error(404, "Plugin not found, no installed plugin with that id", nil)
+	}
+
+	resourcePlugin, exists := plugins.GetResourcePlugin(pluginID)
+	if !exists {
+		return Error(404, "Plugin has no running backend", nil)
+	}
+
+	config, rsp := getPluginResourceConfig(c, pluginID)
+	if rsp != nil {
+		return rsp
+	}
+
+	body, err := ioutil.ReadAll(c.Req.Request.Body)
+	if err != nil {
+		return Error(400, "Failed to read request body", err)
+	}
+
+	req := &backendplugin.ResourceRequest{
+		PluginId: pluginID,
+		OrgId:    c.OrgId,
+		User: &backendplugin.User{
+			Id:             c.UserId,
+			Login:          c.Login,
+			Name:           c.Name,
+			Email:          c.Email,
+			Role:           string(c.OrgRole),
+			IsGrafanaAdmin: c.IsGrafanaAdmin,
+		},
+		Method:  c.Req.Method,
+		Path:    c.Params("*"),
+		Query:   c.Req.URL.RawQuery,
+		Headers: toPluginResourceHeaders(c.Req.Header, pluginResourceRequestHeaders...),
+		Body:    body,
+		Config:  config,
+	}
+
+	ctx, cancel := context.WithTimeout(c.Req.Context(), time.Duration(setting.DataProxyTimeout)*time.Second)
+	defer cancel()
+
+	res, err := resourcePlugin.CallResource(ctx, req)
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return Error(404, "Plugin has no resources", nil)
+		}
+		return Error(502, "Failed to call plugin resource", err)
+	}
+
+	code := int(res.Status)
+	if code == 0 {
+		code = 200
+	}
+
+	result := Respond(code, res.Body)
+	for _, h := range res.Headers {
+		if http.CanonicalHeaderKey(h.Name) == "Set-Cookie" {
+			continue
+		}
+		for _, v := range h.Values {
+			result.header.Add(h.Name, v)
+		}
+	}
+
+	return result
+}
+
+// getPluginResourceConfig returns the settings of an app plugin in the org,
+// whose resources can only be called when it is enabled. Datasource plugins
+// have no settings of their own.
+func getPluginResourceConfig(c *m.ReqContext, pluginID string) (*backendplugin.PluginConfig, Response) {
+	if _, isApp := plugins.Apps[pluginID]; !isApp {
+		return nil, nil
+	}
+
+	query := m.GetPluginSettingByIdQuery{PluginId: pluginID, OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		if err == m.ErrPluginSettingNotFound {
+			return nil, Error(404, "Plugin not enabled", nil)
+		}
+		return nil, Error(500, "Failed to get plugin settings", err)
+	}
+
+	if !query.Result.Enabled {
+		return nil, Error(404, "Plugin not enabled", nil)
+	}
+
+	jsonData, err := json.Marshal(query.Result.JsonData)
+	if err != nil {
+		return nil, Error(500, "Failed to encode plugin settings", err)
+	}
+
+	return &backendplugin.PluginConfig{
+		JsonData:                string(jsonData),
+		DecryptedSecureJsonData: query.Result.SecureJsonData.Decrypt(),
+	}, nil
+}
+
+func toPluginResourceHeaders(header http.Header, skip ...string) []*backendplugin.Header {
+	result := make([]*backendplugin.Header, 0, len(header))
+
+	for name, values := range header {
+		skipped := false
+		for _, s := range skip {
+			if http.CanonicalHeaderKey(name) == s {
+				skipped = true
+			}
+		}
+		if !skipped {
+			result = append(result, &backendplugin.Header{Name: name, Values: values})
+		}
+	}
+
+	return result
+}
@@ -75,12 +75,20 @@ func (proxy *DataSourceProxy) HandleRequest() {
 		FlushInterval: time.Millisecond * 200,
 	}
 
-	var err error
-	reverseProxy.Transport, err = proxy.ds.GetHttpTransport()
+	transport, err := proxy.ds.GetHttpTransport()
 	if err != nil {
 		proxy.ctx.JsonApiErr(400, "Unable to load TLS certificate", err)
 		return
 	}
+	reverseProxy.Transport = transport
+
+	if proxy.route != nil && len(proxy.route.Middlewares) > 0 {
+		reverseProxy.Transport, err = applyRouteMiddlewares(proxy.route, proxy.ds, transport)
+		if err != nil {
+			proxy.ctx.JsonApiErr(500, "Failed to set up plugin route middlewares", err)
+			return
+		}
+	}
 
 	proxy.logRequest()
 
@@ -0,0 +1,162 @@
+package pluginproxy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/plugins"
+)
+
+// RouteMiddleware wraps the transport the data proxy sends the requests to a
+// datasource route through. It gets the datasource, the settings the route
+// declares for it, with the template variables interpolated, and the
+// transport to pass the requests on to.
+type RouteMiddleware func(ds *m.DataSource, settings map[string]string, next http.RoundTripper) (http.RoundTripper, error)
+
+// RoundTripperFunc lets a function be used as a transport.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+var (
+	routeMiddlewaresMu sync.RWMutex
+	routeMiddlewares   = map[string]RouteMiddleware{}
+)
+
+func init() {
+	RegisterRouteMiddleware("bodyLimit", newBodyLimitMiddleware)
+	RegisterRouteMiddleware("sigv4", newSigV4Middleware)
+	RegisterRouteMiddleware("tlsClientAuth", newTLSClientAuthMiddleware)
+}
+
+// RegisterRouteMiddleware makes the middleware available to the routes of
+// datasource plugins by its type.
+func RegisterRouteMiddleware(middlewareType string, middleware RouteMiddleware) {
+	routeMiddlewaresMu.Lock()
+	defer routeMiddlewaresMu.Unlock()
+
+	routeMiddlewares[middlewareType] = middleware
+}
+
+func getRouteMiddleware(middlewareType string) (RouteMiddleware, bool) {
+	routeMiddlewaresMu.RLock()
+	defer routeMiddlewaresMu.RUnlock()
+
+	middleware, exists := routeMiddlewares[middlewareType]
+	return middleware, exists
+}
+
+// applyRouteMiddlewares wraps the transport in the middlewares of the route,
+// so the first one declared handles the requests first.
+func applyRouteMiddlewares(route *plugins.AppPluginRoute, ds *m.DataSource, transport http.RoundTripper) (http.RoundTripper, error) {
+	data := templateData{
+		JsonData:       map[string]interface{}{},
+		SecureJsonData: ds.SecureJsonData.Decrypt(),
+	}
+	if ds.JsonData != nil {
+		data.JsonData = ds.JsonData.MustMap()
+	}
+
+	for i := len(route.Middlewares) - 1; i >= 0; i-- {
+		declared := route.Middlewares[i]
+
+		middleware, exists := getRouteMiddleware(declared.Type)
+		if !exists {
+			return nil, fmt.Errorf("unknown plugin route middleware %q", declared.Type)
+		}
+
+		settings, err := interpolateMiddlewareSettings(declared.Settings, data)
+		if err != nil {
+			return nil, err
+		}
+
+		if transport, err = middleware(ds, settings, transport); err != nil {
+			return nil, fmt.Errorf("invalid plugin route middleware %q: %v", declared.Type, err)
+		}
+	}
+
+	return transport, nil
+}
+
+func interpolateMiddlewareSettings(settings map[string]interface{}, data templateData) (map[string]string, error) {
+	result := make(map[string]string, len(settings))
+
+	for key, value := range settings {
+		switch v := value.(type) {
+		case string:
+			interpolated, err := InterpolateString(v, data)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = interpolated
+		case float64:
+			result[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			result[key] = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("plugin route middleware setting %q should be a string, number or boolean", key)
+		}
+	}
+
+	return result, nil
+}
+
+// newBodyLimitMiddleware answers with 413 Request Entity Too Large, without
+// sending the request, when its body is larger than maxBytes.
+func newBodyLimitMiddleware(ds *m.DataSource, settings map[string]string, next http.RoundTripper) (http.RoundTripper, error) {
+	maxBytes, err := strconv.ParseInt(settings["maxBytes"], 10, 64)
+	if err != nil || maxBytes < 0 {
+		return nil, fmt.Errorf("maxBytes should be a number of bytes")
+	}
+
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body == nil {
+			return next.RoundTrip(req)
+		}
+
+		if req.ContentLength > maxBytes {
+			req.Body.Close()
+			return newBodyTooLargeResponse(req), nil
+		}
+
+		// the length of chunked bodies is only known once they are read
+		body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxBytes+1))
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if int64(len(body)) > maxBytes {
+			return newBodyTooLargeResponse(req), nil
+		}
+
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		return next.RoundTrip(req)
+	}), nil
+}
+
+func newBodyTooLargeResponse(req *http.Request) *http.Response {
+	body := `{"message":"Request body too large"}`
+
+	return &http.Response{
+		Status:        "413 Request Entity Too Large",
+		StatusCode:    http.StatusRequestEntityTooLarge,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
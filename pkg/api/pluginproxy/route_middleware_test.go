@@ -0,0 +1,230 @@
+package pluginproxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/components/securejsondata"
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/plugins"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRouteMiddlewares(t *testing.T) {
+	Convey("Plugin route middlewares", t, func() {
+		ds := &m.DataSource{
+			JsonData: simplejson.NewFromAny(map[string]interface{}{
+				"region": "eu-west-1",
+			}),
+			SecureJsonData: securejsondata.GetEncryptedJsonData(map[string]string{
+				"secretKey": "secret",
+			}),
+		}
+
+		var sent *http.Request
+		transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			sent = req
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader("")), Request: req}, nil
+		})
+
+		newRequest := func(body string) *http.Request {
+			req, _ := http.NewRequest("POST", "https://search.eu-west-1.es.amazonaws.com/_msearch", strings.NewReader(body))
+			return req
+		}
+
+		Convey("Should run the middlewares in the order they are declared", func() {
+			calls := []string{}
+			record := func(name string) RouteMiddleware {
+				return func(ds *m.DataSource, settings map[string]string, next http.RoundTripper) (http.RoundTripper, error) {
+					return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+						calls = append(calls, name+":"+settings["value"])
+						return next.RoundTrip(req)
+					}), nil
+				}
+			}
+			RegisterRouteMiddleware("first", record("first"))
+			RegisterRouteMiddleware("second", record("second"))
+
+			route := &plugins.AppPluginRoute{Middlewares: []*plugins.AppPluginRouteMiddleware{
+				{Type: "first", Settings: map[string]interface{}{"value": "{{.JsonData.region}}"}},
+				{Type: "second", Settings: map[string]interface{}{"value": 10.0}},
+			}}
+
+			rt, err := applyRouteMiddlewares(route, ds, transport)
+			So(err, ShouldBeNil)
+
+			_, err = rt.RoundTrip(newRequest(""))
+			So(err, ShouldBeNil)
+			So(calls, ShouldResemble, []string{"first:eu-west-1", "second:10"})
+		})
+
+		Convey("Should fail on unknown middlewares", func() {
+			route := &plugins.AppPluginRoute{Middlewares: []*plugins.AppPluginRouteMiddleware{{Type: "unknown"}}}
+
+			_, err := applyRouteMiddlewares(route, ds, transport)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `unknown plugin route middleware "unknown"`)
+		})
+
+		Convey("Body limit", func() {
+			route := &plugins.AppPluginRoute{Middlewares: []*plugins.AppPluginRouteMiddleware{
+				{Type: "bodyLimit", Settings: map[string]interface{}{"maxBytes": 5.0}},
+			}}
+			rt, err := applyRouteMiddlewares(route, ds, transport)
+			So(err, ShouldBeNil)
+
+			Convey("Should send bodies within the limit", func() {
+				res, err := rt.RoundTrip(newRequest("small"))
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, 200)
+
+				body, _ := ioutil.ReadAll(sent.Body)
+				So(string(body), ShouldEqual, "small")
+			})
+
+			Convey("Should refuse larger bodies without sending them", func() {
+				res, err := rt.RoundTrip(newRequest("too large"))
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, 413)
+				So(sent, ShouldBeNil)
+			})
+
+			Convey("Should refuse larger bodies of unknown length", func() {
+				req := newRequest("too large")
+				req.ContentLength = -1
+
+				res, err := rt.RoundTrip(req)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, 413)
+				So(sent, ShouldBeNil)
+			})
+
+			Convey("Should need maxBytes", func() {
+				route.Middlewares[0].Settings = map[string]interface{}{}
+				_, err := applyRouteMiddlewares(route, ds, transport)
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("SigV4", func() {
+			route := &plugins.AppPluginRoute{Middlewares: []*plugins.AppPluginRouteMiddleware{
+				{Type: "sigv4", Settings: map[string]interface{}{
+					"region":    "{{.JsonData.region}}",
+					"service":   "es",
+					"accessKey": "AKID",
+					"secretKey": "{{.SecureJsonData.secretKey}}",
+				}},
+			}}
+
+			Convey("Should sign the request and keep its body", func() {
+				rt, err := applyRouteMiddlewares(route, ds, transport)
+				So(err, ShouldBeNil)
+
+				_, err = rt.RoundTrip(newRequest(`{"query":{}}`))
+				So(err, ShouldBeNil)
+
+				So(sent.Header.Get("Authorization"), ShouldStartWith, "AWS4-HMAC-SHA256 Credential=AKID/")
+				So(sent.Header.Get("Authorization"), ShouldContainSubstring, "/eu-west-1/es/aws4_request")
+				So(sent.Header.Get("X-Amz-Date"), ShouldNotBeEmpty)
+
+				body, _ := ioutil.ReadAll(sent.Body)
+				So(string(body), ShouldEqual, `{"query":{}}`)
+			})
+
+			Convey("Should need a region and a service", func() {
+				delete(route.Middlewares[0].Settings, "service")
+				_, err := applyRouteMiddlewares(route, ds, transport)
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("TLS client auth", func() {
+			cert, key := generateTestCertificate()
+			ds.Id = 42
+			ds.Updated = time.Now()
+			ds.SecureJsonData = securejsondata.GetEncryptedJsonData(map[string]string{
+				"routeCert": cert,
+				"routeKey":  key,
+			})
+
+			route := &plugins.AppPluginRoute{Middlewares: []*plugins.AppPluginRouteMiddleware{
+				{Type: "tlsClientAuth", Settings: map[string]interface{}{
+					"cert": "{{.SecureJsonData.routeCert}}",
+					"key":  "{{.SecureJsonData.routeKey}}",
+				}},
+			}}
+
+			Convey("Should send the requests with the certificate of the route", func() {
+				base := &http.Transport{TLSHandshakeTimeout: time.Second}
+				rt, err := applyRouteMiddlewares(route, ds, base)
+				So(err, ShouldBeNil)
+
+				tlsTransport, ok := rt.(*http.Transport)
+				So(ok, ShouldBeTrue)
+				So(tlsTransport, ShouldNotEqual, base)
+				So(tlsTransport.TLSClientConfig.Certificates, ShouldHaveLength, 1)
+				So(tlsTransport.TLSHandshakeTimeout, ShouldEqual, time.Second)
+
+				Convey("And reuse the transport", func() {
+					again, err := applyRouteMiddlewares(route, ds, base)
+					So(err, ShouldBeNil)
+					So(again, ShouldEqual, rt)
+				})
+
+				Convey("And replace the transport when the datasource is updated", func() {
+					ds.Updated = ds.Updated.Add(time.Second)
+
+					again, err := applyRouteMiddlewares(route, ds, base)
+					So(err, ShouldBeNil)
+					So(again, ShouldNotEqual, rt)
+					So(tlsAuthTransports[ds.Id], ShouldHaveLength, 1)
+				})
+			})
+
+			Convey("Should be the last middleware", func() {
+				_, err := applyRouteMiddlewares(route, ds, transport)
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func generateTestCertificate() (string, string) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "grafana"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		panic(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		panic(err)
+	}
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return string(cert), string(key)
+}
@@ -0,0 +1,81 @@
+package pluginproxy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+var (
+	// the credentials of profiles and of the default chain are cached, as
+	// they may be fetched from the metadata service and expire
+	awsCredentialsMu    sync.Mutex
+	awsCredentialsCache = map[string]*credentials.Credentials{}
+)
+
+// newSigV4Middleware signs the requests with AWS Signature Version 4, for
+// services like Amazon Elasticsearch Service. It uses the accessKey and
+// secretKey when set, the credentials of the profile in the shared
+// credentials file when set, or else the default credential chain of the
+// AWS SDK.
+func newSigV4Middleware(ds *m.DataSource, settings map[string]string, next http.RoundTripper) (http.RoundTripper, error) {
+	region, service := settings["region"], settings["service"]
+	if region == "" || service == "" {
+		return nil, errors.New("region and service are required")
+	}
+
+	signer := v4.NewSigner(getAWSCredentials(settings))
+
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		var body io.ReadSeeker
+		if req.Body != nil {
+			data, err := ioutil.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			body = bytes.NewReader(data)
+		}
+
+		// the signer sets the body back on the request
+		if _, err := signer.Sign(req, body, service, region, time.Now()); err != nil {
+			return nil, err
+		}
+
+		return next.RoundTrip(req)
+	}), nil
+}
+
+func getAWSCredentials(settings map[string]string) *credentials.Credentials {
+	if settings["accessKey"] != "" || settings["secretKey"] != "" {
+		return credentials.NewStaticCredentials(settings["accessKey"], settings["secretKey"], "")
+	}
+
+	awsCredentialsMu.Lock()
+	defer awsCredentialsMu.Unlock()
+
+	profile := settings["profile"]
+	if creds, exists := awsCredentialsCache[profile]; exists {
+		return creds
+	}
+
+	var creds *credentials.Credentials
+	if profile != "" {
+		creds = credentials.NewSharedCredentials("", profile)
+	} else {
+		creds = defaults.CredChain(defaults.Config(), defaults.Handlers())
+	}
+
+	awsCredentialsCache[profile] = creds
+	return creds
+}
@@ -0,0 +1,96 @@
+package pluginproxy
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+var (
+	// transports are kept per datasource and certificate, so the connections
+	// to the route are reused, until the datasource is updated
+	tlsAuthTransportsMu sync.Mutex
+	tlsAuthTransports   = map[int64]map[string]*tlsAuthTransport{}
+)
+
+type tlsAuthTransport struct {
+	*http.Transport
+
+	base    *http.Transport
+	updated time.Time
+}
+
+// newTLSClientAuthMiddleware sends the requests with the client certificate
+// of the route, cert and key, and trusts the caCert when set. As it replaces
+// the transport of the datasource, it should be the last middleware of the
+// route.
+func newTLSClientAuthMiddleware(ds *m.DataSource, settings map[string]string, next http.RoundTripper) (http.RoundTripper, error) {
+	base, ok := next.(*http.Transport)
+	if !ok {
+		return nil, errors.New("should be the last middleware of the route")
+	}
+
+	key := fmt.Sprintf("%x", sha256.Sum256([]byte(settings["cert"]+"\x00"+settings["key"]+"\x00"+settings["caCert"])))
+
+	tlsAuthTransportsMu.Lock()
+	defer tlsAuthTransportsMu.Unlock()
+
+	transports, exists := tlsAuthTransports[ds.Id]
+	if !exists {
+		transports = map[string]*tlsAuthTransport{}
+		tlsAuthTransports[ds.Id] = transports
+	}
+
+	// the transports of previous versions of the datasource are not used
+	// anymore
+	for k, t := range transports {
+		if !t.updated.Equal(ds.Updated) || t.base != base {
+			t.CloseIdleConnections()
+			delete(transports, k)
+		}
+	}
+
+	if t, exists := transports[key]; exists {
+		return t.Transport, nil
+	}
+
+	cert, err := tls.X509KeyPair([]byte(settings["cert"]), []byte(settings["key"]))
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{}
+	if base.TLSClientConfig != nil {
+		tlsConfig = base.TLSClientConfig.Clone()
+	}
+	tlsConfig.Certificates = []tls.Certificate{cert}
+
+	if settings["caCert"] != "" {
+		caPool := x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM([]byte(settings["caCert"])) {
+			return nil, errors.New("failed to parse caCert")
+		}
+		tlsConfig.RootCAs = caPool
+	}
+
+	transport := &http.Transport{
+		TLSClientConfig:       tlsConfig,
+		Proxy:                 base.Proxy,
+		Dial:                  base.Dial,
+		DialContext:           base.DialContext,
+		TLSHandshakeTimeout:   base.TLSHandshakeTimeout,
+		ExpectContinueTimeout: base.ExpectContinueTimeout,
+		MaxIdleConns:          base.MaxIdleConns,
+		IdleConnTimeout:       base.IdleConnTimeout,
+	}
+
+	transports[key] = &tlsAuthTransport{Transport: transport, base: base, updated: ds.Updated}
+	return transport, nil
+}
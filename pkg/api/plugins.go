@@ -53,6 +53,7 @@ func (hs *HTTPServer) GetPluginList(c *m.ReqContext) Response {
 			HasUpdate:     pluginDef.GrafanaNetHasUpdate,
 			DefaultNavUrl: pluginDef.DefaultNavUrl,
 			State:         pluginDef.State,
+			Signature:     pluginDef.Signature,
 		}
 
 		if pluginSetting, exists := pluginSettingsMap[pluginDef.Id]; exists {
@@ -104,6 +105,7 @@ func GetPluginSettingByID(c *m.ReqContext) Response {
 		LatestVersion: def.GrafanaNetVersion,
 		HasUpdate:     def.GrafanaNetHasUpdate,
 		State:         def.State,
+		Signature:     def.Signature,
 	}
 
 	query := m.GetPluginSettingByIdQuery{PluginId: pluginID, OrgId: c.OrgId}
@@ -115,6 +117,7 @@ func GetPluginSettingByID(c *m.ReqContext) Response {
 		dto.Enabled = query.Result.Enabled
 		dto.Pinned = query.Result.Pinned
 		dto.JsonData = query.Result.JsonData
+		dto.ReadOnly = query.Result.ReadOnly
 	}
 
 	return JSON(200, dto)
@@ -130,6 +133,15 @@ func UpdatePluginSetting(c *m.ReqContext, cmd m.UpdatePluginSettingCmd) Response
 		return Error(404, "Plugin not installed.", nil)
 	}
 
+	query := m.GetPluginSettingByIdQuery{PluginId: pluginID, OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		if err != m.ErrPluginSettingNotFound {
+			return Error(500, "Failed to get plugin settings", err)
+		}
+	} else if query.Result.ReadOnly {
+		return Error(403, "Cannot update read-only plugin settings", m.ErrPluginSettingIsReadOnly)
+	}
+
 	if err := bus.Dispatch(&cmd); err != nil {
 		return Error(500, "Failed to update plugin setting", err)
 	}
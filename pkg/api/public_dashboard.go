@@ -0,0 +1,179 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/api/dtos"
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+	"github.com/Seasheller/grafana/pkg/services/publicdashboards"
+	"github.com/Seasheller/grafana/pkg/util"
+)
+
+// GET /api/dashboards/uid/:uid/public
+func GetPublicDashboards(c *m.ReqContext) Response {
+	dash, rsp := getPublicDashboardTarget(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	query := m.GetPublicDashboardsQuery{OrgId: c.OrgId, DashboardId: dash.Id}
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to get public dashboards", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+// POST /api/dashboards/uid/:uid/public
+func CreatePublicDashboard(c *m.ReqContext, cmd m.CreatePublicDashboardCommand) Response {
+	dash, rsp := getPublicDashboardTarget(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	if cmd.Expires < 0 {
+		return Error(400, "Expires cannot be negative", nil)
+	}
+
+	cmd.OrgId = c.OrgId
+	cmd.DashboardId = dash.Id
+	cmd.UserId = c.UserId
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return Error(500, "Failed to create public dashboard", err)
+	}
+
+	return JSON(200, cmd.Result)
+}
+
+// DELETE /api/dashboards/uid/:uid/public/:id
+func RevokePublicDashboard(c *m.ReqContext) Response {
+	dash, rsp := getPublicDashboardTarget(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	cmd := m.RevokePublicDashboardCommand{Id: c.ParamsInt64(":id"), OrgId: c.OrgId, DashboardId: dash.Id}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == m.ErrPublicDashboardNotFound {
+			return Error(404, err.Error(), err)
+		}
+		return Error(500, "Failed to revoke public dashboard", err)
+	}
+
+	return Success("Public dashboard revoked")
+}
+
+// getPublicDashboardTarget returns the dashboard whose public dashboards are
+// managed, which requires permission to save it.
+func getPublicDashboardTarget(c *m.ReqContext) (*m.Dashboard, Response) {
+	dash, rsp := getDashboardHelper(c.OrgId, "", 0, c.Params(":uid"))
+	if rsp != nil {
+		return nil, rsp
+	}
+
+	guardian := guardian.New(dash.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return nil, dashboardGuardianResponse(err)
+	}
+
+	return dash, nil
+}
+
+// GET /api/public/dashboards/:accessToken
+func (hs *HTTPServer) GetPublicDashboard(c *m.ReqContext) Response {
+	query := m.GetPublicDashboardByTokenQuery{AccessToken: c.Params(":accessToken")}
+	if err := bus.Dispatch(&query); err != nil {
+		if err == m.ErrPublicDashboardNotFound {
+			return Error(404, "Public dashboard not found", nil)
+		}
+		return Error(500, "Failed to get public dashboard", err)
+	}
+
+	publicDashboard := query.Result
+	if !publicDashboard.IsActive(time.Now()) {
+		return Error(404, "Public dashboard not found", nil)
+	}
+
+	dash, rsp := getDashboardHelper(publicDashboard.OrgId, "", publicDashboard.DashboardId, "")
+	if rsp != nil {
+		return rsp
+	}
+
+	recordPublicDashboardUsage(c, &m.RecordPublicDashboardUsageCommand{Id: publicDashboard.Id, Views: 1})
+	hs.auditPublicDashboardView(c, publicDashboard, dash)
+
+	dto := dtos.DashboardFullWithMeta{
+		Dashboard: dash.Data,
+		Meta: dtos.DashboardMeta{
+			Type:     m.DashTypeDB,
+			IsPublic: true,
+			Slug:     dash.Slug,
+			Created:  dash.Created,
+			Updated:  dash.Updated,
+			Expires:  publicDashboard.Expires,
+			Version:  dash.Version,
+		},
+	}
+
+	return JSON(200, dto)
+}
+
+// checkPublicDashboardQueries only lets the access token of a public
+// dashboard run the queries of its panels.
+func checkPublicDashboardQueries(c *m.ReqContext, ds *m.DataSource, queries []*simplejson.Json) Response {
+	publicDashboard := c.PublicDashboard
+
+	dash, rsp := getDashboardHelper(publicDashboard.OrgId, "", publicDashboard.DashboardId, "")
+	if rsp != nil {
+		return rsp
+	}
+
+	usage := &m.RecordPublicDashboardUsageCommand{Id: publicDashboard.Id, Queries: 1}
+	err := publicdashboards.ValidateQueries(dash, ds, queries)
+	if err != nil {
+		usage.Queries, usage.DeniedQueries = 0, 1
+		c.Logger.Warn("Denied query of public dashboard", "publicDashboardId", publicDashboard.Id, "datasource", ds.Name)
+	}
+	recordPublicDashboardUsage(c, usage)
+
+	if err != nil {
+		return Error(403, err.Error(), err)
+	}
+
+	return nil
+}
+
+func recordPublicDashboardUsage(c *m.ReqContext, cmd *m.RecordPublicDashboardUsageCommand) {
+	cmd.Time = time.Now()
+	if err := bus.Dispatch(cmd); err != nil {
+		c.Logger.Error("Failed to record public dashboard usage", "publicDashboardId", cmd.Id, "error", err)
+	}
+}
+
+// auditPublicDashboardView records views of public dashboards in the audit
+// log, which otherwise only has the requests that change something.
+func (hs *HTTPServer) auditPublicDashboardView(c *m.ReqContext, publicDashboard *m.PublicDashboard, dash *m.Dashboard) {
+	if hs.AuditService == nil || !hs.AuditService.IsEnabled() {
+		return
+	}
+
+	cmd := &m.CreateAuditEntryCommand{
+		OrgId:     publicDashboard.OrgId,
+		Action:    "view",
+		Resource:  "public-dashboards",
+		Target:    fmt.Sprintf("/api/dashboards/uid/%s/public/%d", dash.Uid, publicDashboard.Id),
+		Method:    c.Req.Method,
+		Status:    200,
+		IpAddress: c.RemoteAddr(),
+		RequestId: util.GenerateShortUID(),
+	}
+
+	if err := hs.AuditService.Record(cmd); err != nil {
+		c.Logger.Error("Failed to record audit entry", "publicDashboardId", publicDashboard.Id, "error", err)
+	}
+}
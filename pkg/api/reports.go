@@ -0,0 +1,147 @@
+package api
+
+import (
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+	"github.com/Seasheller/grafana/pkg/services/reports"
+	"github.com/Seasheller/grafana/pkg/util"
+)
+
+const defaultReportDeliveriesLimit = 100
+
+func GetReports(c *m.ReqContext) Response {
+	query := m.GetReportsQuery{OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to get reports", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+func GetReport(c *m.ReqContext) Response {
+	query := m.GetReportQuery{Id: c.ParamsInt64(":id"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return reportErrorResponse(err, "Failed to get report")
+	}
+
+	return JSON(200, query.Result)
+}
+
+func CreateReport(c *m.ReqContext, cmd m.CreateReportCommand) Response {
+	if rsp := validateReport(c, &cmd.ReportSettings); rsp != nil {
+		return rsp
+	}
+
+	cmd.OrgId = c.OrgId
+	cmd.UserId = c.UserId
+	cmd.NextRun, _ = reports.NextRun(cmd.Schedule, time.Now())
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return Error(500, "Failed to create report", err)
+	}
+
+	return JSON(200, cmd.Result)
+}
+
+// UpdateReport saves the report, which is from then on rendered with the
+// permissions of the user saving it.
+func UpdateReport(c *m.ReqContext, cmd m.UpdateReportCommand) Response {
+	if rsp := validateReport(c, &cmd.ReportSettings); rsp != nil {
+		return rsp
+	}
+
+	cmd.Id = c.ParamsInt64(":id")
+	cmd.OrgId = c.OrgId
+	cmd.UserId = c.UserId
+	cmd.NextRun, _ = reports.NextRun(cmd.Schedule, time.Now())
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return reportErrorResponse(err, "Failed to update report")
+	}
+
+	return JSON(200, cmd.Result)
+}
+
+func DeleteReport(c *m.ReqContext) Response {
+	cmd := m.DeleteReportCommand{Id: c.ParamsInt64(":id"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&cmd); err != nil {
+		return reportErrorResponse(err, "Failed to delete report")
+	}
+
+	return Success("Report deleted")
+}
+
+// SendReport sends the report right away, regardless of its schedule.
+func SendReport(c *m.ReqContext) Response {
+	query := m.GetReportQuery{Id: c.ParamsInt64(":id"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return reportErrorResponse(err, "Failed to get report")
+	}
+
+	cmd := m.SendReportCommand{Report: query.Result}
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		return reportErrorResponse(err, "Failed to send report")
+	}
+
+	return JSON(200, cmd.Result)
+}
+
+func GetReportDeliveries(c *m.ReqContext) Response {
+	reportQuery := m.GetReportQuery{Id: c.ParamsInt64(":id"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&reportQuery); err != nil {
+		return reportErrorResponse(err, "Failed to get report")
+	}
+
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultReportDeliveriesLimit
+	}
+
+	query := m.GetReportDeliveriesQuery{ReportId: reportQuery.Result.Id, OrgId: c.OrgId, Limit: limit}
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to get report deliveries", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+// validateReport checks the settings of the report and that the user can
+// view its dashboard, so a report never shows more than its owner may see.
+func validateReport(c *m.ReqContext, settings *m.ReportSettings) Response {
+	if err := reports.ValidateSettings(settings); err != nil {
+		return Error(400, err.Error(), err)
+	}
+
+	query := m.GetDashboardQuery{OrgId: c.OrgId, Uid: settings.DashboardUid}
+	if err := bus.Dispatch(&query); err != nil {
+		if err == m.ErrDashboardNotFound {
+			return Error(400, m.ErrReportDashboardNotFound.Error(), err)
+		}
+		return Error(500, "Failed to get dashboard", err)
+	}
+
+	canView, err := guardian.New(query.Result.Id, c.OrgId, c.SignedInUser).CanView()
+	if err != nil {
+		return Error(500, "Error while checking dashboard permissions", err)
+	}
+	if !canView {
+		return Error(403, m.ErrReportAccessDenied.Error(), nil)
+	}
+
+	return nil
+}
+
+func reportErrorResponse(err error, message string) Response {
+	switch err {
+	case m.ErrReportNotFound:
+		return JSON(404, util.DynMap{"status": "not-found", "message": err.Error()})
+	case m.ErrReportAccessDenied:
+		return Error(403, err.Error(), err)
+	case m.ErrReportDashboardNotFound:
+		return Error(400, err.Error(), err)
+	}
+	return Error(500, message, err)
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReportsApiEndpoint(t *testing.T) {
+	Convey("Given a dashboard to report on", t, func() {
+		bus.AddHandler("test", func(query *m.GetDashboardQuery) error {
+			if query.Uid != "hosts" {
+				return m.ErrDashboardNotFound
+			}
+			query.Result = &m.Dashboard{Id: 1, OrgId: TestOrgID, Uid: "hosts", Data: simplejson.New()}
+			return nil
+		})
+
+		var created *m.CreateReportCommand
+		bus.AddHandler("test", func(cmd *m.CreateReportCommand) error {
+			created = cmd
+			cmd.Result = &m.Report{Id: 1, OrgId: cmd.OrgId, UserId: cmd.UserId, ReportSettings: cmd.ReportSettings}
+			return nil
+		})
+
+		origNewGuardian := guardian.New
+		defer func() {
+			guardian.New = origNewGuardian
+		}()
+
+		cmd := m.CreateReportCommand{
+			ReportSettings: m.ReportSettings{
+				Name:         "Daily",
+				DashboardUid: "hosts",
+				Recipients:   "ops@example.com",
+				Schedule:     "0 8 * * *",
+			},
+		}
+
+		createReportScenario := func(desc string, fn scenarioFunc) {
+			loggedInUserScenarioWithRole(desc, "POST", "/api/reports", "/api/reports", m.ROLE_EDITOR, func(sc *scenarioContext) {
+				sc.handlerFunc = func(c *m.ReqContext) Response {
+					return CreateReport(c, cmd)
+				}
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+				fn(sc)
+			})
+		}
+
+		Convey("When the user can view the dashboard", func() {
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+
+			createReportScenario("When creating a report", func(sc *scenarioContext) {
+				So(sc.resp.Code, ShouldEqual, 200)
+				So(created.UserId, ShouldEqual, TestUserID)
+				So(created.Format, ShouldEqual, m.ReportFormatPDF)
+				So(created.NextRun.IsZero(), ShouldBeFalse)
+			})
+
+			cmd.Schedule = "sometimes"
+			createReportScenario("When creating a report with an invalid schedule", func(sc *scenarioContext) {
+				So(sc.resp.Code, ShouldEqual, 400)
+				So(created, ShouldBeNil)
+			})
+		})
+
+		Convey("When the user cannot view the dashboard", func() {
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: false})
+
+			createReportScenario("When creating a report", func(sc *scenarioContext) {
+				So(sc.resp.Code, ShouldEqual, 403)
+				So(created, ShouldBeNil)
+			})
+		})
+	})
+}
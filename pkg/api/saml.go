@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/url"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/infra/metrics"
+	"github.com/Seasheller/grafana/pkg/login"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/setting"
+)
+
+var samlLogger = log.New("saml.auth")
+
+func (hs *HTTPServer) SAMLMetadata(c *models.ReqContext) {
+	if !hs.SAMLService.IsEnabled() {
+		c.Handle(404, "SAML not enabled", nil)
+		return
+	}
+
+	metadata, err := hs.SAMLService.MetadataXML()
+	if err != nil {
+		c.Handle(500, "Failed to build SAML metadata", err)
+		return
+	}
+
+	c.Resp.Header().Set("Content-Type", "application/samlmetadata+xml")
+	c.Resp.WriteHeader(200)
+	if _, err := c.Resp.Write(metadata); err != nil {
+		samlLogger.Error("Failed to write SAML metadata", "error", err)
+	}
+}
+
+func (hs *HTTPServer) SAMLLogin(c *models.ReqContext) {
+	if !hs.SAMLService.IsEnabled() {
+		c.Handle(404, "SAML not enabled", nil)
+		return
+	}
+
+	redirectTo, _ := url.QueryUnescape(c.GetCookie("redirect_to"))
+	if redirectTo != "" {
+		c.SetCookie("redirect_to", "", -1, setting.AppSubUrl+"/")
+	}
+
+	idpURL, err := hs.SAMLService.LoginRedirectURL(redirectTo)
+	if err != nil {
+		c.Handle(500, "Failed to start SAML login", err)
+		return
+	}
+
+	c.Redirect(idpURL)
+}
+
+func (hs *HTTPServer) SAMLACS(c *models.ReqContext) {
+	if !hs.SAMLService.IsEnabled() {
+		c.Handle(404, "SAML not enabled", nil)
+		return
+	}
+
+	resp, err := hs.SAMLService.ParseLoginResponse(c.Req.Request)
+	if err != nil {
+		hs.redirectWithError(c, login.ErrInvalidCredentials, "error", err)
+		return
+	}
+
+	cmd := &models.UpsertUserCommand{
+		ReqContext:    c,
+		ExternalUser:  resp.ExternalUser,
+		SignupAllowed: hs.Cfg.SAML.AllowSignUp,
+	}
+
+	if err := bus.Dispatch(cmd); err != nil {
+		hs.redirectWithError(c, err)
+		return
+	}
+
+	// Do not expose disabled status,
+	// just show incorrect user credentials error (see #17947)
+	if cmd.Result.IsDisabled {
+		samlLogger.Warn("User is disabled", "user", cmd.Result.Login)
+		hs.redirectWithError(c, login.ErrInvalidCredentials)
+		return
+	}
+
+	if err := hs.SAMLService.SaveSession(cmd.Result.Id, resp.Session); err != nil {
+		samlLogger.Error("Failed to save SAML session", "error", err)
+	}
+
+	hs.loginUserWithUser(cmd.Result, c)
+
+	metrics.MApiLoginSAML.Inc()
+
+	if resp.RedirectTo != "" {
+		c.Redirect(resp.RedirectTo)
+		return
+	}
+
+	c.Redirect(setting.AppSubUrl + "/")
+}
+
+// SAMLSLO handles single logout messages from the IdP, both logout
+// requests it initiates and responses to logouts Grafana started.
+func (hs *HTTPServer) SAMLSLO(c *models.ReqContext) {
+	if !hs.SAMLService.IsEnabled() {
+		c.Handle(404, "SAML not enabled", nil)
+		return
+	}
+
+	if c.Query("SAMLRequest") != "" {
+		userId, redirectTo, err := hs.SAMLService.HandleLogoutRequest(c.Req.Request)
+		if err != nil {
+			c.Handle(400, "Invalid SAML logout request", err)
+			return
+		}
+
+		if userId > 0 {
+			if err := hs.AuthTokenService.RevokeAllUserTokens(c.Req.Context(), userId); err != nil {
+				samlLogger.Error("Failed to revoke user sessions", "userId", userId, "error", err)
+			}
+		}
+
+		c.Redirect(redirectTo)
+		return
+	}
+
+	if err := hs.SAMLService.HandleLogoutResponse(c.Req.Request); err != nil {
+		samlLogger.Warn("Invalid SAML logout response", "error", err)
+	}
+
+	hs.redirectAfterLogout(c)
+}
@@ -54,6 +54,9 @@ func Search(c *m.ReqContext) Response {
 		Type:         dashboardType,
 		FolderIds:    folderIDs,
 		Permission:   permission,
+		FullText:     c.Query("fullText") == "true",
+		Datasource:   c.Query("datasource"),
+		PanelType:    c.Query("panelType"),
 	}
 
 	err := bus.Dispatch(&searchQuery)
@@ -64,6 +64,10 @@ func (hs *HTTPServer) SignUpStep2(c *m.ReqContext, form dtos.SignUpStep2Form) Re
 		OrgName:  form.OrgName,
 	}
 
+	if rsp := hs.validatePassword(&m.User{Login: form.Username, Email: form.Email}, form.Password); rsp != nil {
+		return rsp
+	}
+
 	// verify email
 	if setting.VerifyEmailEnabled {
 		if ok, rsp := verifyUserSignUpEmail(form.Email, form.Code); !ok {
@@ -209,7 +209,7 @@ func (hs *HTTPServer) ChangeActiveOrgAndRedirectToHome(c *m.ReqContext) {
 	c.Redirect(setting.AppSubUrl + "/")
 }
 
-func ChangeUserPassword(c *m.ReqContext, cmd m.ChangeUserPasswordCommand) Response {
+func (hs *HTTPServer) ChangeUserPassword(c *m.ReqContext, cmd m.ChangeUserPasswordCommand) Response {
 	if setting.LDAPEnabled || setting.AuthProxyEnabled {
 		return Error(400, "Not allowed to change password when LDAP or Auth Proxy is enabled", nil)
 	}
@@ -225,9 +225,8 @@ func ChangeUserPassword(c *m.ReqContext, cmd m.ChangeUserPasswordCommand) Respon
 		return Error(401, "Invalid old password", nil)
 	}
 
-	password := m.Password(cmd.NewPassword)
-	if password.IsWeak() {
-		return Error(400, "New password is too short", nil)
+	if rsp := hs.validatePassword(userQuery.Result, cmd.NewPassword); rsp != nil {
+		return rsp
 	}
 
 	cmd.UserId = c.UserId
@@ -3,6 +3,8 @@ package commands
 import (
 	"archive/zip"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -10,11 +12,15 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
 
 	"github.com/Seasheller/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/Seasheller/grafana/pkg/plugins/signature"
+	"github.com/Seasheller/grafana/pkg/util"
 	"github.com/fatih/color"
+	"github.com/hashicorp/go-version"
 
 	"github.com/Seasheller/grafana/pkg/cmd/grafana-cli/logger"
 	m "github.com/Seasheller/grafana/pkg/cmd/grafana-cli/models"
@@ -59,11 +65,22 @@ func installCommand(c utils.CommandLine) error {
 	return InstallPlugin(pluginToInstall, version, c)
 }
 
-// InstallPlugin downloads the plugin code as a zip file from the Grafana.com API
-// and then extracts the zip into the plugins directory.
+// InstallPlugin downloads the plugin code as a zip file from the Grafana.com API,
+// or a static plugin repository, and then extracts the zip into the plugins
+// directory. The plugins it depends on are installed as well.
 func InstallPlugin(pluginName, version string, c utils.CommandLine) error {
+	return installPlugin(pluginName, version, c.PluginURL(), c, map[string]bool{})
+}
+
+// installPlugin installs the plugin from the download url, which is either
+// an url or path to a zip file or the path to the directory of a plugin, or
+// from the plugin repository when it is empty. Installing tracks the plugins
+// being installed, so that dependency cycles are only installed once.
+func installPlugin(pluginName, version, downloadURL string, c utils.CommandLine, installing map[string]bool) error {
+	installing[pluginName] = true
+
 	pluginFolder := c.PluginDirectory()
-	downloadURL := c.PluginURL()
+	checksum := ""
 	if downloadURL == "" {
 		plugin, err := s.GetPlugin(pluginName, c.RepoDirectory())
 		if err != nil {
@@ -75,13 +92,9 @@ func InstallPlugin(pluginName, version string, c utils.CommandLine) error {
 			return err
 		}
 
-		if version == "" {
-			version = v.Version
-		}
-		downloadURL = fmt.Sprintf("%s/%s/versions/%s/download",
-			c.GlobalString("repo"),
-			pluginName,
-			version)
+		version = v.Version
+		checksum = v.Checksum
+		downloadURL = s.GetDownloadURL(c.RepoDirectory(), plugin, v)
 	}
 
 	logger.Infof("installing %v @ %v\n", pluginName, version)
@@ -89,23 +102,177 @@ func InstallPlugin(pluginName, version string, c utils.CommandLine) error {
 	logger.Infof("into: %v\n", pluginFolder)
 	logger.Info("\n")
 
-	err := downloadFile(pluginName, pluginFolder, downloadURL)
+	var err error
+	if fileInfo, statErr := os.Stat(downloadURL); statErr == nil && fileInfo.IsDir() {
+		err = copyPluginDir(downloadURL, path.Join(pluginFolder, pluginName))
+	} else {
+		err = downloadFile(pluginName, pluginFolder, downloadURL, checksum)
+	}
 	if err != nil {
 		return err
 	}
 
+	if err := verifySignature(c, pluginFolder, pluginName); err != nil {
+		return err
+	}
+
 	logger.Infof("%s Installed %s successfully \n", color.GreenString("✔"), pluginName)
 
-	res, _ := s.ReadPlugin(pluginFolder, pluginName)
-	for _, v := range res.Dependencies.Plugins {
-		InstallPlugin(v.Id, "", c)
-		logger.Infof("Installed dependency: %v ✔\n", v.Id)
+	return installDependencies(pluginName, c, installing)
+}
+
+// installDependencies installs the plugins the installed plugin depends on,
+// which are not installed yet or whose installed version does not satisfy
+// the dependency, from the plugin repository. Dependencies that are not in
+// the plugin repository, like the plugins shipped with Grafana, are skipped.
+func installDependencies(pluginName string, c utils.CommandLine, installing map[string]bool) error {
+	pluginFolder := c.PluginDirectory()
+
+	res, err := s.ReadPlugin(pluginFolder, pluginName)
+	if err != nil {
+		return err
+	}
+
+	for _, dep := range res.Dependencies.Plugins {
+		if installing[dep.Id] {
+			continue
+		}
+
+		installed, err := s.ReadPlugin(pluginFolder, dep.Id)
+		isInstalled := err == nil
+		if isInstalled && dependencySatisfied(installed.Info.Version, dep.Version) {
+			logger.Infof("Dependency %v @ %v is installed\n", dep.Id, installed.Info.Version)
+			continue
+		}
+
+		plugin, err := s.GetPlugin(dep.Id, c.RepoDirectory())
+		if err != nil {
+			logger.Infof("%s Skipping dependency %v: %v\n", color.YellowString("!"), dep.Id, err)
+			continue
+		}
+
+		v, err := selectDependencyVersion(plugin, dep.Version)
+		if err != nil {
+			return fmt.Errorf("Failed to install dependency %v of %v: %v", dep.Id, pluginName, err)
+		}
+
+		if isInstalled {
+			if err := s.RemoveInstalledPlugin(pluginFolder, dep.Id); err != nil {
+				return err
+			}
+		}
+
+		if err := installPlugin(dep.Id, v.Version, "", c, installing); err != nil {
+			return fmt.Errorf("Failed to install dependency %v of %v: %v", dep.Id, pluginName, err)
+		}
+		logger.Infof("Installed dependency: %v ✔\n", dep.Id)
+	}
+
+	return nil
+}
+
+// dependencySatisfied returns true if the installed version satisfies the
+// required version, which is either the minimum version or a version
+// constraint.
+func dependencySatisfied(installed, required string) bool {
+	if required == "" {
+		return true
+	}
+
+	installedVersion, err := version.NewVersion(installed)
+	if err != nil {
+		return false
+	}
+
+	constraint := required
+	if _, err := version.NewVersion(required); err == nil {
+		constraint = ">= " + required
+	}
+
+	constraints, err := version.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+
+	return constraints.Check(installedVersion)
+}
+
+// selectDependencyVersion returns the highest version of the plugin that
+// satisfies the required version.
+func selectDependencyVersion(plugin m.Plugin, required string) (m.Version, error) {
+	var result m.Version
+	var highest *version.Version
+
+	for _, v := range plugin.Versions {
+		if !dependencySatisfied(v.Version, required) {
+			continue
+		}
+
+		current, err := version.NewVersion(v.Version)
+		if err != nil {
+			continue
+		}
+
+		if highest == nil || current.GreaterThan(highest) {
+			result = v
+			highest = current
+		}
+	}
+
+	if highest == nil {
+		return m.Version{}, fmt.Errorf("Could not find a version that satisfies %v", required)
 	}
 
-	return err
+	return result, nil
+}
+
+// verifySignature verifies the manifest of the installed plugin, and removes
+// the plugin again when it has no valid signature and the signature policy
+// refuses it.
+func verifySignature(c utils.CommandLine, pluginFolder, pluginName string) error {
+	plugin, err := s.ReadPlugin(pluginFolder, pluginName)
+	if err != nil {
+		return err
+	}
+
+	keys, err := signature.ParseKeys(util.SplitString(c.GlobalString("signatureKeys")))
+	if err != nil {
+		return err
+	}
+
+	pluginDir := path.Join(pluginFolder, pluginName, "dist")
+	if _, err := os.Stat(path.Join(pluginDir, "plugin.json")); err != nil {
+		pluginDir = path.Join(pluginFolder, pluginName)
+	}
+
+	status, reason := signature.Verify(pluginDir, plugin.Id, keys)
+	if status == signature.StatusValid {
+		logger.Infof("%s Signature of %s is valid\n", color.GreenString("✔"), pluginName)
+		return nil
+	}
+
+	for _, id := range util.SplitString(c.GlobalString("allowUnsignedPlugins")) {
+		if id == plugin.Id {
+			return nil
+		}
+	}
+
+	if c.GlobalString("signaturePolicy") == "refuse" {
+		if err := s.RemoveInstalledPlugin(pluginFolder, pluginName); err != nil {
+			logger.Errorf("Failed to remove plugin: %v\n", err)
+		}
+		return fmt.Errorf("Plugin %s has signature status %s: %v", pluginName, status, reason)
+	}
+
+	logger.Infof("%s Plugin %s has signature status %s: %v\n", color.YellowString("!"), pluginName, status, reason)
+	return nil
 }
 
 func SelectVersion(plugin m.Plugin, version string) (m.Version, error) {
+	if len(plugin.Versions) == 0 {
+		return m.Version{}, errors.New("Could not find any version of the plugin")
+	}
+
 	if version == "" {
 		return plugin.Versions[0], nil
 	}
@@ -127,13 +294,13 @@ func RemoveGitBuildFromName(pluginName, filename string) string {
 var retryCount = 0
 var permissionsDeniedMessage = "Could not create %s. Permission denied. Make sure you have write access to plugindir"
 
-func downloadFile(pluginName, filePath, url string) (err error) {
+func downloadFile(pluginName, filePath, url, checksum string) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			retryCount++
 			if retryCount < 3 {
 				fmt.Println("Failed downloading. Will retry once.")
-				err = downloadFile(pluginName, filePath, url)
+				err = downloadFile(pluginName, filePath, url, checksum)
 			} else {
 				failure := fmt.Sprintf("%v", r)
 				if failure == "runtime error: makeslice: len out of range" {
@@ -159,15 +326,72 @@ func downloadFile(pluginName, filePath, url string) (err error) {
 		}
 		defer resp.Body.Close()
 
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("Failed to download %s: %s", url, resp.Status)
+		}
+
 		bytes, err = ioutil.ReadAll(resp.Body)
 		if err != nil {
 			return err
 		}
 	}
 
+	if err := verifyChecksum(bytes, checksum); err != nil {
+		return err
+	}
+
 	return extractFiles(bytes, pluginName, filePath)
 }
 
+// verifyChecksum compares the hex encoded SHA-256 checksum, if there is one,
+// with the checksum of the downloaded zip file.
+func verifyChecksum(body []byte, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256(body)
+	if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, checksum) {
+		return fmt.Errorf("Checksum of the downloaded plugin %s does not match the expected checksum %s", actual, checksum)
+	}
+
+	return nil
+}
+
+// copyPluginDir copies the directory of a plugin into the plugins directory.
+func copyPluginDir(src, dst string) error {
+	return filepath.Walk(src, func(srcPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, srcPath)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join(dst, rel)
+
+		if info.IsDir() {
+			err := os.MkdirAll(dstPath, 0755)
+			if permissionsError(err) {
+				return fmt.Errorf(permissionsDeniedMessage, dstPath)
+			}
+			return err
+		}
+
+		data, err := ioutil.ReadFile(srcPath)
+		if err != nil {
+			return err
+		}
+
+		err = ioutil.WriteFile(dstPath, data, info.Mode())
+		if permissionsError(err) {
+			return fmt.Errorf(permissionsDeniedMessage, dstPath)
+		}
+		return err
+	})
+}
+
 func extractFiles(body []byte, pluginName string, filePath string) error {
 	r, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
 	if err != nil {
@@ -1,10 +1,14 @@
 package commands
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
+	m "github.com/Seasheller/grafana/pkg/cmd/grafana-cli/models"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -78,3 +82,76 @@ func TestExtractFiles(t *testing.T) {
 		So(err, ShouldBeNil)
 	})
 }
+
+func TestDependencyVersions(t *testing.T) {
+	Convey("When checking if an installed version satisfies a dependency", t, func() {
+		So(dependencySatisfied("1.2.0", ""), ShouldBeTrue)
+		So(dependencySatisfied("1.2.0", "1.0.0"), ShouldBeTrue)
+		So(dependencySatisfied("1.2.0", "1.2.0"), ShouldBeTrue)
+		So(dependencySatisfied("1.2.0", "1.3.0"), ShouldBeFalse)
+		So(dependencySatisfied("1.2.0", ">=1.0.0, <2.0.0"), ShouldBeTrue)
+		So(dependencySatisfied("2.1.0", ">=1.0.0, <2.0.0"), ShouldBeFalse)
+		So(dependencySatisfied("not-a-version", "1.0.0"), ShouldBeFalse)
+	})
+
+	Convey("When selecting the version of a dependency", t, func() {
+		plugin := m.Plugin{
+			Id: "test-app",
+			Versions: []m.Version{
+				{Version: "1.1.0"},
+				{Version: "2.0.0"},
+				{Version: "1.3.0"},
+			},
+		}
+
+		Convey("Should select the highest version that satisfies it", func() {
+			v, err := selectDependencyVersion(plugin, "<2.0.0")
+			So(err, ShouldBeNil)
+			So(v.Version, ShouldEqual, "1.3.0")
+
+			v, err = selectDependencyVersion(plugin, "1.0.0")
+			So(err, ShouldBeNil)
+			So(v.Version, ShouldEqual, "2.0.0")
+		})
+
+		Convey("Should fail if no version satisfies it", func() {
+			_, err := selectDependencyVersion(plugin, "3.0.0")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestDownloadChecksum(t *testing.T) {
+	Convey("When downloading a plugin with a checksum", t, func() {
+		err := os.RemoveAll("testdata/fake-plugins-dir")
+		So(err, ShouldBeNil)
+
+		err = os.MkdirAll("testdata/fake-plugins-dir", 0774)
+		So(err, ShouldBeNil)
+
+		zipFile := "testdata/grafana-simple-json-datasource-ec18fa4da8096a952608a7e4c7782b4260b41bcf.zip"
+		body, err := ioutil.ReadFile(zipFile)
+		So(err, ShouldBeNil)
+		sum := sha256.Sum256(body)
+
+		Convey("Should install it if the checksum matches", func() {
+			err := downloadFile("grafana-simple-json-datasource", "testdata/fake-plugins-dir", zipFile, hex.EncodeToString(sum[:]))
+			So(err, ShouldBeNil)
+
+			_, err = os.Stat("testdata/fake-plugins-dir/grafana-simple-json-datasource/simple-plugin_linux_amd64")
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Should not install it if the checksum does not match", func() {
+			err := downloadFile("grafana-simple-json-datasource", "testdata/fake-plugins-dir", zipFile, strings.Repeat("0", 64))
+			So(err, ShouldNotBeNil)
+
+			_, err = os.Stat("testdata/fake-plugins-dir/grafana-simple-json-datasource")
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Reset(func() {
+			os.RemoveAll("testdata/fake-plugins-dir")
+		})
+	})
+}
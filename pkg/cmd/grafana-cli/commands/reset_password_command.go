@@ -7,6 +7,7 @@ import (
 	"github.com/Seasheller/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/Seasheller/grafana/pkg/cmd/grafana-cli/utils"
 	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/passwordpolicy"
 	"github.com/Seasheller/grafana/pkg/services/sqlstore"
 	"github.com/Seasheller/grafana/pkg/util"
 	"github.com/fatih/color"
@@ -17,17 +18,16 @@ const AdminUserId = 1
 func resetPasswordCommand(c utils.CommandLine, sqlStore *sqlstore.SqlStore) error {
 	newPassword := c.Args().First()
 
-	password := models.Password(newPassword)
-	if password.IsWeak() {
-		return fmt.Errorf("New password is too short")
-	}
-
 	userQuery := models.GetUserByIdQuery{Id: AdminUserId}
 
 	if err := bus.Dispatch(&userQuery); err != nil {
 		return fmt.Errorf("Could not read user from database. Error: %v", err)
 	}
 
+	if err := passwordpolicy.Validate(sqlStore.Cfg.PasswordPolicy, userQuery.Result, newPassword); err != nil {
+		return err
+	}
+
 	passwordHashed := util.EncodePassword(newPassword, userQuery.Result.Salt)
 
 	cmd := models.ChangeUserPasswordCommand{
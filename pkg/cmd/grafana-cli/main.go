@@ -33,13 +33,13 @@ func main() {
 		},
 		cli.StringFlag{
 			Name:   "repo",
-			Usage:  "url to the plugin repository",
+			Usage:  "url to the plugin repository, or url or path to the index of a static plugin repository",
 			Value:  "https://grafana.com/api/plugins",
 			EnvVar: "GF_PLUGIN_REPO",
 		},
 		cli.StringFlag{
 			Name:   "pluginUrl",
-			Usage:  "Full url to the plugin zip file instead of downloading the plugin from grafana.com/api",
+			Usage:  "Full url or path to the plugin zip file or path to the plugin directory instead of downloading the plugin from grafana.com/api",
 			Value:  "",
 			EnvVar: "GF_PLUGIN_URL",
 		},
@@ -47,6 +47,22 @@ func main() {
 			Name:  "insecure",
 			Usage: "Skip TLS verification (insecure)",
 		},
+		cli.StringFlag{
+			Name:   "signatureKeys",
+			Usage:  "trusted keys plugin manifests can be signed with, as <key id>:<base64 Ed25519 public key>, separated by comma",
+			EnvVar: "GF_PLUGINS_SIGNATURE_KEYS",
+		},
+		cli.StringFlag{
+			Name:   "signaturePolicy",
+			Usage:  "what to do with installed plugins without a valid signature: warn or refuse",
+			Value:  "warn",
+			EnvVar: "GF_PLUGINS_SIGNATURE_POLICY",
+		},
+		cli.StringFlag{
+			Name:   "allowUnsignedPlugins",
+			Usage:  "ids of plugins that are installed without a valid signature, separated by comma",
+			EnvVar: "GF_PLUGINS_ALLOW_UNSIGNED_PLUGINS",
+		},
 		cli.BoolFlag{
 			Name:  "debug, d",
 			Usage: "enable debug logging",
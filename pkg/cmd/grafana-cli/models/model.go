@@ -14,8 +14,17 @@ type InstalledPlugin struct {
 }
 
 type Dependencies struct {
-	GrafanaVersion string   `json:"grafanaVersion"`
-	Plugins        []Plugin `json:"plugins"`
+	GrafanaVersion string       `json:"grafanaVersion"`
+	Plugins        []Dependency `json:"plugins"`
+}
+
+// Dependency is a plugin another plugin depends on. The version is the
+// minimum version, or a version constraint like ">=1.2.0, <2.0.0".
+type Dependency struct {
+	Id      string `json:"id"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
 }
 
 type PluginInfo struct {
@@ -29,10 +38,15 @@ type Plugin struct {
 	Versions []Version `json:"versions"`
 }
 
+// Version is a version of a plugin in a plugin repository. Static
+// repositories can give the url of the zip file of the version, relative to
+// the index, and its hex encoded SHA-256 checksum.
 type Version struct {
-	Commit  string `json:"commit"`
-	Url     string `json:"url"`
-	Version string `json:"version"`
+	Commit      string `json:"commit"`
+	Url         string `json:"url"`
+	Version     string `json:"version"`
+	DownloadUrl string `json:"downloadUrl,omitempty"`
+	Checksum    string `json:"checksum,omitempty"`
 }
 
 type PluginRepo struct {
@@ -49,6 +49,10 @@ func Init(version string, skipTLSVerify bool) {
 }
 
 func ListAllPlugins(repoUrl string) (m.PluginRepo, error) {
+	if IsStaticRepo(repoUrl) {
+		return readStaticRepo(repoUrl)
+	}
+
 	body, err := sendRequest(repoUrl, "repo")
 
 	if err != nil {
@@ -123,6 +127,10 @@ func RemoveInstalledPlugin(pluginPath, pluginName string) error {
 
 func GetPlugin(pluginId, repoUrl string) (m.Plugin, error) {
 	logger.Debugf("getting plugin metadata from: %v pluginId: %v \n", repoUrl, pluginId)
+	if IsStaticRepo(repoUrl) {
+		return getStaticRepoPlugin(pluginId, repoUrl)
+	}
+
 	body, err := sendRequest(repoUrl, "repo", pluginId)
 
 	if err != nil {
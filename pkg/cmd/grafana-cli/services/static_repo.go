@@ -0,0 +1,118 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	m "github.com/Seasheller/grafana/pkg/cmd/grafana-cli/models"
+)
+
+// StaticRepoIndexFileName is the name of the index of a static repository
+// that is given as a local directory.
+const StaticRepoIndexFileName = "repo.json"
+
+// IsStaticRepo returns true if the repository is a static index file, with
+// the schema of the plugin list of the grafana.com API, rather than the
+// grafana.com API. The index is given by its URL or local path ending with
+// .json, or by the local directory it is in.
+func IsStaticRepo(repoUrl string) bool {
+	if strings.HasSuffix(strings.ToLower(repoUrl), ".json") {
+		return true
+	}
+
+	return isLocalPath(repoUrl)
+}
+
+func staticRepoIndex(repoUrl string) string {
+	if isLocalPath(repoUrl) {
+		if fileInfo, err := IoHelper.Stat(repoUrl); err == nil && fileInfo.IsDir() {
+			return filepath.Join(repoUrl, StaticRepoIndexFileName)
+		}
+	}
+
+	return repoUrl
+}
+
+func readStaticRepo(repoUrl string) (m.PluginRepo, error) {
+	index := staticRepoIndex(repoUrl)
+
+	var body []byte
+	var err error
+	if isLocalPath(index) {
+		body, err = IoHelper.ReadFile(index)
+	} else {
+		body, err = sendRequest(index)
+	}
+	if err != nil {
+		return m.PluginRepo{}, fmt.Errorf("Failed to read plugin repository index %s. error: %v", index, err)
+	}
+
+	var data m.PluginRepo
+	if err := json.Unmarshal(body, &data); err != nil {
+		return m.PluginRepo{}, fmt.Errorf("Failed to parse plugin repository index %s. error: %v", index, err)
+	}
+
+	return data, nil
+}
+
+func getStaticRepoPlugin(pluginId, repoUrl string) (m.Plugin, error) {
+	repo, err := readStaticRepo(repoUrl)
+	if err != nil {
+		return m.Plugin{}, err
+	}
+
+	for _, plugin := range repo.Plugins {
+		if plugin.Id == pluginId {
+			return plugin, nil
+		}
+	}
+
+	return m.Plugin{}, fmt.Errorf("Failed to find requested plugin, check if the plugin_id is correct. error: %v", ErrNotFoundError)
+}
+
+// GetDownloadURL returns the URL or local path to download the version of the
+// plugin from. Static repositories give it as the download URL of the
+// version, relative to the index, and default to <plugin id>-<version>.zip
+// next to the index.
+func GetDownloadURL(repoUrl string, plugin m.Plugin, v m.Version) string {
+	if !IsStaticRepo(repoUrl) {
+		return fmt.Sprintf("%s/%s/versions/%s/download", repoUrl, plugin.Id, v.Version)
+	}
+
+	downloadUrl := v.DownloadUrl
+	if downloadUrl == "" {
+		downloadUrl = fmt.Sprintf("%s-%s.zip", plugin.Id, v.Version)
+	}
+
+	index := staticRepoIndex(repoUrl)
+	if isLocalPath(index) {
+		if isLocalPath(downloadUrl) && !filepath.IsAbs(downloadUrl) {
+			return filepath.Join(filepath.Dir(index), filepath.FromSlash(downloadUrl))
+		}
+		return downloadUrl
+	}
+
+	base, err := url.Parse(index)
+	if err != nil {
+		return downloadUrl
+	}
+	ref, err := url.Parse(downloadUrl)
+	if err != nil {
+		return downloadUrl
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+// isLocalPath returns true unless the location is an http or https URL.
+func isLocalPath(location string) bool {
+	u, err := url.Parse(location)
+	if err != nil {
+		return true
+	}
+
+	return u.Scheme != "http" && u.Scheme != "https"
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	m "github.com/Seasheller/grafana/pkg/cmd/grafana-cli/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testRepoIndex = `{
+  "plugins": [
+    {
+      "id": "test-app",
+      "versions": [
+        {"version": "1.1.0", "downloadUrl": "zips/test-app-1.1.0.zip", "checksum": "abc"},
+        {"version": "1.0.0"}
+      ]
+    }
+  ]
+}`
+
+func TestStaticRepo(t *testing.T) {
+	Convey("Given a static repository in a local directory", t, func() {
+		dir, err := ioutil.TempDir("", "plugin-repo")
+		So(err, ShouldBeNil)
+		err = ioutil.WriteFile(filepath.Join(dir, StaticRepoIndexFileName), []byte(testRepoIndex), 0644)
+		So(err, ShouldBeNil)
+
+		So(IsStaticRepo(dir), ShouldBeTrue)
+
+		Convey("Should list its plugins", func() {
+			repo, err := ListAllPlugins(dir)
+			So(err, ShouldBeNil)
+			So(repo.Plugins, ShouldHaveLength, 1)
+			So(repo.Plugins[0].Versions[0].Checksum, ShouldEqual, "abc")
+		})
+
+		Convey("Should get a plugin", func() {
+			plugin, err := GetPlugin("test-app", dir)
+			So(err, ShouldBeNil)
+			So(plugin.Versions, ShouldHaveLength, 2)
+
+			Convey("With download urls relative to the index", func() {
+				So(GetDownloadURL(dir, plugin, plugin.Versions[0]), ShouldEqual, filepath.Join(dir, "zips", "test-app-1.1.0.zip"))
+				So(GetDownloadURL(dir, plugin, plugin.Versions[1]), ShouldEqual, filepath.Join(dir, "test-app-1.0.0.zip"))
+			})
+		})
+
+		Convey("Should fail to get a plugin that is not in it", func() {
+			_, err := GetPlugin("other-app", dir)
+			So(err, ShouldNotBeNil)
+		})
+
+		Reset(func() {
+			os.RemoveAll(dir)
+		})
+	})
+
+	Convey("Given a static repository on a web server", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/mirror/repo.json" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(testRepoIndex))
+		}))
+		repoUrl := server.URL + "/mirror/repo.json"
+
+		So(IsStaticRepo(repoUrl), ShouldBeTrue)
+		So(IsStaticRepo("https://grafana.com/api/plugins"), ShouldBeFalse)
+
+		Convey("Should get a plugin with download urls relative to the index", func() {
+			plugin, err := GetPlugin("test-app", repoUrl)
+			So(err, ShouldBeNil)
+			So(GetDownloadURL(repoUrl, plugin, plugin.Versions[0]), ShouldEqual, server.URL+"/mirror/zips/test-app-1.1.0.zip")
+			So(GetDownloadURL(repoUrl, plugin, plugin.Versions[1]), ShouldEqual, server.URL+"/mirror/test-app-1.0.0.zip")
+		})
+
+		Convey("Should use the download api of grafana.com", func() {
+			url := GetDownloadURL("https://grafana.com/api/plugins", m.Plugin{Id: "test-app"}, m.Version{Version: "1.0.0"})
+			So(url, ShouldEqual, "https://grafana.com/api/plugins/test-app/versions/1.0.0/download")
+		})
+
+		Reset(func() {
+			server.Close()
+		})
+	})
+}
@@ -33,10 +33,13 @@ import (
 	_ "github.com/Seasheller/grafana/pkg/services/alerting"
 	_ "github.com/Seasheller/grafana/pkg/services/auth"
 	_ "github.com/Seasheller/grafana/pkg/services/cleanup"
+	_ "github.com/Seasheller/grafana/pkg/services/ldapsync"
 	_ "github.com/Seasheller/grafana/pkg/services/notifications"
 	_ "github.com/Seasheller/grafana/pkg/services/provisioning"
 	_ "github.com/Seasheller/grafana/pkg/services/rendering"
+	_ "github.com/Seasheller/grafana/pkg/services/reports"
 	_ "github.com/Seasheller/grafana/pkg/services/search"
+	_ "github.com/Seasheller/grafana/pkg/services/snapshots"
 	_ "github.com/Seasheller/grafana/pkg/services/sqlstore"
 	"github.com/Seasheller/grafana/pkg/setting"
 )
@@ -72,7 +75,7 @@ func (g *GrafanaServerImpl) Run() error {
 	g.loadConfiguration()
 	g.writePIDFile()
 
-	login.Init()
+	login.Init(g.cfg)
 	social.NewOAuthService()
 
 	serviceGraph := inject.Graph{}
@@ -24,6 +24,7 @@ const (
 	DiffJSON DiffType = iota
 	DiffBasic
 	DiffDelta
+	DiffSemantic
 )
 
 type Options struct {
@@ -51,6 +52,8 @@ func ParseDiffType(diff string) DiffType {
 		return DiffBasic
 	case "delta":
 		return DiffDelta
+	case "semantic":
+		return DiffSemantic
 	}
 	return DiffBasic
 }
@@ -81,6 +84,10 @@ func CalculateDiff(options *Options) (*Result, error) {
 	baseData := baseVersionQuery.Result.Data
 	newData := newVersionQuery.Result.Data
 
+	if options.DiffType == DiffSemantic {
+		return calculateSemanticDiff(baseData, newData)
+	}
+
 	left, jsonDiff, err := getDiff(baseData, newData)
 	if err != nil {
 		return nil, err
@@ -118,6 +125,22 @@ func CalculateDiff(options *Options) (*Result, error) {
 	return result, nil
 }
 
+// calculateSemanticDiff lists the changes of the two versions as JSON. Unlike
+// the other diff types, identical versions result in an empty list.
+func calculateSemanticDiff(baseData, newData *simplejson.Json) (*Result, error) {
+	dashDiff, err := CompareDashboards(baseData, newData)
+	if err != nil {
+		return nil, err
+	}
+
+	delta, err := json.Marshal(dashDiff)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Delta: delta}, nil
+}
+
 // getDiff computes the diff of two dashboard versions.
 func getDiff(baseData, newData *simplejson.Json) (interface{}, diff.Diff, error) {
 	leftBytes, err := baseData.Encode()
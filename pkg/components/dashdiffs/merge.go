@@ -0,0 +1,223 @@
+package dashdiffs
+
+import (
+	"reflect"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	"github.com/Seasheller/grafana/pkg/models"
+)
+
+// MergeConflict is a part of a dashboard that was changed differently in
+// both versions being merged. The merged dashboard keeps their version.
+type MergeConflict struct {
+	Target  ChangeTarget `json:"target"`
+	PanelId int64        `json:"panelId,omitempty"`
+	Name    string       `json:"name,omitempty"`
+	Ours    interface{}  `json:"ours"`
+	Theirs  interface{}  `json:"theirs"`
+}
+
+// MergeResult is the result of a three-way merge of dashboard versions.
+type MergeResult struct {
+	Dashboard *simplejson.Json `json:"dashboard"`
+	Conflicts []*MergeConflict `json:"conflicts"`
+}
+
+// MergeDashboards merges the changes made to a dashboard by a user whose
+// save failed with models.ErrDashboardVersionMismatch (ours) with the
+// changes saved by someone else in the meantime (theirs). Base is the
+// version both were based on.
+//
+// Dashboard properties, top level panels matched by id and variables matched
+// by name are merged as a whole. When one of them was changed differently in
+// both versions it is reported as a conflict and their version is kept. The
+// merged dashboard has the version of theirs, so it can be saved when there
+// are no conflicts or the conflicts have been resolved.
+func MergeDashboards(baseData, oursData, theirsData *simplejson.Json) (*MergeResult, error) {
+	base, err := toPlainMap(baseData)
+	if err != nil {
+		return nil, err
+	}
+
+	ours, err := toPlainMap(oursData)
+	if err != nil {
+		return nil, err
+	}
+
+	theirs, err := toPlainMap(theirsData)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MergeResult{Conflicts: make([]*MergeConflict, 0)}
+	merged := make(map[string]interface{})
+
+	for _, key := range unionKeys(base, ours, theirs) {
+		if key == "panels" || key == "templating" {
+			continue
+		}
+
+		value, conflict := mergeValue(base[key], ours[key], theirs[key])
+		if conflict {
+			result.Conflicts = append(result.Conflicts, &MergeConflict{Target: TargetDashboard, Name: key, Ours: ours[key], Theirs: theirs[key]})
+		}
+
+		if value != nil {
+			merged[key] = value
+		}
+	}
+
+	if panels := result.mergePanels(base, ours, theirs); panels != nil {
+		merged["panels"] = panels
+	}
+
+	if templating := result.mergeTemplating(base, ours, theirs); templating != nil {
+		merged["templating"] = templating
+	}
+
+	if version, ok := theirs["version"]; ok {
+		merged["version"] = version
+	}
+
+	result.Dashboard = simplejson.NewFromAny(merged)
+	return result, nil
+}
+
+// MergeWithLatestVersion merges the unsaved dashboard with the changes
+// saved in the latest version of the dashboard since the version the unsaved
+// dashboard is based on.
+func MergeWithLatestVersion(latest *models.Dashboard, ours *simplejson.Json) (*MergeResult, error) {
+	baseVersionQuery := models.GetDashboardVersionQuery{
+		OrgId:       latest.OrgId,
+		DashboardId: latest.Id,
+		Version:     ours.Get("version").MustInt(),
+	}
+
+	if err := bus.Dispatch(&baseVersionQuery); err != nil {
+		return nil, err
+	}
+
+	return MergeDashboards(baseVersionQuery.Result.Data, ours, latest.Data)
+}
+
+func (r *MergeResult) mergePanels(base, ours, theirs map[string]interface{}) []interface{} {
+	basePanels := panelsById(base)
+	ourPanels := panelsById(ours)
+	theirPanels := panelsById(theirs)
+
+	if basePanels == nil && ourPanels == nil && theirPanels == nil {
+		return nil
+	}
+
+	list := make([]interface{}, 0)
+	merged := make(map[int64]bool)
+	for _, p := range append(orderedPanels(theirs), orderedPanels(ours)...) {
+		id := toInt64(p.(map[string]interface{})["id"])
+		if merged[id] {
+			continue
+		}
+		merged[id] = true
+
+		value, conflict := mergeValue(basePanels[id], ourPanels[id], theirPanels[id])
+		if conflict {
+			r.Conflicts = append(r.Conflicts, &MergeConflict{
+				Target:  TargetPanel,
+				PanelId: id,
+				Name:    panelTitle(p.(map[string]interface{})),
+				Ours:    ourPanels[id],
+				Theirs:  theirPanels[id],
+			})
+		}
+
+		if value != nil {
+			list = append(list, value)
+		}
+	}
+
+	return list
+}
+
+func (r *MergeResult) mergeTemplating(base, ours, theirs map[string]interface{}) interface{} {
+	baseVariables := getVariables(base)
+	ourVariables := getVariables(ours)
+	theirVariables := getVariables(theirs)
+
+	if ours["templating"] == nil && theirs["templating"] == nil {
+		return nil
+	}
+
+	list := make([]interface{}, 0)
+	merged := make(map[string]bool)
+	for _, name := range append(variableNames(theirs), variableNames(ours)...) {
+		if merged[name] {
+			continue
+		}
+		merged[name] = true
+
+		value, conflict := mergeValue(baseVariables[name], ourVariables[name], theirVariables[name])
+		if conflict {
+			r.Conflicts = append(r.Conflicts, &MergeConflict{Target: TargetVariable, Name: name, Ours: ourVariables[name], Theirs: theirVariables[name]})
+		}
+
+		if value != nil {
+			list = append(list, value)
+		}
+	}
+
+	templating := make(map[string]interface{})
+	if t, ok := theirs["templating"].(map[string]interface{}); ok {
+		templating = copyMap(t)
+	}
+	templating["list"] = list
+
+	return templating
+}
+
+// mergeValue returns the merged value, and whether both versions changed the
+// value differently, in which case their value is returned.
+func mergeValue(base, ours, theirs interface{}) (interface{}, bool) {
+	if reflect.DeepEqual(ours, theirs) || reflect.DeepEqual(ours, base) {
+		return theirs, false
+	}
+
+	if reflect.DeepEqual(theirs, base) {
+		return ours, false
+	}
+
+	return theirs, true
+}
+
+func orderedPanels(dash map[string]interface{}) []interface{} {
+	panels, _ := dash["panels"].([]interface{})
+	result := make([]interface{}, 0, len(panels))
+	for _, p := range panels {
+		if _, ok := p.(map[string]interface{}); ok {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func panelsById(dash map[string]interface{}) map[int64]interface{} {
+	if _, ok := dash["panels"]; !ok {
+		return nil
+	}
+
+	result := make(map[int64]interface{})
+	for _, p := range orderedPanels(dash) {
+		result[toInt64(p.(map[string]interface{})["id"])] = p
+	}
+	return result
+}
+
+func variableNames(dash map[string]interface{}) []string {
+	templating, _ := dash["templating"].(map[string]interface{})
+	list, _ := templating["list"].([]interface{})
+
+	names := make([]string, 0, len(list))
+	for i, v := range list {
+		names = append(names, variableName(i, v))
+	}
+	return names
+}
@@ -0,0 +1,432 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+)
+
+// ChangeAction is what happened to the changed part of a dashboard.
+type ChangeAction string
+
+const (
+	ActionAdded   ChangeAction = "added"
+	ActionRemoved ChangeAction = "removed"
+	ActionMoved   ChangeAction = "moved"
+	ActionUpdated ChangeAction = "updated"
+)
+
+// ChangeTarget is the part of a dashboard a change applies to.
+type ChangeTarget string
+
+const (
+	TargetDashboard ChangeTarget = "dashboard"
+	TargetPanel     ChangeTarget = "panel"
+	TargetQuery     ChangeTarget = "query"
+	TargetThreshold ChangeTarget = "threshold"
+	TargetVariable  ChangeTarget = "variable"
+	TargetAlert     ChangeTarget = "alert"
+)
+
+// DashboardChange is a single change between two versions of a dashboard.
+// Name is the changed dashboard or panel property, the refId of a query or
+// the name of a variable. Old and New are the values before and after the
+// change, and are empty for added and removed items respectively.
+type DashboardChange struct {
+	Action     ChangeAction `json:"action"`
+	Target     ChangeTarget `json:"target"`
+	PanelId    int64        `json:"panelId,omitempty"`
+	PanelTitle string       `json:"panelTitle,omitempty"`
+	Name       string       `json:"name,omitempty"`
+	Old        interface{}  `json:"old,omitempty"`
+	New        interface{}  `json:"new,omitempty"`
+}
+
+// DashboardDiff lists the changes between two versions of a dashboard.
+type DashboardDiff struct {
+	Changes []*DashboardChange `json:"changes"`
+}
+
+// dashboardKeysIgnored are dashboard properties that change on every save
+// or are compared separately.
+var dashboardKeysIgnored = map[string]bool{
+	"id":         true,
+	"version":    true,
+	"panels":     true,
+	"rows":       true,
+	"templating": true,
+}
+
+// panelKeysIgnored are panel properties that are compared separately.
+var panelKeysIgnored = map[string]bool{
+	"id":      true,
+	"gridPos": true,
+	"panels":  true,
+	"targets": true,
+	"alert":   true,
+}
+
+// thresholdPaths are the paths of the thresholds in the different panel types.
+var thresholdPaths = [][]string{
+	{"thresholds"},
+	{"options", "thresholds"},
+	{"options", "fieldOptions", "thresholds"},
+	{"fieldConfig", "defaults", "thresholds"},
+}
+
+// panelRef is a panel together with where it is placed in the dashboard.
+type panelRef struct {
+	model    map[string]interface{}
+	id       int64
+	row      string
+	position interface{}
+}
+
+// CompareDashboards computes the changes between two versions of a
+// dashboard. Panels are matched by id and queries by refId, so reordering
+// panels only reports them as moved.
+func CompareDashboards(baseData, newData *simplejson.Json) (*DashboardDiff, error) {
+	base, err := toPlainMap(baseData)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := toPlainMap(newData)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DashboardDiff{Changes: make([]*DashboardChange, 0)}
+	result.compareDashboardProperties(base, next)
+	result.comparePanels(base, next)
+	result.compareVariables(base, next)
+
+	return result, nil
+}
+
+func (d *DashboardDiff) add(change *DashboardChange) {
+	d.Changes = append(d.Changes, change)
+}
+
+func (d *DashboardDiff) compareDashboardProperties(base, next map[string]interface{}) {
+	for _, key := range unionKeys(base, next) {
+		if dashboardKeysIgnored[key] {
+			continue
+		}
+
+		oldValue, newValue := base[key], next[key]
+		if !reflect.DeepEqual(oldValue, newValue) {
+			d.add(&DashboardChange{Action: actionFor(oldValue, newValue), Target: TargetDashboard, Name: key, Old: oldValue, New: newValue})
+		}
+	}
+}
+
+func (d *DashboardDiff) comparePanels(base, next map[string]interface{}) {
+	basePanels := getPanelRefs(base)
+	newPanels := getPanelRefs(next)
+
+	baseById := make(map[int64]*panelRef)
+	for _, p := range basePanels {
+		baseById[p.id] = p
+	}
+
+	newById := make(map[int64]*panelRef)
+	for _, p := range newPanels {
+		newById[p.id] = p
+	}
+
+	for _, p := range basePanels {
+		if _, exists := newById[p.id]; !exists {
+			d.add(&DashboardChange{Action: ActionRemoved, Target: TargetPanel, PanelId: p.id, PanelTitle: panelTitle(p.model), Old: p.model})
+		}
+	}
+
+	for _, p := range newPanels {
+		old, exists := baseById[p.id]
+		if !exists {
+			d.add(&DashboardChange{Action: ActionAdded, Target: TargetPanel, PanelId: p.id, PanelTitle: panelTitle(p.model), New: p.model})
+			continue
+		}
+
+		d.comparePanel(old, p)
+	}
+}
+
+func (d *DashboardDiff) comparePanel(old, next *panelRef) {
+	change := func(action ChangeAction, target ChangeTarget, name string, oldValue, newValue interface{}) {
+		d.add(&DashboardChange{
+			Action:     action,
+			Target:     target,
+			PanelId:    next.id,
+			PanelTitle: panelTitle(next.model),
+			Name:       name,
+			Old:        oldValue,
+			New:        newValue,
+		})
+	}
+
+	if old.row != next.row || !reflect.DeepEqual(old.position, next.position) {
+		change(ActionMoved, TargetPanel, "", old.position, next.position)
+	}
+
+	oldProperties := withoutThresholds(old.model)
+	newProperties := withoutThresholds(next.model)
+	for _, key := range unionKeys(oldProperties, newProperties) {
+		if panelKeysIgnored[key] {
+			continue
+		}
+
+		oldValue, newValue := oldProperties[key], newProperties[key]
+		if !reflect.DeepEqual(oldValue, newValue) {
+			change(actionFor(oldValue, newValue), TargetPanel, key, oldValue, newValue)
+		}
+	}
+
+	oldQueries := getQueries(old.model)
+	newQueries := getQueries(next.model)
+	for _, refId := range unionKeys(oldQueries, newQueries) {
+		oldQuery, newQuery := oldQueries[refId], newQueries[refId]
+		if !reflect.DeepEqual(oldQuery, newQuery) {
+			change(actionFor(oldQuery, newQuery), TargetQuery, refId, oldQuery, newQuery)
+		}
+	}
+
+	oldThresholds := getThresholds(old.model)
+	newThresholds := getThresholds(next.model)
+	if !reflect.DeepEqual(oldThresholds, newThresholds) {
+		change(actionFor(oldThresholds, newThresholds), TargetThreshold, "", oldThresholds, newThresholds)
+	}
+
+	oldAlert, newAlert := old.model["alert"], next.model["alert"]
+	if !reflect.DeepEqual(oldAlert, newAlert) {
+		change(actionFor(oldAlert, newAlert), TargetAlert, alertName(oldAlert, newAlert), oldAlert, newAlert)
+	}
+}
+
+func (d *DashboardDiff) compareVariables(base, next map[string]interface{}) {
+	oldVariables := getVariables(base)
+	newVariables := getVariables(next)
+
+	for _, name := range unionKeys(oldVariables, newVariables) {
+		oldVariable, newVariable := oldVariables[name], newVariables[name]
+		if !reflect.DeepEqual(oldVariable, newVariable) {
+			d.add(&DashboardChange{Action: actionFor(oldVariable, newVariable), Target: TargetVariable, Name: name, Old: oldVariable, New: newVariable})
+		}
+	}
+}
+
+// toPlainMap converts the dashboard to plain JSON values, so that values
+// decoded in different ways compare equal.
+func toPlainMap(data *simplejson.Json) (map[string]interface{}, error) {
+	if data == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	bytes, err := data.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// getPanelRefs returns all panels of the dashboard, including panels in
+// collapsed rows and in rows of dashboards using the old schema.
+func getPanelRefs(dash map[string]interface{}) []*panelRef {
+	result := make([]*panelRef, 0)
+
+	var walk func(panels []interface{}, row string)
+	walk = func(panels []interface{}, row string) {
+		for i, p := range panels {
+			panel, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			ref := &panelRef{model: panel, id: toInt64(panel["id"]), row: row, position: panel["gridPos"]}
+			if ref.position == nil {
+				ref.position = i
+			}
+			result = append(result, ref)
+
+			if nested, ok := panel["panels"].([]interface{}); ok {
+				walk(nested, fmt.Sprintf("panel-%d", ref.id))
+			}
+		}
+	}
+
+	if panels, ok := dash["panels"].([]interface{}); ok {
+		walk(panels, "")
+	}
+
+	if rows, ok := dash["rows"].([]interface{}); ok {
+		for i, r := range rows {
+			if row, ok := r.(map[string]interface{}); ok {
+				if panels, ok := row["panels"].([]interface{}); ok {
+					walk(panels, fmt.Sprintf("row-%d", i))
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// getQueries returns the queries of a panel by refId. Queries without refId
+// are keyed by their position.
+func getQueries(panel map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	targets, _ := panel["targets"].([]interface{})
+	for i, t := range targets {
+		refId := fmt.Sprintf("#%d", i)
+		if target, ok := t.(map[string]interface{}); ok {
+			if id, ok := target["refId"].(string); ok && id != "" {
+				refId = id
+			}
+		}
+		result[refId] = t
+	}
+
+	return result
+}
+
+func getThresholds(panel map[string]interface{}) interface{} {
+	for _, path := range thresholdPaths {
+		var value interface{} = panel
+		for _, key := range path {
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = obj[key]
+		}
+
+		if value != nil {
+			return value
+		}
+	}
+
+	return nil
+}
+
+// withoutThresholds returns a copy of the panel without its thresholds, so
+// that they are only reported as threshold changes.
+func withoutThresholds(panel map[string]interface{}) map[string]interface{} {
+	result := copyMap(panel)
+
+	for _, path := range thresholdPaths {
+		obj := result
+		for i, key := range path {
+			if i == len(path)-1 {
+				delete(obj, key)
+				break
+			}
+
+			child, ok := obj[key].(map[string]interface{})
+			if !ok {
+				break
+			}
+
+			child = copyMap(child)
+			obj[key] = child
+			obj = child
+		}
+	}
+
+	return result
+}
+
+func copyMap(obj map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(obj))
+	for key, value := range obj {
+		result[key] = value
+	}
+	return result
+}
+
+func getVariables(dash map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	templating, _ := dash["templating"].(map[string]interface{})
+	list, _ := templating["list"].([]interface{})
+	for i, v := range list {
+		result[variableName(i, v)] = v
+	}
+
+	return result
+}
+
+// variableName returns the name of a variable, or its position when it has
+// no name.
+func variableName(index int, v interface{}) string {
+	if variable, ok := v.(map[string]interface{}); ok {
+		if name, ok := variable["name"].(string); ok && name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("#%d", index)
+}
+
+func panelTitle(panel map[string]interface{}) string {
+	title, _ := panel["title"].(string)
+	return title
+}
+
+func alertName(alerts ...interface{}) string {
+	for _, a := range alerts {
+		if alert, ok := a.(map[string]interface{}); ok {
+			if name, ok := alert["name"].(string); ok {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func actionFor(oldValue, newValue interface{}) ChangeAction {
+	if oldValue == nil {
+		return ActionAdded
+	}
+	if newValue == nil {
+		return ActionRemoved
+	}
+	return ActionUpdated
+}
+
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case float64:
+		return int64(v)
+	case json.Number:
+		i, _ := v.Int64()
+		return i
+	}
+	return 0
+}
+
+// unionKeys returns the keys of both maps in sorted order.
+func unionKeys(maps ...map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,203 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSemanticDiff(t *testing.T) {
+	const (
+		baseJSON = `{
+			"id": 1,
+			"version": 3,
+			"title": "Services",
+			"panels": [
+				{
+					"id": 1,
+					"type": "graph",
+					"title": "Requests",
+					"gridPos": {"x": 0, "y": 0, "w": 12, "h": 8},
+					"targets": [{"refId": "A", "expr": "rate(requests[5m])"}, {"refId": "B", "expr": "up"}],
+					"thresholds": [{"value": 100, "op": "gt"}]
+				},
+				{
+					"id": 2,
+					"type": "singlestat",
+					"title": "Errors",
+					"gridPos": {"x": 12, "y": 0, "w": 12, "h": 8},
+					"targets": [{"refId": "A", "expr": "errors"}]
+				},
+				{
+					"id": 3,
+					"type": "text",
+					"title": "Notes",
+					"gridPos": {"x": 0, "y": 8, "w": 24, "h": 4}
+				}
+			],
+			"templating": {
+				"list": [
+					{"name": "env", "query": "label_values(env)"},
+					{"name": "host", "query": "label_values(host)"}
+				]
+			}
+		}`
+
+		newJSON = `{
+			"id": 1,
+			"version": 4,
+			"title": "Services",
+			"panels": [
+				{
+					"id": 2,
+					"type": "singlestat",
+					"title": "Errors",
+					"gridPos": {"x": 0, "y": 0, "w": 12, "h": 8},
+					"targets": [{"refId": "A", "expr": "errors"}],
+					"alert": {"name": "Too many errors"}
+				},
+				{
+					"id": 1,
+					"type": "graph",
+					"title": "Requests",
+					"gridPos": {"x": 12, "y": 0, "w": 12, "h": 8},
+					"targets": [{"refId": "A", "expr": "rate(requests[1m])"}, {"refId": "C", "expr": "down"}],
+					"thresholds": [{"value": 200, "op": "gt"}]
+				},
+				{
+					"id": 4,
+					"type": "table",
+					"title": "Hosts",
+					"gridPos": {"x": 0, "y": 8, "w": 24, "h": 4}
+				}
+			],
+			"templating": {
+				"list": [
+					{"name": "host", "query": "label_values(host)"},
+					{"name": "env", "query": "label_values(environment)"}
+				]
+			}
+		}`
+	)
+
+	Convey("Testing semantic dashboard diffs", t, func() {
+		baseData, err := simplejson.NewJson([]byte(baseJSON))
+		So(err, ShouldBeNil)
+
+		newData, err := simplejson.NewJson([]byte(newJSON))
+		So(err, ShouldBeNil)
+
+		Convey("Should report dashboard aware changes", func() {
+			result, err := CompareDashboards(baseData, newData)
+			So(err, ShouldBeNil)
+
+			changes := make(map[string]int)
+			for _, change := range result.Changes {
+				changes[string(change.Target)+" "+string(change.Action)]++
+			}
+
+			So(changes, ShouldResemble, map[string]int{
+				"panel removed":     1,
+				"panel added":       1,
+				"panel moved":       2,
+				"query updated":     1,
+				"query removed":     1,
+				"query added":       1,
+				"threshold updated": 1,
+				"alert added":       1,
+				"variable updated":  1,
+			})
+		})
+
+		Convey("Should name the changed query and variable", func() {
+			result, err := CompareDashboards(baseData, newData)
+			So(err, ShouldBeNil)
+
+			for _, change := range result.Changes {
+				if change.Target == TargetQuery && change.Action == ActionUpdated {
+					So(change.PanelId, ShouldEqual, 1)
+					So(change.Name, ShouldEqual, "A")
+				}
+				if change.Target == TargetVariable {
+					So(change.Name, ShouldEqual, "env")
+				}
+			}
+		})
+
+		Convey("Should not report changes for identical dashboards", func() {
+			result, err := CompareDashboards(baseData, baseData)
+			So(err, ShouldBeNil)
+			So(len(result.Changes), ShouldEqual, 0)
+		})
+	})
+
+	Convey("Testing three-way merge of dashboards", t, func() {
+		base := simplejson.NewFromAny(map[string]interface{}{
+			"version": 1,
+			"title":   "Services",
+			"panels": []interface{}{
+				map[string]interface{}{"id": 1, "title": "Requests"},
+				map[string]interface{}{"id": 2, "title": "Errors"},
+			},
+		})
+
+		Convey("Should merge changes to different panels", func() {
+			ours := simplejson.NewFromAny(map[string]interface{}{
+				"version": 1,
+				"title":   "Services",
+				"panels": []interface{}{
+					map[string]interface{}{"id": 1, "title": "Requests per second"},
+					map[string]interface{}{"id": 2, "title": "Errors"},
+					map[string]interface{}{"id": 3, "title": "Latency"},
+				},
+			})
+			theirs := simplejson.NewFromAny(map[string]interface{}{
+				"version": 2,
+				"title":   "All services",
+				"panels": []interface{}{
+					map[string]interface{}{"id": 1, "title": "Requests"},
+				},
+			})
+
+			result, err := MergeDashboards(base, ours, theirs)
+			So(err, ShouldBeNil)
+			So(len(result.Conflicts), ShouldEqual, 0)
+
+			merged := result.Dashboard
+			So(merged.Get("version").MustInt(), ShouldEqual, 2)
+			So(merged.Get("title").MustString(), ShouldEqual, "All services")
+
+			panels := merged.Get("panels").MustArray()
+			So(len(panels), ShouldEqual, 2)
+			So(merged.Get("panels").GetIndex(0).Get("title").MustString(), ShouldEqual, "Requests per second")
+			So(merged.Get("panels").GetIndex(1).Get("title").MustString(), ShouldEqual, "Latency")
+		})
+
+		Convey("Should report conflicting changes to the same panel", func() {
+			ours := simplejson.NewFromAny(map[string]interface{}{
+				"version": 1,
+				"title":   "Services",
+				"panels": []interface{}{
+					map[string]interface{}{"id": 1, "title": "Ours"},
+					map[string]interface{}{"id": 2, "title": "Errors"},
+				},
+			})
+			theirs := simplejson.NewFromAny(map[string]interface{}{
+				"version": 2,
+				"title":   "Services",
+				"panels": []interface{}{
+					map[string]interface{}{"id": 1, "title": "Theirs"},
+					map[string]interface{}{"id": 2, "title": "Errors"},
+				},
+			})
+
+			result, err := MergeDashboards(base, ours, theirs)
+			So(err, ShouldBeNil)
+			So(len(result.Conflicts), ShouldEqual, 1)
+			So(result.Conflicts[0].Target, ShouldEqual, TargetPanel)
+			So(result.Conflicts[0].PanelId, ShouldEqual, 1)
+			So(result.Dashboard.Get("panels").GetIndex(0).Get("title").MustString(), ShouldEqual, "Theirs")
+		})
+	})
+}
@@ -89,3 +89,22 @@ type UserUpdated struct {
 	Login     string    `json:"login"`
 	Email     string    `json:"email"`
 }
+
+type DashboardSaved struct {
+	Timestamp time.Time `json:"timestamp"`
+	Id        int64     `json:"id"`
+	Uid       string    `json:"uid"`
+	OrgId     int64     `json:"orgId"`
+	Title     string    `json:"title"`
+	Version   int       `json:"version"`
+	UserId    int64     `json:"userId"`
+	IsFolder  bool      `json:"isFolder"`
+}
+
+type DashboardDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Id        int64     `json:"id"`
+	Uid       string    `json:"uid"`
+	OrgId     int64     `json:"orgId"`
+	IsFolder  bool      `json:"isFolder"`
+}
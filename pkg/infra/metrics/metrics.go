@@ -97,6 +97,9 @@ var (
 
 	// LDAPUsersSyncExecutionTime is a metric summary for LDAP users sync execution duration
 	LDAPUsersSyncExecutionTime prometheus.Summary
+
+	// MPluginBackendRestarts is a metric counter for restarts of the backend of plugins
+	MPluginBackendRestarts *prometheus.CounterVec
 )
 
 // Timers
@@ -146,6 +149,15 @@ var (
 	// StatsTotalActiveAdmins is a metric total amount of active admins
 	StatsTotalActiveAdmins prometheus.Gauge
 
+	// MPluginBackendUp is a metric that is 1 when the backend of a plugin is running
+	MPluginBackendUp *prometheus.GaugeVec
+
+	// MDataSourceHealthUp is a metric that is 1 when the last health check of a datasource succeeded
+	MDataSourceHealthUp *prometheus.GaugeVec
+
+	// MDataSourceHealthLatency is a metric of the duration of the last health check of a datasource
+	MDataSourceHealthLatency *prometheus.GaugeVec
+
 	// grafanaBuildVersion is a metric with a constant '1' value labeled by version, revision, branch, and goversion from which Grafana was built
 	grafanaBuildVersion *prometheus.GaugeVec
 )
@@ -303,6 +315,24 @@ func init() {
 		Namespace: exporterName,
 	}, []string{"type"})
 
+	MPluginBackendRestarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "plugin_backend_restarts_total",
+		Help:      "counter for restarts of the backend of plugins",
+		Namespace: exporterName,
+	}, []string{"plugin_id"})
+
+	MDataSourceHealthUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "datasource_health_up",
+		Help:      "1 when the last health check of the datasource succeeded",
+		Namespace: exporterName,
+	}, []string{"org_id", "datasource_id", "datasource", "type"})
+
+	MDataSourceHealthLatency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "datasource_health_latency_seconds",
+		Help:      "duration of the last health check of the datasource",
+		Namespace: exporterName,
+	}, []string{"org_id", "datasource_id", "datasource", "type"})
+
 	MAwsCloudWatchGetMetricStatistics = newCounterStartingAtZero(prometheus.CounterOpts{
 		Name:      "aws_cloudwatch_get_metric_statistics_total",
 		Help:      "counter for getting metric statistics from aws",
@@ -417,6 +447,12 @@ func init() {
 		Namespace: exporterName,
 	})
 
+	MPluginBackendUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "plugin_backend_up",
+		Help:      "1 when the backend of the plugin is running",
+		Namespace: exporterName,
+	}, []string{"plugin_id"})
+
 	grafanaBuildVersion = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name:      "build_info",
 		Help:      "A metric with a constant '1' value labeled by version, revision, branch, and goversion from which Grafana was built",
@@ -479,6 +515,10 @@ func initMetricVars() {
 		StatsTotalActiveViewers,
 		StatsTotalActiveEditors,
 		StatsTotalActiveAdmins,
+		MPluginBackendRestarts,
+		MPluginBackendUp,
+		MDataSourceHealthUp,
+		MDataSourceHealthLatency,
 		grafanaBuildVersion,
 	)
 
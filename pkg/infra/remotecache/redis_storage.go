@@ -57,11 +57,21 @@ func parseRedisConnStr(connStr string) (*redis.Options, error) {
 }
 
 func newRedisStorage(opts *setting.RemoteCacheOptions) (*redisStorage, error) {
-	opt, err := parseRedisConnStr(opts.ConnStr)
+	c, err := NewRedisClient(opts.ConnStr)
 	if err != nil {
 		return nil, err
 	}
-	return &redisStorage{c: redis.NewClient(opt)}, nil
+	return &redisStorage{c: c}, nil
+}
+
+// NewRedisClient returns a client of the redis server of the connection
+// string, in the format of the connstr of the remote cache.
+func NewRedisClient(connStr string) (*redis.Client, error) {
+	opt, err := parseRedisConnStr(connStr)
+	if err != nil {
+		return nil, err
+	}
+	return redis.NewClient(opt), nil
 }
 
 // Set sets value to given key in session.
@@ -6,6 +6,7 @@ import (
 	"github.com/Seasheller/grafana/pkg/bus"
 	"github.com/Seasheller/grafana/pkg/models"
 	"github.com/Seasheller/grafana/pkg/services/ldap"
+	"github.com/Seasheller/grafana/pkg/setting"
 )
 
 var (
@@ -17,9 +18,15 @@ var (
 	ErrTooManyLoginAttempts  = errors.New("Too many consecutive incorrect login attempts for user. Login for user temporarily blocked")
 	ErrPasswordEmpty         = errors.New("No password provided")
 	ErrUserDisabled          = errors.New("User is disabled")
+	ErrPasswordExpired       = errors.New("Password expired")
 )
 
-func Init() {
+// passwordPolicy is the policy the passwords of users stored in Grafana are
+// checked against when they log in
+var passwordPolicy setting.PasswordPolicySettings
+
+func Init(cfg *setting.Cfg) {
+	passwordPolicy = cfg.PasswordPolicy
 	bus.AddHandler("auth", AuthenticateUser)
 }
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/Seasheller/grafana/pkg/bus"
 	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/passwordpolicy"
 	"github.com/Seasheller/grafana/pkg/util"
 )
 
@@ -35,5 +36,11 @@ var loginUsingGrafanaDB = func(query *m.LoginUserQuery) error {
 	}
 
 	query.User = user
+
+	// the user is set so an expired password can be used to set a new one
+	if passwordpolicy.IsExpired(passwordPolicy, user) {
+		return ErrPasswordExpired
+	}
+
 	return nil
 }
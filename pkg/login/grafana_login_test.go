@@ -2,11 +2,13 @@ package login
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 
 	"github.com/Seasheller/grafana/pkg/bus"
 	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/setting"
 )
 
 func TestGrafanaLogin(t *testing.T) {
@@ -64,6 +66,20 @@ func TestGrafanaLogin(t *testing.T) {
 			})
 		})
 
+		grafanaLoginScenario("When login with an expired password", func(sc *grafanaLoginScenarioContext) {
+			sc.withExpiredPassword()
+			err := loginUsingGrafanaDB(sc.loginUserQuery)
+
+			Convey("it should return password expired error", func() {
+				So(err, ShouldEqual, ErrPasswordExpired)
+			})
+
+			Convey("it should pupulate user object", func() {
+				So(sc.loginUserQuery.User, ShouldNotBeNil)
+				So(sc.loginUserQuery.User.Login, ShouldEqual, sc.loginUserQuery.Username)
+			})
+		})
+
 		grafanaLoginScenario("When login with disabled user", func(sc *grafanaLoginScenarioContext) {
 			sc.withDisabledUser()
 			err := loginUsingGrafanaDB(sc.loginUserQuery)
@@ -93,6 +109,7 @@ type grafanaLoginScenarioFunc func(c *grafanaLoginScenarioContext)
 func grafanaLoginScenario(desc string, fn grafanaLoginScenarioFunc) {
 	Convey(desc, func() {
 		origValidatePassword := validatePassword
+		origPasswordPolicy := passwordPolicy
 
 		sc := &grafanaLoginScenarioContext{
 			loginUserQuery: &m.LoginUserQuery{
@@ -105,6 +122,7 @@ func grafanaLoginScenario(desc string, fn grafanaLoginScenarioFunc) {
 
 		defer func() {
 			validatePassword = origValidatePassword
+			passwordPolicy = origPasswordPolicy
 		}()
 
 		fn(sc)
@@ -144,6 +162,18 @@ func (sc *grafanaLoginScenarioContext) withValidCredentials() {
 	mockPasswordValidation(true, sc)
 }
 
+func (sc *grafanaLoginScenarioContext) withExpiredPassword() {
+	passwordPolicy = setting.PasswordPolicySettings{MaxAgeDays: 90}
+	sc.getUserByLoginQueryReturns(&m.User{
+		Id:                1,
+		Login:             sc.loginUserQuery.Username,
+		Password:          sc.loginUserQuery.Password,
+		Salt:              "salt",
+		PasswordChangedAt: time.Now().AddDate(0, 0, -91),
+	})
+	mockPasswordValidation(true, sc)
+}
+
 func (sc *grafanaLoginScenarioContext) withNonExistingUser() {
 	sc.getUserByLoginQueryReturns(nil)
 }
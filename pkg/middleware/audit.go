@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/audit"
+	"github.com/Seasheller/grafana/pkg/util"
+	macaron "gopkg.in/macaron.v1"
+)
+
+const requestIdHeader = "X-Request-Id"
+
+// Audit records mutating API calls once they have been handled. Needs to be
+// added after the context handler so the signed in user is known.
+func Audit(auditService *audit.AuditService) macaron.Handler {
+	return func(c *models.ReqContext) {
+		if !auditService.IsEnabled() || !audit.ShouldAudit(c.Req.Method, c.Req.URL.Path) {
+			return
+		}
+
+		requestId := c.Req.Header.Get(requestIdHeader)
+		if requestId == "" || len(requestId) > 40 {
+			requestId = util.GenerateShortUID()
+		}
+		c.Resp.Header().Set(requestIdHeader, requestId)
+
+		c.Next()
+
+		cmd := &models.CreateAuditEntryCommand{
+			OrgId:     c.OrgId,
+			UserId:    c.UserId,
+			Login:     c.Login,
+			Action:    audit.ActionFromMethod(c.Req.Method),
+			Resource:  audit.ResourceFromPath(c.Req.URL.Path),
+			Target:    c.Req.URL.Path,
+			Method:    c.Req.Method,
+			Status:    c.Resp.Status(),
+			IpAddress: c.RemoteAddr(),
+			RequestId: requestId,
+		}
+
+		if err := auditService.Record(cmd); err != nil {
+			c.Logger.Error("Failed to record audit entry", "path", c.Req.URL.Path, "error", err)
+		}
+	}
+}
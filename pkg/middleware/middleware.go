@@ -14,6 +14,7 @@ import (
 	"github.com/Seasheller/grafana/pkg/components/apikeygen"
 	"github.com/Seasheller/grafana/pkg/infra/log"
 	"github.com/Seasheller/grafana/pkg/infra/remotecache"
+	"github.com/Seasheller/grafana/pkg/login"
 	"github.com/Seasheller/grafana/pkg/models"
 	"github.com/Seasheller/grafana/pkg/setting"
 	"github.com/Seasheller/grafana/pkg/util"
@@ -166,6 +167,11 @@ func initContextWithBasicAuth(ctx *models.ReqContext, orgId int64) bool {
 
 	loginUserQuery := models.LoginUserQuery{Username: username, Password: password, User: user}
 	if err := bus.Dispatch(&loginUserQuery); err != nil {
+		if err == login.ErrPasswordExpired {
+			ctx.JsonApiErr(401, "Password expired", err)
+			return true
+		}
+
 		ctx.JsonApiErr(401, "Invalid username or password", err)
 		return true
 	}
@@ -16,6 +16,7 @@ import (
 	"github.com/Seasheller/grafana/pkg/api/dtos"
 	"github.com/Seasheller/grafana/pkg/bus"
 	"github.com/Seasheller/grafana/pkg/infra/remotecache"
+	authlogin "github.com/Seasheller/grafana/pkg/login"
 	"github.com/Seasheller/grafana/pkg/models"
 	"github.com/Seasheller/grafana/pkg/services/auth"
 	"github.com/Seasheller/grafana/pkg/services/login"
@@ -171,6 +172,26 @@ func TestMiddlewareContext(t *testing.T) {
 			})
 		})
 
+		middlewareScenario(t, "Using basic auth with an expired password", func(sc *scenarioContext) {
+			bus.AddHandler("test", func(query *models.GetUserByLoginQuery) error {
+				query.Result = &models.User{Id: 12}
+				return nil
+			})
+
+			bus.AddHandler("test", func(loginUserQuery *models.LoginUserQuery) error {
+				return authlogin.ErrPasswordExpired
+			})
+
+			setting.BasicAuthEnabled = true
+			authHeader := util.GetBasicAuthHeader("myUser", "myPass")
+			sc.fakeReq("GET", "/").withAuthorizationHeader(authHeader).exec()
+
+			Convey("Should return 401", func() {
+				So(sc.resp.Code, ShouldEqual, 401)
+				So(sc.respJson["message"], ShouldEqual, "Password expired")
+			})
+		})
+
 		middlewareScenario(t, "Valid api key", func(sc *scenarioContext) {
 			keyhash := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
 
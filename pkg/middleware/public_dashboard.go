@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/setting"
+)
+
+const publicDashboardTokenHeader = "X-Grafana-Public-Dashboard-Token"
+
+// PublicDashboardAuth lets requests with the access token of a public
+// dashboard in the X-Grafana-Public-Dashboard-Token header through as an
+// anonymous viewer in the org of the dashboard. The handlers check what the
+// token allows through ReqContext.PublicDashboard, so it must only be added
+// to routes that do.
+func PublicDashboardAuth(c *m.ReqContext) {
+	token := c.Req.Header.Get(publicDashboardTokenHeader)
+	if token == "" || !setting.PublicDashboardsEnabled {
+		return
+	}
+
+	query := m.GetPublicDashboardByTokenQuery{AccessToken: token}
+	if err := bus.Dispatch(&query); err != nil {
+		if err == m.ErrPublicDashboardNotFound {
+			c.JsonApiErr(401, "Invalid public dashboard token", nil)
+			return
+		}
+		c.JsonApiErr(500, "Failed to get public dashboard", err)
+		return
+	}
+
+	publicDashboard := query.Result
+	if !publicDashboard.IsActive(getTime()) {
+		c.JsonApiErr(401, "Public dashboard token has expired or been revoked", nil)
+		return
+	}
+
+	c.PublicDashboard = publicDashboard
+	c.IsSignedIn = false
+	c.AllowAnonymous = true
+	c.SignedInUser = &m.SignedInUser{
+		IsAnonymous: true,
+		OrgId:       publicDashboard.OrgId,
+		OrgRole:     m.ROLE_VIEWER,
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+)
+
+type AuditEntry struct {
+	Id        int64     `json:"id"`
+	OrgId     int64     `json:"orgId"`
+	UserId    int64     `json:"userId"`
+	Login     string    `json:"login"`
+	Action    string    `json:"action"`
+	Resource  string    `json:"resource"`
+	Target    string    `json:"target"`
+	Method    string    `json:"method"`
+	Status    int       `json:"status"`
+	IpAddress string    `json:"ipAddress"`
+	RequestId string    `json:"requestId"`
+	Created   time.Time `json:"created"`
+}
+
+// ---------------------
+// COMMANDS
+
+type CreateAuditEntryCommand struct {
+	OrgId     int64
+	UserId    int64
+	Login     string
+	Action    string
+	Resource  string
+	Target    string
+	Method    string
+	Status    int
+	IpAddress string
+	RequestId string
+
+	Result *AuditEntry
+}
+
+type DeleteOldAuditEntriesCommand struct {
+	OlderThan   time.Time
+	DeletedRows int64
+}
+
+// ---------------------
+// QUERIES
+
+type SearchAuditEntriesQuery struct {
+	OrgId    int64
+	UserId   int64
+	Login    string
+	Action   string
+	Resource string
+	From     time.Time
+	To       time.Time
+	Page     int
+	Limit    int
+
+	Result SearchAuditEntriesQueryResult
+}
+
+type SearchAuditEntriesQueryResult struct {
+	TotalCount int64         `json:"totalCount"`
+	Entries    []*AuditEntry `json:"entries"`
+	Page       int           `json:"page"`
+	PerPage    int           `json:"perPage"`
+}
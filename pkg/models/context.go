@@ -19,6 +19,10 @@ type ReqContext struct {
 	AllowAnonymous bool
 	SkipCache      bool
 	Logger         log.Logger
+
+	// PublicDashboard is set when the request is authorized by the access
+	// token of a public dashboard.
+	PublicDashboard *PublicDashboard
 }
 
 // Handle handles and logs error by given status.
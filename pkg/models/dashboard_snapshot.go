@@ -1,11 +1,17 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/Seasheller/grafana/pkg/components/simplejson"
 )
 
+// Typed errors
+var (
+	ErrDashboardSnapshotAccessDenied = errors.New("Access denied to take a snapshot of the dashboard")
+)
+
 // DashboardSnapshot model
 type DashboardSnapshot struct {
 	Id                int64
@@ -62,6 +68,27 @@ type CreateDashboardSnapshotCommand struct {
 	Result *DashboardSnapshot
 }
 
+// CreateServerSideSnapshotCommand creates a snapshot of a saved dashboard
+// by running the queries of its panels on the server, so that no browser is
+// needed to take it.
+type CreateServerSideSnapshotCommand struct {
+	DashboardUid string `json:"-"`
+	Name         string `json:"name"`
+	Expires      int64  `json:"expires"`
+
+	// From and To default to the time range of the dashboard.
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// Variables overrides the current values of template variables by name.
+	Variables map[string][]string `json:"variables"`
+
+	OrgId int64         `json:"-"`
+	User  *SignedInUser `json:"-"`
+
+	Result *DashboardSnapshot
+}
+
 type DeleteDashboardSnapshotCommand struct {
 	DeleteKey string `json:"-"`
 }
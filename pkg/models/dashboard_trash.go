@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Typed errors
+var (
+	ErrDashboardTrashItemNotFound = errors.New("Deleted dashboard not found")
+)
+
+// DashboardTrashItem is a deleted dashboard or folder that can be restored
+// until it is purged. Dashboards and subfolders deleted together with a
+// folder are stored as items with the folder's item as parent, and are
+// restored and purged together with it.
+type DashboardTrashItem struct {
+	Id          int64     `json:"id"`
+	OrgId       int64     `json:"orgId"`
+	ParentId    int64     `json:"-"`
+	DashboardId int64     `json:"dashboardId"`
+	Uid         string    `json:"uid"`
+	Title       string    `json:"title"`
+	FolderId    int64     `json:"folderId"`
+	IsFolder    bool      `json:"isFolder"`
+	Data        string    `json:"-"`
+	Deleted     time.Time `json:"deleted"`
+}
+
+//
+// COMMANDS
+//
+
+// RestoreDashboardCommand restores a deleted dashboard, or a deleted folder
+// with everything that was in it.
+type RestoreDashboardCommand struct {
+	OrgId   int64
+	TrashId int64
+
+	// Result holds the restored dashboards, starting with the restored item itself.
+	Result []*Dashboard
+}
+
+// PurgeDashboardTrashCommand permanently deletes a deleted dashboard or folder.
+type PurgeDashboardTrashCommand struct {
+	OrgId   int64
+	TrashId int64
+}
+
+type DeleteExpiredDashboardTrashCommand struct {
+	OlderThan   time.Time
+	DeletedRows int64
+}
+
+//
+// QUERIES
+//
+
+// GetDashboardTrashQuery returns the deleted dashboards and folders of an org,
+// without the items deleted together with a folder.
+type GetDashboardTrashQuery struct {
+	OrgId int64
+
+	Result []*DashboardTrashItem
+}
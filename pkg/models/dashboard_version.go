@@ -8,8 +8,9 @@ import (
 )
 
 var (
-	ErrDashboardVersionNotFound = errors.New("Dashboard version not found")
-	ErrNoVersionsForDashboardId = errors.New("No dashboard versions found for the given DashboardId")
+	ErrDashboardVersionNotFound     = errors.New("Dashboard version not found")
+	ErrNoVersionsForDashboardId     = errors.New("No dashboard versions found for the given DashboardId")
+	ErrDashboardVersionLabelTooLong = errors.New("Dashboard version label cannot be longer than 190 characters")
 )
 
 // A DashboardVersion represents the comparable data in a dashboard, allowing
@@ -26,6 +27,9 @@ type DashboardVersion struct {
 
 	Message string           `json:"message"`
 	Data    *simplejson.Json `json:"data"`
+	// Label names the version, e.g. a release. Labeled versions are never
+	// deleted by the clean up of old versions.
+	Label string `json:"label"`
 }
 
 // DashboardVersionMeta extends the dashboard version model with the names
@@ -41,6 +45,7 @@ type DashboardVersionMeta struct {
 	Message       string           `json:"message"`
 	Data          *simplejson.Json `json:"data"`
 	CreatedBy     string           `json:"createdBy"`
+	Label         string           `json:"label"`
 }
 
 // DashboardVersionDTO represents a dashboard version, without the dashboard
@@ -54,6 +59,7 @@ type DashboardVersionDTO struct {
 	Created       time.Time `json:"created"`
 	CreatedBy     string    `json:"createdBy"`
 	Message       string    `json:"message"`
+	Label         string    `json:"label"`
 }
 
 //
@@ -81,6 +87,19 @@ type GetDashboardVersionsQuery struct {
 // Commands
 //
 
+// DeleteExpiredVersionsCommand deletes the versions exceeding the global
+// number of versions to keep, except for dashboards in folders with a
+// retention rule.
 type DeleteExpiredVersionsCommand struct {
 	DeletedRows int64
 }
+
+// SetDashboardVersionLabelCommand labels a version, or removes its label
+// when Label is empty.
+type SetDashboardVersionLabelCommand struct {
+	Label string `json:"label"`
+
+	DashboardId int64 `json:"-"`
+	OrgId       int64 `json:"-"`
+	Version     int   `json:"-"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Typed errors
+var (
+	ErrDashboardVersionRetentionNotFound = errors.New("Dashboard version retention not found")
+	ErrDashboardVersionRetentionInvalid  = errors.New("Dashboard version retention days cannot be negative")
+)
+
+// DashboardVersionRetention decides which versions of the dashboards in a
+// folder are kept, replacing the global number of versions to keep. It also
+// applies to the subfolders that have no retention of their own.
+//
+// All versions younger than KeepAllDays are kept. For the following
+// KeepDailyDays the newest version of each day is kept, and for the
+// following KeepWeeklyDays the newest version of each week. Older versions
+// are deleted, unless KeepWeeklyDays is 0, which keeps one version per week
+// forever. The latest version of a dashboard and labeled versions are always
+// kept.
+type DashboardVersionRetention struct {
+	Id             int64     `json:"id"`
+	OrgId          int64     `json:"orgId"`
+	FolderId       int64     `json:"folderId"`
+	KeepAllDays    int       `json:"keepAllDays"`
+	KeepDailyDays  int       `json:"keepDailyDays"`
+	KeepWeeklyDays int       `json:"keepWeeklyDays"`
+	Created        time.Time `json:"created"`
+	Updated        time.Time `json:"updated"`
+}
+
+// DashboardVersionRetentionItem is a version of a dashboard that a
+// retention applies to.
+type DashboardVersionRetentionItem struct {
+	Id          int64
+	DashboardId int64
+	Version     int
+	Created     time.Time
+	Label       string
+	RetentionId int64
+}
+
+//
+// COMMANDS
+//
+
+type SaveDashboardVersionRetentionCommand struct {
+	KeepAllDays    int `json:"keepAllDays"`
+	KeepDailyDays  int `json:"keepDailyDays"`
+	KeepWeeklyDays int `json:"keepWeeklyDays"`
+
+	OrgId    int64 `json:"-"`
+	FolderId int64 `json:"-"`
+
+	Result *DashboardVersionRetention
+}
+
+func (cmd *SaveDashboardVersionRetentionCommand) Validate() error {
+	if cmd.KeepAllDays < 0 || cmd.KeepDailyDays < 0 || cmd.KeepWeeklyDays < 0 {
+		return ErrDashboardVersionRetentionInvalid
+	}
+	return nil
+}
+
+type DeleteDashboardVersionRetentionCommand struct {
+	OrgId    int64
+	FolderId int64
+}
+
+type DeleteDashboardVersionsCommand struct {
+	Ids []int64
+
+	DeletedRows int64
+}
+
+//
+// QUERIES
+//
+
+type GetDashboardVersionRetentionQuery struct {
+	OrgId    int64
+	FolderId int64
+
+	Result *DashboardVersionRetention
+}
+
+// GetDashboardVersionRetentionsQuery returns the retentions of all orgs.
+type GetDashboardVersionRetentionsQuery struct {
+	Result []*DashboardVersionRetention
+}
+
+// GetDashboardVersionRetentionItemsQuery returns the versions of all
+// dashboards in folders with a retention, with the retention that applies
+// to them. Versions are ordered by dashboard, newest first.
+type GetDashboardVersionRetentionItemsQuery struct {
+	Result []*DashboardVersionRetentionItem
+}
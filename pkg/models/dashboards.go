@@ -20,7 +20,8 @@ var (
 	ErrDashboardWithSameNameInFolderExists       = errors.New("A dashboard with the same name in the folder already exists")
 	ErrDashboardVersionMismatch                  = errors.New("The dashboard has been changed by someone else")
 	ErrDashboardTitleEmpty                       = errors.New("Dashboard title cannot be empty")
-	ErrDashboardFolderCannotHaveParent           = errors.New("A Dashboard Folder cannot be added to another folder")
+	ErrDashboardFolderCannotBeOwnParent          = errors.New("A folder cannot be moved into itself or one of its subfolders")
+	ErrDashboardFolderMaxDepthExceeded           = fmt.Errorf("Folders cannot be nested more than %d levels deep", MaxFolderDepth)
 	ErrDashboardsWithSameSlugExists              = errors.New("Multiple dashboards with the same slug exists")
 	ErrDashboardFailedGenerateUniqueUid          = errors.New("Failed to generate unique dashboard id")
 	ErrDashboardTypeMismatch                     = errors.New("Dashboard cannot be changed to a folder")
@@ -33,6 +33,12 @@ var (
 	ErrDataSourceUpdatingOldVersion = errors.New("Trying to update old version of datasource")
 	ErrDatasourceIsReadOnly         = errors.New("Data source is readonly. Can only be updated from configuration")
 	ErrDataSourceAccessDenied       = errors.New("Data source access denied")
+
+	ErrDataSourcePermissionsDisabled   = errors.New("Permissions are not enabled for this data source")
+	ErrDataSourcePermissionExists      = errors.New("Permission already exists for this data source")
+	ErrDataSourcePermissionNotFound    = errors.New("Data source permission not found")
+	ErrDataSourcePermissionInfoMissing = errors.New("Exactly one of user id, team id or role must be set for a data source permission")
+	ErrDataSourcePermissionInvalid     = errors.New("Invalid data source permission")
 )
 
 type DsAccess string
@@ -58,6 +64,10 @@ type DataSource struct {
 	SecureJsonData    securejsondata.SecureJsonData
 	ReadOnly          bool
 
+	// PermissionsEnabled restricts access to org admins and the
+	// users, teams and roles in the data source ACL.
+	PermissionsEnabled bool
+
 	Created time.Time
 	Updated time.Time
 }
@@ -224,18 +234,107 @@ type DsPermissionType int
 const (
 	DsPermissionNoAccess DsPermissionType = iota
 	DsPermissionQuery
+	DsPermissionAdmin
 )
 
 func (p DsPermissionType) String() string {
 	names := map[int]string{
 		int(DsPermissionQuery):    "Query",
 		int(DsPermissionNoAccess): "No Access",
+		int(DsPermissionAdmin):    "Admin",
 	}
 	return names[int(p)]
 }
 
+func (p DsPermissionType) IsValid() bool {
+	return p == DsPermissionQuery || p == DsPermissionAdmin
+}
+
+// DataSourceAcl grants a user, a team or everyone with an org role
+// access to a data source with permissions enabled.
+type DataSourceAcl struct {
+	Id           int64
+	OrgId        int64
+	DatasourceId int64
+
+	UserId     int64
+	TeamId     int64
+	Role       *RoleType // pointer to be nullable
+	Permission DsPermissionType
+
+	Created time.Time
+	Updated time.Time
+}
+
+type DataSourceAclInfoDTO struct {
+	Id           int64 `json:"id"`
+	OrgId        int64 `json:"-"`
+	DatasourceId int64 `json:"datasourceId"`
+
+	UserId         int64            `json:"userId,omitempty"`
+	UserLogin      string           `json:"userLogin,omitempty"`
+	UserEmail      string           `json:"userEmail,omitempty"`
+	UserAvatarUrl  string           `json:"userAvatarUrl,omitempty"`
+	TeamId         int64            `json:"teamId,omitempty"`
+	Team           string           `json:"team,omitempty"`
+	TeamEmail      string           `json:"-"`
+	TeamAvatarUrl  string           `json:"teamAvatarUrl,omitempty"`
+	Role           *RoleType        `json:"role,omitempty"`
+	Permission     DsPermissionType `json:"permission"`
+	PermissionName string           `json:"permissionName"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// DatasourcesPermissionFilterQuery keeps the data sources the user has
+// at least Permission on. Permission defaults to DsPermissionQuery.
 type DatasourcesPermissionFilterQuery struct {
 	User        *SignedInUser
 	Datasources []*DataSource
+	Permission  DsPermissionType
 	Result      []*DataSource
 }
+
+type GetDataSourcePermissionsQuery struct {
+	OrgId        int64
+	DatasourceId int64
+	Result       []*DataSourceAclInfoDTO
+}
+
+type EnableDataSourcePermissionsCommand struct {
+	OrgId        int64
+	DatasourceId int64
+}
+
+// DisableDataSourcePermissionsCommand opens the data source to the
+// whole org again and removes its ACL.
+type DisableDataSourcePermissionsCommand struct {
+	OrgId        int64
+	DatasourceId int64
+}
+
+type AddDataSourcePermissionCommand struct {
+	OrgId        int64            `json:"-"`
+	DatasourceId int64            `json:"-"`
+	UserId       int64            `json:"userId"`
+	TeamId       int64            `json:"teamId"`
+	Role         *RoleType        `json:"role"`
+	Permission   DsPermissionType `json:"permission"`
+
+	Result DataSourceAcl `json:"-"`
+}
+
+type RemoveDataSourcePermissionCommand struct {
+	OrgId        int64
+	DatasourceId int64
+	Id           int64
+}
+
+// UpdateDataSourcePermissionsCommand replaces the ACL of a data source
+// and enables its permissions.
+type UpdateDataSourcePermissionsCommand struct {
+	OrgId        int64
+	DatasourceId int64
+	Items        []*DataSourceAcl
+}
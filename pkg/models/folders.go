@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -15,8 +16,13 @@ var (
 	ErrFolderSameNameExists          = errors.New("A folder or dashboard in the general folder with the same name already exists")
 	ErrFolderFailedGenerateUniqueUid = errors.New("Failed to generate unique folder id")
 	ErrFolderAccessDenied            = errors.New("Access denied to folder")
+	ErrFolderCannotBeOwnParent       = errors.New("A folder cannot be moved into itself or one of its subfolders")
+	ErrFolderMaxDepthExceeded        = fmt.Errorf("Folders cannot be nested more than %d levels deep", MaxFolderDepth)
 )
 
+// MaxFolderDepth is the maximum number of levels folders can be nested.
+const MaxFolderDepth = 8
+
 type Folder struct {
 	Id      int64
 	Uid     string
@@ -30,6 +36,18 @@ type Folder struct {
 	UpdatedBy int64
 	CreatedBy int64
 	HasAcl    bool
+
+	ParentId  int64
+	ParentUid string
+	Parents   []*FolderRef
+}
+
+// FolderRef is a folder on the path from the root to a folder or dashboard.
+type FolderRef struct {
+	Id    int64  `json:"id"`
+	Uid   string `json:"uid"`
+	Title string `json:"title"`
+	Url   string `json:"url"`
 }
 
 // GetDashboardModel turns the command into the saveable model
@@ -75,8 +93,9 @@ func (cmd *UpdateFolderCommand) UpdateDashboardModel(dashFolder *Dashboard, orgI
 //
 
 type CreateFolderCommand struct {
-	Uid   string `json:"uid"`
-	Title string `json:"title"`
+	Uid       string `json:"uid"`
+	Title     string `json:"title"`
+	ParentUid string `json:"parentUid"`
 
 	Result *Folder
 }
@@ -90,10 +109,24 @@ type UpdateFolderCommand struct {
 	Result *Folder
 }
 
+type MoveFolderCommand struct {
+	ParentUid string `json:"parentUid"`
+
+	Result *Folder
+}
+
 //
 // QUERIES
 //
 
+// GetFolderPathsQuery returns, for each of the folders, the folders on the
+// path from the root down to and including the folder itself.
+type GetFolderPathsQuery struct {
+	OrgId     int64
+	FolderIds []int64
+	Result    map[int64][]*FolderRef
+}
+
 type HasEditPermissionInFoldersQuery struct {
 	SignedInUser *SignedInUser
 	Result       bool
@@ -0,0 +1,219 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+)
+
+// Typed errors
+var (
+	ErrLibraryPanelNotFound             = errors.New("Library panel not found")
+	ErrLibraryPanelNameEmpty            = errors.New("Library panel name cannot be empty")
+	ErrLibraryPanelModelEmpty           = errors.New("Library panel model cannot be empty")
+	ErrLibraryPanelWithSameUIDExists    = errors.New("A library panel with the same uid already exists")
+	ErrLibraryPanelWithSameNameExists   = errors.New("A library panel with the same name in the folder already exists")
+	ErrLibraryPanelVersionMismatch      = errors.New("The library panel has been changed by someone else")
+	ErrLibraryPanelInvalidUid           = errors.New("uid contains illegal characters")
+	ErrLibraryPanelUidTooLong           = errors.New("uid to long. max 40 characters")
+	ErrLibraryPanelFailedGenerateUid    = errors.New("Failed to generate unique library panel id")
+	ErrLibraryPanelInUse                = errors.New("Library panel is used by one or more dashboards")
+	ErrFolderContainsLibraryPanelsInUse = errors.New("Folder contains library panels used by dashboards outside of it")
+	ErrLibraryPanelAccessDenied         = errors.New("Access denied to library panel")
+	ErrLibraryPanelFolderMustBeAFolder  = errors.New("Library panels can only be stored in folders")
+	ErrLibraryPanelReferenceUidMissing  = errors.New("Library panel reference in dashboard has no uid")
+	ErrDashboardLibraryPanelNotFound    = errors.New("Dashboard references a library panel that does not exist")
+)
+
+// LibraryPanel is a panel stored on its own so that it can be shared by many
+// dashboards. Dashboards reference it from a panel with a libraryPanel
+// property and get the current model when they are loaded.
+type LibraryPanel struct {
+	Id          int64            `json:"id"`
+	OrgId       int64            `json:"orgId"`
+	FolderId    int64            `json:"folderId"`
+	Uid         string           `json:"uid"`
+	Name        string           `json:"name"`
+	Type        string           `json:"type"`
+	Description string           `json:"description"`
+	Model       *simplejson.Json `json:"model"`
+	Version     int              `json:"version"`
+
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	CreatedBy int64     `json:"createdBy"`
+	UpdatedBy int64     `json:"updatedBy"`
+}
+
+// LibraryPanelVersion is an earlier version of a library panel.
+type LibraryPanelVersion struct {
+	Id             int64            `json:"id"`
+	LibraryPanelId int64            `json:"libraryPanelId"`
+	Version        int              `json:"version"`
+	Name           string           `json:"name"`
+	Model          *simplejson.Json `json:"model"`
+	Created        time.Time        `json:"created"`
+	CreatedBy      int64            `json:"createdBy"`
+}
+
+// LibraryPanelDashboard connects a library panel to a dashboard using it.
+type LibraryPanelDashboard struct {
+	Id             int64
+	LibraryPanelId int64
+	DashboardId    int64
+}
+
+// GetLibraryPanelUids returns the uids of the library panels referenced by
+// the panels of a dashboard, including panels in collapsed rows.
+func GetLibraryPanelUids(data *simplejson.Json) ([]string, error) {
+	uids := make([]string, 0)
+	seen := make(map[string]bool)
+
+	var walk func(panels []interface{}) error
+	walk = func(panels []interface{}) error {
+		for _, p := range panels {
+			panel := simplejson.NewFromAny(p)
+
+			if ref, ok := panel.CheckGet("libraryPanel"); ok {
+				uid := ref.Get("uid").MustString()
+				if uid == "" {
+					return ErrLibraryPanelReferenceUidMissing
+				}
+
+				if !seen[uid] {
+					seen[uid] = true
+					uids = append(uids, uid)
+				}
+			}
+
+			if err := walk(panel.Get("panels").MustArray()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := walk(data.Get("panels").MustArray()); err != nil {
+		return nil, err
+	}
+
+	return uids, nil
+}
+
+// ExpandLibraryPanels replaces every panel of the dashboard that references
+// one of the library panels with the current model of the library panel,
+// keeping only the id and position of the dashboard panel.
+func ExpandLibraryPanels(data *simplejson.Json, libraryPanels []*LibraryPanel) {
+	byUid := make(map[string]*LibraryPanel)
+	for _, lp := range libraryPanels {
+		byUid[lp.Uid] = lp
+	}
+
+	var expand func(panels []interface{})
+	expand = func(panels []interface{}) {
+		for i, p := range panels {
+			panel := simplejson.NewFromAny(p)
+
+			lp, ok := byUid[panel.Get("libraryPanel").Get("uid").MustString()]
+			if !ok {
+				expand(panel.Get("panels").MustArray())
+				continue
+			}
+
+			expanded := simplejson.New()
+			for key, value := range lp.Model.MustMap() {
+				expanded.Set(key, value)
+			}
+
+			if id, ok := panel.CheckGet("id"); ok {
+				expanded.Set("id", id.Interface())
+			}
+
+			if gridPos, ok := panel.CheckGet("gridPos"); ok {
+				expanded.Set("gridPos", gridPos.Interface())
+			}
+
+			expanded.Set("libraryPanel", map[string]interface{}{
+				"uid":     lp.Uid,
+				"name":    lp.Name,
+				"version": lp.Version,
+			})
+
+			panels[i] = expanded.Interface()
+		}
+	}
+
+	expand(data.Get("panels").MustArray())
+}
+
+//
+// COMMANDS
+//
+
+type CreateLibraryPanelCommand struct {
+	FolderId int64            `json:"folderId"`
+	Uid      string           `json:"uid"`
+	Name     string           `json:"name"`
+	Model    *simplejson.Json `json:"model"`
+
+	OrgId  int64 `json:"-"`
+	UserId int64 `json:"-"`
+
+	Result *LibraryPanel
+}
+
+type UpdateLibraryPanelCommand struct {
+	FolderId int64            `json:"folderId"`
+	Name     string           `json:"name"`
+	Model    *simplejson.Json `json:"model"`
+	Version  int              `json:"version"`
+
+	Uid    string `json:"-"`
+	OrgId  int64  `json:"-"`
+	UserId int64  `json:"-"`
+
+	Result *LibraryPanel
+}
+
+type DeleteLibraryPanelCommand struct {
+	OrgId int64
+	Uid   string
+}
+
+//
+// QUERIES
+//
+
+type GetLibraryPanelQuery struct {
+	OrgId int64
+	Uid   string
+
+	Result *LibraryPanel
+}
+
+type GetLibraryPanelsQuery struct {
+	OrgId     int64
+	FolderIds []int64
+	Name      string
+	Limit     int
+
+	Result []*LibraryPanel
+}
+
+type GetLibraryPanelVersionsQuery struct {
+	OrgId int64
+	Uid   string
+
+	Result []*LibraryPanelVersion
+}
+
+// GetLibraryPanelDashboardIdsQuery returns the ids of the dashboards using a
+// library panel.
+type GetLibraryPanelDashboardIdsQuery struct {
+	OrgId int64
+	Uid   string
+
+	Result []int64
+}
@@ -12,6 +12,8 @@ type SendEmailCommand struct {
 	Data         map[string]interface{}
 	Info         string
 	EmbededFiles []string
+	// AttachedFiles are sent as attachments of the email.
+	AttachedFiles []string
 }
 
 type SendEmailCommandSync struct {
@@ -32,6 +34,13 @@ type SendResetPasswordEmailCommand struct {
 	User *User
 }
 
+// CreateResetPasswordCodeQuery creates a password reset code
+// without sending it, e.g. to force a password change.
+type CreateResetPasswordCodeQuery struct {
+	User   *User
+	Result string
+}
+
 type ValidateResetPasswordCodeQuery struct {
 	Code   string
 	Result *User
@@ -8,7 +8,8 @@ import (
 )
 
 var (
-	ErrPluginSettingNotFound = errors.New("Plugin setting not found")
+	ErrPluginSettingNotFound   = errors.New("Plugin setting not found")
+	ErrPluginSettingIsReadOnly = errors.New("Plugin setting is readonly. Can only be updated from configuration")
 )
 
 type PluginSetting struct {
@@ -20,6 +21,7 @@ type PluginSetting struct {
 	JsonData       map[string]interface{}
 	SecureJsonData securejsondata.SecureJsonData
 	PluginVersion  string
+	ReadOnly       bool
 
 	Created time.Time
 	Updated time.Time
@@ -38,6 +40,7 @@ type UpdatePluginSettingCmd struct {
 
 	PluginId string `json:"-"`
 	OrgId    int64  `json:"-"`
+	ReadOnly bool   `json:"-"`
 }
 
 // specific command, will only update version
@@ -47,6 +50,14 @@ type UpdatePluginSettingVersionCmd struct {
 	OrgId         int64  `json:"-"`
 }
 
+// UpdatePluginSettingReadOnlyCmd sets whether the settings of the plugin can
+// be changed with the API, without changing the settings.
+type UpdatePluginSettingReadOnlyCmd struct {
+	PluginId string
+	OrgId    int64
+	ReadOnly bool
+}
+
 func (cmd *UpdatePluginSettingCmd) GetEncryptedJsonData() securejsondata.SecureJsonData {
 	return securejsondata.GetEncryptedJsonData(cmd.SecureJsonData)
 }
@@ -64,6 +75,7 @@ type PluginSettingInfoDTO struct {
 	Enabled       bool
 	Pinned        bool
 	PluginVersion string
+	ReadOnly      bool
 }
 
 type GetPluginSettingByIdQuery struct {
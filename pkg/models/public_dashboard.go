@@ -0,0 +1,85 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Typed errors
+var (
+	ErrPublicDashboardNotFound    = errors.New("Public dashboard not found")
+	ErrPublicDashboardQueryDenied = errors.New("Query is not part of the public dashboard")
+)
+
+// PublicDashboard is an access token that lets anyone view a single
+// dashboard and run the queries of its panels without signing in.
+// Revoked tokens are kept so their usage can still be audited.
+type PublicDashboard struct {
+	Id          int64     `json:"id"`
+	OrgId       int64     `json:"orgId"`
+	DashboardId int64     `json:"dashboardId"`
+	AccessToken string    `json:"accessToken"`
+	CreatedBy   int64     `json:"createdBy"`
+	Revoked     bool      `json:"revoked"`
+	Expires     time.Time `json:"expires"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+
+	// Usage of the token
+	Views         int64     `json:"views"`
+	Queries       int64     `json:"queries"`
+	DeniedQueries int64     `json:"deniedQueries"`
+	LastUsed      time.Time `json:"lastUsed"`
+}
+
+// IsActive returns true if the token has neither been revoked nor expired.
+func (p *PublicDashboard) IsActive(now time.Time) bool {
+	return !p.Revoked && p.Expires.After(now)
+}
+
+//
+// COMMANDS
+//
+
+type CreatePublicDashboardCommand struct {
+	// Expires is the number of seconds the token is valid, 0 for no expiry.
+	Expires int64 `json:"expires"`
+
+	OrgId       int64 `json:"-"`
+	DashboardId int64 `json:"-"`
+	UserId      int64 `json:"-"`
+
+	Result *PublicDashboard
+}
+
+type RevokePublicDashboardCommand struct {
+	Id          int64
+	OrgId       int64
+	DashboardId int64
+}
+
+// RecordPublicDashboardUsageCommand adds to the usage counters of a token.
+type RecordPublicDashboardUsageCommand struct {
+	Id            int64
+	Views         int64
+	Queries       int64
+	DeniedQueries int64
+	Time          time.Time
+}
+
+//
+// QUERIES
+//
+
+type GetPublicDashboardByTokenQuery struct {
+	AccessToken string
+
+	Result *PublicDashboard
+}
+
+type GetPublicDashboardsQuery struct {
+	OrgId       int64
+	DashboardId int64
+
+	Result []*PublicDashboard
+}
@@ -0,0 +1,220 @@
+package models
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Typed errors
+var (
+	ErrReportNotFound          = errors.New("Report not found")
+	ErrReportNameEmpty         = errors.New("Report name cannot be empty")
+	ErrReportInvalidSchedule   = errors.New("Report schedule is not a valid cron expression")
+	ErrReportNoRecipients      = errors.New("Report has no recipients")
+	ErrReportInvalidRecipient  = errors.New("Report recipient is not a valid email address")
+	ErrReportInvalidLayout     = errors.New("Report layout must be dashboard or panels")
+	ErrReportInvalidFormat     = errors.New("Report format must be pdf or png")
+	ErrReportAccessDenied      = errors.New("Access denied to the dashboard of the report")
+	ErrReportDashboardNotFound = errors.New("Dashboard of the report not found")
+)
+
+type ReportLayout string
+type ReportFormat string
+type ReportDeliveryState string
+
+const (
+	// ReportLayoutDashboard renders the whole dashboard as one image.
+	ReportLayoutDashboard ReportLayout = "dashboard"
+	// ReportLayoutPanels renders every panel as an image of its own.
+	ReportLayoutPanels ReportLayout = "panels"
+
+	ReportFormatPDF ReportFormat = "pdf"
+	ReportFormatPNG ReportFormat = "png"
+
+	ReportDeliverySent   ReportDeliveryState = "sent"
+	ReportDeliveryFailed ReportDeliveryState = "failed"
+)
+
+func (l ReportLayout) IsValid() bool {
+	return l == ReportLayoutDashboard || l == ReportLayoutPanels
+}
+
+func (f ReportFormat) IsValid() bool {
+	return f == ReportFormatPDF || f == ReportFormatPNG
+}
+
+// ReportSettings holds what can be changed in a report through the API.
+type ReportSettings struct {
+	Name         string       `json:"name"`
+	DashboardUid string       `json:"dashboardUid"`
+	Layout       ReportLayout `json:"layout"`
+	Format       ReportFormat `json:"format"`
+	// PanelIds limits the panels layout to some panels of the dashboard.
+	PanelIds  []int64             `json:"panelIds"`
+	Variables map[string][]string `json:"variables"`
+	TimeFrom  string              `json:"timeFrom"`
+	TimeTo    string              `json:"timeTo"`
+	// Recipients are separated by ";", "," or new lines.
+	Recipients string `json:"recipients"`
+	Message    string `json:"message"`
+	Schedule   string `json:"schedule"`
+	Enabled    bool   `json:"enabled"`
+}
+
+// RecipientList returns the email addresses of the recipients.
+func (r *ReportSettings) RecipientList() []string {
+	fields := strings.FieldsFunc(r.Recipients, func(c rune) bool {
+		return c == ';' || c == ',' || c == '\n'
+	})
+
+	recipients := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			recipients = append(recipients, field)
+		}
+	}
+	return recipients
+}
+
+// Validate checks the settings and fills in the defaults. The schedule is
+// validated by the reports service, which parses it.
+func (r *ReportSettings) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return ErrReportNameEmpty
+	}
+
+	if r.Layout == "" {
+		r.Layout = ReportLayoutDashboard
+	}
+	if !r.Layout.IsValid() {
+		return ErrReportInvalidLayout
+	}
+
+	if r.Format == "" {
+		r.Format = ReportFormatPDF
+	}
+	if !r.Format.IsValid() {
+		return ErrReportInvalidFormat
+	}
+
+	recipients := r.RecipientList()
+	if len(recipients) == 0 {
+		return ErrReportNoRecipients
+	}
+	for _, recipient := range recipients {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return ErrReportInvalidRecipient
+		}
+	}
+
+	return nil
+}
+
+// Report is a dashboard that is rendered and sent by email on a schedule.
+// It is rendered with the permissions of the user who last saved it.
+type Report struct {
+	Id             int64 `json:"id"`
+	OrgId          int64 `json:"orgId"`
+	UserId         int64 `json:"userId"`
+	ReportSettings `xorm:"extends"`
+
+	NextRun time.Time `json:"nextRun"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// ReportDelivery records a single delivery of a report.
+type ReportDelivery struct {
+	Id         int64               `json:"id"`
+	OrgId      int64               `json:"orgId"`
+	ReportId   int64               `json:"reportId"`
+	State      ReportDeliveryState `json:"state"`
+	Error      string              `json:"error"`
+	Recipients string              `json:"recipients"`
+	Scheduled  bool                `json:"scheduled"`
+	Created    time.Time           `json:"created"`
+}
+
+//
+// COMMANDS
+//
+
+type CreateReportCommand struct {
+	ReportSettings
+
+	OrgId   int64     `json:"-"`
+	UserId  int64     `json:"-"`
+	NextRun time.Time `json:"-"`
+
+	Result *Report
+}
+
+type UpdateReportCommand struct {
+	ReportSettings
+
+	Id      int64     `json:"-"`
+	OrgId   int64     `json:"-"`
+	UserId  int64     `json:"-"`
+	NextRun time.Time `json:"-"`
+
+	Result *Report
+}
+
+type DeleteReportCommand struct {
+	Id    int64
+	OrgId int64
+}
+
+// SetReportNextRunCommand moves a report to its next scheduled run.
+type SetReportNextRunCommand struct {
+	Id      int64
+	NextRun time.Time
+}
+
+// SendReportCommand renders and sends a report right away.
+type SendReportCommand struct {
+	Report    *Report
+	Scheduled bool
+
+	Result *ReportDelivery
+}
+
+type CreateReportDeliveryCommand struct {
+	Delivery *ReportDelivery
+}
+
+//
+// QUERIES
+//
+
+type GetReportQuery struct {
+	Id    int64
+	OrgId int64
+
+	Result *Report
+}
+
+type GetReportsQuery struct {
+	OrgId int64
+
+	Result []*Report
+}
+
+// GetDueReportsQuery returns the enabled reports of all orgs that are
+// scheduled to run at or before Now.
+type GetDueReportsQuery struct {
+	Now time.Time
+
+	Result []*Report
+}
+
+type GetReportDeliveriesQuery struct {
+	ReportId int64
+	OrgId    int64
+	Limit    int
+
+	Result []*ReportDelivery
+}
@@ -25,5 +25,5 @@ type StreamList []*StreamInfo
 
 type StreamManager interface {
 	GetStreamList() StreamList
-	Push(data *StreamPacket)
+	PublishToOrg(orgId int64, stream string, message interface{}) error
 }
@@ -35,9 +35,10 @@ type User struct {
 	IsAdmin bool
 	OrgId   int64
 
-	Created    time.Time
-	Updated    time.Time
-	LastSeenAt time.Time
+	Created           time.Time
+	Updated           time.Time
+	LastSeenAt        time.Time
+	PasswordChangedAt time.Time
 }
 
 func (u *User) NameOrFallback() string {
@@ -85,6 +86,13 @@ type ChangeUserPasswordCommand struct {
 	UserId int64 `json:"-"`
 }
 
+type UserPasswordHistory struct {
+	Id       int64
+	UserId   int64
+	Password string
+	Created  time.Time
+}
+
 type UpdateUserPermissionsCommand struct {
 	IsGrafanaAdmin bool
 	UserId         int64 `json:"-"`
@@ -122,6 +130,14 @@ type GetUserByEmailQuery struct {
 	Result *User
 }
 
+// GetUserPasswordHistoryQuery returns the most recent password hashes
+// of a user, newest first.
+type GetUserPasswordHistoryQuery struct {
+	UserId int64
+	Limit  int
+	Result []*UserPasswordHistory
+}
+
 type GetUserByIdQuery struct {
 	Id     int64
 	Result *User
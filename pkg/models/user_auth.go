@@ -8,6 +8,7 @@ import (
 
 const (
 	AuthModuleLDAP = "ldap"
+	AuthModuleSAML = "auth.saml"
 )
 
 type UserAuth struct {
@@ -100,6 +101,12 @@ type GetAuthInfoQuery struct {
 	Result *UserAuth
 }
 
+type GetUsersByAuthModuleQuery struct {
+	AuthModule string
+
+	Result []*User
+}
+
 type SyncTeamsCommand struct {
 	ExternalUser *ExternalUserInfo
 	User         *User
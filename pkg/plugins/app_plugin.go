@@ -1,12 +1,14 @@
 package plugins
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 
 	"github.com/Seasheller/grafana/pkg/models"
 	"github.com/Seasheller/grafana/pkg/setting"
 	"github.com/gosimple/slug"
+	plugin "github.com/hashicorp/go-plugin"
 )
 
 type AppPluginCss struct {
@@ -18,6 +20,8 @@ type AppPlugin struct {
 	FrontendPluginBase
 	Routes []*AppPluginRoute `json:"routes"`
 
+	BackendPluginBase
+
 	FoundChildPlugins []*PluginInclude `json:"-"`
 	Pinned            bool             `json:"-"`
 }
@@ -30,6 +34,10 @@ type AppPluginRoute struct {
 	Headers      []AppPluginRouteHeader `json:"headers"`
 	TokenAuth    *JwtTokenAuth          `json:"tokenAuth"`
 	JwtTokenAuth *JwtTokenAuth          `json:"jwtTokenAuth"`
+
+	// Middlewares handle the requests the data proxy sends to the route, in
+	// the order they are declared.
+	Middlewares []*AppPluginRouteMiddleware `json:"middlewares"`
 }
 
 type AppPluginRouteHeader struct {
@@ -37,6 +45,14 @@ type AppPluginRouteHeader struct {
 	Content string `json:"content"`
 }
 
+// AppPluginRouteMiddleware is a middleware registered with the data proxy by
+// its type. String settings can use the same template variables as the
+// headers of the route.
+type AppPluginRouteMiddleware struct {
+	Type     string                 `json:"type"`
+	Settings map[string]interface{} `json:"settings"`
+}
+
 // JwtTokenAuth struct is both for normal Token Auth and JWT Token Auth with
 // an uploaded JWT file.
 type JwtTokenAuth struct {
@@ -58,6 +74,18 @@ func (app *AppPlugin) Load(decoder *json.Decoder, pluginDir string) error {
 	return nil
 }
 
+var appHandshakeConfig = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "grafana_plugin_type",
+	MagicCookieValue: "app",
+}
+
+// startBackendPlugin starts the backend of the app, which only serves the
+// resources of the app.
+func (app *AppPlugin) startBackendPlugin(ctx context.Context) error {
+	return app.startBackend(ctx, &app.PluginBase, appHandshakeConfig, nil, nil)
+}
+
 func (app *AppPlugin) initApp() {
 	app.initFrontendPlugin()
 
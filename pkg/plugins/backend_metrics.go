@@ -0,0 +1,122 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/Seasheller/grafana/pkg/plugins/backendplugin"
+)
+
+// backendMetricsPrefix is added to the names of the metrics of plugins, which
+// would otherwise collide with the metrics of Grafana, like go_goroutines.
+const backendMetricsPrefix = "grafana_plugin_"
+
+// reservedBackendMetricsPrefix is the prefix of the metrics Grafana exports
+// about the backend of plugins, like grafana_plugin_backend_up. The metrics
+// of plugins with this prefix are left out, since a name exported twice
+// fails the whole scrape.
+const reservedBackendMetricsPrefix = backendMetricsPrefix + "backend_"
+
+type backendPluginGatherer struct{}
+
+// BackendPluginGatherer gathers the metrics the backend of plugins collect,
+// with the plugin_id label set to the id of the plugin, so they can be served
+// next to the metrics of Grafana.
+func BackendPluginGatherer() prometheus.Gatherer {
+	return backendPluginGatherer{}
+}
+
+// Gather leaves out the plugins that fail to collect their metrics, so that
+// they don't fail the scrape of the metrics of Grafana.
+func (backendPluginGatherer) Gather() ([]*dto.MetricFamily, error) {
+	backendPluginsLock.RLock()
+	backends := make([]*BackendPluginBase, 0, len(backendPlugins))
+	for _, b := range backendPlugins {
+		backends = append(backends, b)
+	}
+	backendPluginsLock.RUnlock()
+
+	families := map[string]*dto.MetricFamily{}
+	for _, b := range backends {
+		diagnostics := b.getDiagnostics()
+		if diagnostics == nil {
+			continue
+		}
+
+		pluginFamilies, err := collectBackendMetrics(diagnostics)
+		if err != nil {
+			b.log.Debug("Failed to collect plugin metrics", "error", err)
+			continue
+		}
+
+		mergeBackendMetrics(families, b.base.Id, pluginFamilies)
+	}
+
+	result := make([]*dto.MetricFamily, 0, len(families))
+	for _, family := range families {
+		result = append(result, family)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GetName() < result[j].GetName()
+	})
+
+	return result, nil
+}
+
+func collectBackendMetrics(diagnostics backendplugin.DiagnosticsPlugin) (map[string]*dto.MetricFamily, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), backendCallTimeout)
+	defer cancel()
+
+	res, err := diagnostics.CollectMetrics(ctx, &backendplugin.CollectMetricsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var parser expfmt.TextParser
+	return parser.TextToMetricFamilies(bytes.NewReader(res.Prometheus))
+}
+
+// mergeBackendMetrics adds the metric families of a plugin to the families of
+// all plugins. Families whose type differs from the one of another plugin, and
+// families that would collide with the metrics of Grafana, are left out.
+func mergeBackendMetrics(families map[string]*dto.MetricFamily, pluginId string, pluginFamilies map[string]*dto.MetricFamily) {
+	for name, family := range pluginFamilies {
+		name = backendMetricsPrefix + name
+		if strings.HasPrefix(name, reservedBackendMetricsPrefix) {
+			continue
+		}
+
+		for _, metric := range family.Metric {
+			labels := []*dto.LabelPair{{Name: proto.String("plugin_id"), Value: proto.String(pluginId)}}
+			for _, label := range metric.Label {
+				if label.GetName() != "plugin_id" {
+					labels = append(labels, label)
+				}
+			}
+
+			sort.Slice(labels, func(i, j int) bool {
+				return labels[i].GetName() < labels[j].GetName()
+			})
+			metric.Label = labels
+		}
+
+		existing, exists := families[name]
+		if !exists {
+			family.Name = proto.String(name)
+			families[name] = family
+			continue
+		}
+
+		if existing.GetType() == family.GetType() {
+			existing.Metric = append(existing.Metric, family.Metric...)
+		}
+	}
+}
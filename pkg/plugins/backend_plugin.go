@@ -0,0 +1,405 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/infra/metrics"
+	"github.com/Seasheller/grafana/pkg/plugins/backendplugin"
+	plugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BackendPluginState is the state of the process running the backend of a
+// plugin.
+type BackendPluginState string
+
+const (
+	BackendPluginStarting  BackendPluginState = "starting"
+	BackendPluginRunning   BackendPluginState = "running"
+	BackendPluginBackoff   BackendPluginState = "backoff"
+	BackendPluginCrashLoop BackendPluginState = "crash-loop"
+	BackendPluginStopped   BackendPluginState = "stopped"
+)
+
+const (
+	backendRestartMinBackoff = time.Second
+	backendRestartMaxBackoff = time.Minute * 5
+	// a backend that ran this long before exiting is restarted with the
+	// minimum backoff again
+	backendStableDuration = time.Minute
+	// number of failures in a row after which a backend is crash looping
+	backendCrashLoopFailures   = 5
+	backendHealthCheckInterval = time.Second * 30
+	backendCallTimeout         = time.Second * 5
+)
+
+var (
+	backendPlugins     = map[string]*BackendPluginBase{}
+	backendPluginsLock sync.RWMutex
+)
+
+// BackendPluginHealth is the result of the last health check of a backend.
+type BackendPluginHealth struct {
+	Status  string    `json:"status"`
+	Message string    `json:"message"`
+	Checked time.Time `json:"checked"`
+}
+
+// BackendPluginStatus describes the process running the backend of a plugin.
+type BackendPluginStatus struct {
+	PluginId    string              `json:"pluginId"`
+	Type        string              `json:"type"`
+	State       BackendPluginState  `json:"state"`
+	Restarts    int                 `json:"restarts"`
+	Failures    int                 `json:"failures"`
+	Started     time.Time           `json:"started"`
+	Exited      time.Time           `json:"exited"`
+	NextRestart time.Time           `json:"nextRestart"`
+	LastError   string              `json:"lastError"`
+	Health      BackendPluginHealth `json:"health"`
+}
+
+// BackendPluginBase runs the backend of a plugin, which sets backend and
+// executable in its plugin.json, as a sub process. When the process exits it
+// is restarted with an exponential backoff, and once it failed
+// backendCrashLoopFailures times in a row it is crash looping and restarted
+// with the maximum backoff.
+type BackendPluginBase struct {
+	Backend    bool   `json:"backend,omitempty"`
+	Executable string `json:"executable,omitempty"`
+
+	log       log.Logger
+	client    *plugin.Client
+	base      *PluginBase
+	handshake plugin.HandshakeConfig
+	plugins   map[string]plugin.Plugin
+	onStart   func(rpcClient plugin.ClientProtocol) error
+
+	mu          sync.RWMutex
+	state       BackendPluginState
+	restarts    int
+	failures    int
+	started     time.Time
+	exited      time.Time
+	nextRestart time.Time
+	lastError   string
+	health      BackendPluginHealth
+	resource    backendplugin.ResourcePlugin
+	diagnostics backendplugin.DiagnosticsPlugin
+}
+
+// startBackend starts the sub process. The plugins are the go-plugin plugins
+// the backend serves, besides the resource and diagnostics plugins, and
+// onStart is called with the client of every new sub process to dispense them.
+func (b *BackendPluginBase) startBackend(ctx context.Context, base *PluginBase, handshake plugin.HandshakeConfig, plugins map[string]plugin.Plugin, onStart func(plugin.ClientProtocol) error) error {
+	b.log = plog.New("plugin-id", base.Id)
+	b.base = base
+	b.handshake = handshake
+	b.plugins = map[string]plugin.Plugin{
+		backendplugin.ResourcePluginName:    &backendplugin.ResourcePluginImpl{},
+		backendplugin.DiagnosticsPluginName: &backendplugin.DiagnosticsPluginImpl{},
+	}
+	for name, p := range plugins {
+		b.plugins[name] = p
+	}
+	b.onStart = onStart
+	b.state = BackendPluginStarting
+
+	backendPluginsLock.Lock()
+	backendPlugins[base.Id] = b
+	backendPluginsLock.Unlock()
+
+	err := b.spawnSubProcess(time.Now())
+	go b.manage(ctx)
+
+	return err
+}
+
+func (b *BackendPluginBase) spawnSubProcess(now time.Time) error {
+	cmd := ComposePluginStartCommmand(b.Executable)
+	fullpath := path.Join(b.base.PluginDir, cmd)
+
+	client := plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  b.handshake,
+		Plugins:          b.plugins,
+		Cmd:              exec.Command(fullpath),
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+		Logger:           LogWrapper{Logger: b.log},
+	})
+
+	b.mu.Lock()
+	b.client = client
+	b.mu.Unlock()
+
+	resource, diagnostics, err := b.dispense(client)
+	if err != nil {
+		client.Kill()
+		b.failed(now, err)
+		return err
+	}
+
+	b.running(now, resource, diagnostics)
+	return nil
+}
+
+// dispense dispenses the plugins of a new sub process. Backends that don't
+// serve resources or diagnostics answer those calls as unimplemented.
+func (b *BackendPluginBase) dispense(client *plugin.Client) (backendplugin.ResourcePlugin, backendplugin.DiagnosticsPlugin, error) {
+	rpcClient, err := client.Client()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if b.onStart != nil {
+		if err := b.onStart(rpcClient); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	resource, err := rpcClient.Dispense(backendplugin.ResourcePluginName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	diagnostics, err := rpcClient.Dispense(backendplugin.DiagnosticsPluginName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resource.(backendplugin.ResourcePlugin), diagnostics.(backendplugin.DiagnosticsPlugin), nil
+}
+
+// manage restarts the sub process when it exits and checks its health until
+// the context is done.
+func (b *BackendPluginBase) manage(ctx context.Context) {
+	ticker := time.NewTicker(time.Second * 1)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			switch b.getState() {
+			case BackendPluginRunning:
+				if b.getClient().Exited() {
+					b.failed(now, errors.New("Plugin process exited"))
+					b.log.Warn("Plugin process exited", "name", b.base.Name, "nextRestart", b.getStatus().NextRestart)
+				} else if now.Sub(b.getStatus().Health.Checked) >= backendHealthCheckInterval {
+					b.checkHealth(ctx, now)
+				}
+			case BackendPluginBackoff, BackendPluginCrashLoop:
+				if !now.Before(b.getStatus().NextRestart) {
+					b.restart(now)
+				}
+			}
+		}
+	}
+}
+
+func (b *BackendPluginBase) restart(now time.Time) {
+	b.mu.Lock()
+	b.restarts++
+	b.mu.Unlock()
+	metrics.MPluginBackendRestarts.WithLabelValues(b.base.Id).Inc()
+
+	b.log.Debug("Spawning new sub process", "name", b.base.Name, "id", b.base.Id)
+	if err := b.spawnSubProcess(now); err != nil {
+		b.log.Error("Failed to spawn subprocess", "error", err, "state", b.getState())
+	}
+}
+
+func (b *BackendPluginBase) running(now time.Time, resource backendplugin.ResourcePlugin, diagnostics backendplugin.DiagnosticsPlugin) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BackendPluginRunning
+	b.started = now
+	b.nextRestart = time.Time{}
+	b.resource = resource
+	b.diagnostics = diagnostics
+	b.health = BackendPluginHealth{}
+
+	if b.base != nil {
+		metrics.MPluginBackendUp.WithLabelValues(b.base.Id).Set(1)
+	}
+}
+
+// failed schedules the restart of a sub process that exited or failed to
+// start.
+func (b *BackendPluginBase) failed(now time.Time, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BackendPluginStopped {
+		return
+	}
+
+	if b.state == BackendPluginRunning && now.Sub(b.started) >= backendStableDuration {
+		b.failures = 0
+	}
+
+	b.failures++
+	b.exited = now
+	b.lastError = err.Error()
+	b.nextRestart = now.Add(backendRestartBackoff(b.failures))
+	b.resource = nil
+	b.diagnostics = nil
+
+	b.state = BackendPluginBackoff
+	if b.failures >= backendCrashLoopFailures {
+		b.state = BackendPluginCrashLoop
+	}
+
+	if b.base != nil {
+		metrics.MPluginBackendUp.WithLabelValues(b.base.Id).Set(0)
+	}
+}
+
+// backendRestartBackoff returns how long to wait before restarting a sub
+// process that failed the number of times in a row.
+func backendRestartBackoff(failures int) time.Duration {
+	backoff := backendRestartMinBackoff
+	for i := 1; i < failures && backoff < backendRestartMaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > backendRestartMaxBackoff {
+		return backendRestartMaxBackoff
+	}
+	return backoff
+}
+
+func (b *BackendPluginBase) checkHealth(ctx context.Context, now time.Time) {
+	diagnostics := b.getDiagnostics()
+	if diagnostics == nil {
+		return
+	}
+
+	health := BackendPluginHealth{Status: "unknown", Checked: now}
+
+	ctx, cancel := context.WithTimeout(ctx, backendCallTimeout)
+	defer cancel()
+
+	res, err := diagnostics.CheckHealth(ctx, &backendplugin.CheckHealthRequest{})
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		health.Message = "Plugin does not report its health"
+	case err != nil:
+		health.Status = "error"
+		health.Message = err.Error()
+	default:
+		health.Status = strings.ToLower(res.Status.String())
+		health.Message = res.Message
+	}
+
+	if health.Status == "error" {
+		b.log.Warn("Plugin health check failed", "name", b.base.Name, "message", health.Message)
+	}
+
+	b.mu.Lock()
+	b.health = health
+	b.mu.Unlock()
+}
+
+func (b *BackendPluginBase) getState() BackendPluginState {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.state
+}
+
+func (b *BackendPluginBase) getClient() *plugin.Client {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.client
+}
+
+func (b *BackendPluginBase) getDiagnostics() backendplugin.DiagnosticsPlugin {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.diagnostics
+}
+
+func (b *BackendPluginBase) getStatus() *BackendPluginStatus {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	result := &BackendPluginStatus{
+		State:       b.state,
+		Restarts:    b.restarts,
+		Failures:    b.failures,
+		Started:     b.started,
+		Exited:      b.exited,
+		NextRestart: b.nextRestart,
+		LastError:   b.lastError,
+		Health:      b.health,
+	}
+
+	if b.base != nil {
+		result.PluginId = b.base.Id
+		result.Type = b.base.Type
+	}
+
+	return result
+}
+
+func (b *BackendPluginBase) Kill() {
+	b.mu.Lock()
+	b.state = BackendPluginStopped
+	b.resource = nil
+	b.diagnostics = nil
+	client := b.client
+	b.mu.Unlock()
+
+	if client != nil {
+		b.log.Debug("Killing subprocess ", "name", b.base.Name)
+		client.Kill()
+	}
+}
+
+// GetResourcePlugin returns the backend of the plugin that handles the calls
+// to its resources, if the backend of the plugin is running.
+func GetResourcePlugin(pluginId string) (backendplugin.ResourcePlugin, bool) {
+	backendPluginsLock.RLock()
+	b, exists := backendPlugins[pluginId]
+	backendPluginsLock.RUnlock()
+
+	if !exists {
+		return nil, false
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.resource, b.resource != nil
+}
+
+// GetBackendPluginStatuses returns the status of the backend of the plugins,
+// sorted by plugin id.
+func GetBackendPluginStatuses() []*BackendPluginStatus {
+	backendPluginsLock.RLock()
+	defer backendPluginsLock.RUnlock()
+
+	result := make([]*BackendPluginStatus, 0, len(backendPlugins))
+	for _, b := range backendPlugins {
+		result = append(result, b.getStatus())
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].PluginId < result[j].PluginId
+	})
+
+	return result
+}
@@ -0,0 +1,126 @@
+package plugins
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBackendPluginLifecycle(t *testing.T) {
+	Convey("Restart backoff", t, func() {
+		So(backendRestartBackoff(1), ShouldEqual, time.Second)
+		So(backendRestartBackoff(2), ShouldEqual, time.Second*2)
+		So(backendRestartBackoff(4), ShouldEqual, time.Second*8)
+		So(backendRestartBackoff(9), ShouldEqual, time.Second*256)
+		So(backendRestartBackoff(10), ShouldEqual, time.Minute*5)
+		So(backendRestartBackoff(100), ShouldEqual, time.Minute*5)
+	})
+
+	Convey("Given a running backend", t, func() {
+		now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+		b := &BackendPluginBase{}
+		b.running(now, nil, nil)
+
+		So(b.getState(), ShouldEqual, BackendPluginRunning)
+
+		Convey("When it exits right away, it should back off", func() {
+			b.failed(now.Add(time.Second), errors.New("exited"))
+
+			status := b.getStatus()
+			So(status.State, ShouldEqual, BackendPluginBackoff)
+			So(status.Failures, ShouldEqual, 1)
+			So(status.LastError, ShouldEqual, "exited")
+			So(status.NextRestart, ShouldEqual, now.Add(time.Second*2))
+		})
+
+		Convey("When it keeps failing, it should be crash looping", func() {
+			at := now
+			for i := 0; i < backendCrashLoopFailures; i++ {
+				at = at.Add(time.Second)
+				b.failed(at, errors.New("exited"))
+			}
+
+			status := b.getStatus()
+			So(status.State, ShouldEqual, BackendPluginCrashLoop)
+			So(status.Failures, ShouldEqual, backendCrashLoopFailures)
+			So(status.NextRestart, ShouldEqual, at.Add(time.Second*16))
+
+			Convey("And runs long enough after a restart, it should start over", func() {
+				b.running(at, nil, nil)
+				b.failed(at.Add(backendStableDuration), errors.New("exited"))
+
+				status := b.getStatus()
+				So(status.State, ShouldEqual, BackendPluginBackoff)
+				So(status.Failures, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When it is stopped, it should not be restarted", func() {
+			b.Kill()
+			b.failed(now.Add(time.Second), errors.New("exited"))
+
+			So(b.getState(), ShouldEqual, BackendPluginStopped)
+			So(b.getStatus().Failures, ShouldEqual, 0)
+		})
+	})
+}
+
+func TestBackendPluginMetrics(t *testing.T) {
+	Convey("When merging the metrics of plugins", t, func() {
+		families := map[string]*dto.MetricFamily{}
+
+		mergeBackendMetrics(families, "app-a", parseMetrics(`
+# TYPE go_goroutines gauge
+go_goroutines 10
+# TYPE requests_total counter
+requests_total{path="/items",plugin_id="other"} 3
+`))
+		mergeBackendMetrics(families, "app-b", parseMetrics(`
+# TYPE go_goroutines gauge
+go_goroutines 20
+# TYPE requests_total gauge
+requests_total 1
+# TYPE backend_up gauge
+backend_up 1
+`))
+
+		Convey("Should prefix the names and set the plugin id", func() {
+			So(families, ShouldHaveLength, 2)
+
+			goroutines := families["grafana_plugin_go_goroutines"]
+			So(goroutines.GetName(), ShouldEqual, "grafana_plugin_go_goroutines")
+			So(goroutines.Metric, ShouldHaveLength, 2)
+			So(goroutines.Metric[0].Label[0].GetValue(), ShouldEqual, "app-a")
+			So(goroutines.Metric[1].Label[0].GetValue(), ShouldEqual, "app-b")
+			So(goroutines.Metric[1].Gauge.GetValue(), ShouldEqual, 20)
+		})
+
+		Convey("Should replace the plugin id label and keep labels sorted", func() {
+			labels := families["grafana_plugin_requests_total"].Metric[0].Label
+			So(labels, ShouldHaveLength, 2)
+			So(labels[0].GetName(), ShouldEqual, "path")
+			So(labels[1].GetName(), ShouldEqual, "plugin_id")
+			So(labels[1].GetValue(), ShouldEqual, "app-a")
+		})
+
+		Convey("Should leave out families with a different type", func() {
+			So(families["grafana_plugin_requests_total"].Metric, ShouldHaveLength, 1)
+		})
+
+		Convey("Should leave out families that collide with the metrics of Grafana", func() {
+			So(families, ShouldNotContainKey, "grafana_plugin_backend_up")
+		})
+	})
+}
+
+func parseMetrics(text string) map[string]*dto.MetricFamily {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(strings.NewReader(text))
+	So(err, ShouldBeNil)
+	return families
+}
@@ -0,0 +1,58 @@
+package backendplugin
+
+import (
+	"context"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+)
+
+// DiagnosticsPluginName is the name the backend of a plugin serves the
+// diagnostics protocol under.
+const DiagnosticsPluginName = "diagnostics"
+
+// DiagnosticsPlugin reports the health and the metrics of the backend of a
+// plugin.
+type DiagnosticsPlugin interface {
+	CheckHealth(ctx context.Context, req *CheckHealthRequest) (*CheckHealthResponse, error)
+	CollectMetrics(ctx context.Context, req *CollectMetricsRequest) (*CollectMetricsResponse, error)
+}
+
+// DiagnosticsPluginImpl connects a DiagnosticsPlugin to go-plugin.
+type DiagnosticsPluginImpl struct {
+	plugin.NetRPCUnsupportedPlugin
+	Plugin DiagnosticsPlugin
+}
+
+func (p *DiagnosticsPluginImpl) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	RegisterDiagnosticsServer(s, &DiagnosticsGRPCServer{p.Plugin})
+	return nil
+}
+
+func (p *DiagnosticsPluginImpl) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &DiagnosticsGRPCClient{NewDiagnosticsClient(c)}, nil
+}
+
+type DiagnosticsGRPCClient struct {
+	DiagnosticsClient
+}
+
+func (m *DiagnosticsGRPCClient) CheckHealth(ctx context.Context, req *CheckHealthRequest) (*CheckHealthResponse, error) {
+	return m.DiagnosticsClient.CheckHealth(ctx, req)
+}
+
+func (m *DiagnosticsGRPCClient) CollectMetrics(ctx context.Context, req *CollectMetricsRequest) (*CollectMetricsResponse, error) {
+	return m.DiagnosticsClient.CollectMetrics(ctx, req)
+}
+
+type DiagnosticsGRPCServer struct {
+	DiagnosticsPlugin
+}
+
+func (m *DiagnosticsGRPCServer) CheckHealth(ctx context.Context, req *CheckHealthRequest) (*CheckHealthResponse, error) {
+	return m.DiagnosticsPlugin.CheckHealth(ctx, req)
+}
+
+func (m *DiagnosticsGRPCServer) CollectMetrics(ctx context.Context, req *CollectMetricsRequest) (*CollectMetricsResponse, error) {
+	return m.DiagnosticsPlugin.CollectMetrics(ctx, req)
+}
@@ -0,0 +1,269 @@
+// Messages and service of diagnostics.proto, in the layout generated by
+// protoc-gen-go for github.com/golang/protobuf v1.2.
+
+package backendplugin
+
+import (
+	context "context"
+
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+)
+
+type CheckHealthResponse_HealthStatus int32
+
+const (
+	CheckHealthResponse_UNKNOWN CheckHealthResponse_HealthStatus = 0
+	CheckHealthResponse_OK      CheckHealthResponse_HealthStatus = 1
+	CheckHealthResponse_ERROR   CheckHealthResponse_HealthStatus = 2
+)
+
+var CheckHealthResponse_HealthStatus_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "OK",
+	2: "ERROR",
+}
+var CheckHealthResponse_HealthStatus_value = map[string]int32{
+	"UNKNOWN": 0,
+	"OK":      1,
+	"ERROR":   2,
+}
+
+func (x CheckHealthResponse_HealthStatus) String() string {
+	return proto.EnumName(CheckHealthResponse_HealthStatus_name, int32(x))
+}
+
+type CheckHealthRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CheckHealthRequest) Reset()         { *m = CheckHealthRequest{} }
+func (m *CheckHealthRequest) String() string { return proto.CompactTextString(m) }
+func (*CheckHealthRequest) ProtoMessage()    {}
+func (m *CheckHealthRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckHealthRequest.Unmarshal(m, b)
+}
+func (m *CheckHealthRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckHealthRequest.Marshal(b, m, deterministic)
+}
+func (dst *CheckHealthRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckHealthRequest.Merge(dst, src)
+}
+func (m *CheckHealthRequest) XXX_Size() int {
+	return xxx_messageInfo_CheckHealthRequest.Size(m)
+}
+func (m *CheckHealthRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckHealthRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CheckHealthRequest proto.InternalMessageInfo
+
+type CheckHealthResponse struct {
+	Status               CheckHealthResponse_HealthStatus `protobuf:"varint,1,opt,name=status,proto3,enum=backendplugin.CheckHealthResponse_HealthStatus" json:"status,omitempty"`
+	Message              string                           `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                         `json:"-"`
+	XXX_unrecognized     []byte                           `json:"-"`
+	XXX_sizecache        int32                            `json:"-"`
+}
+
+func (m *CheckHealthResponse) Reset()         { *m = CheckHealthResponse{} }
+func (m *CheckHealthResponse) String() string { return proto.CompactTextString(m) }
+func (*CheckHealthResponse) ProtoMessage()    {}
+func (m *CheckHealthResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckHealthResponse.Unmarshal(m, b)
+}
+func (m *CheckHealthResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckHealthResponse.Marshal(b, m, deterministic)
+}
+func (dst *CheckHealthResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckHealthResponse.Merge(dst, src)
+}
+func (m *CheckHealthResponse) XXX_Size() int {
+	return xxx_messageInfo_CheckHealthResponse.Size(m)
+}
+func (m *CheckHealthResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckHealthResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CheckHealthResponse proto.InternalMessageInfo
+
+func (m *CheckHealthResponse) GetStatus() CheckHealthResponse_HealthStatus {
+	if m != nil {
+		return m.Status
+	}
+	return CheckHealthResponse_UNKNOWN
+}
+
+func (m *CheckHealthResponse) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type CollectMetricsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CollectMetricsRequest) Reset()         { *m = CollectMetricsRequest{} }
+func (m *CollectMetricsRequest) String() string { return proto.CompactTextString(m) }
+func (*CollectMetricsRequest) ProtoMessage()    {}
+func (m *CollectMetricsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CollectMetricsRequest.Unmarshal(m, b)
+}
+func (m *CollectMetricsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CollectMetricsRequest.Marshal(b, m, deterministic)
+}
+func (dst *CollectMetricsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CollectMetricsRequest.Merge(dst, src)
+}
+func (m *CollectMetricsRequest) XXX_Size() int {
+	return xxx_messageInfo_CollectMetricsRequest.Size(m)
+}
+func (m *CollectMetricsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CollectMetricsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CollectMetricsRequest proto.InternalMessageInfo
+
+type CollectMetricsResponse struct {
+	Prometheus           []byte   `protobuf:"bytes,1,opt,name=prometheus,proto3" json:"prometheus,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CollectMetricsResponse) Reset()         { *m = CollectMetricsResponse{} }
+func (m *CollectMetricsResponse) String() string { return proto.CompactTextString(m) }
+func (*CollectMetricsResponse) ProtoMessage()    {}
+func (m *CollectMetricsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CollectMetricsResponse.Unmarshal(m, b)
+}
+func (m *CollectMetricsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CollectMetricsResponse.Marshal(b, m, deterministic)
+}
+func (dst *CollectMetricsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CollectMetricsResponse.Merge(dst, src)
+}
+func (m *CollectMetricsResponse) XXX_Size() int {
+	return xxx_messageInfo_CollectMetricsResponse.Size(m)
+}
+func (m *CollectMetricsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CollectMetricsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CollectMetricsResponse proto.InternalMessageInfo
+
+func (m *CollectMetricsResponse) GetPrometheus() []byte {
+	if m != nil {
+		return m.Prometheus
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*CheckHealthRequest)(nil), "backendplugin.CheckHealthRequest")
+	proto.RegisterType((*CheckHealthResponse)(nil), "backendplugin.CheckHealthResponse")
+	proto.RegisterType((*CollectMetricsRequest)(nil), "backendplugin.CollectMetricsRequest")
+	proto.RegisterType((*CollectMetricsResponse)(nil), "backendplugin.CollectMetricsResponse")
+	proto.RegisterEnum("backendplugin.CheckHealthResponse_HealthStatus", CheckHealthResponse_HealthStatus_name, CheckHealthResponse_HealthStatus_value)
+}
+
+// DiagnosticsClient is the client API for Diagnostics service.
+type DiagnosticsClient interface {
+	CheckHealth(ctx context.Context, in *CheckHealthRequest, opts ...grpc.CallOption) (*CheckHealthResponse, error)
+	CollectMetrics(ctx context.Context, in *CollectMetricsRequest, opts ...grpc.CallOption) (*CollectMetricsResponse, error)
+}
+
+type diagnosticsClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDiagnosticsClient(cc *grpc.ClientConn) DiagnosticsClient {
+	return &diagnosticsClient{cc}
+}
+
+func (c *diagnosticsClient) CheckHealth(ctx context.Context, in *CheckHealthRequest, opts ...grpc.CallOption) (*CheckHealthResponse, error) {
+	out := new(CheckHealthResponse)
+	err := c.cc.Invoke(ctx, "/backendplugin.Diagnostics/CheckHealth", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *diagnosticsClient) CollectMetrics(ctx context.Context, in *CollectMetricsRequest, opts ...grpc.CallOption) (*CollectMetricsResponse, error) {
+	out := new(CollectMetricsResponse)
+	err := c.cc.Invoke(ctx, "/backendplugin.Diagnostics/CollectMetrics", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DiagnosticsServer is the server API for Diagnostics service.
+type DiagnosticsServer interface {
+	CheckHealth(context.Context, *CheckHealthRequest) (*CheckHealthResponse, error)
+	CollectMetrics(context.Context, *CollectMetricsRequest) (*CollectMetricsResponse, error)
+}
+
+func RegisterDiagnosticsServer(s *grpc.Server, srv DiagnosticsServer) {
+	s.RegisterService(&_Diagnostics_serviceDesc, srv)
+}
+
+func _Diagnostics_CheckHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckHealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DiagnosticsServer).CheckHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/backendplugin.Diagnostics/CheckHealth",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DiagnosticsServer).CheckHealth(ctx, req.(*CheckHealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Diagnostics_CollectMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DiagnosticsServer).CollectMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/backendplugin.Diagnostics/CollectMetrics",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DiagnosticsServer).CollectMetrics(ctx, req.(*CollectMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Diagnostics_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "backendplugin.Diagnostics",
+	HandlerType: (*DiagnosticsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CheckHealth",
+			Handler:    _Diagnostics_CheckHealth_Handler,
+		},
+		{
+			MethodName: "CollectMetrics",
+			Handler:    _Diagnostics_CollectMetrics_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "diagnostics.proto",
+}
@@ -0,0 +1,51 @@
+// Package backendplugin holds the protocols, besides the query protocol of
+// datasources, that Grafana speaks with the backend of plugins.
+package backendplugin
+
+import (
+	"context"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+)
+
+// ResourcePluginName is the name the backend of a plugin serves the resource
+// protocol under.
+const ResourcePluginName = "resource"
+
+// ResourcePlugin handles the HTTP requests made to the resources of a plugin.
+type ResourcePlugin interface {
+	CallResource(ctx context.Context, req *ResourceRequest) (*ResourceResponse, error)
+}
+
+// ResourcePluginImpl connects a ResourcePlugin to go-plugin. Plugins serve it
+// next to their other plugins, and Grafana dispenses it to call them.
+type ResourcePluginImpl struct {
+	plugin.NetRPCUnsupportedPlugin
+	Plugin ResourcePlugin
+}
+
+func (p *ResourcePluginImpl) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	RegisterResourcePluginServer(s, &GRPCServer{p.Plugin})
+	return nil
+}
+
+func (p *ResourcePluginImpl) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &GRPCClient{NewResourcePluginClient(c)}, nil
+}
+
+type GRPCClient struct {
+	ResourcePluginClient
+}
+
+func (m *GRPCClient) CallResource(ctx context.Context, req *ResourceRequest) (*ResourceResponse, error) {
+	return m.ResourcePluginClient.CallResource(ctx, req)
+}
+
+type GRPCServer struct {
+	ResourcePlugin
+}
+
+func (m *GRPCServer) CallResource(ctx context.Context, req *ResourceRequest) (*ResourceResponse, error) {
+	return m.ResourcePlugin.CallResource(ctx, req)
+}
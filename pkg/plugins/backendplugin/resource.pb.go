@@ -0,0 +1,398 @@
+// Messages and service of resource.proto, in the layout generated by
+// protoc-gen-go for github.com/golang/protobuf v1.2.
+
+package backendplugin
+
+import (
+	context "context"
+
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type ResourceRequest struct {
+	PluginId             string        `protobuf:"bytes,1,opt,name=pluginId,proto3" json:"pluginId,omitempty"`
+	OrgId                int64         `protobuf:"varint,2,opt,name=orgId,proto3" json:"orgId,omitempty"`
+	User                 *User         `protobuf:"bytes,3,opt,name=user" json:"user,omitempty"`
+	Method               string        `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	Path                 string        `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`
+	Query                string        `protobuf:"bytes,6,opt,name=query,proto3" json:"query,omitempty"`
+	Headers              []*Header     `protobuf:"bytes,7,rep,name=headers" json:"headers,omitempty"`
+	Body                 []byte        `protobuf:"bytes,8,opt,name=body,proto3" json:"body,omitempty"`
+	Config               *PluginConfig `protobuf:"bytes,9,opt,name=config" json:"config,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ResourceRequest) Reset()         { *m = ResourceRequest{} }
+func (m *ResourceRequest) String() string { return proto.CompactTextString(m) }
+func (*ResourceRequest) ProtoMessage()    {}
+func (m *ResourceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResourceRequest.Unmarshal(m, b)
+}
+func (m *ResourceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResourceRequest.Marshal(b, m, deterministic)
+}
+func (dst *ResourceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResourceRequest.Merge(dst, src)
+}
+func (m *ResourceRequest) XXX_Size() int {
+	return xxx_messageInfo_ResourceRequest.Size(m)
+}
+func (m *ResourceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResourceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResourceRequest proto.InternalMessageInfo
+
+func (m *ResourceRequest) GetPluginId() string {
+	if m != nil {
+		return m.PluginId
+	}
+	return ""
+}
+
+func (m *ResourceRequest) GetOrgId() int64 {
+	if m != nil {
+		return m.OrgId
+	}
+	return 0
+}
+
+func (m *ResourceRequest) GetUser() *User {
+	if m != nil {
+		return m.User
+	}
+	return nil
+}
+
+func (m *ResourceRequest) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *ResourceRequest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *ResourceRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *ResourceRequest) GetHeaders() []*Header {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+func (m *ResourceRequest) GetBody() []byte {
+	if m != nil {
+		return m.Body
+	}
+	return nil
+}
+
+func (m *ResourceRequest) GetConfig() *PluginConfig {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+type User struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Login                string   `protobuf:"bytes,2,opt,name=login,proto3" json:"login,omitempty"`
+	Name                 string   `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Email                string   `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	Role                 string   `protobuf:"bytes,5,opt,name=role,proto3" json:"role,omitempty"`
+	IsGrafanaAdmin       bool     `protobuf:"varint,6,opt,name=isGrafanaAdmin,proto3" json:"isGrafanaAdmin,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *User) Reset()         { *m = User{} }
+func (m *User) String() string { return proto.CompactTextString(m) }
+func (*User) ProtoMessage()    {}
+func (m *User) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_User.Unmarshal(m, b)
+}
+func (m *User) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_User.Marshal(b, m, deterministic)
+}
+func (dst *User) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_User.Merge(dst, src)
+}
+func (m *User) XXX_Size() int {
+	return xxx_messageInfo_User.Size(m)
+}
+func (m *User) XXX_DiscardUnknown() {
+	xxx_messageInfo_User.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_User proto.InternalMessageInfo
+
+func (m *User) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *User) GetLogin() string {
+	if m != nil {
+		return m.Login
+	}
+	return ""
+}
+
+func (m *User) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *User) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *User) GetRole() string {
+	if m != nil {
+		return m.Role
+	}
+	return ""
+}
+
+func (m *User) GetIsGrafanaAdmin() bool {
+	if m != nil {
+		return m.IsGrafanaAdmin
+	}
+	return false
+}
+
+type Header struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Values               []string `protobuf:"bytes,2,rep,name=values" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Header) Reset()         { *m = Header{} }
+func (m *Header) String() string { return proto.CompactTextString(m) }
+func (*Header) ProtoMessage()    {}
+func (m *Header) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Header.Unmarshal(m, b)
+}
+func (m *Header) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Header.Marshal(b, m, deterministic)
+}
+func (dst *Header) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Header.Merge(dst, src)
+}
+func (m *Header) XXX_Size() int {
+	return xxx_messageInfo_Header.Size(m)
+}
+func (m *Header) XXX_DiscardUnknown() {
+	xxx_messageInfo_Header.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Header proto.InternalMessageInfo
+
+func (m *Header) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Header) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type PluginConfig struct {
+	JsonData                string            `protobuf:"bytes,1,opt,name=jsonData,proto3" json:"jsonData,omitempty"`
+	DecryptedSecureJsonData map[string]string `protobuf:"bytes,2,rep,name=decryptedSecureJsonData" json:"decryptedSecureJsonData,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral    struct{}          `json:"-"`
+	XXX_unrecognized        []byte            `json:"-"`
+	XXX_sizecache           int32             `json:"-"`
+}
+
+func (m *PluginConfig) Reset()         { *m = PluginConfig{} }
+func (m *PluginConfig) String() string { return proto.CompactTextString(m) }
+func (*PluginConfig) ProtoMessage()    {}
+func (m *PluginConfig) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PluginConfig.Unmarshal(m, b)
+}
+func (m *PluginConfig) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PluginConfig.Marshal(b, m, deterministic)
+}
+func (dst *PluginConfig) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PluginConfig.Merge(dst, src)
+}
+func (m *PluginConfig) XXX_Size() int {
+	return xxx_messageInfo_PluginConfig.Size(m)
+}
+func (m *PluginConfig) XXX_DiscardUnknown() {
+	xxx_messageInfo_PluginConfig.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PluginConfig proto.InternalMessageInfo
+
+func (m *PluginConfig) GetJsonData() string {
+	if m != nil {
+		return m.JsonData
+	}
+	return ""
+}
+
+func (m *PluginConfig) GetDecryptedSecureJsonData() map[string]string {
+	if m != nil {
+		return m.DecryptedSecureJsonData
+	}
+	return nil
+}
+
+type ResourceResponse struct {
+	Status               int32     `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	Headers              []*Header `protobuf:"bytes,2,rep,name=headers" json:"headers,omitempty"`
+	Body                 []byte    `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ResourceResponse) Reset()         { *m = ResourceResponse{} }
+func (m *ResourceResponse) String() string { return proto.CompactTextString(m) }
+func (*ResourceResponse) ProtoMessage()    {}
+func (m *ResourceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResourceResponse.Unmarshal(m, b)
+}
+func (m *ResourceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResourceResponse.Marshal(b, m, deterministic)
+}
+func (dst *ResourceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResourceResponse.Merge(dst, src)
+}
+func (m *ResourceResponse) XXX_Size() int {
+	return xxx_messageInfo_ResourceResponse.Size(m)
+}
+func (m *ResourceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResourceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResourceResponse proto.InternalMessageInfo
+
+func (m *ResourceResponse) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *ResourceResponse) GetHeaders() []*Header {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+func (m *ResourceResponse) GetBody() []byte {
+	if m != nil {
+		return m.Body
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ResourceRequest)(nil), "backendplugin.ResourceRequest")
+	proto.RegisterType((*User)(nil), "backendplugin.User")
+	proto.RegisterType((*Header)(nil), "backendplugin.Header")
+	proto.RegisterType((*PluginConfig)(nil), "backendplugin.PluginConfig")
+	proto.RegisterType((*ResourceResponse)(nil), "backendplugin.ResourceResponse")
+	proto.RegisterMapType((map[string]string)(nil), "backendplugin.PluginConfig.DecryptedSecureJsonDataEntry")
+}
+
+// ResourcePluginClient is the client API for ResourcePlugin service.
+type ResourcePluginClient interface {
+	CallResource(ctx context.Context, in *ResourceRequest, opts ...grpc.CallOption) (*ResourceResponse, error)
+}
+
+type resourcePluginClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewResourcePluginClient(cc *grpc.ClientConn) ResourcePluginClient {
+	return &resourcePluginClient{cc}
+}
+
+func (c *resourcePluginClient) CallResource(ctx context.Context, in *ResourceRequest, opts ...grpc.CallOption) (*ResourceResponse, error) {
+	out := new(ResourceResponse)
+	err := c.cc.Invoke(ctx, "/backendplugin.ResourcePlugin/CallResource", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ResourcePluginServer is the server API for ResourcePlugin service.
+type ResourcePluginServer interface {
+	CallResource(context.Context, *ResourceRequest) (*ResourceResponse, error)
+}
+
+func RegisterResourcePluginServer(s *grpc.Server, srv ResourcePluginServer) {
+	s.RegisterService(&_ResourcePlugin_serviceDesc, srv)
+}
+
+func _ResourcePlugin_CallResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourcePluginServer).CallResource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/backendplugin.ResourcePlugin/CallResource",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourcePluginServer).CallResource(ctx, req.(*ResourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _ResourcePlugin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "backendplugin.ResourcePlugin",
+	HandlerType: (*ResourcePluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CallResource",
+			Handler:    _ResourcePlugin_CallResource_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "resource.proto",
+}
@@ -0,0 +1,59 @@
+package backendplugin
+
+import (
+	"context"
+	"testing"
+
+	plugin "github.com/hashicorp/go-plugin"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type echoResourcePlugin struct{}
+
+func (p *echoResourcePlugin) CallResource(ctx context.Context, req *ResourceRequest) (*ResourceResponse, error) {
+	return &ResourceResponse{
+		Status: 201,
+		Headers: []*Header{
+			{Name: "X-Path", Values: []string{req.Path}},
+			{Name: "X-User", Values: []string{req.User.Login, req.User.Role}},
+			{Name: "X-Secret", Values: []string{req.Config.DecryptedSecureJsonData["token"]}},
+		},
+		Body: req.Body,
+	}, nil
+}
+
+func TestResourcePlugin(t *testing.T) {
+	Convey("Given a plugin serving resources over gRPC", t, func() {
+		client, server := plugin.TestPluginGRPCConn(t, map[string]plugin.Plugin{
+			ResourcePluginName: &ResourcePluginImpl{Plugin: &echoResourcePlugin{}},
+		})
+		defer client.Close()
+		defer server.Stop()
+
+		raw, err := client.Dispense(ResourcePluginName)
+		So(err, ShouldBeNil)
+
+		Convey("Should pass the request and response through", func() {
+			res, err := raw.(ResourcePlugin).CallResource(context.Background(), &ResourceRequest{
+				PluginId: "test-app",
+				OrgId:    2,
+				User:     &User{Id: 3, Login: "viewer", Role: "Viewer"},
+				Method:   "POST",
+				Path:     "items/1",
+				Body:     []byte(`{"name":"item"}`),
+				Config: &PluginConfig{
+					JsonData:                `{"url":"http://localhost"}`,
+					DecryptedSecureJsonData: map[string]string{"token": "secret"},
+				},
+			})
+
+			So(err, ShouldBeNil)
+			So(res.Status, ShouldEqual, 201)
+			So(string(res.Body), ShouldEqual, `{"name":"item"}`)
+			So(res.Headers, ShouldHaveLength, 3)
+			So(res.Headers[0].Values, ShouldResemble, []string{"items/1"})
+			So(res.Headers[1].Values, ShouldResemble, []string{"viewer", "Viewer"})
+			So(res.Headers[2].Values, ShouldResemble, []string{"secret"})
+		})
+	})
+}
@@ -0,0 +1,68 @@
+package backendplugin
+
+import (
+	"context"
+	"io"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+)
+
+// StreamPluginName is the name the backend of a datasource plugin serves the
+// stream protocol under.
+const StreamPluginName = "stream"
+
+// StreamPlugin streams data of a datasource. RunStream sends packets until
+// the context is done, which is when the last client unsubscribed, or the
+// stream ends.
+type StreamPlugin interface {
+	RunStream(ctx context.Context, req *StreamRequest, send func(*StreamPacket) error) error
+}
+
+// StreamPluginImpl connects a StreamPlugin to go-plugin.
+type StreamPluginImpl struct {
+	plugin.NetRPCUnsupportedPlugin
+	Plugin StreamPlugin
+}
+
+func (p *StreamPluginImpl) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	RegisterStreamPluginServer(s, &StreamGRPCServer{p.Plugin})
+	return nil
+}
+
+func (p *StreamPluginImpl) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &StreamGRPCClient{NewStreamPluginClient(c)}, nil
+}
+
+type StreamGRPCClient struct {
+	StreamPluginClient
+}
+
+func (m *StreamGRPCClient) RunStream(ctx context.Context, req *StreamRequest, send func(*StreamPacket) error) error {
+	stream, err := m.StreamPluginClient.RunStream(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		packet, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := send(packet); err != nil {
+			return err
+		}
+	}
+}
+
+type StreamGRPCServer struct {
+	StreamPlugin
+}
+
+func (m *StreamGRPCServer) RunStream(req *StreamRequest, server StreamPlugin_RunStreamServer) error {
+	return m.StreamPlugin.RunStream(server.Context(), req, server.Send)
+}
@@ -0,0 +1,322 @@
+// Messages and service of stream.proto, in the layout generated by
+// protoc-gen-go for github.com/golang/protobuf v1.2.
+
+package backendplugin
+
+import (
+	context "context"
+
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+
+type StreamRequest struct {
+	PluginId             string        `protobuf:"bytes,1,opt,name=pluginId,proto3" json:"pluginId,omitempty"`
+	OrgId                int64         `protobuf:"varint,2,opt,name=orgId,proto3" json:"orgId,omitempty"`
+	User                 *User         `protobuf:"bytes,3,opt,name=user" json:"user,omitempty"`
+	Path                 string        `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	DatasourceId         int64         `protobuf:"varint,5,opt,name=datasourceId,proto3" json:"datasourceId,omitempty"`
+	DatasourceName       string        `protobuf:"bytes,6,opt,name=datasourceName,proto3" json:"datasourceName,omitempty"`
+	DatasourceUrl        string        `protobuf:"bytes,7,opt,name=datasourceUrl,proto3" json:"datasourceUrl,omitempty"`
+	Config               *PluginConfig `protobuf:"bytes,8,opt,name=config" json:"config,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *StreamRequest) Reset()         { *m = StreamRequest{} }
+func (m *StreamRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamRequest) ProtoMessage()    {}
+func (m *StreamRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamRequest.Unmarshal(m, b)
+}
+func (m *StreamRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamRequest.Marshal(b, m, deterministic)
+}
+func (dst *StreamRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamRequest.Merge(dst, src)
+}
+func (m *StreamRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamRequest.Size(m)
+}
+func (m *StreamRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamRequest proto.InternalMessageInfo
+
+func (m *StreamRequest) GetPluginId() string {
+	if m != nil {
+		return m.PluginId
+	}
+	return ""
+}
+
+func (m *StreamRequest) GetOrgId() int64 {
+	if m != nil {
+		return m.OrgId
+	}
+	return 0
+}
+
+func (m *StreamRequest) GetUser() *User {
+	if m != nil {
+		return m.User
+	}
+	return nil
+}
+
+func (m *StreamRequest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *StreamRequest) GetDatasourceId() int64 {
+	if m != nil {
+		return m.DatasourceId
+	}
+	return 0
+}
+
+func (m *StreamRequest) GetDatasourceName() string {
+	if m != nil {
+		return m.DatasourceName
+	}
+	return ""
+}
+
+func (m *StreamRequest) GetDatasourceUrl() string {
+	if m != nil {
+		return m.DatasourceUrl
+	}
+	return ""
+}
+
+func (m *StreamRequest) GetConfig() *PluginConfig {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+type StreamPacket struct {
+	Series               []*StreamSeries `protobuf:"bytes,1,rep,name=series" json:"series,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *StreamPacket) Reset()         { *m = StreamPacket{} }
+func (m *StreamPacket) String() string { return proto.CompactTextString(m) }
+func (*StreamPacket) ProtoMessage()    {}
+func (m *StreamPacket) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamPacket.Unmarshal(m, b)
+}
+func (m *StreamPacket) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamPacket.Marshal(b, m, deterministic)
+}
+func (dst *StreamPacket) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamPacket.Merge(dst, src)
+}
+func (m *StreamPacket) XXX_Size() int {
+	return xxx_messageInfo_StreamPacket.Size(m)
+}
+func (m *StreamPacket) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamPacket.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamPacket proto.InternalMessageInfo
+
+func (m *StreamPacket) GetSeries() []*StreamSeries {
+	if m != nil {
+		return m.Series
+	}
+	return nil
+}
+
+type StreamSeries struct {
+	Name                 string         `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Points               []*StreamPoint `protobuf:"bytes,2,rep,name=points" json:"points,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *StreamSeries) Reset()         { *m = StreamSeries{} }
+func (m *StreamSeries) String() string { return proto.CompactTextString(m) }
+func (*StreamSeries) ProtoMessage()    {}
+func (m *StreamSeries) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamSeries.Unmarshal(m, b)
+}
+func (m *StreamSeries) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamSeries.Marshal(b, m, deterministic)
+}
+func (dst *StreamSeries) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamSeries.Merge(dst, src)
+}
+func (m *StreamSeries) XXX_Size() int {
+	return xxx_messageInfo_StreamSeries.Size(m)
+}
+func (m *StreamSeries) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamSeries.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamSeries proto.InternalMessageInfo
+
+func (m *StreamSeries) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StreamSeries) GetPoints() []*StreamPoint {
+	if m != nil {
+		return m.Points
+	}
+	return nil
+}
+
+type StreamPoint struct {
+	Timestamp            int64    `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Value                float64  `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamPoint) Reset()         { *m = StreamPoint{} }
+func (m *StreamPoint) String() string { return proto.CompactTextString(m) }
+func (*StreamPoint) ProtoMessage()    {}
+func (m *StreamPoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamPoint.Unmarshal(m, b)
+}
+func (m *StreamPoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamPoint.Marshal(b, m, deterministic)
+}
+func (dst *StreamPoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamPoint.Merge(dst, src)
+}
+func (m *StreamPoint) XXX_Size() int {
+	return xxx_messageInfo_StreamPoint.Size(m)
+}
+func (m *StreamPoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamPoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamPoint proto.InternalMessageInfo
+
+func (m *StreamPoint) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *StreamPoint) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*StreamRequest)(nil), "backendplugin.StreamRequest")
+	proto.RegisterType((*StreamPacket)(nil), "backendplugin.StreamPacket")
+	proto.RegisterType((*StreamSeries)(nil), "backendplugin.StreamSeries")
+	proto.RegisterType((*StreamPoint)(nil), "backendplugin.StreamPoint")
+}
+
+// StreamPluginClient is the client API for StreamPlugin service.
+type StreamPluginClient interface {
+	RunStream(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (StreamPlugin_RunStreamClient, error)
+}
+
+type streamPluginClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewStreamPluginClient(cc *grpc.ClientConn) StreamPluginClient {
+	return &streamPluginClient{cc}
+}
+
+func (c *streamPluginClient) RunStream(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (StreamPlugin_RunStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_StreamPlugin_serviceDesc.Streams[0], "/backendplugin.StreamPlugin/RunStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &streamPluginRunStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StreamPlugin_RunStreamClient interface {
+	Recv() (*StreamPacket, error)
+	grpc.ClientStream
+}
+
+type streamPluginRunStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *streamPluginRunStreamClient) Recv() (*StreamPacket, error) {
+	m := new(StreamPacket)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// StreamPluginServer is the server API for StreamPlugin service.
+type StreamPluginServer interface {
+	RunStream(*StreamRequest, StreamPlugin_RunStreamServer) error
+}
+
+func RegisterStreamPluginServer(s *grpc.Server, srv StreamPluginServer) {
+	s.RegisterService(&_StreamPlugin_serviceDesc, srv)
+}
+
+func _StreamPlugin_RunStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StreamPluginServer).RunStream(m, &streamPluginRunStreamServer{stream})
+}
+
+type StreamPlugin_RunStreamServer interface {
+	Send(*StreamPacket) error
+	grpc.ServerStream
+}
+
+type streamPluginRunStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *streamPluginRunStreamServer) Send(m *StreamPacket) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _StreamPlugin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "backendplugin.StreamPlugin",
+	HandlerType: (*StreamPluginServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RunStream",
+			Handler:       _StreamPlugin_RunStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "stream.proto",
+}
@@ -0,0 +1,52 @@
+package backendplugin
+
+import (
+	"context"
+	"testing"
+
+	plugin "github.com/hashicorp/go-plugin"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type counterStreamPlugin struct{}
+
+func (p *counterStreamPlugin) RunStream(ctx context.Context, req *StreamRequest, send func(*StreamPacket) error) error {
+	for i := 0; i < 3; i++ {
+		err := send(&StreamPacket{
+			Series: []*StreamSeries{
+				{Name: req.Path, Points: []*StreamPoint{{Timestamp: int64(i), Value: float64(i * 10)}}},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestStreamPlugin(t *testing.T) {
+	Convey("Given a plugin serving streams over gRPC", t, func() {
+		client, server := plugin.TestPluginGRPCConn(t, map[string]plugin.Plugin{
+			StreamPluginName: &StreamPluginImpl{Plugin: &counterStreamPlugin{}},
+		})
+		defer client.Close()
+		defer server.Stop()
+
+		raw, err := client.Dispense(StreamPluginName)
+		So(err, ShouldBeNil)
+
+		Convey("Should receive the packets until the stream ends", func() {
+			packets := []*StreamPacket{}
+			err := raw.(StreamPlugin).RunStream(context.Background(), &StreamRequest{Path: "counter"}, func(packet *StreamPacket) error {
+				packets = append(packets, packet)
+				return nil
+			})
+
+			So(err, ShouldBeNil)
+			So(packets, ShouldHaveLength, 3)
+			So(packets[2].Series[0].Name, ShouldEqual, "counter")
+			So(packets[2].Series[0].Points[0].Value, ShouldEqual, 20)
+		})
+	})
+}
@@ -0,0 +1,92 @@
+package wrapper
+
+import (
+	"context"
+	"math"
+
+	"github.com/Seasheller/grafana/pkg/components/null"
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/plugins/backendplugin"
+	"github.com/Seasheller/grafana/pkg/tsdb"
+	"github.com/grafana/grafana-plugin-model/go/datasource"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func NewStreamingDatasourcePluginWrapper(log log.Logger, plugin datasource.DatasourcePlugin, stream backendplugin.StreamPlugin) *StreamingDatasourcePluginWrapper {
+	return &StreamingDatasourcePluginWrapper{
+		DatasourcePluginWrapper: NewDatasourcePluginWrapper(log, plugin),
+		stream:                  stream,
+	}
+}
+
+// StreamingDatasourcePluginWrapper queries the backend of a datasource plugin
+// and runs its streams. Backends that don't serve the stream protocol don't
+// support streaming.
+type StreamingDatasourcePluginWrapper struct {
+	*DatasourcePluginWrapper
+	stream backendplugin.StreamPlugin
+}
+
+func (tw *StreamingDatasourcePluginWrapper) RunStream(ctx context.Context, ds *models.DataSource, req *tsdb.StreamRequest, send func(*models.StreamPacket) error) error {
+	jsonData, err := ds.JsonData.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	pbReq := &backendplugin.StreamRequest{
+		PluginId:       ds.Type,
+		OrgId:          ds.OrgId,
+		Path:           req.Path,
+		DatasourceId:   ds.Id,
+		DatasourceName: ds.Name,
+		DatasourceUrl:  ds.Url,
+		Config: &backendplugin.PluginConfig{
+			JsonData:                string(jsonData),
+			DecryptedSecureJsonData: ds.SecureJsonData.Decrypt(),
+		},
+	}
+
+	if req.User != nil {
+		pbReq.User = &backendplugin.User{
+			Id:             req.User.UserId,
+			Login:          req.User.Login,
+			Name:           req.User.Name,
+			Email:          req.User.Email,
+			Role:           string(req.User.OrgRole),
+			IsGrafanaAdmin: req.User.IsGrafanaAdmin,
+		}
+	}
+
+	err = tw.stream.RunStream(ctx, pbReq, func(packet *backendplugin.StreamPacket) error {
+		return send(mapStreamPacket(req.Stream, packet))
+	})
+	if status.Code(err) == codes.Unimplemented {
+		return tsdb.ErrStreamingNotSupported
+	}
+
+	return err
+}
+
+func mapStreamPacket(stream string, packet *backendplugin.StreamPacket) *models.StreamPacket {
+	result := &models.StreamPacket{
+		Stream: stream,
+		Series: make([]models.StreamSeries, 0, len(packet.Series)),
+	}
+
+	for _, s := range packet.Series {
+		points := make(models.TimeSeriesPoints, 0, len(s.Points))
+		for _, p := range s.Points {
+			value := null.NewFloat(p.Value, !math.IsNaN(p.Value))
+			points = append(points, models.TimePoint{value, null.FloatFrom(float64(p.Timestamp))})
+		}
+
+		result.Series = append(result.Series, models.StreamSeries{
+			Name:   s.Name,
+			Points: points,
+		})
+	}
+
+	return result
+}
@@ -1,9 +1,11 @@
 package wrapper
 
 import (
+	"math"
 	"testing"
 
 	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/plugins/backendplugin"
 	"github.com/Seasheller/grafana/pkg/tsdb"
 	"github.com/grafana/grafana-plugin-model/go/datasource"
 )
@@ -107,3 +109,32 @@ func TestMappingRowValue(t *testing.T) {
 		t.Fatalf("Expected %v, was %v", nil, haveNil)
 	}
 }
+
+func TestMapStreamPacket(t *testing.T) {
+	packet := mapStreamPacket("ds/1/random-walk", &backendplugin.StreamPacket{
+		Series: []*backendplugin.StreamSeries{
+			{
+				Name: "walk",
+				Points: []*backendplugin.StreamPoint{
+					{Timestamp: 1000, Value: 1.5},
+					{Timestamp: 2000, Value: math.NaN()},
+				},
+			},
+		},
+	})
+
+	if packet.Stream != "ds/1/random-walk" {
+		t.Errorf("stream should be ds/1/random-walk, got %s", packet.Stream)
+	}
+	if len(packet.Series) != 1 || len(packet.Series[0].Points) != 2 {
+		t.Fatalf("could not map all series and points")
+	}
+
+	points := packet.Series[0].Points
+	if points[0][0].Float64 != 1.5 || points[0][1].Float64 != 1000 {
+		t.Errorf("first point should be [1.5, 1000], got %v", points[0])
+	}
+	if points[1][0].Valid {
+		t.Errorf("NaN should be mapped to null, got %v", points[1][0])
+	}
+}
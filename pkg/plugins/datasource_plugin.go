@@ -3,12 +3,9 @@ package plugins
 import (
 	"context"
 	"encoding/json"
-	"os/exec"
-	"path"
-	"time"
 
-	"github.com/Seasheller/grafana/pkg/infra/log"
 	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/plugins/backendplugin"
 	"github.com/Seasheller/grafana/pkg/plugins/datasource/wrapper"
 	"github.com/Seasheller/grafana/pkg/tsdb"
 	"github.com/grafana/grafana-plugin-model/go/datasource"
@@ -31,11 +28,7 @@ type DataSourcePlugin struct {
 	Routes        []*AppPluginRoute `json:"routes"`
 	Streaming     bool              `json:"streaming"`
 
-	Backend    bool   `json:"backend,omitempty"`
-	Executable string `json:"executable,omitempty"`
-
-	log    log.Logger
-	client *plugin.Client
+	BackendPluginBase
 }
 
 func (p *DataSourcePlugin) Load(decoder *json.Decoder, pluginDir string) error {
@@ -57,70 +50,31 @@ var handshakeConfig = plugin.HandshakeConfig{
 	MagicCookieValue: "datasource",
 }
 
-func (p *DataSourcePlugin) startBackendPlugin(ctx context.Context, log log.Logger) error {
-	p.log = log.New("plugin-id", p.Id)
-
-	err := p.spawnSubProcess()
-	if err == nil {
-		go p.restartKilledProcess(ctx)
+func (p *DataSourcePlugin) startBackendPlugin(ctx context.Context) error {
+	plugins := map[string]plugin.Plugin{
+		p.Id:                           &datasource.DatasourcePluginImpl{},
+		backendplugin.StreamPluginName: &backendplugin.StreamPluginImpl{},
 	}
 
-	return err
-}
-
-func (p *DataSourcePlugin) spawnSubProcess() error {
-	cmd := ComposePluginStartCommmand(p.Executable)
-	fullpath := path.Join(p.PluginDir, cmd)
+	return p.startBackend(ctx, &p.PluginBase, handshakeConfig, plugins, func(rpcClient plugin.ClientProtocol) error {
+		raw, err := rpcClient.Dispense(p.Id)
+		if err != nil {
+			return err
+		}
 
-	p.client = plugin.NewClient(&plugin.ClientConfig{
-		HandshakeConfig:  handshakeConfig,
-		Plugins:          map[string]plugin.Plugin{p.Id: &datasource.DatasourcePluginImpl{}},
-		Cmd:              exec.Command(fullpath),
-		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
-		Logger:           LogWrapper{Logger: p.log},
-	})
+		plugin := raw.(datasource.DatasourcePlugin)
 
-	rpcClient, err := p.client.Client()
-	if err != nil {
-		return err
-	}
+		rawStream, err := rpcClient.Dispense(backendplugin.StreamPluginName)
+		if err != nil {
+			return err
+		}
 
-	raw, err := rpcClient.Dispense(p.Id)
-	if err != nil {
-		return err
-	}
+		stream := rawStream.(backendplugin.StreamPlugin)
 
-	plugin := raw.(datasource.DatasourcePlugin)
+		tsdb.RegisterTsdbQueryEndpoint(p.Id, func(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+			return wrapper.NewStreamingDatasourcePluginWrapper(p.log, plugin, stream), nil
+		})
 
-	tsdb.RegisterTsdbQueryEndpoint(p.Id, func(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
-		return wrapper.NewDatasourcePluginWrapper(p.log, plugin), nil
+		return nil
 	})
-
-	return nil
-}
-
-func (p *DataSourcePlugin) restartKilledProcess(ctx context.Context) error {
-	ticker := time.NewTicker(time.Second * 1)
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			if p.client.Exited() {
-				err := p.spawnSubProcess()
-				p.log.Debug("Spawning new sub process", "name", p.Name, "id", p.Id)
-				if err != nil {
-					p.log.Error("Failed to spawn subprocess")
-				}
-			}
-		}
-	}
-}
-
-func (p *DataSourcePlugin) Kill() {
-	if p.client != nil {
-		p.log.Debug("Killing subprocess ", "name", p.Name)
-		p.client.Kill()
-	}
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/plugins/signature"
 	"github.com/Seasheller/grafana/pkg/setting"
 )
 
@@ -57,6 +58,8 @@ type PluginBase struct {
 
 	GrafanaNetVersion   string `json:"-"`
 	GrafanaNetHasUpdate bool   `json:"-"`
+
+	Signature signature.Status `json:"-"`
 }
 
 func (pb *PluginBase) registerPlugin(pluginDir string) error {
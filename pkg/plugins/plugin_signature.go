@@ -0,0 +1,79 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Seasheller/grafana/pkg/plugins/signature"
+	"github.com/Seasheller/grafana/pkg/setting"
+	"golang.org/x/crypto/ed25519"
+)
+
+// signatureKeys are the trusted keys the manifests of plugins can be signed
+// with.
+var signatureKeys map[string]ed25519.PublicKey
+
+// verifySignature verifies the manifest of the plugin in the dir. Plugins
+// included in an app without a manifest of their own are verified with the
+// manifest of the app.
+func (scanner *PluginScanner) verifySignature(pluginDir, pluginId string) (signature.Status, error) {
+	if setting.StaticRootPath != "" && strings.HasPrefix(pluginDir, setting.StaticRootPath) {
+		return signature.StatusInternal, nil
+	}
+
+	if _, err := os.Stat(filepath.Join(pluginDir, signature.ManifestFileName)); os.IsNotExist(err) {
+		if appDir, appId := scanner.findParentPlugin(pluginDir); appDir != "" {
+			return signature.Verify(appDir, appId, signatureKeys)
+		}
+	}
+
+	return signature.Verify(pluginDir, pluginId, signatureKeys)
+}
+
+// findParentPlugin returns the dir and id of the closest plugin the plugin in
+// the dir is included in, within the scanned path.
+func (scanner *PluginScanner) findParentPlugin(pluginDir string) (string, string) {
+	root := filepath.Clean(scanner.pluginPath)
+
+	for dir := filepath.Dir(filepath.Clean(pluginDir)); strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if data, err := os.Open(filepath.Join(dir, "plugin.json")); err == nil {
+			parent := PluginBase{}
+			err := json.NewDecoder(data).Decode(&parent)
+			data.Close()
+			if err == nil && parent.Id != "" {
+				return dir, parent.Id
+			}
+		}
+
+		if dir == root || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	return "", ""
+}
+
+// checkSignature applies the signature policy to a plugin about to be
+// loaded.
+func checkSignature(pluginId string, status signature.Status, reason error) error {
+	if status == signature.StatusInternal || status == signature.StatusValid {
+		return nil
+	}
+
+	for _, id := range setting.PluginsAllowUnsigned {
+		if id == pluginId {
+			plog.Debug("Loading allowed plugin without valid signature", "id", pluginId, "signature", status)
+			return nil
+		}
+	}
+
+	if setting.PluginSignaturePolicy == "refuse" {
+		return fmt.Errorf("Plugin %s has signature status %s: %v", pluginId, status, reason)
+	}
+
+	plog.Warn("Loading plugin without valid signature", "id", pluginId, "signature", status, "reason", reason)
+	return nil
+}
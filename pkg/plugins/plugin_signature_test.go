@@ -0,0 +1,80 @@
+package plugins
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Seasheller/grafana/pkg/plugins/signature"
+	"github.com/Seasheller/grafana/pkg/setting"
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/crypto/ed25519"
+	"gopkg.in/ini.v1"
+)
+
+func TestPluginSignatureVerification(t *testing.T) {
+	Convey("Given plugins with and without signature", t, func() {
+		dir, err := ioutil.TempDir("", "plugins")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		So(err, ShouldBeNil)
+
+		writePluginFile(dir, "signed-app/plugin.json", `{"id":"signed-app","type":"app","name":"Signed"}`)
+		writePluginFile(dir, "signed-app/datasource/plugin.json", `{"id":"signed-datasource","type":"datasource","name":"Included"}`)
+		err = signature.Sign(filepath.Join(dir, "signed-app"), &signature.Manifest{Plugin: "signed-app", KeyId: "internal"}, private)
+		So(err, ShouldBeNil)
+
+		writePluginFile(dir, "unsigned-app/plugin.json", `{"id":"unsigned-app","type":"app","name":"Unsigned"}`)
+		writePluginFile(dir, "internal-app/plugin.json", `{"id":"internal-app","type":"app","name":"Internal"}`)
+
+		setting.Raw = ini.Empty()
+		sec, _ := setting.Raw.NewSection("plugin.signed")
+		sec.NewKey("path", dir)
+		setting.PluginSignatureKeys = []string{"internal:" + base64.StdEncoding.EncodeToString(public)}
+		setting.PluginsAllowUnsigned = []string{"internal-app"}
+		defer func() {
+			setting.PluginSignatureKeys = nil
+			setting.PluginSignaturePolicy = ""
+			setting.PluginsAllowUnsigned = nil
+		}()
+
+		Convey("When the policy is to warn", func() {
+			setting.PluginSignaturePolicy = "warn"
+			pm := &PluginManager{}
+			So(pm.Init(), ShouldBeNil)
+
+			Convey("Should load all plugins with their signature status", func() {
+				So(Plugins["signed-app"].Signature, ShouldEqual, signature.StatusValid)
+				So(Plugins["signed-datasource"].Signature, ShouldEqual, signature.StatusValid)
+				So(Plugins["unsigned-app"].Signature, ShouldEqual, signature.StatusUnsigned)
+				So(Plugins["internal-app"].Signature, ShouldEqual, signature.StatusUnsigned)
+			})
+		})
+
+		Convey("When the policy is to refuse", func() {
+			setting.PluginSignaturePolicy = "refuse"
+			writePluginFile(dir, "signed-app/module.js", "define([], function() {})")
+
+			pm := &PluginManager{}
+			So(pm.Init(), ShouldBeNil)
+
+			Convey("Should only load allowed plugins without valid signature", func() {
+				So(Plugins, ShouldContainKey, "internal-app")
+				So(Plugins, ShouldNotContainKey, "unsigned-app")
+				So(Plugins, ShouldNotContainKey, "signed-app")
+				So(Plugins, ShouldNotContainKey, "signed-datasource")
+			})
+		})
+	})
+}
+
+func writePluginFile(dir, name, content string) {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	So(os.MkdirAll(filepath.Dir(path), 0755), ShouldBeNil)
+	So(ioutil.WriteFile(path, []byte(content), 0644), ShouldBeNil)
+}
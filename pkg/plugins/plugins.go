@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/plugins/signature"
 	"github.com/Seasheller/grafana/pkg/registry"
 	"github.com/Seasheller/grafana/pkg/setting"
 	"github.com/Seasheller/grafana/pkg/util"
@@ -43,7 +44,13 @@ type PluginManager struct {
 }
 
 func init() {
-	registry.RegisterService(&PluginManager{})
+	// plugins are loaded before the services that use them, like the
+	// provisioning of app plugin settings
+	registry.Register(&registry.Descriptor{
+		Name:         "PluginManager",
+		Instance:     &PluginManager{},
+		InitPriority: registry.Medium,
+	})
 }
 
 func (pm *PluginManager) Init() error {
@@ -62,6 +69,12 @@ func (pm *PluginManager) Init() error {
 		"renderer":   RendererPlugin{},
 	}
 
+	keys, err := signature.ParseKeys(setting.PluginSignatureKeys)
+	if err != nil {
+		pm.log.Error("Failed to parse plugin signature keys", "error", err)
+	}
+	signatureKeys = keys
+
 	pm.log.Info("Starting plugin search")
 	scan(path.Join(setting.StaticRootPath, "app/plugins"))
 
@@ -98,12 +111,20 @@ func (pm *PluginManager) Init() error {
 func (pm *PluginManager) startBackendPlugins(ctx context.Context) error {
 	for _, ds := range DataSources {
 		if ds.Backend {
-			if err := ds.startBackendPlugin(ctx, plog); err != nil {
+			if err := ds.startBackendPlugin(ctx); err != nil {
 				pm.log.Error("Failed to init plugin.", "error", err, "plugin", ds.Id)
 			}
 		}
 	}
 
+	for _, app := range Apps {
+		if app.Backend {
+			if err := app.startBackendPlugin(ctx); err != nil {
+				pm.log.Error("Failed to init plugin.", "error", err, "plugin", app.Id)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -128,6 +149,9 @@ func (pm *PluginManager) Run(ctx context.Context) error {
 	for _, p := range DataSources {
 		p.Kill()
 	}
+	for _, p := range Apps {
+		p.Kill()
+	}
 
 	return ctx.Err()
 }
@@ -210,10 +234,20 @@ func (scanner *PluginScanner) loadPluginJson(pluginJsonFilePath string) error {
 	if !exists {
 		return errors.New("Unknown plugin type " + pluginCommon.Type)
 	}
+	status, reason := scanner.verifySignature(currentDir, pluginCommon.Id)
+	if err := checkSignature(pluginCommon.Id, status, reason); err != nil {
+		return err
+	}
+
 	loader = reflect.New(reflect.TypeOf(pluginGoType)).Interface().(PluginLoader)
 
 	reader.Seek(0, 0)
-	return loader.Load(jsonParser, currentDir)
+	if err := loader.Load(jsonParser, currentDir); err != nil {
+		return err
+	}
+
+	Plugins[pluginCommon.Id].Signature = status
+	return nil
 }
 
 func GetPluginMarkdown(pluginId string, name string) ([]byte, error) {
@@ -0,0 +1,224 @@
+// Package signature verifies the manifests of plugins. A manifest lists every
+// file of a plugin with its SHA-256 hash and is signed with an Ed25519 key,
+// so that a plugin is only trusted when it comes from the owner of a trusted
+// key and none of its files were changed, added or removed since.
+package signature
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// ManifestFileName is the name of the manifest, next to the plugin.json of
+// the plugin.
+const ManifestFileName = "MANIFEST.json"
+
+// Status is the result of verifying the signature of a plugin.
+type Status string
+
+const (
+	// StatusInternal is the status of the plugins shipped with Grafana,
+	// which are not verified.
+	StatusInternal Status = "internal"
+	// StatusValid means the manifest is signed with a trusted key and all
+	// files match it.
+	StatusValid Status = "valid"
+	// StatusUnsigned means the plugin has no manifest.
+	StatusUnsigned Status = "unsigned"
+	// StatusInvalid means the manifest is not signed with a trusted key,
+	// or is for another plugin.
+	StatusInvalid Status = "invalid"
+	// StatusModified means files were changed, added or removed since the
+	// manifest was signed.
+	StatusModified Status = "modified"
+)
+
+// Manifest lists the files of a plugin, by their path relative to the
+// directory of the manifest with forward slashes, with the hex encoded
+// SHA-256 hash of their content. The signature is the base64 encoded Ed25519
+// signature of the JSON encoding of the manifest without the signature.
+type Manifest struct {
+	Plugin    string            `json:"plugin"`
+	Version   string            `json:"version"`
+	KeyId     string            `json:"keyId"`
+	Files     map[string]string `json:"files"`
+	Signature string            `json:"signature,omitempty"`
+}
+
+func (m *Manifest) payload() ([]byte, error) {
+	unsigned := *m
+	unsigned.Signature = ""
+	return json.Marshal(&unsigned)
+}
+
+// ParseKeys parses trusted public keys in the form <key id>:<base64 key>.
+func ParseKeys(keys []string) (map[string]ed25519.PublicKey, error) {
+	result := make(map[string]ed25519.PublicKey)
+
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		parts := strings.SplitN(key, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("key %q is not in the form <key id>:<base64 public key>", key)
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil || len(decoded) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("key %q is not a base64 encoded Ed25519 public key", parts[0])
+		}
+
+		result[parts[0]] = ed25519.PublicKey(decoded)
+	}
+
+	return result, nil
+}
+
+// Verify verifies the manifest in dir, which must be signed for the plugin
+// with the id by one of the keys. The error tells why the status is not
+// StatusValid.
+func Verify(dir, pluginId string, keys map[string]ed25519.PublicKey) (Status, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, ManifestFileName))
+	if os.IsNotExist(err) {
+		return StatusUnsigned, errors.New("plugin has no manifest")
+	}
+	if err != nil {
+		return StatusInvalid, err
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return StatusInvalid, fmt.Errorf("failed to parse manifest: %v", err)
+	}
+
+	if manifest.Plugin != pluginId {
+		return StatusInvalid, fmt.Errorf("manifest is for plugin %q", manifest.Plugin)
+	}
+
+	key, exists := keys[manifest.KeyId]
+	if !exists {
+		return StatusInvalid, fmt.Errorf("manifest is signed with unknown key %q", manifest.KeyId)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(manifest.Signature)
+	if err != nil {
+		return StatusInvalid, errors.New("manifest signature is not base64 encoded")
+	}
+
+	payload, err := manifest.payload()
+	if err != nil {
+		return StatusInvalid, err
+	}
+
+	if !ed25519.Verify(key, payload, sig) {
+		return StatusInvalid, errors.New("manifest signature is invalid")
+	}
+
+	files, err := hashFiles(dir)
+	if err != nil {
+		return StatusModified, err
+	}
+
+	for file, hash := range files {
+		expected, exists := manifest.Files[file]
+		if !exists {
+			return StatusModified, fmt.Errorf("file %q is not in the manifest", file)
+		}
+		if hash != expected {
+			return StatusModified, fmt.Errorf("file %q was modified", file)
+		}
+	}
+
+	for file := range manifest.Files {
+		if _, exists := files[file]; !exists {
+			return StatusModified, fmt.Errorf("file %q is missing", file)
+		}
+	}
+
+	return StatusValid, nil
+}
+
+// Sign lists the files in dir in the manifest, signs it with the key and
+// writes it to dir.
+func Sign(dir string, manifest *Manifest, key ed25519.PrivateKey) error {
+	files, err := hashFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	manifest.Files = files
+	payload, err := manifest.payload()
+	if err != nil {
+		return err
+	}
+	manifest.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, ManifestFileName), data, 0644)
+}
+
+// hashFiles returns the hashes of the files in dir, except the manifest.
+func hashFiles(dir string) (map[string]string, error) {
+	result := make(map[string]string)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ManifestFileName {
+			return nil
+		}
+
+		hash, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+
+		result[rel] = hash
+		return nil
+	})
+
+	return result, err
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,100 @@
+package signature
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestPluginSignature(t *testing.T) {
+	Convey("Given a plugin signed with a trusted key", t, func() {
+		dir, err := ioutil.TempDir("", "plugin-signature")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		writeFile(dir, "plugin.json", `{"id":"test-app","type":"app"}`)
+		writeFile(dir, "module.js", "define([], function() {})")
+		writeFile(dir, "img/logo.svg", "<svg></svg>")
+
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		So(err, ShouldBeNil)
+
+		keys, err := ParseKeys([]string{"internal:" + base64.StdEncoding.EncodeToString(public)})
+		So(err, ShouldBeNil)
+
+		err = Sign(dir, &Manifest{Plugin: "test-app", Version: "1.0.0", KeyId: "internal"}, private)
+		So(err, ShouldBeNil)
+
+		Convey("Should be valid", func() {
+			status, err := Verify(dir, "test-app", keys)
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, StatusValid)
+		})
+
+		Convey("Should be invalid for another plugin", func() {
+			status, _ := Verify(dir, "other-app", keys)
+			So(status, ShouldEqual, StatusInvalid)
+		})
+
+		Convey("Should be invalid when the key is not trusted", func() {
+			other, _, _ := ed25519.GenerateKey(rand.Reader)
+			keys["internal"] = other
+
+			status, _ := Verify(dir, "test-app", keys)
+			So(status, ShouldEqual, StatusInvalid)
+		})
+
+		Convey("Should be modified when a file changed", func() {
+			writeFile(dir, "module.js", "define([], function() { steal() })")
+
+			status, err := Verify(dir, "test-app", keys)
+			So(status, ShouldEqual, StatusModified)
+			So(err.Error(), ShouldContainSubstring, "module.js")
+		})
+
+		Convey("Should be modified when a file was added", func() {
+			writeFile(dir, "plugin_linux_amd64", "#!/bin/sh")
+
+			status, _ := Verify(dir, "test-app", keys)
+			So(status, ShouldEqual, StatusModified)
+		})
+
+		Convey("Should be modified when a file was removed", func() {
+			So(os.Remove(filepath.Join(dir, "img", "logo.svg")), ShouldBeNil)
+
+			status, _ := Verify(dir, "test-app", keys)
+			So(status, ShouldEqual, StatusModified)
+		})
+
+		Convey("Should be unsigned without manifest", func() {
+			So(os.Remove(filepath.Join(dir, ManifestFileName)), ShouldBeNil)
+
+			status, _ := Verify(dir, "test-app", keys)
+			So(status, ShouldEqual, StatusUnsigned)
+		})
+	})
+
+	Convey("When parsing keys", t, func() {
+		_, err := ParseKeys([]string{"internal"})
+		So(err, ShouldNotBeNil)
+
+		_, err = ParseKeys([]string{"internal:bm90IGEga2V5"})
+		So(err, ShouldNotBeNil)
+
+		keys, err := ParseKeys([]string{""})
+		So(err, ShouldBeNil)
+		So(keys, ShouldBeEmpty)
+	})
+}
+
+func writeFile(dir, name, content string) {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	So(os.MkdirAll(filepath.Dir(path), 0755), ShouldBeNil)
+	So(ioutil.WriteFile(path, []byte(content), 0644), ShouldBeNil)
+}
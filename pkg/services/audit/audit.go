@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/registry"
+	"github.com/Seasheller/grafana/pkg/setting"
+)
+
+func init() {
+	registry.RegisterService(&AuditService{})
+}
+
+// AuditService records who changed what through the HTTP API. Entries are
+// stored in the database and optionally appended to a JSON lines file.
+type AuditService struct {
+	Bus bus.Bus      `inject:""`
+	Cfg *setting.Cfg `inject:""`
+
+	log     log.Logger
+	fileMu  sync.Mutex
+	logFile *os.File
+}
+
+func (s *AuditService) Init() error {
+	s.log = log.New("audit")
+
+	if !s.IsEnabled() || s.Cfg.Audit.LogPath == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Cfg.Audit.LogPath), 0750); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.Cfg.Audit.LogPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return err
+	}
+
+	s.logFile = file
+	return nil
+}
+
+func (s *AuditService) IsDisabled() bool {
+	return !s.IsEnabled()
+}
+
+func (s *AuditService) Run(ctx context.Context) error {
+	<-ctx.Done()
+
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	if s.logFile != nil {
+		s.logFile.Close()
+		s.logFile = nil
+	}
+
+	return ctx.Err()
+}
+
+// IsEnabled returns true if audit logging is turned on in the configuration.
+func (s *AuditService) IsEnabled() bool {
+	return s.Cfg != nil && s.Cfg.Audit.Enabled
+}
+
+// Record stores an audit entry and writes it to the audit log file if one is configured.
+func (s *AuditService) Record(cmd *models.CreateAuditEntryCommand) error {
+	if err := s.Bus.Dispatch(cmd); err != nil {
+		return err
+	}
+
+	s.writeToFile(cmd.Result)
+	return nil
+}
+
+func (s *AuditService) writeToFile(entry *models.AuditEntry) {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	if s.logFile == nil || entry == nil {
+		return
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		s.log.Error("Failed to marshal audit entry", "error", err)
+		return
+	}
+
+	if _, err := s.logFile.Write(append(line, '\n')); err != nil {
+		s.log.Error("Failed to write audit entry to file", "file", s.Cfg.Audit.LogPath, "error", err)
+	}
+}
+
+// notAuditedPaths are the API paths of requests that only read or proxy data,
+// although they are not GET requests. They are sent on every refresh of
+// panels, so auditing them would flood the audit entries.
+var notAuditedPaths = []string{
+	"/api/tsdb/query",
+	"/api/datasources/proxy",
+	"/api/plugin-proxy",
+	"/api/gnet",
+	"/api/frontend-metrics",
+	"/api/search",
+	"/api/dashboards/calculate-diff",
+	"/api/dashboards/merge",
+	"/api/alerts/test",
+	"/api/alert-notifications/test",
+	"/api/streams/push",
+}
+
+var pluginResourcesPath = regexp.MustCompile(`^/api/plugins/[^/]+/resources(/|$)`)
+
+// ShouldAudit returns true for requests that can change state through the HTTP API.
+func ShouldAudit(method string, path string) bool {
+	if !strings.HasPrefix(path, "/api/") || ActionFromMethod(method) == "" {
+		return false
+	}
+
+	for _, notAudited := range notAuditedPaths {
+		if path == notAudited || strings.HasPrefix(path, notAudited+"/") {
+			return false
+		}
+	}
+
+	return !pluginResourcesPath.MatchString(path)
+}
+
+// ActionFromMethod maps a HTTP method to the audit action it represents.
+func ActionFromMethod(method string) string {
+	switch strings.ToUpper(method) {
+	case "POST":
+		return "create"
+	case "PUT", "PATCH":
+		return "update"
+	case "DELETE":
+		return "delete"
+	default:
+		return ""
+	}
+}
+
+// ResourceFromPath returns the kind of resource an API path refers to,
+// e.g. `datasources` for `/api/datasources/1` and `admin.users` for
+// `/api/admin/users/1/password`.
+func ResourceFromPath(path string) string {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/"), "/"), "/")
+	if len(parts) == 0 || parts[0] == "" {
+		return ""
+	}
+
+	resource := parts[0]
+	if resource == "admin" && len(parts) > 1 && !isNumeric(parts[1]) {
+		resource += "." + parts[1]
+	}
+
+	return resource
+}
+
+func isNumeric(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
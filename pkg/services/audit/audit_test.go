@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldAudit(t *testing.T) {
+	assert.True(t, ShouldAudit("POST", "/api/datasources"))
+	assert.True(t, ShouldAudit("DELETE", "/api/dashboards/uid/abc"))
+	assert.True(t, ShouldAudit("PATCH", "/api/org/users/2"))
+	assert.False(t, ShouldAudit("GET", "/api/datasources"))
+	assert.False(t, ShouldAudit("POST", "/login"))
+	assert.False(t, ShouldAudit("POST", "/api/tsdb/query"))
+	assert.False(t, ShouldAudit("POST", "/api/datasources/proxy/1/api/v1/query_range"))
+	assert.False(t, ShouldAudit("PUT", "/api/plugin-proxy/my-app/items/1"))
+	assert.False(t, ShouldAudit("POST", "/api/plugins/my-app/resources/query"))
+	assert.False(t, ShouldAudit("POST", "/api/dashboards/calculate-diff"))
+	assert.False(t, ShouldAudit("POST", "/api/dashboards/merge"))
+	assert.True(t, ShouldAudit("POST", "/api/plugins/my-app/settings"))
+	assert.True(t, ShouldAudit("POST", "/api/datasources/1/permissions"))
+}
+
+func TestResourceFromPath(t *testing.T) {
+	tests := map[string]string{
+		"/api/datasources/1":                        "datasources",
+		"/api/dashboards/id/2/permissions":          "dashboards",
+		"/api/admin/users/1/password":               "admin.users",
+		"/api/admin/provisioning/dashboards/reload": "admin.provisioning",
+		"/api/org": "org",
+		"/api/":    "",
+	}
+
+	for path, expected := range tests {
+		assert.Equal(t, expected, ResourceFromPath(path), path)
+	}
+}
@@ -0,0 +1,206 @@
+// Package bundles exports a dashboard together with what it depends on into
+// a portable archive, and imports such archives into another organization or
+// Grafana server, mapping the dependencies to existing ones or creating them.
+package bundles
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	"github.com/Seasheller/grafana/pkg/models"
+)
+
+const (
+	// bundleVersion is the version of the archive format.
+	bundleVersion = 1
+
+	manifestFile  = "bundle.json"
+	dashboardFile = "dashboard.json"
+
+	// maxFileSize limits the size of the files read from an archive.
+	maxFileSize = 50 << 20
+)
+
+// Typed errors
+var (
+	ErrBundleInvalid                = errors.New("Invalid dashboard bundle")
+	ErrBundleVersionUnsupported     = errors.New("Dashboard bundle version is not supported")
+	ErrBundleFolderExport           = errors.New("Folders cannot be exported, only dashboards")
+	ErrBundleAccessDenied           = errors.New("Access denied to dashboard")
+	ErrBundleCreateDatasourceDenied = errors.New("Only organization admins can create data sources")
+)
+
+// Manifest describes the dashboard of a bundle and everything it depends on.
+type Manifest struct {
+	Version        int             `json:"version"`
+	GrafanaVersion string          `json:"grafanaVersion"`
+	Exported       time.Time       `json:"exported"`
+	Dashboard      DashboardRef    `json:"dashboard"`
+	Folders        []*Folder       `json:"folders"`
+	Datasources    []*Datasource   `json:"datasources"`
+	Notifiers      []*Notifier     `json:"notifiers"`
+	LibraryPanels  []*LibraryPanel `json:"libraryPanels"`
+	Plugins        []*Plugin       `json:"plugins"`
+}
+
+type DashboardRef struct {
+	Uid   string `json:"uid"`
+	Title string `json:"title"`
+}
+
+// Folder is one of the folders on the path from the root to the dashboard.
+type Folder struct {
+	Uid   string `json:"uid"`
+	Title string `json:"title"`
+}
+
+// Datasource is the definition of a data source without its passwords and
+// secure json data. Only the name and type are exported for users who are
+// not organization admins.
+type Datasource struct {
+	Name            string           `json:"name"`
+	Type            string           `json:"type"`
+	Access          models.DsAccess  `json:"access,omitempty"`
+	Url             string           `json:"url,omitempty"`
+	Database        string           `json:"database,omitempty"`
+	User            string           `json:"user,omitempty"`
+	BasicAuth       bool             `json:"basicAuth,omitempty"`
+	BasicAuthUser   string           `json:"basicAuthUser,omitempty"`
+	WithCredentials bool             `json:"withCredentials,omitempty"`
+	JsonData        *simplejson.Json `json:"jsonData,omitempty"`
+}
+
+// Notifier is a stub of an alert notification channel used by the alerts
+// of the dashboard. Its settings are not exported as they hold secrets.
+type Notifier struct {
+	Uid  string `json:"uid"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type LibraryPanel struct {
+	Uid   string           `json:"uid"`
+	Name  string           `json:"name"`
+	Model *simplejson.Json `json:"model"`
+}
+
+// Plugin is a panel or data source plugin the dashboard needs, with the
+// version installed where it was exported.
+type Plugin struct {
+	Type    string `json:"type"`
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Bundle is a dashboard with its manifest.
+type Bundle struct {
+	Manifest  *Manifest
+	Dashboard *simplejson.Json
+}
+
+// Write writes the bundle as a zip archive.
+func (b *Bundle) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{manifestFile, b.Manifest},
+		{dashboardFile, b.Dashboard},
+	}
+
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.content, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// Read reads a bundle from a zip archive.
+func Read(data []byte) (*Bundle, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrBundleInvalid
+	}
+
+	b := &Bundle{}
+	for _, file := range zr.File {
+		switch file.Name {
+		case manifestFile:
+			b.Manifest = &Manifest{}
+			err = readJSON(file, b.Manifest)
+		case dashboardFile:
+			b.Dashboard = simplejson.New()
+			err = readJSON(file, b.Dashboard)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if b.Manifest == nil || b.Dashboard == nil {
+		return nil, ErrBundleInvalid
+	}
+	if b.Manifest.Version != bundleVersion {
+		return nil, ErrBundleVersionUnsupported
+	}
+
+	return b, nil
+}
+
+func readJSON(file *zip.File, v interface{}) error {
+	rc, err := file.Open()
+	if err != nil {
+		return ErrBundleInvalid
+	}
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(rc, maxFileSize))
+	if err != nil {
+		return ErrBundleInvalid
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrBundleInvalid
+	}
+	return nil
+}
+
+// MissingDependenciesError is returned when importing a bundle whose data
+// sources or notifiers neither exist nor are mapped or created.
+type MissingDependenciesError struct {
+	Datasources []string
+	Notifiers   []string
+}
+
+func (e *MissingDependenciesError) Error() string {
+	missing := make([]string, 0, 2)
+	if len(e.Datasources) > 0 {
+		missing = append(missing, "data sources "+strings.Join(e.Datasources, ", "))
+	}
+	if len(e.Notifiers) > 0 {
+		missing = append(missing, "notifiers "+strings.Join(e.Notifiers, ", "))
+	}
+	return fmt.Sprintf("Dashboard bundle has missing dependencies: %s", strings.Join(missing, "; "))
+}
@@ -0,0 +1,287 @@
+package bundles
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/plugins"
+	"github.com/Seasheller/grafana/pkg/services/dashboards"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testDashboardJSON = `{
+	"id": 5,
+	"uid": "hosts",
+	"title": "Hosts",
+	"version": 3,
+	"panels": [
+		{
+			"id": 1,
+			"type": "graph",
+			"datasource": "prom",
+			"targets": [{"refId": "A", "expr": "up"}],
+			"alert": {"name": "Down", "notifications": [{"id": 7}]}
+		},
+		{
+			"id": 2,
+			"type": "row",
+			"panels": [
+				{
+					"id": 3,
+					"type": "table",
+					"datasource": "-- Mixed --",
+					"targets": [{"refId": "A", "datasource": "prom"}, {"refId": "B", "datasource": "$ds"}]
+				}
+			]
+		},
+		{"id": 4, "type": "text"},
+		{"id": 5, "type": "graph", "libraryPanel": {"uid": "lp1", "name": "Shared"}}
+	],
+	"templating": {
+		"list": [
+			{"name": "ds", "type": "datasource", "query": "loki"},
+			{"name": "host", "type": "query", "datasource": "prom", "query": "label_values(host)"}
+		]
+	}
+}`
+
+type fakeFolderService struct {
+	folders map[string]*models.Folder
+	created []*models.CreateFolderCommand
+}
+
+func (s *fakeFolderService) GetFolders(limit int64) ([]*models.Folder, error) { return nil, nil }
+func (s *fakeFolderService) GetFolderByID(id int64) (*models.Folder, error)   { return nil, nil }
+func (s *fakeFolderService) GetFolderByUID(uid string) (*models.Folder, error) {
+	if folder, ok := s.folders[uid]; ok {
+		return folder, nil
+	}
+	return nil, models.ErrFolderNotFound
+}
+func (s *fakeFolderService) CreateFolder(cmd *models.CreateFolderCommand) error {
+	s.created = append(s.created, cmd)
+	cmd.Result = &models.Folder{Id: int64(100 + len(s.created)), Uid: cmd.Uid, Title: cmd.Title}
+	s.folders[cmd.Uid] = cmd.Result
+	return nil
+}
+func (s *fakeFolderService) UpdateFolder(uid string, cmd *models.UpdateFolderCommand) error {
+	return nil
+}
+func (s *fakeFolderService) MoveFolder(uid string, cmd *models.MoveFolderCommand) error { return nil }
+func (s *fakeFolderService) DeleteFolder(uid string) (*models.Folder, error)            { return nil, nil }
+
+func TestDashboardBundles(t *testing.T) {
+	Convey("Given a dashboard in a folder", t, func() {
+		defer bus.ClearBusHandlers()
+
+		origNewGuardian := guardian.New
+		origNewFolderService := dashboards.NewFolderService
+		defer func() {
+			guardian.New = origNewGuardian
+			dashboards.NewFolderService = origNewFolderService
+		}()
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true, CanSaveValue: true})
+
+		data, err := simplejson.NewJson([]byte(testDashboardJSON))
+		So(err, ShouldBeNil)
+
+		bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+			query.Result = &models.Dashboard{Id: 5, OrgId: 1, Uid: "hosts", Title: "Hosts", FolderId: 3, Data: data}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetFolderPathsQuery) error {
+			query.Result = map[int64][]*models.FolderRef{3: {{Id: 2, Uid: "infra", Title: "Infra"}, {Id: 3, Uid: "hosts-folder", Title: "Hosts"}}}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetDataSourcesQuery) error {
+			query.Result = []*models.DataSource{
+				{Id: 1, Name: "prom", Type: "prometheus", Url: "http://prom:9090", Password: "secret", Access: models.DS_ACCESS_PROXY},
+				{Id: 2, Name: "logs", Type: "loki", IsDefault: true},
+			}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetAlertNotificationsQuery) error {
+			if query.Id == 7 {
+				query.Result = &models.AlertNotification{Id: 7, Uid: "pager", Name: "Pager", Type: "pagerduty"}
+			}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetLibraryPanelQuery) error {
+			query.Result = &models.LibraryPanel{Uid: "lp1", Name: "Shared", Model: simplejson.NewFromAny(map[string]interface{}{"type": "graph"})}
+			return nil
+		})
+
+		admin := &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+
+		Convey("When exporting it", func() {
+			cmd := ExportDashboardCommand{OrgId: 1, Uid: "hosts", User: admin}
+			So(ExportDashboard(&cmd), ShouldBeNil)
+			manifest := cmd.Result.Manifest
+
+			Convey("Should bundle the folders, data sources and notifiers it uses", func() {
+				So(len(manifest.Folders), ShouldEqual, 2)
+				So(manifest.Folders[0].Uid, ShouldEqual, "infra")
+
+				So(len(manifest.Datasources), ShouldEqual, 1)
+				So(manifest.Datasources[0].Name, ShouldEqual, "prom")
+				So(manifest.Datasources[0].Url, ShouldEqual, "http://prom:9090")
+
+				So(len(manifest.Notifiers), ShouldEqual, 1)
+				So(manifest.Notifiers[0].Uid, ShouldEqual, "pager")
+
+				So(len(manifest.LibraryPanels), ShouldEqual, 1)
+			})
+
+			Convey("Should list the plugins it needs", func() {
+				ids := make([]string, 0)
+				for _, p := range manifest.Plugins {
+					ids = append(ids, p.Type+":"+p.Id)
+				}
+				So(ids, ShouldResemble, []string{"datasource:loki", "datasource:prometheus", "panel:graph", "panel:table", "panel:text"})
+			})
+
+			Convey("Should refer to notifiers by uid and drop the id of the dashboard", func() {
+				dash := cmd.Result.Dashboard
+				So(dash.Get("panels").GetIndex(0).Get("alert").Get("notifications").GetIndex(0).Get("uid").MustString(), ShouldEqual, "pager")
+				_, hasId := dash.CheckGet("id")
+				So(hasId, ShouldBeFalse)
+				So(data.Get("id").MustInt64(), ShouldEqual, 5)
+			})
+
+			Convey("Should read back what it writes", func() {
+				var buf bytes.Buffer
+				So(cmd.Result.Write(&buf), ShouldBeNil)
+
+				bundle, err := Read(buf.Bytes())
+				So(err, ShouldBeNil)
+				So(bundle.Manifest.Dashboard.Uid, ShouldEqual, "hosts")
+				So(bundle.Dashboard.Get("title").MustString(), ShouldEqual, "Hosts")
+			})
+		})
+
+		Convey("When exporting it as an editor", func() {
+			cmd := ExportDashboardCommand{OrgId: 1, Uid: "hosts", User: &models.SignedInUser{UserId: 2, OrgId: 1, OrgRole: models.ROLE_EDITOR}}
+			So(ExportDashboard(&cmd), ShouldBeNil)
+
+			Convey("Should only export the names and types of data sources", func() {
+				So(cmd.Result.Manifest.Datasources[0].Url, ShouldEqual, "")
+				So(cmd.Result.Manifest.Datasources[0].Type, ShouldEqual, "prometheus")
+			})
+		})
+
+		Convey("When importing its bundle into another organization", func() {
+			exportCmd := ExportDashboardCommand{OrgId: 1, Uid: "hosts", User: admin}
+			So(ExportDashboard(&exportCmd), ShouldBeNil)
+			bundle := exportCmd.Result
+
+			bus.ClearBusHandlers()
+
+			folders := &fakeFolderService{folders: map[string]*models.Folder{"infra": {Id: 2, Uid: "infra", Title: "Infra"}}}
+			dashboards.NewFolderService = func(orgId int64, user *models.SignedInUser) dashboards.FolderService {
+				return folders
+			}
+
+			bus.AddHandler("test", func(query *models.GetDataSourceByNameQuery) error {
+				if query.Name == "Prometheus" {
+					query.Result = &models.DataSource{Id: 9, Name: "Prometheus", Type: "prometheus"}
+					return nil
+				}
+				return models.ErrDataSourceNotFound
+			})
+			bus.AddHandler("test", func(query *models.GetAlertNotificationsWithUidQuery) error { return nil })
+			bus.AddHandler("test", func(query *models.GetAlertNotificationsQuery) error { return nil })
+			bus.AddHandler("test", func(query *models.GetLibraryPanelQuery) error {
+				return models.ErrLibraryPanelNotFound
+			})
+
+			var createdNotifier *models.CreateAlertNotificationCommand
+			bus.AddHandler("test", func(cmd *models.CreateAlertNotificationCommand) error {
+				createdNotifier = cmd
+				cmd.Result = &models.AlertNotification{Uid: cmd.Uid, Name: cmd.Name}
+				return nil
+			})
+			var createdLibraryPanel *models.CreateLibraryPanelCommand
+			bus.AddHandler("test", func(cmd *models.CreateLibraryPanelCommand) error {
+				createdLibraryPanel = cmd
+				return nil
+			})
+			var imported *plugins.ImportDashboardCommand
+			bus.AddHandler("test", func(cmd *plugins.ImportDashboardCommand) error {
+				imported = cmd
+				cmd.Result = &plugins.PluginDashboardInfoDTO{Title: "Hosts", ImportedUrl: "/d/hosts/hosts"}
+				return nil
+			})
+
+			Convey("Should fail without changes when dependencies are missing", func() {
+				cmd := ImportBundleCommand{Bundle: bundle, OrgId: 2, User: admin}
+				err := ImportBundle(&cmd)
+
+				missing, ok := err.(*MissingDependenciesError)
+				So(ok, ShouldBeTrue)
+				So(missing.Datasources, ShouldResemble, []string{"prom"})
+				So(missing.Notifiers, ShouldResemble, []string{"Pager"})
+				So(len(folders.created), ShouldEqual, 0)
+				So(imported, ShouldBeNil)
+			})
+
+			Convey("Should map and create the dependencies", func() {
+				cmd := ImportBundleCommand{
+					Bundle: bundle,
+					OrgId:  2,
+					User:   admin,
+					Options: ImportOptions{
+						Datasources:   map[string]string{"prom": "Prometheus"},
+						CreateMissing: true,
+					},
+				}
+				So(ImportBundle(&cmd), ShouldBeNil)
+
+				So(len(folders.created), ShouldEqual, 1)
+				So(folders.created[0].Uid, ShouldEqual, "hosts-folder")
+				So(folders.created[0].ParentUid, ShouldEqual, "infra")
+				So(imported.FolderId, ShouldEqual, 101)
+
+				So(createdNotifier.Uid, ShouldEqual, "pager")
+				So(createdLibraryPanel.FolderId, ShouldEqual, 101)
+
+				dash := imported.Dashboard
+				So(dash.Get("panels").GetIndex(0).Get("datasource").MustString(), ShouldEqual, "Prometheus")
+				So(dash.Get("panels").GetIndex(1).Get("panels").GetIndex(0).Get("targets").GetIndex(0).Get("datasource").MustString(), ShouldEqual, "Prometheus")
+				So(dash.Get("panels").GetIndex(1).Get("panels").GetIndex(0).Get("targets").GetIndex(1).Get("datasource").MustString(), ShouldEqual, "$ds")
+				So(dash.Get("templating").Get("list").GetIndex(1).Get("datasource").MustString(), ShouldEqual, "Prometheus")
+
+				So(cmd.Result.CreatedFolders, ShouldResemble, []string{"Hosts"})
+				So(cmd.Result.CreatedNotifiers, ShouldResemble, []string{"Pager"})
+			})
+
+			Convey("Should not let editors create data sources", func() {
+				cmd := ImportBundleCommand{
+					Bundle:  bundle,
+					OrgId:   2,
+					User:    &models.SignedInUser{UserId: 2, OrgId: 2, OrgRole: models.ROLE_EDITOR},
+					Options: ImportOptions{CreateMissing: true},
+				}
+				So(ImportBundle(&cmd), ShouldEqual, ErrBundleCreateDatasourceDenied)
+			})
+
+			Convey("Should copy library panels into dashboards in the General folder", func() {
+				bundle.Manifest.Folders = []*Folder{}
+				cmd := ImportBundleCommand{
+					Bundle:  bundle,
+					OrgId:   2,
+					User:    admin,
+					Options: ImportOptions{Datasources: map[string]string{"prom": "Prometheus"}, CreateMissing: true},
+				}
+				So(ImportBundle(&cmd), ShouldBeNil)
+
+				So(createdLibraryPanel, ShouldBeNil)
+				_, linked := imported.Dashboard.Get("panels").GetIndex(3).CheckGet("libraryPanel")
+				So(linked, ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -0,0 +1,214 @@
+package bundles
+
+import (
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/plugins"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+	"github.com/Seasheller/grafana/pkg/setting"
+)
+
+type ExportDashboardCommand struct {
+	OrgId int64
+	Uid   string
+	User  *models.SignedInUser
+
+	Result *Bundle
+}
+
+func init() {
+	bus.AddHandler("bundles", ExportDashboard)
+	bus.AddHandler("bundles", ImportBundle)
+}
+
+// ExportDashboard bundles the dashboard with its folders, the data sources,
+// notifiers and library panels it uses and the plugins it needs.
+func ExportDashboard(cmd *ExportDashboardCommand) error {
+	query := models.GetDashboardQuery{OrgId: cmd.OrgId, Uid: cmd.Uid}
+	if err := bus.Dispatch(&query); err != nil {
+		return err
+	}
+	dash := query.Result
+
+	if dash.IsFolder {
+		return ErrBundleFolderExport
+	}
+
+	canView, err := guardian.New(dash.Id, cmd.OrgId, cmd.User).CanView()
+	if err != nil {
+		return err
+	}
+	if !canView {
+		return ErrBundleAccessDenied
+	}
+
+	data, err := copyJson(dash.Data)
+	if err != nil {
+		return err
+	}
+	data.Del("id")
+	data.Del("version")
+	data.Set("uid", dash.Uid)
+
+	manifest := &Manifest{
+		Version:        bundleVersion,
+		GrafanaVersion: setting.BuildVersion,
+		Exported:       time.Now().UTC(),
+		Dashboard:      DashboardRef{Uid: dash.Uid, Title: dash.Title},
+		Folders:        make([]*Folder, 0),
+		Datasources:    make([]*Datasource, 0),
+		Notifiers:      make([]*Notifier, 0),
+		LibraryPanels:  make([]*LibraryPanel, 0),
+		Plugins:        make([]*Plugin, 0),
+	}
+
+	if dash.FolderId > 0 {
+		pathQuery := models.GetFolderPathsQuery{OrgId: cmd.OrgId, FolderIds: []int64{dash.FolderId}}
+		if err := bus.Dispatch(&pathQuery); err != nil {
+			return err
+		}
+		for _, f := range pathQuery.Result[dash.FolderId] {
+			manifest.Folders = append(manifest.Folders, &Folder{Uid: f.Uid, Title: f.Title})
+		}
+	}
+
+	refs := findReferences(data)
+
+	if err := exportDatasources(manifest, refs, cmd.OrgId, cmd.User); err != nil {
+		return err
+	}
+
+	if err := exportNotifiers(manifest, refs, cmd.OrgId); err != nil {
+		return err
+	}
+
+	uids, err := models.GetLibraryPanelUids(data)
+	if err != nil {
+		return err
+	}
+	for _, uid := range uids {
+		lpQuery := models.GetLibraryPanelQuery{OrgId: cmd.OrgId, Uid: uid}
+		if err := bus.Dispatch(&lpQuery); err != nil {
+			return err
+		}
+		lp := lpQuery.Result
+		manifest.LibraryPanels = append(manifest.LibraryPanels, &LibraryPanel{Uid: lp.Uid, Name: lp.Name, Model: lp.Model})
+	}
+
+	for _, panelType := range refs.panelTypes {
+		plugin := &Plugin{Type: "panel", Id: panelType}
+		if p, ok := plugins.Panels[panelType]; ok {
+			plugin.Name = p.Name
+			plugin.Version = p.Info.Version
+		}
+		manifest.Plugins = append(manifest.Plugins, plugin)
+	}
+
+	cmd.Result = &Bundle{Manifest: manifest, Dashboard: data}
+	return nil
+}
+
+// exportDatasources adds the data sources the dashboard uses by name, and
+// the plugins of all data sources it uses, to the manifest.
+func exportDatasources(manifest *Manifest, refs *references, orgId int64, user *models.SignedInUser) error {
+	query := models.GetDataSourcesQuery{OrgId: orgId, User: user}
+	if err := bus.Dispatch(&query); err != nil {
+		return err
+	}
+
+	byName := make(map[string]*models.DataSource)
+	types := append([]string{}, refs.datasourceTypes...)
+	for _, ds := range query.Result {
+		byName[ds.Name] = ds
+		if ds.IsDefault && refs.usesDefault {
+			types = append(types, ds.Type)
+		}
+	}
+
+	for _, name := range refs.datasources {
+		ds, ok := byName[name]
+		if !ok {
+			continue
+		}
+		types = append(types, ds.Type)
+
+		exported := &Datasource{Name: ds.Name, Type: ds.Type}
+		if user.OrgRole == models.ROLE_ADMIN {
+			exported.Access = ds.Access
+			exported.Url = ds.Url
+			exported.Database = ds.Database
+			exported.User = ds.User
+			exported.BasicAuth = ds.BasicAuth
+			exported.BasicAuthUser = ds.BasicAuthUser
+			exported.WithCredentials = ds.WithCredentials
+			exported.JsonData = ds.JsonData
+		}
+		manifest.Datasources = append(manifest.Datasources, exported)
+	}
+
+	seen := make(map[string]bool)
+	for _, dsType := range types {
+		if seen[dsType] {
+			continue
+		}
+		seen[dsType] = true
+
+		plugin := &Plugin{Type: "datasource", Id: dsType}
+		if p, ok := plugins.DataSources[dsType]; ok {
+			plugin.Name = p.Name
+			plugin.Version = p.Info.Version
+		}
+		manifest.Plugins = append(manifest.Plugins, plugin)
+	}
+
+	return nil
+}
+
+// exportNotifiers adds stubs of the notifiers of the alerts to the manifest
+// and makes the alerts refer to them by uid, as ids differ between servers.
+func exportNotifiers(manifest *Manifest, refs *references, orgId int64) error {
+	seen := make(map[string]bool)
+
+	for _, notification := range refs.notifications {
+		var notifier *models.AlertNotification
+
+		if uid := notification.Get("uid").MustString(); uid != "" {
+			query := models.GetAlertNotificationsWithUidQuery{OrgId: orgId, Uid: uid}
+			if err := bus.Dispatch(&query); err != nil {
+				return err
+			}
+			notifier = query.Result
+		} else {
+			query := models.GetAlertNotificationsQuery{OrgId: orgId, Id: notification.Get("id").MustInt64()}
+			if err := bus.Dispatch(&query); err != nil {
+				return err
+			}
+			notifier = query.Result
+		}
+
+		if notifier == nil {
+			continue
+		}
+
+		notification.Del("id")
+		notification.Set("uid", notifier.Uid)
+
+		if !seen[notifier.Uid] {
+			seen[notifier.Uid] = true
+			manifest.Notifiers = append(manifest.Notifiers, &Notifier{Uid: notifier.Uid, Name: notifier.Name, Type: notifier.Type})
+		}
+	}
+
+	return nil
+}
+
+func copyJson(data *simplejson.Json) (*simplejson.Json, error) {
+	encoded, err := data.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return simplejson.NewJson(encoded)
+}
@@ -0,0 +1,365 @@
+package bundles
+
+import (
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/plugins"
+	"github.com/Seasheller/grafana/pkg/services/dashboards"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+)
+
+// ImportOptions tells how to map the dependencies of a bundle.
+type ImportOptions struct {
+	// FolderUid is the folder to import the dashboard into. When empty, the
+	// folders of the bundle are used and created when missing.
+	FolderUid string `json:"folderUid"`
+	Overwrite bool   `json:"overwrite"`
+	// Datasources maps data source names of the bundle to existing data
+	// sources. Unmapped data sources are matched by name.
+	Datasources map[string]string `json:"datasources"`
+	// Notifiers maps notifier uids of the bundle to existing notifiers.
+	// Unmapped notifiers are matched by uid, then by name.
+	Notifiers map[string]string `json:"notifiers"`
+	// CreateMissing creates the data sources and notifiers that cannot be
+	// matched, from their definitions in the bundle.
+	CreateMissing bool `json:"createMissing"`
+}
+
+type ImportBundleCommand struct {
+	Bundle  *Bundle
+	Options ImportOptions
+	OrgId   int64
+	User    *models.SignedInUser
+
+	Result *ImportResult
+}
+
+type ImportResult struct {
+	Uid                  string    `json:"uid"`
+	Title                string    `json:"title"`
+	Url                  string    `json:"url"`
+	FolderId             int64     `json:"folderId"`
+	CreatedFolders       []string  `json:"createdFolders"`
+	CreatedDatasources   []string  `json:"createdDatasources"`
+	CreatedNotifiers     []string  `json:"createdNotifiers"`
+	CreatedLibraryPanels []string  `json:"createdLibraryPanels"`
+	MissingPlugins       []*Plugin `json:"missingPlugins"`
+}
+
+type importer struct {
+	cmd     *ImportBundleCommand
+	result  *ImportResult
+	folders dashboards.FolderService
+
+	datasourceNames  map[string]string
+	datasourcesToAdd []*Datasource
+	notifierUids     map[string]string
+	notifiersToAdd   []*Notifier
+}
+
+// ImportBundle imports the dashboard of the bundle. All data sources and
+// notifiers are resolved before anything is created, so an import with
+// missing dependencies changes nothing.
+func ImportBundle(cmd *ImportBundleCommand) error {
+	imp := &importer{
+		cmd: cmd,
+		result: &ImportResult{
+			CreatedFolders:       make([]string, 0),
+			CreatedDatasources:   make([]string, 0),
+			CreatedNotifiers:     make([]string, 0),
+			CreatedLibraryPanels: make([]string, 0),
+			MissingPlugins:       make([]*Plugin, 0),
+		},
+		folders:         dashboards.NewFolderService(cmd.OrgId, cmd.User),
+		datasourceNames: make(map[string]string),
+		notifierUids:    make(map[string]string),
+	}
+
+	missing := &MissingDependenciesError{}
+	if err := imp.resolveDatasources(missing); err != nil {
+		return err
+	}
+	if err := imp.resolveNotifiers(missing); err != nil {
+		return err
+	}
+	if len(missing.Datasources) > 0 || len(missing.Notifiers) > 0 {
+		return missing
+	}
+
+	if len(imp.datasourcesToAdd) > 0 && imp.cmd.User.OrgRole != models.ROLE_ADMIN {
+		return ErrBundleCreateDatasourceDenied
+	}
+
+	folderId, err := imp.importFolders()
+	if err != nil {
+		return err
+	}
+
+	if err := imp.createDependencies(); err != nil {
+		return err
+	}
+
+	data, err := copyJson(cmd.Bundle.Dashboard)
+	if err != nil {
+		return err
+	}
+	renameDatasources(data.Interface(), imp.datasourceNames)
+	for _, notification := range findReferences(data).notifications {
+		if uid, ok := imp.notifierUids[notification.Get("uid").MustString()]; ok {
+			notification.Set("uid", uid)
+		}
+	}
+
+	if err := imp.importLibraryPanels(data, folderId); err != nil {
+		return err
+	}
+
+	importCmd := plugins.ImportDashboardCommand{
+		Dashboard: data,
+		Overwrite: cmd.Options.Overwrite,
+		FolderId:  folderId,
+		OrgId:     cmd.OrgId,
+		User:      cmd.User,
+		Inputs:    []plugins.ImportDashboardInput{},
+	}
+	if err := bus.Dispatch(&importCmd); err != nil {
+		return err
+	}
+
+	for _, plugin := range cmd.Bundle.Manifest.Plugins {
+		if !isPluginInstalled(plugin) {
+			imp.result.MissingPlugins = append(imp.result.MissingPlugins, plugin)
+		}
+	}
+
+	imp.result.Uid = data.Get("uid").MustString()
+	imp.result.Title = importCmd.Result.Title
+	imp.result.Url = importCmd.Result.ImportedUrl
+	imp.result.FolderId = folderId
+	cmd.Result = imp.result
+	return nil
+}
+
+func (imp *importer) resolveDatasources(missing *MissingDependenciesError) error {
+	for _, ds := range imp.cmd.Bundle.Manifest.Datasources {
+		name, mapped := imp.cmd.Options.Datasources[ds.Name]
+		if !mapped {
+			name = ds.Name
+		}
+
+		query := models.GetDataSourceByNameQuery{OrgId: imp.cmd.OrgId, Name: name}
+		err := bus.Dispatch(&query)
+		if err == nil {
+			imp.datasourceNames[ds.Name] = query.Result.Name
+			continue
+		}
+		if err != models.ErrDataSourceNotFound {
+			return err
+		}
+
+		if imp.cmd.Options.CreateMissing && !mapped {
+			imp.datasourcesToAdd = append(imp.datasourcesToAdd, ds)
+			continue
+		}
+		missing.Datasources = append(missing.Datasources, ds.Name)
+	}
+
+	return nil
+}
+
+func (imp *importer) resolveNotifiers(missing *MissingDependenciesError) error {
+	for _, n := range imp.cmd.Bundle.Manifest.Notifiers {
+		uid, mapped := imp.cmd.Options.Notifiers[n.Uid]
+		if !mapped {
+			uid = n.Uid
+		}
+
+		query := models.GetAlertNotificationsWithUidQuery{OrgId: imp.cmd.OrgId, Uid: uid}
+		if err := bus.Dispatch(&query); err != nil {
+			return err
+		}
+		if query.Result != nil {
+			imp.notifierUids[n.Uid] = query.Result.Uid
+			continue
+		}
+		if mapped {
+			missing.Notifiers = append(missing.Notifiers, n.Name)
+			continue
+		}
+
+		byName := models.GetAlertNotificationsQuery{OrgId: imp.cmd.OrgId, Name: n.Name}
+		if err := bus.Dispatch(&byName); err != nil {
+			return err
+		}
+		if byName.Result != nil {
+			imp.notifierUids[n.Uid] = byName.Result.Uid
+			continue
+		}
+
+		if imp.cmd.Options.CreateMissing {
+			imp.notifiersToAdd = append(imp.notifiersToAdd, n)
+			continue
+		}
+		missing.Notifiers = append(missing.Notifiers, n.Name)
+	}
+
+	return nil
+}
+
+// importFolders returns the folder to import the dashboard into, creating
+// the folders of the bundle that don't exist.
+func (imp *importer) importFolders() (int64, error) {
+	if imp.cmd.Options.FolderUid != "" {
+		folder, err := imp.folders.GetFolderByUID(imp.cmd.Options.FolderUid)
+		if err != nil {
+			return 0, err
+		}
+		return folder.Id, nil
+	}
+
+	var folderId int64
+	parentUid := ""
+	for _, f := range imp.cmd.Bundle.Manifest.Folders {
+		folder, err := imp.folders.GetFolderByUID(f.Uid)
+		if err == models.ErrFolderNotFound {
+			createCmd := &models.CreateFolderCommand{Uid: f.Uid, Title: f.Title, ParentUid: parentUid}
+			if err := imp.folders.CreateFolder(createCmd); err != nil {
+				return 0, err
+			}
+			folder = createCmd.Result
+			imp.result.CreatedFolders = append(imp.result.CreatedFolders, folder.Title)
+		} else if err != nil {
+			return 0, err
+		}
+
+		folderId = folder.Id
+		parentUid = folder.Uid
+	}
+
+	return folderId, nil
+}
+
+func (imp *importer) createDependencies() error {
+	for _, ds := range imp.datasourcesToAdd {
+		access := ds.Access
+		if access == "" {
+			access = models.DS_ACCESS_PROXY
+		}
+
+		cmd := models.AddDataSourceCommand{
+			OrgId:           imp.cmd.OrgId,
+			Name:            ds.Name,
+			Type:            ds.Type,
+			Access:          access,
+			Url:             ds.Url,
+			Database:        ds.Database,
+			User:            ds.User,
+			BasicAuth:       ds.BasicAuth,
+			BasicAuthUser:   ds.BasicAuthUser,
+			WithCredentials: ds.WithCredentials,
+			JsonData:        ds.JsonData,
+		}
+		if cmd.JsonData == nil {
+			cmd.JsonData = simplejson.New()
+		}
+		if err := bus.Dispatch(&cmd); err != nil {
+			return err
+		}
+
+		imp.datasourceNames[ds.Name] = cmd.Result.Name
+		imp.result.CreatedDatasources = append(imp.result.CreatedDatasources, ds.Name)
+	}
+
+	for _, n := range imp.notifiersToAdd {
+		cmd := models.CreateAlertNotificationCommand{
+			OrgId:    imp.cmd.OrgId,
+			Uid:      n.Uid,
+			Name:     n.Name,
+			Type:     n.Type,
+			Settings: simplejson.New(),
+		}
+		if err := bus.Dispatch(&cmd); err != nil {
+			return err
+		}
+
+		imp.notifierUids[n.Uid] = cmd.Result.Uid
+		imp.result.CreatedNotifiers = append(imp.result.CreatedNotifiers, n.Name)
+	}
+
+	return nil
+}
+
+// importLibraryPanels creates the library panels of the bundle that don't
+// exist in the folder of the dashboard. Library panels can only be stored
+// in folders, so dashboards imported into the General folder get copies of
+// the panels instead.
+func (imp *importer) importLibraryPanels(data *simplejson.Json, folderId int64) error {
+	unlink := make(map[string]bool)
+
+	for _, lp := range imp.cmd.Bundle.Manifest.LibraryPanels {
+		query := models.GetLibraryPanelQuery{OrgId: imp.cmd.OrgId, Uid: lp.Uid}
+		err := bus.Dispatch(&query)
+		if err == nil {
+			continue
+		}
+		if err != models.ErrLibraryPanelNotFound {
+			return err
+		}
+
+		if folderId == 0 {
+			unlink[lp.Uid] = true
+			continue
+		}
+
+		canSave, err := guardian.New(folderId, imp.cmd.OrgId, imp.cmd.User).CanSave()
+		if err != nil {
+			return err
+		}
+		if !canSave {
+			return models.ErrLibraryPanelAccessDenied
+		}
+
+		createCmd := models.CreateLibraryPanelCommand{
+			OrgId:    imp.cmd.OrgId,
+			UserId:   imp.cmd.User.UserId,
+			FolderId: folderId,
+			Uid:      lp.Uid,
+			Name:     lp.Name,
+			Model:    lp.Model,
+		}
+		if err := bus.Dispatch(&createCmd); err != nil {
+			return err
+		}
+		imp.result.CreatedLibraryPanels = append(imp.result.CreatedLibraryPanels, lp.Name)
+	}
+
+	if len(unlink) > 0 {
+		unlinkLibraryPanels(data.Get("panels").MustArray(), unlink)
+	}
+	return nil
+}
+
+// unlinkLibraryPanels removes the references to the library panels, keeping
+// the panel models exported with the dashboard.
+func unlinkLibraryPanels(panels []interface{}, uids map[string]bool) {
+	for _, p := range panels {
+		panel := simplejson.NewFromAny(p)
+		if uids[panel.Get("libraryPanel").Get("uid").MustString()] {
+			panel.Del("libraryPanel")
+		}
+		unlinkLibraryPanels(panel.Get("panels").MustArray(), uids)
+	}
+}
+
+func isPluginInstalled(plugin *Plugin) bool {
+	switch plugin.Type {
+	case "panel":
+		_, ok := plugins.Panels[plugin.Id]
+		return ok
+	case "datasource":
+		_, ok := plugins.DataSources[plugin.Id]
+		return ok
+	}
+	return true
+}
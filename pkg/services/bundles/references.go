@@ -0,0 +1,123 @@
+package bundles
+
+import (
+	"strings"
+
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+)
+
+const (
+	grafanaDatasource   = "-- Grafana --"
+	mixedDatasource     = "-- Mixed --"
+	dashboardDatasource = "-- Dashboard --"
+)
+
+// references are what a dashboard refers to by name, uid or id.
+type references struct {
+	datasources     []string
+	usesDefault     bool
+	datasourceTypes []string
+	panelTypes      []string
+	notifications   []*simplejson.Json
+}
+
+// findReferences walks the panels, including panels in rows, template
+// variables and annotations of the dashboard.
+func findReferences(data *simplejson.Json) *references {
+	refs := &references{}
+	seen := make(map[string]bool)
+
+	addDatasource := func(value *simplejson.Json) {
+		name, isString := value.Interface().(string)
+		if value.Interface() == nil || (isString && name == "") {
+			refs.usesDefault = true
+			return
+		}
+		if !isString || strings.HasPrefix(name, "$") ||
+			name == grafanaDatasource || name == mixedDatasource || name == dashboardDatasource {
+			return
+		}
+		if !seen["ds:"+name] {
+			seen["ds:"+name] = true
+			refs.datasources = append(refs.datasources, name)
+		}
+	}
+
+	add := func(list *[]string, kind, value string) {
+		if value != "" && !seen[kind+value] {
+			seen[kind+value] = true
+			*list = append(*list, value)
+		}
+	}
+
+	var walk func(panels []interface{})
+	walk = func(panels []interface{}) {
+		for _, p := range panels {
+			panel := simplejson.NewFromAny(p)
+
+			if panelType := panel.Get("type").MustString(); panelType != "row" {
+				add(&refs.panelTypes, "panel:", panelType)
+			}
+
+			if len(panel.Get("targets").MustArray()) > 0 || panel.Get("datasource").Interface() != nil {
+				addDatasource(panel.Get("datasource"))
+			}
+			if panel.Get("datasource").MustString() == mixedDatasource {
+				for _, t := range panel.Get("targets").MustArray() {
+					addDatasource(simplejson.NewFromAny(t).Get("datasource"))
+				}
+			}
+
+			for _, n := range panel.Get("alert").Get("notifications").MustArray() {
+				refs.notifications = append(refs.notifications, simplejson.NewFromAny(n))
+			}
+
+			walk(panel.Get("panels").MustArray())
+		}
+	}
+
+	walk(data.Get("panels").MustArray())
+	for _, row := range data.Get("rows").MustArray() {
+		walk(simplejson.NewFromAny(row).Get("panels").MustArray())
+	}
+
+	for _, v := range data.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		switch variable.Get("type").MustString() {
+		case "datasource":
+			add(&refs.datasourceTypes, "type:", variable.Get("query").MustString())
+		case "query":
+			addDatasource(variable.Get("datasource"))
+		}
+	}
+
+	for _, a := range data.Get("annotations").Get("list").MustArray() {
+		annotation := simplejson.NewFromAny(a)
+		if _, ok := annotation.CheckGet("datasource"); ok {
+			addDatasource(annotation.Get("datasource"))
+		}
+	}
+
+	return refs
+}
+
+// renameDatasources replaces the data source names of the dashboard, in any
+// datasource property, with the names they are mapped to.
+func renameDatasources(value interface{}, names map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if name, ok := item.(string); ok && key == "datasource" {
+				if renamed, ok := names[name]; ok {
+					v[key] = renamed
+				}
+				continue
+			}
+			renameDatasources(item, names)
+		}
+	case []interface{}:
+		for _, item := range v {
+			renameDatasources(item, names)
+		}
+	}
+}
@@ -40,9 +40,18 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			srv.cleanUpTmpFiles()
 			srv.deleteExpiredSnapshots()
 			srv.deleteExpiredDashboardVersions()
+			srv.ServerLockService.LockAndExecute(ctx, "delete dashboard versions by retention", time.Minute*10, func() {
+				srv.deleteDashboardVersionsByRetention()
+			})
+			srv.ServerLockService.LockAndExecute(ctx, "delete expired dashboard trash", time.Minute*10, func() {
+				srv.deleteExpiredDashboardTrash()
+			})
 			srv.ServerLockService.LockAndExecute(ctx, "delete old login attempts", time.Minute*10, func() {
 				srv.deleteOldLoginAttempts()
 			})
+			srv.ServerLockService.LockAndExecute(ctx, "delete old audit entries", time.Minute*10, func() {
+				srv.deleteOldAuditEntries()
+			})
 
 		case <-ctx.Done():
 			return ctx.Err()
@@ -107,6 +116,17 @@ func (srv *CleanUpService) deleteExpiredDashboardVersions() {
 	}
 }
 
+func (srv *CleanUpService) deleteExpiredDashboardTrash() {
+	cmd := m.DeleteExpiredDashboardTrashCommand{
+		OlderThan: time.Now().AddDate(0, 0, -setting.DashboardTrashRetentionDays),
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		srv.log.Error("Problem deleting expired dashboard trash", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired dashboard trash", "rows affected", cmd.DeletedRows)
+	}
+}
+
 func (srv *CleanUpService) deleteOldLoginAttempts() {
 	if srv.Cfg.DisableBruteForceLoginProtection {
 		return
@@ -121,3 +141,18 @@ func (srv *CleanUpService) deleteOldLoginAttempts() {
 		srv.log.Debug("Deleted expired login attempts", "rows affected", cmd.DeletedRows)
 	}
 }
+
+func (srv *CleanUpService) deleteOldAuditEntries() {
+	if !srv.Cfg.Audit.Enabled || srv.Cfg.Audit.MaxAgeDays <= 0 {
+		return
+	}
+
+	cmd := m.DeleteOldAuditEntriesCommand{
+		OlderThan: time.Now().AddDate(0, 0, -srv.Cfg.Audit.MaxAgeDays),
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		srv.log.Error("Problem deleting old audit entries", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted old audit entries", "rows affected", cmd.DeletedRows)
+	}
+}
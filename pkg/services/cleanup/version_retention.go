@@ -0,0 +1,109 @@
+package cleanup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+// maxRetentionVersionsToDelete limits how many versions are deleted by the
+// retentions in one run, like the global clean up of versions.
+const maxRetentionVersionsToDelete = 100
+
+func (srv *CleanUpService) deleteDashboardVersionsByRetention() {
+	retentionsQuery := m.GetDashboardVersionRetentionsQuery{}
+	if err := bus.Dispatch(&retentionsQuery); err != nil {
+		srv.log.Error("Failed to get dashboard version retentions", "error", err.Error())
+		return
+	}
+
+	if len(retentionsQuery.Result) == 0 {
+		return
+	}
+
+	itemsQuery := m.GetDashboardVersionRetentionItemsQuery{}
+	if err := bus.Dispatch(&itemsQuery); err != nil {
+		srv.log.Error("Failed to get dashboard versions to apply retentions to", "error", err.Error())
+		return
+	}
+
+	ids := versionsToDelete(retentionsQuery.Result, itemsQuery.Result, time.Now())
+	if len(ids) > maxRetentionVersionsToDelete {
+		ids = ids[:maxRetentionVersionsToDelete]
+	}
+
+	cmd := m.DeleteDashboardVersionsCommand{Ids: ids}
+	if err := bus.Dispatch(&cmd); err != nil {
+		srv.log.Error("Failed to delete dashboard versions by retention", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted dashboard versions by retention", "rows affected", cmd.DeletedRows)
+	}
+}
+
+// versionsToDelete returns the ids of the versions the retentions do not
+// keep. The versions of each dashboard must be ordered newest first.
+func versionsToDelete(retentions []*m.DashboardVersionRetention, versions []*m.DashboardVersionRetentionItem, now time.Time) []int64 {
+	byId := make(map[int64]*m.DashboardVersionRetention, len(retentions))
+	for _, r := range retentions {
+		byId[r.Id] = r
+	}
+
+	ids := make([]int64, 0)
+
+	var dashboardId int64
+	var kept map[string]bool
+	for i, version := range versions {
+		retention, ok := byId[version.RetentionId]
+		if !ok {
+			continue
+		}
+
+		latest := i == 0 || version.DashboardId != dashboardId
+		if latest {
+			dashboardId = version.DashboardId
+			kept = make(map[string]bool)
+		}
+
+		if latest || version.Label != "" {
+			continue
+		}
+
+		if !keepVersion(retention, version.Created, now, kept) {
+			ids = append(ids, version.Id)
+		}
+	}
+
+	return ids
+}
+
+// keepVersion decides whether a version created at the given time is kept.
+// kept holds the days and weeks that already have a newer version.
+func keepVersion(retention *m.DashboardVersionRetention, created time.Time, now time.Time, kept map[string]bool) bool {
+	keepAllSince := now.AddDate(0, 0, -retention.KeepAllDays)
+	keepDailySince := keepAllSince.AddDate(0, 0, -retention.KeepDailyDays)
+	keepWeeklySince := keepDailySince.AddDate(0, 0, -retention.KeepWeeklyDays)
+
+	var period string
+	created = created.UTC()
+
+	switch {
+	case !created.Before(keepAllSince):
+		return true
+	case !created.Before(keepDailySince):
+		period = "day " + created.Format("2006-01-02")
+	case retention.KeepWeeklyDays == 0 || !created.Before(keepWeeklySince):
+		year, week := created.ISOWeek()
+		period = fmt.Sprintf("week %d-%d", year, week)
+	default:
+		return false
+	}
+
+	if kept[period] {
+		return false
+	}
+
+	kept[period] = true
+	return true
+}
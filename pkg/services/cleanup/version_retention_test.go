@@ -0,0 +1,68 @@
+package cleanup
+
+import (
+	"testing"
+	"time"
+
+	m "github.com/Seasheller/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestVersionsToDelete(t *testing.T) {
+	Convey("Given a retention keeping all versions for 7 days, then daily for 30 days and weekly for 365 days", t, func() {
+		now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+		retention := &m.DashboardVersionRetention{Id: 1, KeepAllDays: 7, KeepDailyDays: 30, KeepWeeklyDays: 365}
+
+		var id int64
+		version := func(dashboardId int64, age time.Duration, label string) *m.DashboardVersionRetentionItem {
+			id++
+			return &m.DashboardVersionRetentionItem{Id: id, DashboardId: dashboardId, Created: now.Add(-age), Label: label, RetentionId: 1}
+		}
+
+		day := 24 * time.Hour
+
+		Convey("Should keep all recent versions", func() {
+			versions := []*m.DashboardVersionRetentionItem{
+				version(1, time.Hour, ""),
+				version(1, 2*time.Hour, ""),
+				version(1, 6*day, ""),
+			}
+			So(versionsToDelete([]*m.DashboardVersionRetention{retention}, versions, now), ShouldBeEmpty)
+		})
+
+		Convey("Should keep the newest version of each day, then of each week", func() {
+			versions := []*m.DashboardVersionRetentionItem{
+				version(1, time.Hour, ""),
+				version(1, 10*day, ""),
+				version(1, 10*day+time.Hour, ""),
+				version(1, 11*day, ""),
+				// 2026-08-10 and 2026-08-12 are in the same week
+				version(1, now.Sub(time.Date(2026, 8, 12, 10, 0, 0, 0, time.UTC)), ""),
+				version(1, now.Sub(time.Date(2026, 8, 10, 10, 0, 0, 0, time.UTC)), ""),
+				version(1, 410*day, ""),
+			}
+			So(versionsToDelete([]*m.DashboardVersionRetention{retention}, versions, now), ShouldResemble, []int64{3, 6, 7})
+		})
+
+		Convey("Should always keep the latest and labeled versions", func() {
+			versions := []*m.DashboardVersionRetentionItem{
+				version(1, 500*day, ""),
+				version(1, 600*day, "release 2026-Q3"),
+				version(1, 700*day, ""),
+				version(2, 500*day, ""),
+			}
+			So(versionsToDelete([]*m.DashboardVersionRetention{retention}, versions, now), ShouldResemble, []int64{3})
+		})
+
+		Convey("Should keep weekly versions forever without weekly days", func() {
+			retention.KeepWeeklyDays = 0
+			versions := []*m.DashboardVersionRetentionItem{
+				version(1, time.Hour, ""),
+				version(1, 1000*day, ""),
+				version(1, 1000*day+time.Hour, ""),
+				version(1, 1100*day, ""),
+			}
+			So(versionsToDelete([]*m.DashboardVersionRetention{retention}, versions, now), ShouldResemble, []int64{3})
+		})
+	})
+}
@@ -17,6 +17,7 @@ type DashboardService interface {
 	SaveDashboard(dto *SaveDashboardDTO) (*models.Dashboard, error)
 	ImportDashboard(dto *SaveDashboardDTO) (*models.Dashboard, error)
 	DeleteDashboard(dashboardId int64, orgId int64) error
+	RestoreDashboard(trashId int64, orgId int64, user *models.SignedInUser) (*models.Dashboard, error)
 }
 
 // DashboardProvisioningService service for operating on provisioned dashboards
@@ -89,10 +90,6 @@ func (dr *dashboardServiceImpl) buildSaveDashboardCommand(dto *SaveDashboardDTO,
 		return nil, models.ErrDashboardTitleEmpty
 	}
 
-	if dash.IsFolder && dash.FolderId > 0 {
-		return nil, models.ErrDashboardFolderCannotHaveParent
-	}
-
 	if dash.IsFolder && strings.EqualFold(dash.Title, models.RootFolderName) {
 		return nil, models.ErrDashboardFolderNameExists
 	}
@@ -282,6 +279,34 @@ func (dr *dashboardServiceImpl) deleteDashboard(dashboardId int64, orgId int64,
 	return bus.Dispatch(cmd)
 }
 
+// RestoreDashboard restores a deleted dashboard, or a deleted folder with
+// everything in it, from the trash and recreates the alerts of the restored
+// dashboards.
+func (dr *dashboardServiceImpl) RestoreDashboard(trashId int64, orgId int64, user *models.SignedInUser) (*models.Dashboard, error) {
+	cmd := &models.RestoreDashboardCommand{OrgId: orgId, TrashId: trashId}
+	if err := bus.Dispatch(cmd); err != nil {
+		return nil, err
+	}
+
+	for _, dash := range cmd.Result {
+		if dash.IsFolder {
+			continue
+		}
+
+		alertCmd := models.UpdateDashboardAlertsCommand{
+			OrgId:     orgId,
+			Dashboard: dash,
+			User:      user,
+		}
+
+		if err := bus.Dispatch(&alertCmd); err != nil {
+			dr.log.Error("Failed to restore alerts of dashboard", "dashboardId", dash.Id, "error", err)
+		}
+	}
+
+	return cmd.Result[0], nil
+}
+
 func (dr *dashboardServiceImpl) ImportDashboard(dto *SaveDashboardDTO) (*models.Dashboard, error) {
 	cmd, err := dr.buildSaveDashboardCommand(dto, false, true)
 	if err != nil {
@@ -333,6 +358,10 @@ func (s *FakeDashboardService) DeleteDashboard(dashboardId int64, orgId int64) e
 	return nil
 }
 
+func (s *FakeDashboardService) RestoreDashboard(trashId int64, orgId int64, user *models.SignedInUser) (*models.Dashboard, error) {
+	return s.SaveDashboardResult, s.SaveDashboardError
+}
+
 func MockDashboardService(mock *FakeDashboardService) {
 	NewService = func() DashboardService {
 		return mock
@@ -32,13 +32,6 @@ func TestDashboardService(t *testing.T) {
 				}
 			})
 
-			Convey("Should return validation error if it's a folder and have a folder id", func() {
-				dto.Dashboard = models.NewDashboardFolder("Folder")
-				dto.Dashboard.FolderId = 1
-				_, err := service.SaveDashboard(dto)
-				So(err, ShouldEqual, models.ErrDashboardFolderCannotHaveParent)
-			})
-
 			Convey("Should return validation error if folder is named General", func() {
 				dto.Dashboard = models.NewDashboardFolder("General")
 				_, err := service.SaveDashboard(dto)
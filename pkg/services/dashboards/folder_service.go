@@ -14,6 +14,7 @@ type FolderService interface {
 	GetFolderByUID(uid string) (*models.Folder, error)
 	CreateFolder(cmd *models.CreateFolderCommand) error
 	UpdateFolder(uid string, cmd *models.UpdateFolderCommand) error
+	MoveFolder(uid string, cmd *models.MoveFolderCommand) error
 	DeleteFolder(uid string) (*models.Folder, error)
 }
 
@@ -44,9 +45,12 @@ func (dr *dashboardServiceImpl) GetFolders(limit int64) ([]*models.Folder, error
 
 	for _, hit := range searchQuery.Result {
 		folders = append(folders, &models.Folder{
-			Id:    hit.Id,
-			Uid:   hit.Uid,
-			Title: hit.Title,
+			Id:        hit.Id,
+			Uid:       hit.Uid,
+			Title:     hit.Title,
+			ParentId:  hit.FolderId,
+			ParentUid: hit.FolderUid,
+			Parents:   hit.Breadcrumbs,
 		})
 	}
 
@@ -69,7 +73,7 @@ func (dr *dashboardServiceImpl) GetFolderByID(id int64) (*models.Folder, error)
 		return nil, models.ErrFolderAccessDenied
 	}
 
-	return dashToFolder(dashFolder), nil
+	return dr.dashToFolderWithParents(dashFolder)
 }
 
 func (dr *dashboardServiceImpl) GetFolderByUID(uid string) (*models.Folder, error) {
@@ -88,12 +92,20 @@ func (dr *dashboardServiceImpl) GetFolderByUID(uid string) (*models.Folder, erro
 		return nil, models.ErrFolderAccessDenied
 	}
 
-	return dashToFolder(dashFolder), nil
+	return dr.dashToFolderWithParents(dashFolder)
 }
 
 func (dr *dashboardServiceImpl) CreateFolder(cmd *models.CreateFolderCommand) error {
 	dashFolder := cmd.GetDashboardModel(dr.orgId, dr.user.UserId)
 
+	if cmd.ParentUid != "" {
+		parent, err := getFolder(models.GetDashboardQuery{OrgId: dr.orgId, Uid: cmd.ParentUid})
+		if err != nil {
+			return toFolderError(err)
+		}
+		dashFolder.FolderId = parent.Id
+	}
+
 	dto := &SaveDashboardDTO{
 		Dashboard: dashFolder,
 		OrgId:     dr.orgId,
@@ -116,9 +128,8 @@ func (dr *dashboardServiceImpl) CreateFolder(cmd *models.CreateFolderCommand) er
 		return toFolderError(err)
 	}
 
-	cmd.Result = dashToFolder(dashFolder)
-
-	return nil
+	cmd.Result, err = dr.dashToFolderWithParents(dashFolder)
+	return err
 }
 
 func (dr *dashboardServiceImpl) UpdateFolder(existingUid string, cmd *models.UpdateFolderCommand) error {
@@ -153,9 +164,51 @@ func (dr *dashboardServiceImpl) UpdateFolder(existingUid string, cmd *models.Upd
 		return toFolderError(err)
 	}
 
-	cmd.Result = dashToFolder(dashFolder)
+	cmd.Result, err = dr.dashToFolderWithParents(dashFolder)
+	return err
+}
+
+// MoveFolder moves the folder, with everything in it, into the folder with
+// the given parent uid, or to the root when the parent uid is empty.
+func (dr *dashboardServiceImpl) MoveFolder(uid string, cmd *models.MoveFolderCommand) error {
+	dashFolder, err := getFolder(models.GetDashboardQuery{OrgId: dr.orgId, Uid: uid})
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	var parentId int64
+	if cmd.ParentUid != "" {
+		parent, err := getFolder(models.GetDashboardQuery{OrgId: dr.orgId, Uid: cmd.ParentUid})
+		if err != nil {
+			return toFolderError(err)
+		}
+		parentId = parent.Id
+	}
+
+	dashFolder.FolderId = parentId
+
+	dto := &SaveDashboardDTO{
+		Dashboard: dashFolder,
+		OrgId:     dr.orgId,
+		User:      dr.user,
+	}
 
-	return nil
+	saveDashboardCmd, err := dr.buildSaveDashboardCommand(dto, false, false)
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	if err := bus.Dispatch(saveDashboardCmd); err != nil {
+		return toFolderError(err)
+	}
+
+	dashFolder, err = getFolder(models.GetDashboardQuery{OrgId: dr.orgId, Id: saveDashboardCmd.Result.Id})
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	cmd.Result, err = dr.dashToFolderWithParents(dashFolder)
+	return err
 }
 
 func (dr *dashboardServiceImpl) DeleteFolder(uid string) (*models.Folder, error) {
@@ -205,9 +258,30 @@ func dashToFolder(dash *models.Dashboard) *models.Folder {
 		CreatedBy: dash.CreatedBy,
 		Updated:   dash.Updated,
 		UpdatedBy: dash.UpdatedBy,
+		ParentId:  dash.FolderId,
 	}
 }
 
+// dashToFolderWithParents also resolves the folders above a nested folder.
+func (dr *dashboardServiceImpl) dashToFolderWithParents(dash *models.Dashboard) (*models.Folder, error) {
+	folder := dashToFolder(dash)
+	if folder.ParentId == 0 {
+		return folder, nil
+	}
+
+	query := models.GetFolderPathsQuery{OrgId: dr.orgId, FolderIds: []int64{folder.ParentId}}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	folder.Parents = query.Result[folder.ParentId]
+	if len(folder.Parents) > 0 {
+		folder.ParentUid = folder.Parents[len(folder.Parents)-1].Uid
+	}
+
+	return folder, nil
+}
+
 func toFolderError(err error) error {
 	if err == models.ErrDashboardTitleEmpty {
 		return models.ErrFolderTitleEmpty
@@ -237,5 +311,17 @@ func toFolderError(err error) error {
 		err = models.ErrFolderFailedGenerateUniqueUid
 	}
 
+	if err == models.ErrDashboardFolderNotFound {
+		return models.ErrFolderNotFound
+	}
+
+	if err == models.ErrDashboardFolderCannotBeOwnParent {
+		return models.ErrFolderCannotBeOwnParent
+	}
+
+	if err == models.ErrDashboardFolderMaxDepthExceeded {
+		return models.ErrFolderMaxDepthExceeded
+	}
+
 	return err
 }
@@ -65,6 +65,12 @@ func TestFolderService(t *testing.T) {
 				So(err, ShouldEqual, models.ErrFolderAccessDenied)
 			})
 
+			Convey("When moving folder should return access denied error", func() {
+				err := service.MoveFolder("uid", &models.MoveFolderCommand{ParentUid: "parent"})
+				So(err, ShouldNotBeNil)
+				So(err, ShouldEqual, models.ErrFolderAccessDenied)
+			})
+
 			Convey("When deleting folder by uid should return access denied error", func() {
 				_, err := service.DeleteFolder("uid")
 				So(err, ShouldNotBeNil)
@@ -135,6 +141,21 @@ func TestFolderService(t *testing.T) {
 				So(provisioningValidated, ShouldBeFalse)
 			})
 
+			Convey("When moving folder should return the folder with its parents", func() {
+				bus.AddHandler("test", func(query *models.GetFolderPathsQuery) error {
+					query.Result = map[int64][]*models.FolderRef{
+						dash.Id: {{Id: dash.Id, Uid: "parent", Title: "Parent"}},
+					}
+					return nil
+				})
+
+				cmd := &models.MoveFolderCommand{ParentUid: "parent"}
+				err := service.MoveFolder("uid", cmd)
+				So(err, ShouldBeNil)
+				So(cmd.Result.ParentUid, ShouldEqual, "parent")
+				So(len(cmd.Result.Parents), ShouldEqual, 1)
+			})
+
 			Convey("When deleting folder by uid should not return access denied error", func() {
 				_, err := service.DeleteFolder("uid")
 				So(err, ShouldBeNil)
@@ -190,6 +211,8 @@ func TestFolderService(t *testing.T) {
 				{ActualError: models.ErrDashboardNotFound, ExpectedError: models.ErrFolderNotFound},
 				{ActualError: models.ErrDashboardFailedGenerateUniqueUid, ExpectedError: models.ErrFolderFailedGenerateUniqueUid},
 				{ActualError: models.ErrDashboardInvalidUid, ExpectedError: models.ErrDashboardInvalidUid},
+				{ActualError: models.ErrDashboardFolderCannotBeOwnParent, ExpectedError: models.ErrFolderCannotBeOwnParent},
+				{ActualError: models.ErrDashboardFolderMaxDepthExceeded, ExpectedError: models.ErrFolderMaxDepthExceeded},
 			}
 
 			for _, tc := range testCases {
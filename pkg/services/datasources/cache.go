@@ -38,7 +38,7 @@ func (dc *CacheServiceImpl) GetDatasource(datasourceID int64, user *m.SignedInUs
 		if cached, found := dc.CacheService.Get(cacheKey); found {
 			ds := cached.(*m.DataSource)
 			if ds.OrgId == user.OrgId {
-				return ds, nil
+				return dc.checkPermission(ds, user)
 			}
 		}
 	}
@@ -49,5 +49,26 @@ func (dc *CacheServiceImpl) GetDatasource(datasourceID int64, user *m.SignedInUs
 	}
 
 	dc.CacheService.Set(cacheKey, query.Result, time.Second*5)
-	return query.Result, nil
+	return dc.checkPermission(query.Result, user)
+}
+
+func (dc *CacheServiceImpl) checkPermission(ds *m.DataSource, user *m.SignedInUser) (*m.DataSource, error) {
+	query := m.DatasourcesPermissionFilterQuery{
+		User:        user,
+		Datasources: []*m.DataSource{ds},
+		Permission:  m.DsPermissionQuery,
+	}
+
+	if err := dc.Bus.Dispatch(&query); err != nil {
+		if err == bus.ErrHandlerNotFound {
+			return ds, nil
+		}
+		return nil, err
+	}
+
+	if len(query.Result) == 0 {
+		return nil, m.ErrDataSourceAccessDenied
+	}
+
+	return ds, nil
 }
@@ -0,0 +1,156 @@
+package datasources
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/infra/metrics"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/registry"
+	"github.com/Seasheller/grafana/pkg/setting"
+	"github.com/Seasheller/grafana/pkg/tsdb"
+)
+
+// maxConcurrentHealthChecks limits how many datasources are checked at once
+const maxConcurrentHealthChecks = 10
+
+func init() {
+	registry.RegisterService(&HealthCheckService{})
+}
+
+// HealthCheckService periodically checks the health of the datasources of
+// all orgs and exposes the results as metrics. Datasources that don't
+// support health checks are skipped.
+type HealthCheckService struct {
+	Bus bus.Bus      `inject:""`
+	Cfg *setting.Cfg `inject:""`
+
+	log         log.Logger
+	checkHealth tsdb.HandleHealthCheckFunc
+
+	// labels holds the label values of the metrics set for each datasource,
+	// so the metrics of renamed and deleted datasources can be removed
+	labels map[int64][]string
+}
+
+func (s *HealthCheckService) Init() error {
+	s.log = log.New("datasources.health")
+	s.checkHealth = tsdb.HandleHealthCheck
+	s.labels = map[int64][]string{}
+	return nil
+}
+
+func (s *HealthCheckService) IsDisabled() bool {
+	return !s.Cfg.DataSourceHealth.Enabled
+}
+
+func (s *HealthCheckService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.Cfg.DataSourceHealth.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.checkAll(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *HealthCheckService) checkAll(ctx context.Context) {
+	query := m.GetAllDataSourcesQuery{}
+	if err := s.Bus.Dispatch(&query); err != nil {
+		s.log.Error("Failed to get datasources", "error", err)
+		return
+	}
+
+	results := make([]*tsdb.HealthCheckResult, len(query.Result))
+	limit := make(chan struct{}, maxConcurrentHealthChecks)
+
+	var wg sync.WaitGroup
+	for i, ds := range query.Result {
+		wg.Add(1)
+		go func(i int, ds *m.DataSource) {
+			defer wg.Done()
+
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			results[i] = s.check(ctx, ds)
+		}(i, ds)
+	}
+	wg.Wait()
+
+	checked := map[int64]bool{}
+	for i, ds := range query.Result {
+		if results[i] == nil {
+			continue
+		}
+
+		labels := []string{strconv.FormatInt(ds.OrgId, 10), strconv.FormatInt(ds.Id, 10), ds.Name, ds.Type}
+		if previous, exists := s.labels[ds.Id]; exists && !equalLabels(previous, labels) {
+			deleteHealthMetrics(previous)
+		}
+
+		up := 0.0
+		if results[i].Healthy {
+			up = 1
+		}
+		metrics.MDataSourceHealthUp.WithLabelValues(labels...).Set(up)
+		metrics.MDataSourceHealthLatency.WithLabelValues(labels...).Set(results[i].Latency.Seconds())
+
+		s.labels[ds.Id] = labels
+		checked[ds.Id] = true
+	}
+
+	for id, labels := range s.labels {
+		if !checked[id] {
+			deleteHealthMetrics(labels)
+			delete(s.labels, id)
+		}
+	}
+}
+
+// check returns the result of the health check of the datasource, or nil
+// when it can't be checked.
+func (s *HealthCheckService) check(ctx context.Context, ds *m.DataSource) *tsdb.HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, s.Cfg.DataSourceHealth.Timeout)
+	defer cancel()
+
+	result, err := s.checkHealth(ctx, ds)
+	if err != nil {
+		s.log.Debug("Skipping datasource health check", "datasource", ds.Name, "orgId", ds.OrgId, "reason", err)
+		return nil
+	}
+
+	if !result.Healthy {
+		s.log.Warn("Datasource is not healthy", "datasource", ds.Name, "orgId", ds.OrgId, "error", result.Message)
+	}
+
+	return result
+}
+
+func deleteHealthMetrics(labels []string) {
+	metrics.MDataSourceHealthUp.DeleteLabelValues(labels...)
+	metrics.MDataSourceHealthLatency.DeleteLabelValues(labels...)
+}
+
+func equalLabels(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
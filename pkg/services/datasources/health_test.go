@@ -0,0 +1,99 @@
+package datasources
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/infra/metrics"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/setting"
+	"github.com/Seasheller/grafana/pkg/tsdb"
+)
+
+func TestHealthCheckService(t *testing.T) {
+	Convey("Datasource health checks", t, func() {
+		bus.ClearBusHandlers()
+
+		datasources := []*m.DataSource{
+			{Id: 1, OrgId: 1, Name: "prom", Type: "prometheus"},
+			{Id: 2, OrgId: 2, Name: "graphite", Type: "graphite"},
+			{Id: 3, OrgId: 1, Name: "frontend", Type: "frontend-only"},
+		}
+		bus.AddHandler("test", func(query *m.GetAllDataSourcesQuery) error {
+			query.Result = datasources
+			return nil
+		})
+
+		s := &HealthCheckService{
+			Bus: bus.GetBus(),
+			Cfg: &setting.Cfg{DataSourceHealth: setting.DataSourceHealthSettings{Timeout: time.Second}},
+		}
+		So(s.Init(), ShouldBeNil)
+
+		s.checkHealth = func(ctx context.Context, ds *m.DataSource) (*tsdb.HealthCheckResult, error) {
+			switch ds.Type {
+			case "prometheus":
+				return &tsdb.HealthCheckResult{Healthy: true, Latency: time.Millisecond * 250}, nil
+			case "graphite":
+				return &tsdb.HealthCheckResult{Message: "connection refused", Latency: time.Second}, nil
+			}
+			return nil, tsdb.ErrHealthCheckNotSupported
+		}
+
+		s.checkAll(context.Background())
+
+		Convey("Should expose the results of the checks", func() {
+			So(healthMetric(metrics.MDataSourceHealthUp, "1", "1", "prom", "prometheus"), ShouldEqual, 1)
+			So(healthMetric(metrics.MDataSourceHealthLatency, "1", "1", "prom", "prometheus"), ShouldEqual, 0.25)
+			So(healthMetric(metrics.MDataSourceHealthUp, "2", "2", "graphite", "graphite"), ShouldEqual, 0)
+			So(healthMetric(metrics.MDataSourceHealthLatency, "2", "2", "graphite", "graphite"), ShouldEqual, 1)
+		})
+
+		Convey("Should skip the datasources that can't be checked", func() {
+			So(s.labels, ShouldHaveLength, 2)
+			So(s.labels, ShouldNotContainKey, int64(3))
+		})
+
+		Convey("Should remove the metrics of renamed and deleted datasources", func() {
+			datasources = []*m.DataSource{
+				{Id: 1, OrgId: 1, Name: "prometheus", Type: "prometheus"},
+			}
+
+			s.checkAll(context.Background())
+
+			So(s.labels, ShouldResemble, map[int64][]string{1: {"1", "1", "prometheus", "prometheus"}})
+			So(healthMetric(metrics.MDataSourceHealthUp, "1", "1", "prometheus", "prometheus"), ShouldEqual, 1)
+			So(metrics.MDataSourceHealthUp.DeleteLabelValues("1", "1", "prom", "prometheus"), ShouldBeFalse)
+			So(metrics.MDataSourceHealthUp.DeleteLabelValues("2", "2", "graphite", "graphite"), ShouldBeFalse)
+		})
+
+		Convey("Should keep the metrics when the datasources can't be listed", func() {
+			bus.AddHandler("test", func(query *m.GetAllDataSourcesQuery) error {
+				return errors.New("database is locked")
+			})
+
+			s.checkAll(context.Background())
+
+			So(s.labels, ShouldHaveLength, 2)
+			So(healthMetric(metrics.MDataSourceHealthUp, "1", "1", "prom", "prometheus"), ShouldEqual, 1)
+		})
+
+		Reset(func() {
+			metrics.MDataSourceHealthUp.Reset()
+			metrics.MDataSourceHealthLatency.Reset()
+		})
+	})
+}
+
+func healthMetric(vec *prometheus.GaugeVec, labels ...string) float64 {
+	var metric dto.Metric
+	So(vec.WithLabelValues(labels...).Write(&metric), ShouldBeNil)
+	return metric.GetGauge().GetValue()
+}
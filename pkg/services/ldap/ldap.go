@@ -278,24 +278,29 @@ func (server *Server) users(logins []string) (
 	[]*ldap.Entry,
 	error,
 ) {
-	var result *ldap.SearchResult
+	var entries []*ldap.Entry
 	var Config = server.Config
-	var err error
 
+	// users can be under any of the base DNs, and the same entry is found
+	// under each base DN that contains it
+	found := map[string]bool{}
 	for _, base := range Config.SearchBaseDNs {
-		result, err = server.Connection.Search(
+		result, err := server.Connection.Search(
 			server.getSearchRequest(base, logins),
 		)
 		if err != nil {
 			return nil, err
 		}
 
-		if len(result.Entries) > 0 {
-			break
+		for _, entry := range result.Entries {
+			if !found[entry.DN] {
+				found[entry.DN] = true
+				entries = append(entries, entry)
+			}
 		}
 	}
 
-	return result.Entries, nil
+	return entries, nil
 }
 
 // validateGrafanaUser validates user access.
@@ -394,9 +399,27 @@ func (server *Server) buildGrafanaUser(user *ldap.Entry) (*models.ExternalUserIn
 		}
 	}
 
+	// If the mappings grant the Grafana admin flag, users outside of
+	// those groups should lose it instead of keeping it forever
+	if extUser.IsGrafanaAdmin == nil && server.managesGrafanaAdmin() {
+		isGrafanaAdmin := false
+		extUser.IsGrafanaAdmin = &isGrafanaAdmin
+	}
+
 	return extUser, nil
 }
 
+// managesGrafanaAdmin checks if any group mapping sets the Grafana admin flag
+func (server *Server) managesGrafanaAdmin() bool {
+	for _, group := range server.Config.Groups {
+		if group.IsGrafanaAdmin != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
 // shouldAuthAdmin checks if we should use
 // admin username & password for LDAP bind
 func (server *Server) shouldAuthAdmin() bool {
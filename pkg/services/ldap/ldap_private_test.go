@@ -116,6 +116,45 @@ func TestLDAPPrivateMethods(t *testing.T) {
 			So(err, ShouldBeNil)
 			So(result[0].Name, ShouldEqual, "Roel")
 		})
+
+		Convey("removes grafana admin flag outside of admin groups", func() {
+			isGrafanaAdmin := true
+			server := &Server{
+				Config: &ServerConfig{
+					Attr: AttributeMap{
+						Username: "username",
+						Name:     "name",
+						MemberOf: "memberof",
+						Email:    "email",
+					},
+					Groups: []*GroupToOrgRole{
+						{GroupDN: "admins", OrgID: 1, OrgRole: models.ROLE_ADMIN, IsGrafanaAdmin: &isGrafanaAdmin},
+						{GroupDN: "viewers", OrgID: 1, OrgRole: models.ROLE_VIEWER},
+					},
+					SearchBaseDNs: []string{"BaseDNHere"},
+				},
+				Connection: &MockConnection{},
+				log:        log.New("test-logger"),
+			}
+
+			entry := ldap.Entry{
+				DN: "dn",
+				Attributes: []*ldap.EntryAttribute{
+					{Name: "username", Values: []string{"roelgerrits"}},
+					{Name: "email", Values: []string{"roel@test.com"}},
+					{Name: "name", Values: []string{"Roel"}},
+					{Name: "memberof", Values: []string{"viewers"}},
+				},
+			}
+			users := []*ldap.Entry{&entry}
+
+			result, err := server.serializeUsers(users)
+
+			So(err, ShouldBeNil)
+			So(result[0].OrgRoles[1], ShouldEqual, models.ROLE_VIEWER)
+			So(result[0].IsGrafanaAdmin, ShouldNotBeNil)
+			So(*result[0].IsGrafanaAdmin, ShouldBeFalse)
+		})
 	})
 
 	Convey("validateGrafanaUser()", t, func() {
@@ -81,6 +81,40 @@ func TestPublicAPI(t *testing.T) {
 			So(err, ShouldEqual, expected)
 		})
 
+		Convey("Finds the users under every base DN", func() {
+			MockConnection := &MockConnection{}
+			entries := map[string][]*ldap.Entry{
+				"ou=staff": {
+					{DN: "uid=alice,ou=staff", Attributes: []*ldap.EntryAttribute{{Name: "username", Values: []string{"alice"}}}},
+				},
+				"ou=contractors": {
+					{DN: "uid=bob,ou=contractors", Attributes: []*ldap.EntryAttribute{{Name: "username", Values: []string{"bob"}}}},
+				},
+				"dc=example": {
+					{DN: "uid=alice,ou=staff", Attributes: []*ldap.EntryAttribute{{Name: "username", Values: []string{"alice"}}}},
+				},
+			}
+			MockConnection.SearchProvider = func(request *ldap.SearchRequest) (*ldap.SearchResult, error) {
+				return &ldap.SearchResult{Entries: entries[request.BaseDN]}, nil
+			}
+
+			server := &Server{
+				Config: &ServerConfig{
+					Attr:          AttributeMap{Username: "username"},
+					SearchBaseDNs: []string{"ou=staff", "ou=contractors", "dc=example"},
+				},
+				Connection: MockConnection,
+				log:        log.New("test-logger"),
+			}
+
+			searchResult, err := server.Users([]string{"alice", "bob"})
+
+			So(err, ShouldBeNil)
+			So(searchResult, ShouldHaveLength, 2)
+			So(searchResult[0].Login, ShouldEqual, "alice")
+			So(searchResult[1].Login, ShouldEqual, "bob")
+		})
+
 		Convey("Should return empty slice if none were found", func() {
 			MockConnection := &MockConnection{}
 			result := ldap.SearchResult{Entries: []*ldap.Entry{}}
@@ -24,6 +24,7 @@ type MockConnection struct {
 
 	BindProvider                func(username, password string) error
 	UnauthenticatedBindProvider func() error
+	SearchProvider              func(request *ldap.SearchRequest) (*ldap.SearchResult, error)
 }
 
 // Bind mocks Bind connection function
@@ -64,6 +65,10 @@ func (c *MockConnection) Search(sr *ldap.SearchRequest) (*ldap.SearchResult, err
 	c.SearchCalled = true
 	c.SearchAttributes = sr.Attributes
 
+	if c.SearchProvider != nil {
+		return c.SearchProvider(sr)
+	}
+
 	if c.SearchError != nil {
 		return nil, c.SearchError
 	}
@@ -0,0 +1,190 @@
+package ldapsync
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/infra/serverlock"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/registry"
+	"github.com/Seasheller/grafana/pkg/services/ldap"
+	"github.com/Seasheller/grafana/pkg/services/multildap"
+	"github.com/Seasheller/grafana/pkg/setting"
+	"github.com/Seasheller/grafana/pkg/util/errutil"
+)
+
+// getLDAPConfig gets LDAP config
+var getLDAPConfig = multildap.GetConfig
+
+// isLDAPEnabled checks if LDAP is enabled
+var isLDAPEnabled = multildap.IsEnabled
+
+// newLDAP creates multiple LDAP instance
+var newLDAP = multildap.New
+
+// minSyncInterval is the shortest time between two synchronizations,
+// regardless of how many Grafana servers run the job.
+const minSyncInterval = time.Minute * 10
+
+func init() {
+	registry.RegisterService(&LDAPSyncService{})
+}
+
+// LDAPSyncService periodically updates LDAP users in the background so
+// changes in the directory apply without waiting for the next login.
+type LDAPSyncService struct {
+	Bus               bus.Bus                       `inject:""`
+	ServerLockService *serverlock.ServerLockService `inject:""`
+	AuthTokenService  models.UserTokenService       `inject:""`
+
+	log      log.Logger
+	schedule cron.Schedule
+}
+
+func (s *LDAPSyncService) Init() error {
+	s.log = log.New("ldap.sync")
+
+	schedule, err := cron.Parse(setting.LDAPSyncCron)
+	if err != nil {
+		return errutil.Wrapf(err, "Invalid LDAP sync_cron %q", setting.LDAPSyncCron)
+	}
+
+	s.schedule = schedule
+	return nil
+}
+
+func (s *LDAPSyncService) IsDisabled() bool {
+	return !setting.LDAPEnabled || !setting.LDAPActiveSyncEnabled
+}
+
+func (s *LDAPSyncService) Run(ctx context.Context) error {
+	for {
+		next := s.schedule.Next(time.Now())
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-timer.C:
+			err := s.ServerLockService.LockAndExecute(ctx, "ldap sync", minSyncInterval/2, func() {
+				if err := s.SyncUsers(ctx); err != nil {
+					s.log.Error("LDAP synchronization failed", "error", err)
+				}
+			})
+			if err != nil {
+				s.log.Error("Failed to acquire LDAP sync lock", "error", err)
+			}
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// SyncUsers updates every Grafana user that logged in through LDAP with
+// their current directory entry. Users that are gone from the directory,
+// or no longer belong to a mapped group, are disabled and logged out.
+func (s *LDAPSyncService) SyncUsers(ctx context.Context) error {
+	if !isLDAPEnabled() {
+		return nil
+	}
+
+	config, err := getLDAPConfig()
+	if err != nil {
+		return errutil.Wrap("Failed to get LDAP config", err)
+	}
+
+	query := &models.GetUsersByAuthModuleQuery{AuthModule: models.AuthModuleLDAP}
+	if err := s.Bus.Dispatch(query); err != nil {
+		return err
+	}
+
+	if len(query.Result) == 0 {
+		return nil
+	}
+
+	logins := make([]string, 0, len(query.Result))
+	for _, user := range query.Result {
+		logins = append(logins, user.Login)
+	}
+
+	// like login, the first server the user can log in to wins, and the
+	// group mappings of the server decide if the user may log in
+	found := map[string]*ldapUser{}
+	for _, server := range config.Servers {
+		// Bail out on any LDAP error, otherwise an unreachable server
+		// would disable every user.
+		extUsers, err := newLDAP([]*ldap.ServerConfig{server}).Users(logins)
+		if err != nil {
+			return errutil.Wrap("Failed to search LDAP users", err)
+		}
+
+		for _, extUser := range extUsers {
+			login := strings.ToLower(extUser.Login)
+			candidate := &ldapUser{info: extUser, server: server}
+			if existing, exists := found[login]; !exists || (!existing.canLogin() && candidate.canLogin()) {
+				found[login] = candidate
+			}
+		}
+	}
+
+	synced, disabled := 0, 0
+
+	for _, user := range query.Result {
+		entry, ok := found[strings.ToLower(user.Login)]
+		if !ok || !entry.canLogin() {
+			if user.IsDisabled {
+				continue
+			}
+
+			if err := s.disableUser(ctx, user); err != nil {
+				s.log.Error("Failed to disable LDAP user", "user", user.Login, "error", err)
+				continue
+			}
+
+			disabled++
+			continue
+		}
+
+		extUser := entry.info
+		extUser.UserId = user.Id
+		upsert := &models.UpsertUserCommand{
+			ExternalUser:  extUser,
+			SignupAllowed: false,
+		}
+		if err := s.Bus.Dispatch(upsert); err != nil {
+			s.log.Error("Failed to sync LDAP user", "user", user.Login, "error", err)
+			continue
+		}
+
+		synced++
+	}
+
+	s.log.Info("LDAP synchronization done", "synced", synced, "disabled", disabled)
+	return nil
+}
+
+func (s *LDAPSyncService) disableUser(ctx context.Context, user *models.User) error {
+	s.log.Debug("Disabling LDAP user", "user", user.Login)
+
+	if err := s.Bus.Dispatch(&models.DisableUserCommand{UserId: user.Id, IsDisabled: true}); err != nil {
+		return err
+	}
+
+	return s.AuthTokenService.RevokeAllUserTokens(ctx, user.Id)
+}
+
+// ldapUser is a user found on an LDAP server.
+type ldapUser struct {
+	info   *models.ExternalUserInfo
+	server *ldap.ServerConfig
+}
+
+// canLogin reports whether the user may log in, which like on login needs a
+// mapped group only when the server has group mappings.
+func (u *ldapUser) canLogin() bool {
+	return len(u.server.Groups) == 0 || len(u.info.OrgRoles) > 0
+}
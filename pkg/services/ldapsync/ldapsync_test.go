@@ -0,0 +1,207 @@
+package ldapsync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/auth"
+	"github.com/Seasheller/grafana/pkg/services/ldap"
+	"github.com/Seasheller/grafana/pkg/services/multildap"
+)
+
+type fakeMultiLDAP struct {
+	users    []*models.ExternalUserInfo
+	err      error
+	searched []string
+}
+
+func (f *fakeMultiLDAP) Login(query *models.LoginUserQuery) (*models.ExternalUserInfo, error) {
+	return nil, nil
+}
+
+func (f *fakeMultiLDAP) Users(logins []string) ([]*models.ExternalUserInfo, error) {
+	f.searched = logins
+	return f.users, f.err
+}
+
+func (f *fakeMultiLDAP) User(login string) (*models.ExternalUserInfo, error) {
+	return nil, nil
+}
+
+type syncScenario struct {
+	service *LDAPSyncService
+	// ldap is the first server, servers holds the fake of every server
+	ldap     *fakeMultiLDAP
+	config   *ldap.Config
+	servers  map[*ldap.ServerConfig]*fakeMultiLDAP
+	upserted []*models.ExternalUserInfo
+	disabled []int64
+	revoked  []int64
+	teardown func()
+}
+
+func setupSyncScenario(t *testing.T, groups []*ldap.GroupToOrgRole, users ...*models.User) *syncScenario {
+	t.Helper()
+
+	sc := &syncScenario{
+		config:  &ldap.Config{},
+		servers: map[*ldap.ServerConfig]*fakeMultiLDAP{},
+	}
+	sc.ldap = sc.addServer(groups)
+
+	origEnabled, origConfig, origNew := isLDAPEnabled, getLDAPConfig, newLDAP
+	isLDAPEnabled = func() bool { return true }
+	getLDAPConfig = func() (*ldap.Config, error) { return sc.config, nil }
+	newLDAP = func(configs []*ldap.ServerConfig) multildap.IMultiLDAP {
+		require.Len(t, configs, 1)
+		return sc.servers[configs[0]]
+	}
+
+	tokenService := auth.NewFakeUserAuthTokenService()
+	tokenService.RevokeAllUserTokensProvider = func(ctx context.Context, userId int64) error {
+		sc.revoked = append(sc.revoked, userId)
+		return nil
+	}
+
+	b := bus.New()
+	b.AddHandler(func(query *models.GetUsersByAuthModuleQuery) error {
+		assert.Equal(t, models.AuthModuleLDAP, query.AuthModule)
+		query.Result = users
+		return nil
+	})
+	b.AddHandler(func(cmd *models.UpsertUserCommand) error {
+		assert.False(t, cmd.SignupAllowed)
+		sc.upserted = append(sc.upserted, cmd.ExternalUser)
+		return nil
+	})
+	b.AddHandler(func(cmd *models.DisableUserCommand) error {
+		assert.True(t, cmd.IsDisabled)
+		sc.disabled = append(sc.disabled, cmd.UserId)
+		return nil
+	})
+
+	sc.service = &LDAPSyncService{
+		Bus:              b,
+		AuthTokenService: tokenService,
+		log:              log.New("ldap.sync"),
+	}
+
+	sc.teardown = func() {
+		isLDAPEnabled, getLDAPConfig, newLDAP = origEnabled, origConfig, origNew
+	}
+
+	return sc
+}
+
+func (sc *syncScenario) addServer(groups []*ldap.GroupToOrgRole) *fakeMultiLDAP {
+	server := &ldap.ServerConfig{Groups: groups}
+	sc.config.Servers = append(sc.config.Servers, server)
+	sc.servers[server] = &fakeMultiLDAP{}
+	return sc.servers[server]
+}
+
+func TestSyncUsers(t *testing.T) {
+	adminGroups := []*ldap.GroupToOrgRole{{GroupDN: "cn=admins", OrgID: 1, OrgRole: models.ROLE_ADMIN}}
+
+	t.Run("updates users found in LDAP", func(t *testing.T) {
+		sc := setupSyncScenario(t, adminGroups, &models.User{Id: 1, Login: "alice"})
+		defer sc.teardown()
+		sc.ldap.users = []*models.ExternalUserInfo{
+			{Login: "Alice", AuthModule: models.AuthModuleLDAP, OrgRoles: map[int64]models.RoleType{1: models.ROLE_ADMIN}},
+		}
+
+		require.NoError(t, sc.service.SyncUsers(context.Background()))
+
+		assert.Equal(t, []string{"alice"}, sc.ldap.searched)
+		require.Len(t, sc.upserted, 1)
+		assert.Equal(t, int64(1), sc.upserted[0].UserId)
+		assert.Empty(t, sc.disabled)
+		assert.Empty(t, sc.revoked)
+	})
+
+	t.Run("disables and logs out users missing from LDAP", func(t *testing.T) {
+		sc := setupSyncScenario(t, adminGroups,
+			&models.User{Id: 1, Login: "alice"},
+			&models.User{Id: 2, Login: "bob", IsDisabled: true},
+		)
+		defer sc.teardown()
+
+		require.NoError(t, sc.service.SyncUsers(context.Background()))
+
+		assert.Empty(t, sc.upserted)
+		assert.Equal(t, []int64{1}, sc.disabled)
+		assert.Equal(t, []int64{1}, sc.revoked)
+	})
+
+	t.Run("disables users that left every mapped group", func(t *testing.T) {
+		sc := setupSyncScenario(t, adminGroups, &models.User{Id: 1, Login: "alice"})
+		defer sc.teardown()
+		sc.ldap.users = []*models.ExternalUserInfo{
+			{Login: "alice", AuthModule: models.AuthModuleLDAP, OrgRoles: map[int64]models.RoleType{}},
+		}
+
+		require.NoError(t, sc.service.SyncUsers(context.Background()))
+
+		assert.Empty(t, sc.upserted)
+		assert.Equal(t, []int64{1}, sc.disabled)
+		assert.Equal(t, []int64{1}, sc.revoked)
+	})
+
+	t.Run("keeps users without group mappings", func(t *testing.T) {
+		sc := setupSyncScenario(t, nil, &models.User{Id: 1, Login: "alice"})
+		defer sc.teardown()
+		sc.ldap.users = []*models.ExternalUserInfo{
+			{Login: "alice", AuthModule: models.AuthModuleLDAP, OrgRoles: map[int64]models.RoleType{}},
+		}
+
+		require.NoError(t, sc.service.SyncUsers(context.Background()))
+
+		assert.Len(t, sc.upserted, 1)
+		assert.Empty(t, sc.disabled)
+	})
+
+	t.Run("requires mapped groups only on the servers that have group mappings", func(t *testing.T) {
+		sc := setupSyncScenario(t, adminGroups,
+			&models.User{Id: 1, Login: "alice"},
+			&models.User{Id: 2, Login: "bob"},
+			&models.User{Id: 3, Login: "carol"},
+		)
+		defer sc.teardown()
+		second := sc.addServer(nil)
+
+		sc.ldap.users = []*models.ExternalUserInfo{
+			{Login: "alice", AuthModule: models.AuthModuleLDAP, OrgRoles: map[int64]models.RoleType{}},
+			{Login: "carol", AuthModule: models.AuthModuleLDAP, OrgRoles: map[int64]models.RoleType{}},
+		}
+		second.users = []*models.ExternalUserInfo{
+			{Login: "bob", AuthModule: models.AuthModuleLDAP, OrgRoles: map[int64]models.RoleType{}},
+			{Login: "carol", AuthModule: models.AuthModuleLDAP, OrgRoles: map[int64]models.RoleType{}},
+		}
+
+		require.NoError(t, sc.service.SyncUsers(context.Background()))
+
+		// alice can't log in to the first server, and isn't on the second
+		assert.Equal(t, []int64{1}, sc.disabled)
+		require.Len(t, sc.upserted, 2)
+		assert.Equal(t, "bob", sc.upserted[0].Login)
+		assert.Equal(t, "carol", sc.upserted[1].Login)
+	})
+
+	t.Run("does nothing when LDAP can't be searched", func(t *testing.T) {
+		sc := setupSyncScenario(t, adminGroups, &models.User{Id: 1, Login: "alice"})
+		defer sc.teardown()
+		sc.ldap.err = errors.New("connection refused")
+
+		assert.Error(t, sc.service.SyncUsers(context.Background()))
+		assert.Empty(t, sc.upserted)
+		assert.Empty(t, sc.disabled)
+		assert.Empty(t, sc.revoked)
+	})
+}
@@ -6,12 +6,13 @@ import (
 )
 
 type Message struct {
-	To           []string
-	From         string
-	Subject      string
-	Body         string
-	Info         string
-	EmbededFiles []string
+	To            []string
+	From          string
+	Subject       string
+	Body          string
+	Info          string
+	EmbededFiles  []string
+	AttachedFiles []string
 }
 
 func setDefaultTemplateData(data map[string]interface{}, u *m.User) {
@@ -33,6 +33,9 @@ func (ns *NotificationService) send(msg *Message) (int, error) {
 		for _, file := range msg.EmbededFiles {
 			m.Embed(file)
 		}
+		for _, file := range msg.AttachedFiles {
+			m.Attach(file)
+		}
 
 		m.SetBody("text/html", msg.Body)
 
@@ -127,10 +130,11 @@ func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (
 	}
 
 	return &Message{
-		To:           cmd.To,
-		From:         fmt.Sprintf("%s <%s>", ns.Cfg.Smtp.FromName, ns.Cfg.Smtp.FromAddress),
-		Subject:      subject,
-		Body:         buffer.String(),
-		EmbededFiles: cmd.EmbededFiles,
+		To:            cmd.To,
+		From:          fmt.Sprintf("%s <%s>", ns.Cfg.Smtp.FromName, ns.Cfg.Smtp.FromAddress),
+		Subject:       subject,
+		Body:          buffer.String(),
+		EmbededFiles:  cmd.EmbededFiles,
+		AttachedFiles: cmd.AttachedFiles,
 	}, nil
 }
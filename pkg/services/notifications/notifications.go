@@ -43,6 +43,7 @@ func (ns *NotificationService) Init() error {
 
 	ns.Bus.AddHandler(ns.sendResetPasswordEmail)
 	ns.Bus.AddHandler(ns.validateResetPasswordCode)
+	ns.Bus.AddHandler(ns.createResetPasswordCode)
 	ns.Bus.AddHandler(ns.sendEmailCommandHandler)
 
 	ns.Bus.AddHandlerCtx(ns.sendEmailCommandHandlerSync)
@@ -119,12 +120,13 @@ func subjectTemplateFunc(obj map[string]interface{}, value string) string {
 
 func (ns *NotificationService) sendEmailCommandHandlerSync(ctx context.Context, cmd *m.SendEmailCommandSync) error {
 	message, err := ns.buildEmailMessage(&m.SendEmailCommand{
-		Data:         cmd.Data,
-		Info:         cmd.Info,
-		Template:     cmd.Template,
-		To:           cmd.To,
-		EmbededFiles: cmd.EmbededFiles,
-		Subject:      cmd.Subject,
+		Data:          cmd.Data,
+		Info:          cmd.Info,
+		Template:      cmd.Template,
+		To:            cmd.To,
+		EmbededFiles:  cmd.EmbededFiles,
+		AttachedFiles: cmd.AttachedFiles,
+		Subject:       cmd.Subject,
 	})
 
 	if err != nil {
@@ -157,6 +159,11 @@ func (ns *NotificationService) sendResetPasswordEmail(cmd *m.SendResetPasswordEm
 	})
 }
 
+func (ns *NotificationService) createResetPasswordCode(query *m.CreateResetPasswordCodeQuery) error {
+	query.Result = createUserEmailCode(query.User, nil)
+	return nil
+}
+
 func (ns *NotificationService) validateResetPasswordCode(query *m.ValidateResetPasswordCodeQuery) error {
 	login := getLoginForEmailCode(query.Code)
 	if login == "" {
@@ -0,0 +1,130 @@
+// Package passwordpolicy checks passwords of users stored in Grafana
+// against the rules configured in the [password_policy] section.
+package passwordpolicy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/setting"
+	"github.com/Seasheller/grafana/pkg/util"
+)
+
+// PolicyError lists every rule a password breaks. Its message is meant
+// to be shown to the user.
+type PolicyError struct {
+	Reasons []string
+}
+
+func (e *PolicyError) Error() string {
+	return "Password " + strings.Join(e.Reasons, ", ")
+}
+
+// Validate checks password against the policy. user is the account the
+// password is for; for accounts not yet created only Login and Email
+// need to be set and the history check is skipped.
+func Validate(policy setting.PasswordPolicySettings, user *models.User, password string) error {
+	var reasons []string
+
+	if len([]rune(password)) < policy.MinLength {
+		reasons = append(reasons, fmt.Sprintf("must be at least %d characters long", policy.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if policy.RequireUppercase && !hasUpper {
+		reasons = append(reasons, "must contain an uppercase letter")
+	}
+	if policy.RequireLowercase && !hasLower {
+		reasons = append(reasons, "must contain a lowercase letter")
+	}
+	if policy.RequireDigit && !hasDigit {
+		reasons = append(reasons, "must contain a digit")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		reasons = append(reasons, "must contain a symbol")
+	}
+
+	for _, denied := range policy.DenyList {
+		if strings.EqualFold(password, denied) {
+			reasons = append(reasons, "is too common")
+			break
+		}
+	}
+
+	if user != nil {
+		if (user.Login != "" && strings.EqualFold(password, user.Login)) ||
+			(user.Email != "" && strings.EqualFold(password, user.Email)) {
+			reasons = append(reasons, "must not match the login or email")
+		}
+
+		reused, err := isReused(policy, user, password)
+		if err != nil {
+			return err
+		}
+		if reused {
+			reasons = append(reasons, fmt.Sprintf("must not match any of the last %d passwords", policy.HistorySize))
+		}
+	}
+
+	if len(reasons) > 0 {
+		return &PolicyError{Reasons: reasons}
+	}
+
+	return nil
+}
+
+func isReused(policy setting.PasswordPolicySettings, user *models.User, password string) (bool, error) {
+	if policy.HistorySize <= 0 || user.Id == 0 {
+		return false, nil
+	}
+
+	hashed := util.EncodePassword(password, user.Salt)
+
+	// users created before password history was recorded only
+	// have their current password
+	if hashed == user.Password {
+		return true, nil
+	}
+
+	query := models.GetUserPasswordHistoryQuery{UserId: user.Id, Limit: policy.HistorySize}
+	if err := bus.Dispatch(&query); err != nil {
+		return false, err
+	}
+
+	for _, entry := range query.Result {
+		if entry.Password == hashed {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// IsExpired returns true when the user's password is older than the
+// configured maximum age. Users without a password stored in Grafana
+// never expire.
+func IsExpired(policy setting.PasswordPolicySettings, user *models.User) bool {
+	if policy.MaxAgeDays <= 0 || user.Password == "" || user.PasswordChangedAt.IsZero() {
+		return false
+	}
+
+	maxAge := time.Duration(policy.MaxAgeDays) * 24 * time.Hour
+	return time.Since(user.PasswordChangedAt) > maxAge
+}
@@ -0,0 +1,109 @@
+package passwordpolicy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/setting"
+	"github.com/Seasheller/grafana/pkg/util"
+)
+
+func reasonsOf(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+
+	policyErr, ok := err.(*PolicyError)
+	require.True(t, ok, "expected a PolicyError, got %v", err)
+	return policyErr.Reasons
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("minimum length", func(t *testing.T) {
+		policy := setting.PasswordPolicySettings{MinLength: 8}
+
+		assert.Equal(t, []string{"must be at least 8 characters long"}, reasonsOf(t, Validate(policy, nil, "short")))
+		assert.NoError(t, Validate(policy, nil, "long enough"))
+	})
+
+	t.Run("character classes", func(t *testing.T) {
+		policy := setting.PasswordPolicySettings{
+			RequireUppercase: true,
+			RequireLowercase: true,
+			RequireDigit:     true,
+			RequireSymbol:    true,
+		}
+
+		assert.Equal(t, []string{
+			"must contain an uppercase letter",
+			"must contain a digit",
+			"must contain a symbol",
+		}, reasonsOf(t, Validate(policy, nil, "password")))
+		assert.NoError(t, Validate(policy, nil, "Passw0rd!"))
+	})
+
+	t.Run("deny list ignores case", func(t *testing.T) {
+		policy := setting.PasswordPolicySettings{DenyList: []string{"password", "grafana"}}
+
+		assert.Equal(t, []string{"is too common"}, reasonsOf(t, Validate(policy, nil, "Grafana")))
+		assert.NoError(t, Validate(policy, nil, "grafana1"))
+	})
+
+	t.Run("rejects login and email", func(t *testing.T) {
+		user := &models.User{Login: "alice", Email: "alice@example.com"}
+
+		assert.Error(t, Validate(setting.PasswordPolicySettings{}, user, "Alice"))
+		assert.Error(t, Validate(setting.PasswordPolicySettings{}, user, "alice@example.com"))
+		assert.NoError(t, Validate(setting.PasswordPolicySettings{}, user, "alice1"))
+	})
+
+	t.Run("error message lists every reason", func(t *testing.T) {
+		policy := setting.PasswordPolicySettings{MinLength: 8, RequireDigit: true}
+
+		err := Validate(policy, nil, "abc")
+		assert.EqualError(t, err, "Password must be at least 8 characters long, must contain a digit")
+	})
+
+	t.Run("history", func(t *testing.T) {
+		user := &models.User{Id: 1, Login: "alice", Salt: "salt"}
+		user.Password = util.EncodePassword("current", user.Salt)
+		policy := setting.PasswordPolicySettings{HistorySize: 2}
+
+		bus.ClearBusHandlers()
+		defer bus.ClearBusHandlers()
+
+		bus.AddHandler("test", func(query *models.GetUserPasswordHistoryQuery) error {
+			assert.Equal(t, int64(1), query.UserId)
+			assert.Equal(t, 2, query.Limit)
+			query.Result = []*models.UserPasswordHistory{
+				{Password: user.Password},
+				{Password: util.EncodePassword("previous", user.Salt)},
+			}
+			return nil
+		})
+
+		assert.Equal(t, []string{"must not match any of the last 2 passwords"}, reasonsOf(t, Validate(policy, user, "previous")))
+		assert.Error(t, Validate(policy, user, "current"))
+		assert.NoError(t, Validate(policy, user, "brand new"))
+	})
+}
+
+func TestIsExpired(t *testing.T) {
+	policy := setting.PasswordPolicySettings{MaxAgeDays: 30}
+
+	old := &models.User{Password: "hash", PasswordChangedAt: time.Now().AddDate(0, 0, -31)}
+	recent := &models.User{Password: "hash", PasswordChangedAt: time.Now().AddDate(0, 0, -29)}
+	external := &models.User{PasswordChangedAt: time.Now().AddDate(-1, 0, 0)}
+
+	assert.True(t, IsExpired(policy, old))
+	assert.False(t, IsExpired(policy, recent))
+	assert.False(t, IsExpired(policy, external))
+	assert.False(t, IsExpired(setting.PasswordPolicySettings{}, old))
+}
@@ -27,6 +27,7 @@ var (
 type fileReader struct {
 	Cfg                          *DashboardsAsConfig
 	Path                         string
+	FoldersFromFilesStructure    bool
 	log                          log.Logger
 	dashboardProvisioningService dashboards.DashboardProvisioningService
 }
@@ -43,9 +44,12 @@ func NewDashboardFileReader(cfg *DashboardsAsConfig, log log.Logger) (*fileReade
 		log.Warn("[Deprecated] The folder property is deprecated. Please use path instead.")
 	}
 
+	foldersFromFilesStructure, _ := cfg.Options["foldersFromFilesStructure"].(bool)
+
 	return &fileReader{
 		Cfg:                          cfg,
 		Path:                         path,
+		FoldersFromFilesStructure:    foldersFromFilesStructure,
 		log:                          log,
 		dashboardProvisioningService: dashboards.NewProvisioningService(),
 	}, nil
@@ -96,10 +100,20 @@ func (fr *fileReader) startWalkingDisk() error {
 	fr.handleMissingDashboardFiles(provisionedDashboardRefs, filesFoundOnDisk)
 
 	sanityChecker := newProvisioningSanityChecker(fr.Cfg.Name)
+	folderIdsByDir := map[string]int64{".": folderId}
 
 	// save dashboards based on json files
 	for path, fileInfo := range filesFoundOnDisk {
-		provisioningMetadata, err := fr.saveDashboard(path, folderId, fileInfo, provisionedDashboardRefs)
+		dashFolderId := folderId
+		if fr.FoldersFromFilesStructure {
+			dashFolderId, err = fr.getOrCreateFolderIdForDir(resolvedPath, filepath.Dir(path), folderIdsByDir)
+			if err != nil {
+				fr.log.Error("failed to create folder for dashboard", "file", path, "error", err)
+				continue
+			}
+		}
+
+		provisioningMetadata, err := fr.saveDashboard(path, dashFolderId, fileInfo, provisionedDashboardRefs)
 		sanityChecker.track(provisioningMetadata)
 		if err != nil {
 			fr.log.Error("failed to save dashboard", "error", err)
@@ -244,6 +258,63 @@ func getOrCreateFolderId(cfg *DashboardsAsConfig, service dashboards.DashboardPr
 	return cmd.Result.Id, nil
 }
 
+// getOrCreateFolderIdForDir maps the directory, relative to the provisioning path,
+// to nested folders below the folder of the provider, creating any that are missing.
+func (fr *fileReader) getOrCreateFolderIdForDir(resolvedPath string, dir string, folderIdsByDir map[string]int64) (int64, error) {
+	rel, err := filepath.Rel(resolvedPath, dir)
+	if err != nil {
+		return 0, err
+	}
+
+	if folderId, ok := folderIdsByDir[rel]; ok {
+		return folderId, nil
+	}
+
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return 0, fmt.Errorf("%s is outside of the provisioning path", dir)
+	}
+
+	parentId, err := fr.getOrCreateFolderIdForDir(resolvedPath, filepath.Dir(dir), folderIdsByDir)
+	if err != nil {
+		return 0, err
+	}
+
+	folderId, err := getOrCreateSubfolderId(fr.Cfg.OrgId, parentId, filepath.Base(dir), fr.dashboardProvisioningService)
+	if err != nil {
+		return 0, err
+	}
+
+	folderIdsByDir[rel] = folderId
+	return folderId, nil
+}
+
+func getOrCreateSubfolderId(orgId int64, parentId int64, title string, service dashboards.DashboardProvisioningService) (int64, error) {
+	query := &models.GetDashboardsBySlugQuery{OrgId: orgId, Slug: models.SlugifyTitle(title)}
+	if err := bus.Dispatch(query); err != nil {
+		return 0, err
+	}
+
+	for _, dash := range query.Result {
+		if dash.IsFolder && dash.FolderId == parentId {
+			return dash.Id, nil
+		}
+	}
+
+	dash := &dashboards.SaveDashboardDTO{}
+	dash.Dashboard = models.NewDashboardFolder(title)
+	dash.Dashboard.FolderId = parentId
+	dash.Dashboard.OrgId = orgId
+	dash.Overwrite = true
+	dash.OrgId = orgId
+
+	dbDash, err := service.SaveFolderForProvisionedDashboards(dash)
+	if err != nil {
+		return 0, err
+	}
+
+	return dbDash.Id, nil
+}
+
 func resolveSymlink(fileinfo os.FileInfo, path string) (os.FileInfo, error) {
 	checkFilepath, err := filepath.EvalSymlinks(path)
 	if path != checkFilepath {
@@ -23,6 +23,7 @@ var (
 	oneDashboard      = "testdata/test-dashboards/one-dashboard"
 	containingId      = "testdata/test-dashboards/containing-id"
 	unprovision       = "testdata/test-dashboards/unprovision"
+	foldersFromFiles  = "testdata/test-dashboards/folders-from-files"
 
 	fakeService *fakeDashboardProvisioningService
 )
@@ -83,6 +84,7 @@ func TestDashboardFileReader(t *testing.T) {
 		fakeService = mockDashboardProvisioningService()
 
 		bus.AddHandler("test", mockGetDashboardQuery)
+		bus.AddHandler("test", mockGetDashboardsBySlugQuery)
 		logger := log.New("test.logger")
 
 		Convey("Reading dashboards from disk", func() {
@@ -170,6 +172,37 @@ func TestDashboardFileReader(t *testing.T) {
 				So(err, ShouldBeNil)
 			})
 
+			Convey("Can map the directory tree to nested folders", func() {
+				cfg.Options["path"] = foldersFromFiles
+				cfg.Options["foldersFromFilesStructure"] = true
+				cfg.Folder = "Provisioned"
+
+				reader, err := NewDashboardFileReader(cfg, logger)
+				So(err, ShouldBeNil)
+
+				err = reader.startWalkingDisk()
+				So(err, ShouldBeNil)
+
+				folders := map[string]*models.Dashboard{}
+				dashboards := map[string]*models.Dashboard{}
+				for _, i := range fakeService.inserted {
+					if i.Dashboard.IsFolder {
+						folders[i.Dashboard.Title] = i.Dashboard
+					} else {
+						dashboards[i.Dashboard.Title] = i.Dashboard
+					}
+				}
+
+				So(len(folders), ShouldEqual, 3)
+				So(folders["team-a"].FolderId, ShouldEqual, folders["Provisioned"].Id)
+				So(folders["service"].FolderId, ShouldEqual, folders["team-a"].Id)
+
+				So(len(dashboards), ShouldEqual, 3)
+				So(dashboards["Root dashboard"].FolderId, ShouldEqual, folders["Provisioned"].Id)
+				So(dashboards["Team A overview"].FolderId, ShouldEqual, folders["team-a"].Id)
+				So(dashboards["Service latency"].FolderId, ShouldEqual, folders["service"].Id)
+			})
+
 			Convey("Two dashboard providers should be able to provisioned the same dashboard without uid", func() {
 				cfg1 := &DashboardsAsConfig{Name: "1", Type: "file", OrgId: 1, Folder: "f1", Options: map[string]interface{}{"path": containingId}}
 				cfg2 := &DashboardsAsConfig{Name: "2", Type: "file", OrgId: 1, Folder: "f2", Options: map[string]interface{}{"path": containingId}}
@@ -406,6 +439,10 @@ func (s *fakeDashboardProvisioningService) SaveProvisionedDashboard(dto *dashboa
 }
 
 func (s *fakeDashboardProvisioningService) SaveFolderForProvisionedDashboards(dto *dashboards.SaveDashboardDTO) (*models.Dashboard, error) {
+	if dto.Dashboard.Id == 0 {
+		dto.Dashboard.Id = rand.Int63n(1000000)
+	}
+
 	s.inserted = append(s.inserted, dto)
 	return dto.Dashboard, nil
 }
@@ -449,3 +486,13 @@ func mockGetDashboardQuery(cmd *models.GetDashboardQuery) error {
 
 	return models.ErrDashboardNotFound
 }
+
+func mockGetDashboardsBySlugQuery(query *models.GetDashboardsBySlugQuery) error {
+	for _, d := range fakeService.inserted {
+		if d.Dashboard.Slug == query.Slug {
+			query.Result = append(query.Result, d.Dashboard)
+		}
+	}
+
+	return nil
+}
@@ -21,6 +21,7 @@ var (
 	versionZero                     = "testdata/version-0"
 	brokenYaml                      = "testdata/broken-yaml"
 	multipleOrgsWithDefault         = "testdata/multiple-org-default"
+	withPermissions                 = "testdata/permissions"
 
 	fakeRepo *fakeRepository
 )
@@ -130,6 +131,32 @@ func TestDatasourceAsConfig(t *testing.T) {
 			})
 		})
 
+		Convey("Configured datasource permissions", func() {
+			bus.AddHandler("test", mockGetUserByLogin)
+			bus.AddHandler("test", mockSearchTeams)
+			bus.AddHandler("test", mockUpdatePermissions)
+
+			dc := newDatasourceProvisioner(logger)
+			err := dc.applyChanges(withPermissions)
+			So(err, ShouldBeNil)
+
+			Convey("should only replace permissions of datasources that configure them", func() {
+				So(len(fakeRepo.inserted), ShouldEqual, 2)
+				So(len(fakeRepo.permissions), ShouldEqual, 1)
+
+				cmd := fakeRepo.permissions[0]
+				So(cmd.OrgId, ShouldEqual, 1)
+				So(cmd.DatasourceId, ShouldEqual, 1)
+				So(len(cmd.Items), ShouldEqual, 3)
+				So(cmd.Items[0].UserId, ShouldEqual, 10)
+				So(cmd.Items[0].Permission, ShouldEqual, models.DsPermissionAdmin)
+				So(cmd.Items[1].TeamId, ShouldEqual, 20)
+				So(cmd.Items[1].Permission, ShouldEqual, models.DsPermissionQuery)
+				So(*cmd.Items[2].Role, ShouldEqual, models.ROLE_EDITOR)
+				So(cmd.Items[2].Permission, ShouldEqual, models.DsPermissionQuery)
+			})
+		})
+
 		Convey("broken yaml should return error", func() {
 			reader := &configReader{}
 			_, err := reader.readConfig(brokenYaml)
@@ -234,6 +261,8 @@ type fakeRepository struct {
 	deleted  []*models.DeleteDataSourceByNameCommand
 	updated  []*models.UpdateDataSourceCommand
 
+	permissions []*models.UpdateDataSourcePermissionsCommand
+
 	loadAll []*models.DataSource
 }
 
@@ -249,6 +278,7 @@ func mockUpdate(cmd *models.UpdateDataSourceCommand) error {
 
 func mockInsert(cmd *models.AddDataSourceCommand) error {
 	fakeRepo.inserted = append(fakeRepo.inserted, cmd)
+	cmd.Result = &models.DataSource{Id: int64(len(fakeRepo.inserted)), OrgId: cmd.OrgId, Name: cmd.Name}
 	return nil
 }
 
@@ -267,3 +297,25 @@ func mockGet(cmd *models.GetDataSourceByNameQuery) error {
 
 	return models.ErrDataSourceNotFound
 }
+
+func mockUpdatePermissions(cmd *models.UpdateDataSourcePermissionsCommand) error {
+	fakeRepo.permissions = append(fakeRepo.permissions, cmd)
+	return nil
+}
+
+func mockGetUserByLogin(query *models.GetUserByLoginQuery) error {
+	if query.LoginOrEmail == "alice" {
+		query.Result = &models.User{Id: 10, Login: "alice"}
+		return nil
+	}
+
+	return models.ErrUserNotFound
+}
+
+func mockSearchTeams(query *models.SearchTeamsQuery) error {
+	if query.Name == "Ops" && query.OrgId == 1 {
+		query.Result.Teams = []*models.TeamDTO{{Id: 20, OrgId: 1, Name: "Ops"}}
+	}
+
+	return nil
+}
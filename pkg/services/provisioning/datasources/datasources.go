@@ -2,6 +2,7 @@ package datasources
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/Seasheller/grafana/pkg/bus"
 
@@ -12,6 +13,7 @@ import (
 
 var (
 	ErrInvalidConfigToManyDefault = errors.New("datasource.yaml config is invalid. Only one datasource per organization can be marked as default")
+	ErrInvalidPermission          = errors.New("datasource.yaml config is invalid. Permissions need one of user, team or role and a permission of Query or Admin")
 )
 
 func Provision(configDirectory string) error {
@@ -43,24 +45,94 @@ func (dc *DatasourceProvisioner) apply(cfg *DatasourcesAsConfig) error {
 			return err
 		}
 
+		var datasourceId int64
 		if err == models.ErrDataSourceNotFound {
 			dc.log.Info("inserting datasource from configuration ", "name", ds.Name)
 			insertCmd := createInsertCommand(ds)
 			if err := bus.Dispatch(insertCmd); err != nil {
 				return err
 			}
+			if insertCmd.Result != nil {
+				datasourceId = insertCmd.Result.Id
+			}
 		} else {
 			dc.log.Debug("updating datasource from configuration", "name", ds.Name)
 			updateCmd := createUpdateCommand(ds, cmd.Result.Id)
 			if err := bus.Dispatch(updateCmd); err != nil {
 				return err
 			}
+			datasourceId = cmd.Result.Id
+		}
+
+		if ds.Permissions != nil {
+			if err := dc.applyPermissions(ds, datasourceId); err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
+// applyPermissions enables permissions for the data source and replaces
+// its ACL. Users and teams that don't exist yet are skipped.
+func (dc *DatasourceProvisioner) applyPermissions(ds *DataSourceFromConfig, datasourceId int64) error {
+	items := make([]*models.DataSourceAcl, 0, len(ds.Permissions))
+
+	for _, p := range ds.Permissions {
+		item := &models.DataSourceAcl{}
+
+		switch strings.ToLower(p.Permission) {
+		case "query":
+			item.Permission = models.DsPermissionQuery
+		case "admin":
+			item.Permission = models.DsPermissionAdmin
+		default:
+			return ErrInvalidPermission
+		}
+
+		switch {
+		case p.User != "" && p.Team == "" && p.Role == "":
+			query := &models.GetUserByLoginQuery{LoginOrEmail: p.User}
+			if err := bus.Dispatch(query); err != nil {
+				if err == models.ErrUserNotFound {
+					dc.log.Warn("skipping datasource permission for unknown user", "datasource", ds.Name, "user", p.User)
+					continue
+				}
+				return err
+			}
+			item.UserId = query.Result.Id
+		case p.Team != "" && p.User == "" && p.Role == "":
+			query := &models.SearchTeamsQuery{OrgId: ds.OrgId, Name: p.Team, Limit: 1, Page: 1}
+			if err := bus.Dispatch(query); err != nil {
+				return err
+			}
+			if len(query.Result.Teams) == 0 {
+				dc.log.Warn("skipping datasource permission for unknown team", "datasource", ds.Name, "team", p.Team)
+				continue
+			}
+			item.TeamId = query.Result.Teams[0].Id
+		case p.Role != "" && p.User == "" && p.Team == "":
+			role := models.RoleType(p.Role)
+			if !role.IsValid() {
+				return ErrInvalidPermission
+			}
+			item.Role = &role
+		default:
+			return ErrInvalidPermission
+		}
+
+		items = append(items, item)
+	}
+
+	dc.log.Debug("updating datasource permissions from configuration", "name", ds.Name, "count", len(items))
+	return bus.Dispatch(&models.UpdateDataSourcePermissionsCommand{
+		OrgId:        ds.OrgId,
+		DatasourceId: datasourceId,
+		Items:        items,
+	})
+}
+
 func (dc *DatasourceProvisioner) applyChanges(configPath string) error {
 	configs, err := dc.cfgProvider.readConfig(configPath)
 	if err != nil {
@@ -42,6 +42,17 @@ type DataSourceFromConfig struct {
 	JsonData          map[string]interface{}
 	SecureJsonData    map[string]string
 	Editable          bool
+
+	// Permissions replaces the data source ACL when set.
+	// nil leaves the permissions as they are.
+	Permissions []*DataSourcePermissionFromConfig
+}
+
+type DataSourcePermissionFromConfig struct {
+	User       string
+	Team       string
+	Role       string
+	Permission string
 }
 
 type DatasourcesAsConfigV0 struct {
@@ -107,6 +118,15 @@ type DataSourceFromConfigV1 struct {
 	JsonData          values.JSONValue      `json:"jsonData" yaml:"jsonData"`
 	SecureJsonData    values.StringMapValue `json:"secureJsonData" yaml:"secureJsonData"`
 	Editable          values.BoolValue      `json:"editable" yaml:"editable"`
+
+	Permissions []*DataSourcePermissionFromConfigV1 `json:"permissions" yaml:"permissions"`
+}
+
+type DataSourcePermissionFromConfigV1 struct {
+	User       values.StringValue `json:"user" yaml:"user"`
+	Team       values.StringValue `json:"team" yaml:"team"`
+	Role       values.StringValue `json:"role" yaml:"role"`
+	Permission values.StringValue `json:"permission" yaml:"permission"`
 }
 
 func (cfg *DatasourcesAsConfigV1) mapToDatasourceFromConfig(apiVersion int64) *DatasourcesAsConfig {
@@ -119,6 +139,19 @@ func (cfg *DatasourcesAsConfigV1) mapToDatasourceFromConfig(apiVersion int64) *D
 	}
 
 	for _, ds := range cfg.Datasources {
+		var permissions []*DataSourcePermissionFromConfig
+		if ds.Permissions != nil {
+			permissions = make([]*DataSourcePermissionFromConfig, 0, len(ds.Permissions))
+			for _, p := range ds.Permissions {
+				permissions = append(permissions, &DataSourcePermissionFromConfig{
+					User:       p.User.Value(),
+					Team:       p.Team.Value(),
+					Role:       p.Role.Value(),
+					Permission: p.Permission.Value(),
+				})
+			}
+		}
+
 		r.Datasources = append(r.Datasources, &DataSourceFromConfig{
 			OrgId:             ds.OrgId.Value(),
 			Name:              ds.Name.Value(),
@@ -137,6 +170,7 @@ func (cfg *DatasourcesAsConfigV1) mapToDatasourceFromConfig(apiVersion int64) *D
 			SecureJsonData:    ds.SecureJsonData.Value(),
 			Editable:          ds.Editable.Value(),
 			Version:           ds.Version.Value(),
+			Permissions:       permissions,
 		})
 
 		// Using Raw value for the warnings here so that even if it uses env interpolation and the env var is empty
@@ -0,0 +1,87 @@
+package plugins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/plugins"
+	"gopkg.in/yaml.v2"
+)
+
+type configReader struct {
+	log log.Logger
+}
+
+func (cr *configReader) readConfig(path string) ([]*pluginsAsConfig, error) {
+	var apps []*pluginsAsConfig
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		cr.log.Error("can't read plugin provisioning files from directory", "path", path, "error", err)
+		return apps, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			app, err := cr.parsePluginConfig(path, file)
+			if err != nil {
+				return nil, err
+			}
+
+			if app != nil {
+				apps = append(apps, app)
+			}
+		}
+	}
+
+	if err := validateApps(apps); err != nil {
+		return nil, err
+	}
+
+	return apps, nil
+}
+
+func (cr *configReader) parsePluginConfig(path string, file os.FileInfo) (*pluginsAsConfig, error) {
+	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
+	yamlFile, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *pluginsAsConfigV1
+	if err := yaml.Unmarshal(yamlFile, &cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToPluginsFromConfig(), nil
+}
+
+// validateApps checks that the provisioned plugins are installed apps, and
+// defaults the org to the main org.
+func validateApps(apps []*pluginsAsConfig) error {
+	for i := range apps {
+		for _, app := range apps[i].Apps {
+			if app.PluginId == "" {
+				return ErrMissingPluginType
+			}
+
+			if _, exists := plugins.Apps[app.PluginId]; !exists {
+				return fmt.Errorf("plugin not installed: %q", app.PluginId)
+			}
+
+			if app.OrgId < 1 {
+				if app.OrgName == "" {
+					app.OrgId = 1
+				} else {
+					app.OrgId = 0
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package plugins
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/plugins"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	correctProperties = "./testdata/correct-properties"
+	brokenYaml        = "./testdata/broken-yaml"
+	unknownApp        = "./testdata/unknown-app"
+	emptyFolder       = "./testdata/empty-folder"
+)
+
+func TestAppsAsConfig(t *testing.T) {
+	Convey("Testing apps as configuration", t, func() {
+		updated := make([]*models.UpdatePluginSettingCmd, 0)
+		bus.ClearBusHandlers()
+		bus.AddHandler("test", func(cmd *models.UpdatePluginSettingCmd) error {
+			updated = append(updated, cmd)
+			return nil
+		})
+		released := make([]*models.UpdatePluginSettingReadOnlyCmd, 0)
+		bus.AddHandler("test", func(cmd *models.UpdatePluginSettingReadOnlyCmd) error {
+			released = append(released, cmd)
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetPluginSettingsQuery) error {
+			query.Result = []*models.PluginSettingInfoDTO{
+				{OrgId: 2, PluginId: "test-app", ReadOnly: true},
+				{OrgId: 4, PluginId: "test-app", ReadOnly: true},
+				{OrgId: 4, PluginId: "other-app", ReadOnly: false},
+			}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetOrgByNameQuery) error {
+			if query.Name != "Second Org." {
+				return models.ErrOrgNotFound
+			}
+			query.Result = &models.Org{Id: 3, Name: query.Name}
+			return nil
+		})
+
+		plugins.Apps = map[string]*plugins.AppPlugin{
+			"test-app": {FrontendPluginBase: plugins.FrontendPluginBase{
+				PluginBase: plugins.PluginBase{Id: "test-app", Info: plugins.PluginInfo{Version: "1.2.0"}},
+			}},
+		}
+
+		Convey("Can read correct properties", func() {
+			_ = os.Setenv("TEST_APP_API_KEY", "secret")
+			cr := &configReader{log: log.New("test logger")}
+			cfg, err := cr.readConfig(correctProperties)
+			_ = os.Unsetenv("TEST_APP_API_KEY")
+			So(err, ShouldBeNil)
+			So(cfg, ShouldHaveLength, 1)
+
+			apps := cfg[0].Apps
+			So(apps, ShouldHaveLength, 2)
+
+			So(apps[0].PluginId, ShouldEqual, "test-app")
+			So(apps[0].OrgId, ShouldEqual, 2)
+			So(apps[0].Enabled, ShouldBeTrue)
+			So(apps[0].Pinned, ShouldBeTrue)
+			So(apps[0].JsonData, ShouldResemble, map[string]interface{}{"apiUrl": "https://api.example.com", "timeout": 30})
+			So(apps[0].SecureJsonData, ShouldResemble, map[string]string{"apiKey": "secret"})
+
+			So(apps[1].OrgId, ShouldEqual, 0)
+			So(apps[1].OrgName, ShouldEqual, "Second Org.")
+			So(apps[1].Enabled, ShouldBeFalse)
+			So(apps[1].Pinned, ShouldBeFalse)
+		})
+
+		Convey("Should fail on broken yaml", func() {
+			cr := &configReader{log: log.New("test logger")}
+			_, err := cr.readConfig(brokenYaml)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Should fail on apps that are not installed", func() {
+			cr := &configReader{log: log.New("test logger")}
+			_, err := cr.readConfig(unknownApp)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `plugin not installed: "unknown-app"`)
+		})
+
+		Convey("Empty folder should not return an error", func() {
+			cr := &configReader{log: log.New("test logger")}
+			cfg, err := cr.readConfig(emptyFolder)
+			So(err, ShouldBeNil)
+			So(cfg, ShouldHaveLength, 0)
+		})
+
+		Convey("When provisioning the apps", func() {
+			_ = os.Setenv("TEST_APP_API_KEY", "secret")
+			ap := newAppProvisioner(log.New("test logger"))
+			err := ap.applyChanges(correctProperties)
+			_ = os.Unsetenv("TEST_APP_API_KEY")
+			So(err, ShouldBeNil)
+			So(updated, ShouldHaveLength, 2)
+
+			Convey("Should save the settings as read-only", func() {
+				cmd := updated[0]
+				So(cmd.PluginId, ShouldEqual, "test-app")
+				So(cmd.OrgId, ShouldEqual, 2)
+				So(cmd.Enabled, ShouldBeTrue)
+				So(cmd.Pinned, ShouldBeTrue)
+				So(cmd.ReadOnly, ShouldBeTrue)
+				So(cmd.PluginVersion, ShouldEqual, "1.2.0")
+				So(cmd.JsonData["apiUrl"], ShouldEqual, "https://api.example.com")
+				So(cmd.SecureJsonData, ShouldResemble, map[string]string{"apiKey": "secret"})
+			})
+
+			Convey("Should resolve the org by its name", func() {
+				cmd := updated[1]
+				So(cmd.OrgId, ShouldEqual, 3)
+				So(cmd.Enabled, ShouldBeFalse)
+				So(cmd.Pinned, ShouldBeFalse)
+				So(cmd.ReadOnly, ShouldBeTrue)
+			})
+
+			Convey("Should make the apps removed from the config editable", func() {
+				So(released, ShouldHaveLength, 1)
+				So(released[0].OrgId, ShouldEqual, 4)
+				So(released[0].PluginId, ShouldEqual, "test-app")
+				So(released[0].ReadOnly, ShouldBeFalse)
+			})
+		})
+	})
+}
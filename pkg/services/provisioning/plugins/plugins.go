@@ -0,0 +1,110 @@
+package plugins
+
+import (
+	"errors"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/plugins"
+)
+
+var (
+	ErrMissingPluginType = errors.New("plugins.yaml config is invalid. Every app needs the id of the plugin as type")
+)
+
+// Provision enables the app plugins in the config files of the directory, and
+// sets their settings, which become read-only in the API. The settings of
+// apps removed from the config files can be changed in the API again.
+func Provision(configDirectory string) error {
+	ap := newAppProvisioner(log.New("provisioning.plugins"))
+	return ap.applyChanges(configDirectory)
+}
+
+type AppProvisioner struct {
+	log         log.Logger
+	cfgProvider *configReader
+}
+
+func newAppProvisioner(log log.Logger) AppProvisioner {
+	return AppProvisioner{
+		log:         log,
+		cfgProvider: &configReader{log: log},
+	}
+}
+
+// pluginSettingKey identifies the settings of a plugin in an org.
+type pluginSettingKey struct {
+	orgId    int64
+	pluginId string
+}
+
+func (ap *AppProvisioner) apply(cfg *pluginsAsConfig, provisioned map[pluginSettingKey]bool) error {
+	for _, app := range cfg.Apps {
+		if app.OrgId == 0 && app.OrgName != "" {
+			getOrg := &models.GetOrgByNameQuery{Name: app.OrgName}
+			if err := bus.Dispatch(getOrg); err != nil {
+				return err
+			}
+			app.OrgId = getOrg.Result.Id
+		}
+
+		ap.log.Debug("updating app from configuration", "type", app.PluginId, "orgId", app.OrgId)
+		cmd := &models.UpdatePluginSettingCmd{
+			OrgId:          app.OrgId,
+			PluginId:       app.PluginId,
+			Enabled:        app.Enabled,
+			Pinned:         app.Pinned,
+			JsonData:       app.JsonData,
+			SecureJsonData: app.SecureJsonData,
+			PluginVersion:  plugins.Apps[app.PluginId].Info.Version,
+			ReadOnly:       true,
+		}
+		if err := bus.Dispatch(cmd); err != nil {
+			return err
+		}
+
+		provisioned[pluginSettingKey{orgId: app.OrgId, pluginId: app.PluginId}] = true
+	}
+
+	return nil
+}
+
+// releaseRemovedApps makes the settings that were provisioned before, but
+// aren't in the config files anymore, editable again.
+func (ap *AppProvisioner) releaseRemovedApps(provisioned map[pluginSettingKey]bool) error {
+	query := &models.GetPluginSettingsQuery{}
+	if err := bus.Dispatch(query); err != nil {
+		return err
+	}
+
+	for _, ps := range query.Result {
+		if !ps.ReadOnly || provisioned[pluginSettingKey{orgId: ps.OrgId, pluginId: ps.PluginId}] {
+			continue
+		}
+
+		ap.log.Debug("app removed from configuration", "type", ps.PluginId, "orgId", ps.OrgId)
+		cmd := &models.UpdatePluginSettingReadOnlyCmd{OrgId: ps.OrgId, PluginId: ps.PluginId, ReadOnly: false}
+		if err := bus.Dispatch(cmd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ap *AppProvisioner) applyChanges(configPath string) error {
+	configs, err := ap.cfgProvider.readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	provisioned := map[pluginSettingKey]bool{}
+	for _, cfg := range configs {
+		if err := ap.apply(cfg, provisioned); err != nil {
+			return err
+		}
+	}
+
+	return ap.releaseRemovedApps(provisioned)
+}
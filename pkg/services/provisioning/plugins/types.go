@@ -0,0 +1,61 @@
+package plugins
+
+import "github.com/Seasheller/grafana/pkg/services/provisioning/values"
+
+type configVersion struct {
+	ApiVersion int64 `json:"apiVersion" yaml:"apiVersion"`
+}
+
+type pluginsAsConfig struct {
+	Apps []*appFromConfig
+}
+
+type appFromConfig struct {
+	OrgId          int64
+	OrgName        string
+	PluginId       string
+	Enabled        bool
+	Pinned         bool
+	JsonData       map[string]interface{}
+	SecureJsonData map[string]string
+}
+
+type pluginsAsConfigV1 struct {
+	configVersion
+
+	Apps []*appFromConfigV1 `json:"apps" yaml:"apps"`
+}
+
+type appFromConfigV1 struct {
+	OrgId          values.Int64Value     `json:"orgId" yaml:"orgId"`
+	OrgName        values.StringValue    `json:"orgName" yaml:"orgName"`
+	Type           values.StringValue    `json:"type" yaml:"type"`
+	Disabled       values.BoolValue      `json:"disabled" yaml:"disabled"`
+	Pinned         values.BoolValue      `json:"pinned" yaml:"pinned"`
+	JsonData       values.JSONValue      `json:"jsonData" yaml:"jsonData"`
+	SecureJsonData values.StringMapValue `json:"secureJsonData" yaml:"secureJsonData"`
+}
+
+func (cfg *pluginsAsConfigV1) mapToPluginsFromConfig() *pluginsAsConfig {
+	r := &pluginsAsConfig{}
+
+	if cfg == nil {
+		return r
+	}
+
+	for _, app := range cfg.Apps {
+		r.Apps = append(r.Apps, &appFromConfig{
+			OrgId:    app.OrgId.Value(),
+			OrgName:  app.OrgName.Value(),
+			PluginId: app.Type.Value(),
+			Enabled:  !app.Disabled.Value(),
+			// provisioned apps are pinned to the side menu unless the
+			// config says otherwise
+			Pinned:         app.Pinned.Raw == "" || app.Pinned.Value(),
+			JsonData:       app.JsonData.Value(),
+			SecureJsonData: app.SecureJsonData.Value(),
+		})
+	}
+
+	return r
+}
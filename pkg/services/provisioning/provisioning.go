@@ -12,6 +12,7 @@ import (
 	"github.com/Seasheller/grafana/pkg/services/provisioning/dashboards"
 	"github.com/Seasheller/grafana/pkg/services/provisioning/datasources"
 	"github.com/Seasheller/grafana/pkg/services/provisioning/notifiers"
+	"github.com/Seasheller/grafana/pkg/services/provisioning/plugins"
 	"github.com/Seasheller/grafana/pkg/setting"
 )
 
@@ -30,6 +31,7 @@ func init() {
 		},
 		notifiers.Provision,
 		datasources.Provision,
+		plugins.Provision,
 	))
 }
 
@@ -37,12 +39,14 @@ func NewProvisioningServiceImpl(
 	newDashboardProvisioner DashboardProvisionerFactory,
 	provisionNotifiers func(string) error,
 	provisionDatasources func(string) error,
+	provisionPlugins func(string) error,
 ) *provisioningServiceImpl {
 	return &provisioningServiceImpl{
 		log:                     log.New("provisioning"),
 		newDashboardProvisioner: newDashboardProvisioner,
 		provisionNotifiers:      provisionNotifiers,
 		provisionDatasources:    provisionDatasources,
+		provisionPlugins:        provisionPlugins,
 	}
 }
 
@@ -54,6 +58,7 @@ type provisioningServiceImpl struct {
 	dashboardProvisioner    DashboardProvisioner
 	provisionNotifiers      func(string) error
 	provisionDatasources    func(string) error
+	provisionPlugins        func(string) error
 	mutex                   sync.Mutex
 }
 
@@ -63,6 +68,11 @@ func (ps *provisioningServiceImpl) Init() error {
 		return err
 	}
 
+	err = ps.ProvisionPlugins()
+	if err != nil {
+		return err
+	}
+
 	err = ps.ProvisionNotifications()
 	if err != nil {
 		return err
@@ -106,6 +116,12 @@ func (ps *provisioningServiceImpl) ProvisionDatasources() error {
 	return errutil.Wrap("Datasource provisioning error", err)
 }
 
+func (ps *provisioningServiceImpl) ProvisionPlugins() error {
+	appPath := path.Join(ps.Cfg.ProvisioningPath, "plugins")
+	err := ps.provisionPlugins(appPath)
+	return errutil.Wrap("App provisioning error", err)
+}
+
 func (ps *provisioningServiceImpl) ProvisionNotifications() error {
 	alertNotificationsPath := path.Join(ps.Cfg.ProvisioningPath, "notifiers")
 	err := ps.provisionNotifiers(alertNotificationsPath)
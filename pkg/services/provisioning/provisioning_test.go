@@ -97,6 +97,7 @@ func setup() *serviceTestStruct {
 		},
 		nil,
 		nil,
+		nil,
 	)
 	serviceTest.service.Cfg = setting.NewCfg()
 
@@ -0,0 +1,118 @@
+// Package publicdashboards checks what the access tokens of public dashboards
+// allow. A token only allows running the queries of the panels of its
+// dashboard, as they are saved, with the saved values of the template
+// variables.
+package publicdashboards
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/snapshots"
+)
+
+const mixedDatasource = "-- Mixed --"
+
+// requestOnlyKeys are added to the queries by the browser and are not
+// compared with the saved queries.
+var requestOnlyKeys = map[string]bool{
+	"refId":         true,
+	"datasource":    true,
+	"datasourceId":  true,
+	"intervalMs":    true,
+	"maxDataPoints": true,
+}
+
+// ValidateQueries returns models.ErrPublicDashboardQueryDenied unless every
+// query is one of the panel queries of the dashboard that use the data source.
+func ValidateQueries(dash *models.Dashboard, ds *models.DataSource, queries []*simplejson.Json) error {
+	allowed := panelQueries(dash.Data, ds)
+
+	for _, query := range queries {
+		if !isAllowed(query.Interface(), allowed) {
+			return models.ErrPublicDashboardQueryDenied
+		}
+	}
+
+	return nil
+}
+
+// panelQueries returns the queries of the panels that use the data source,
+// with the template variables replaced the way the browser replaces them.
+func panelQueries(data *simplejson.Json, ds *models.DataSource) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0)
+
+	for _, panel := range snapshots.GetPanels(data) {
+		panelDatasource := interpolateName(data, panel.Get("datasource").Interface())
+
+		for _, t := range panel.Get("targets").MustArray() {
+			target := simplejson.NewFromAny(t)
+
+			dsName := panelDatasource
+			if dsName == mixedDatasource {
+				dsName = interpolateName(data, target.Get("datasource").Interface())
+			}
+
+			if !usesDatasource(ds, dsName) {
+				continue
+			}
+
+			if query, ok := normalize(snapshots.Interpolate(data, target.Interface(), ds.Type)); ok {
+				result = append(result, query)
+			}
+		}
+	}
+
+	return result
+}
+
+func isAllowed(query interface{}, allowed []map[string]interface{}) bool {
+	normalized, ok := normalize(query)
+	if !ok {
+		return false
+	}
+
+	for _, candidate := range allowed {
+		if reflect.DeepEqual(normalized, candidate) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalize decodes the query the way a query sent by the browser is decoded
+// and drops the keys that are only set in requests.
+func normalize(query interface{}) (map[string]interface{}, bool) {
+	data, err := json.Marshal(query)
+	if err != nil {
+		return nil, false
+	}
+
+	result := make(map[string]interface{})
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, false
+	}
+
+	for key := range requestOnlyKeys {
+		delete(result, key)
+	}
+
+	return result, true
+}
+
+func interpolateName(data *simplejson.Json, name interface{}) string {
+	s, _ := name.(string)
+	return snapshots.Interpolate(data, s, "").(string)
+}
+
+// usesDatasource returns true if the data source name of a panel refers to
+// the data source. Panels without a data source use the default one.
+func usesDatasource(ds *models.DataSource, name string) bool {
+	if name == "" || name == "default" {
+		return ds.IsDefault
+	}
+	return name == ds.Name
+}
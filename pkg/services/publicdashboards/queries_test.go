@@ -0,0 +1,104 @@
+package publicdashboards
+
+import (
+	"testing"
+
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	"github.com/Seasheller/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testDashboardJSON = `{
+	"panels": [
+		{
+			"id": 1,
+			"datasource": "mysql",
+			"targets": [{"refId": "A", "rawSql": "SELECT value FROM metrics WHERE host = '$host'", "format": "time_series"}]
+		},
+		{
+			"id": 2,
+			"type": "row",
+			"panels": [
+				{
+					"id": 3,
+					"datasource": "-- Mixed --",
+					"targets": [
+						{"refId": "A", "datasource": "$ds", "rawSql": "SELECT 1"},
+						{"refId": "B", "datasource": "testdata", "scenarioId": "random_walk"}
+					]
+				}
+			]
+		},
+		{
+			"id": 4,
+			"targets": [{"refId": "A", "scenarioId": "csv_metric_values", "stringInput": "1,2,3"}]
+		}
+	],
+	"templating": {
+		"list": [
+			{"name": "host", "current": {"value": "web1"}},
+			{"name": "ds", "current": {"value": "mysql"}}
+		]
+	}
+}`
+
+func TestValidateQueries(t *testing.T) {
+	Convey("Given a dashboard with panel queries", t, func() {
+		data, err := simplejson.NewJson([]byte(testDashboardJSON))
+		So(err, ShouldBeNil)
+		dash := &models.Dashboard{Id: 1, Data: data}
+
+		mysql := &models.DataSource{Id: 1, Name: "mysql", Type: "mysql"}
+		testdata := &models.DataSource{Id: 2, Name: "testdata", Type: "testdata", IsDefault: true}
+
+		query := func(model string) *simplejson.Json {
+			q, err := simplejson.NewJson([]byte(model))
+			So(err, ShouldBeNil)
+			return q
+		}
+
+		Convey("Should allow the queries of the panels with the variables replaced", func() {
+			queries := []*simplejson.Json{
+				query(`{"refId": "A", "datasourceId": 1, "intervalMs": 1000, "maxDataPoints": 500, "rawSql": "SELECT value FROM metrics WHERE host = 'web1'", "format": "time_series"}`),
+				query(`{"refId": "C", "datasourceId": 1, "rawSql": "SELECT 1"}`),
+			}
+			So(ValidateQueries(dash, mysql, queries), ShouldBeNil)
+		})
+
+		Convey("Should allow queries of panels using the default data source", func() {
+			queries := []*simplejson.Json{
+				query(`{"refId": "A", "datasourceId": 2, "scenarioId": "csv_metric_values", "stringInput": "1,2,3"}`),
+				query(`{"refId": "B", "datasourceId": 2, "datasource": "testdata", "scenarioId": "random_walk"}`),
+			}
+			So(ValidateQueries(dash, testdata, queries), ShouldBeNil)
+		})
+
+		Convey("Should deny changed queries", func() {
+			queries := []*simplejson.Json{
+				query(`{"refId": "A", "datasourceId": 1, "rawSql": "SELECT password FROM users", "format": "time_series"}`),
+			}
+			So(ValidateQueries(dash, mysql, queries), ShouldEqual, models.ErrPublicDashboardQueryDenied)
+		})
+
+		Convey("Should deny other values of the variables", func() {
+			queries := []*simplejson.Json{
+				query(`{"refId": "A", "datasourceId": 1, "rawSql": "SELECT value FROM metrics WHERE host = 'db1'", "format": "time_series"}`),
+			}
+			So(ValidateQueries(dash, mysql, queries), ShouldEqual, models.ErrPublicDashboardQueryDenied)
+		})
+
+		Convey("Should deny queries with added fields", func() {
+			queries := []*simplejson.Json{
+				query(`{"refId": "A", "datasourceId": 2, "scenarioId": "csv_metric_values", "stringInput": "1,2,3", "alias": "x"}`),
+			}
+			So(ValidateQueries(dash, testdata, queries), ShouldEqual, models.ErrPublicDashboardQueryDenied)
+		})
+
+		Convey("Should deny queries of panels on another data source", func() {
+			queries := []*simplejson.Json{
+				query(`{"refId": "A", "datasourceId": 2, "rawSql": "SELECT 1"}`),
+			}
+			So(ValidateQueries(dash, testdata, queries), ShouldEqual, models.ErrPublicDashboardQueryDenied)
+		})
+	})
+}
@@ -0,0 +1,124 @@
+package reports
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"io"
+)
+
+// writePDF writes a PDF document with one page per image. Every page has
+// the size of its image, one pixel being one point. Images are stored as
+// flate compressed RGB, so no PDF library is needed.
+func writePDF(w io.Writer, images []image.Image) error {
+	pdf := &pdfWriter{}
+	pdf.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 and 2 are the catalog and the page tree, followed by
+	// three objects per page: the page, its content and its image.
+	pageIds := make([]int, len(images))
+	for i := range images {
+		pageIds[i] = 3 + i*3
+	}
+
+	pdf.writeObject(1, []byte("<< /Type /Catalog /Pages 2 0 R >>"))
+
+	kids := new(bytes.Buffer)
+	for _, id := range pageIds {
+		fmt.Fprintf(kids, "%d 0 R ", id)
+	}
+	pdf.writeObject(2, []byte(fmt.Sprintf("<< /Type /Pages /Kids [ %s] /Count %d >>", kids.String(), len(images))))
+
+	for i, img := range images {
+		pageId := pageIds[i]
+		width, height := img.Bounds().Dx(), img.Bounds().Dy()
+
+		pdf.writeObject(pageId, []byte(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /XObject << /Im0 %d 0 R >> >> /Contents %d 0 R >>",
+			width, height, pageId+2, pageId+1)))
+
+		content := fmt.Sprintf("q %d 0 0 %d 0 0 cm /Im0 Do Q", width, height)
+		pdf.writeStream(pageId+1, "", []byte(content))
+
+		pixels, err := compressRGB(img)
+		if err != nil {
+			return err
+		}
+		pdf.writeStream(pageId+2, fmt.Sprintf(
+			"/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode ",
+			width, height), pixels)
+	}
+
+	pdf.writeTrailer()
+
+	_, err := w.Write(pdf.buf.Bytes())
+	return err
+}
+
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (p *pdfWriter) writeObject(id int, body []byte) {
+	p.beginObject(id)
+	p.buf.Write(body)
+	p.buf.WriteString("\nendobj\n")
+}
+
+func (p *pdfWriter) writeStream(id int, dict string, data []byte) {
+	p.beginObject(id)
+	fmt.Fprintf(&p.buf, "<< %s/Length %d >>\nstream\n", dict, len(data))
+	p.buf.Write(data)
+	p.buf.WriteString("\nendstream\nendobj\n")
+}
+
+func (p *pdfWriter) beginObject(id int) {
+	for len(p.offsets) < id {
+		p.offsets = append(p.offsets, 0)
+	}
+	p.offsets[id-1] = p.buf.Len()
+	fmt.Fprintf(&p.buf, "%d 0 obj\n", id)
+}
+
+func (p *pdfWriter) writeTrailer() {
+	xref := p.buf.Len()
+	fmt.Fprintf(&p.buf, "xref\n0 %d\n0000000000 65535 f \n", len(p.offsets)+1)
+	for _, offset := range p.offsets {
+		fmt.Fprintf(&p.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&p.buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets)+1, xref)
+}
+
+// compressRGB returns the pixels of the image as compressed RGB, with
+// transparent pixels drawn on white.
+func compressRGB(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+
+	bounds := img.Bounds()
+	row := make([]byte, bounds.Dx()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			i := (x - bounds.Min.X) * 3
+			row[i] = onWhite(r, a)
+			row[i+1] = onWhite(g, a)
+			row[i+2] = onWhite(b, a)
+		}
+		if _, err := zw.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// onWhite blends an alpha premultiplied color channel onto white.
+func onWhite(c, a uint32) byte {
+	return byte((c + 0xffff - a) >> 8)
+}
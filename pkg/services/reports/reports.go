@@ -0,0 +1,114 @@
+// Package reports sends scheduled dashboard reports by email. Dashboards are
+// rendered through the rendering service, either as a whole or panel by
+// panel, and sent as PNG images or assembled into a PDF document.
+package reports
+
+import (
+	"context"
+	"time"
+
+	"github.com/robfig/cron"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/infra/serverlock"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/registry"
+	"github.com/Seasheller/grafana/pkg/services/rendering"
+	"github.com/Seasheller/grafana/pkg/setting"
+)
+
+func init() {
+	registry.RegisterService(&ReportService{})
+}
+
+// ReportService sends the reports that are due every minute, on only one
+// Grafana server at a time, and handles models.SendReportCommand.
+type ReportService struct {
+	Bus               bus.Bus                       `inject:""`
+	RenderService     rendering.Service             `inject:""`
+	ServerLockService *serverlock.ServerLockService `inject:""`
+
+	log log.Logger
+}
+
+func (s *ReportService) Init() error {
+	s.log = log.New("reports")
+	s.Bus.AddHandlerCtx(s.SendReport)
+	return nil
+}
+
+func (s *ReportService) IsDisabled() bool {
+	return !setting.ReportsEnabled
+}
+
+func (s *ReportService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Minute)
+
+	for {
+		select {
+		case <-ticker.C:
+			// The lock is held for half the interval, so that servers
+			// ticking a few seconds apart don't skip a minute.
+			err := s.ServerLockService.LockAndExecute(ctx, "send scheduled reports", time.Minute/2, func() {
+				s.sendDueReports(ctx, time.Now())
+			})
+			if err != nil {
+				s.log.Error("Failed to acquire reports lock", "error", err)
+			}
+		case <-ctx.Done():
+			ticker.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// sendDueReports sends the reports scheduled at or before now. Each report
+// is moved to its next run before it is sent, so a report that fails is not
+// retried until then.
+func (s *ReportService) sendDueReports(ctx context.Context, now time.Time) {
+	query := models.GetDueReportsQuery{Now: now}
+	if err := s.Bus.Dispatch(&query); err != nil {
+		s.log.Error("Failed to get due reports", "error", err)
+		return
+	}
+
+	for _, report := range query.Result {
+		next, err := NextRun(report.Schedule, now)
+		if err != nil {
+			s.log.Error("Invalid report schedule", "reportId", report.Id, "schedule", report.Schedule, "error", err)
+			continue
+		}
+
+		if err := s.Bus.Dispatch(&models.SetReportNextRunCommand{Id: report.Id, NextRun: next}); err != nil {
+			s.log.Error("Failed to schedule report", "reportId", report.Id, "error", err)
+			continue
+		}
+
+		cmd := models.SendReportCommand{Report: report, Scheduled: true}
+		if err := s.SendReport(ctx, &cmd); err != nil {
+			s.log.Error("Failed to send report", "reportId", report.Id, "error", err)
+		}
+	}
+}
+
+// NextRun returns the next time after from that matches the cron schedule,
+// given as a standard five field cron expression or a descriptor like
+// @daily.
+func NextRun(schedule string, from time.Time) (time.Time, error) {
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return time.Time{}, models.ErrReportInvalidSchedule
+	}
+	return sched.Next(from), nil
+}
+
+// ValidateSettings checks the settings of a report before it is stored.
+func ValidateSettings(settings *models.ReportSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	_, err := NextRun(settings.Schedule, time.Now())
+	return err
+}
@@ -0,0 +1,244 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+	"github.com/Seasheller/grafana/pkg/services/rendering"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type fakeRenderService struct {
+	dir  string
+	opts []rendering.Opts
+}
+
+func (r *fakeRenderService) Render(ctx context.Context, opts rendering.Opts) (*rendering.RenderResult, error) {
+	r.opts = append(r.opts, opts)
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+	path := filepath.Join(r.dir, fmt.Sprintf("%d.png", len(r.opts)))
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return &rendering.RenderResult{FilePath: path}, png.Encode(f, img)
+}
+
+func TestReportService(t *testing.T) {
+	Convey("Given a report of a dashboard", t, func() {
+		dir, err := ioutil.TempDir("", "reports-test")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		data := simplejson.NewFromAny(map[string]interface{}{
+			"title": "Hosts",
+			"time":  map[string]interface{}{"from": "now-1h", "to": "now"},
+			"panels": []interface{}{
+				map[string]interface{}{"id": 1, "type": "graph", "gridPos": map[string]interface{}{"y": 0, "h": 8}},
+				map[string]interface{}{"id": 2, "type": "row", "gridPos": map[string]interface{}{"y": 8, "h": 1}, "panels": []interface{}{
+					map[string]interface{}{"id": 3, "type": "graph"},
+				}},
+			},
+		})
+
+		b := bus.New()
+		b.AddHandler(func(query *models.GetSignedInUserQuery) error {
+			query.Result = &models.SignedInUser{UserId: query.UserId, OrgId: query.OrgId, OrgRole: models.ROLE_EDITOR}
+			return nil
+		})
+		b.AddHandler(func(query *models.GetDashboardQuery) error {
+			query.Result = &models.Dashboard{Id: 1, OrgId: 1, Uid: query.Uid, Slug: "hosts", Title: "Hosts", Data: data}
+			return nil
+		})
+
+		var deliveries []*models.ReportDelivery
+		b.AddHandler(func(cmd *models.CreateReportDeliveryCommand) error {
+			deliveries = append(deliveries, cmd.Delivery)
+			return nil
+		})
+
+		var emails []*models.SendEmailCommandSync
+		var attachments [][]byte
+		b.AddHandlerCtx(func(ctx context.Context, cmd *models.SendEmailCommandSync) error {
+			emails = append(emails, cmd)
+			for _, file := range cmd.AttachedFiles {
+				content, err := ioutil.ReadFile(file)
+				if err != nil {
+					return err
+				}
+				attachments = append(attachments, content)
+			}
+			return nil
+		})
+
+		renderer := &fakeRenderService{dir: dir}
+		service := &ReportService{Bus: b, RenderService: renderer, log: log.New("reports.test")}
+
+		origNewGuardian := guardian.New
+		defer func() {
+			guardian.New = origNewGuardian
+		}()
+
+		report := &models.Report{
+			Id:     1,
+			OrgId:  1,
+			UserId: 2,
+			ReportSettings: models.ReportSettings{
+				Name:         "Daily hosts",
+				DashboardUid: "hosts",
+				Layout:       models.ReportLayoutPanels,
+				Format:       models.ReportFormatPDF,
+				Variables:    map[string][]string{"host": {"a", "b"}},
+				Recipients:   "ops@example.com; dev@example.com",
+				Schedule:     "0 8 * * *",
+				Enabled:      true,
+			},
+		}
+
+		Convey("When the owner can view the dashboard", func() {
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+
+			Convey("Should render every panel into one PDF", func() {
+				cmd := models.SendReportCommand{Report: report}
+				So(service.SendReport(context.Background(), &cmd), ShouldBeNil)
+
+				So(len(renderer.opts), ShouldEqual, 2)
+				So(renderer.opts[0].UserId, ShouldEqual, 2)
+				So(renderer.opts[0].OrgRole, ShouldEqual, models.ROLE_EDITOR)
+				So(renderer.opts[0].Path, ShouldStartWith, "d-solo/hosts/hosts?")
+				So(renderer.opts[0].Path, ShouldContainSubstring, "var-host=a&var-host=b")
+				So(renderer.opts[0].Path, ShouldEndWith, "&panelId=1")
+				So(renderer.opts[1].Path, ShouldEndWith, "&panelId=3")
+
+				So(len(emails), ShouldEqual, 1)
+				So(emails[0].To, ShouldResemble, []string{"ops@example.com", "dev@example.com"})
+				So(emails[0].Template, ShouldEqual, "report.html")
+				So(len(attachments), ShouldEqual, 1)
+				So(string(attachments[0]), ShouldStartWith, "%PDF-1.4")
+				So(string(attachments[0]), ShouldContainSubstring, "/Count 2")
+
+				So(cmd.Result.State, ShouldEqual, models.ReportDeliverySent)
+				So(len(deliveries), ShouldEqual, 1)
+			})
+
+			Convey("Should render the whole dashboard as a PNG", func() {
+				report.Layout = models.ReportLayoutDashboard
+				report.Format = models.ReportFormatPNG
+
+				cmd := models.SendReportCommand{Report: report}
+				So(service.SendReport(context.Background(), &cmd), ShouldBeNil)
+
+				So(len(renderer.opts), ShouldEqual, 1)
+				So(renderer.opts[0].Path, ShouldStartWith, "d/hosts/hosts?")
+				So(renderer.opts[0].Path, ShouldEndWith, "&kiosk=true")
+				So(renderer.opts[0].Height, ShouldEqual, panelHeight)
+
+				So(len(emails[0].AttachedFiles), ShouldEqual, 1)
+				So(filepath.Base(emails[0].AttachedFiles[0]), ShouldEqual, "hosts.png")
+			})
+		})
+
+		Convey("When the owner cannot view the dashboard", func() {
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: false})
+
+			cmd := models.SendReportCommand{Report: report, Scheduled: true}
+			So(service.SendReport(context.Background(), &cmd), ShouldEqual, models.ErrReportAccessDenied)
+
+			Convey("Should record a failed delivery without sending", func() {
+				So(len(emails), ShouldEqual, 0)
+				So(len(deliveries), ShouldEqual, 1)
+				So(deliveries[0].State, ShouldEqual, models.ReportDeliveryFailed)
+				So(deliveries[0].Error, ShouldEqual, models.ErrReportAccessDenied.Error())
+				So(deliveries[0].Scheduled, ShouldBeTrue)
+			})
+		})
+
+		Convey("When sending the due reports", func() {
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+
+			now := time.Date(2019, 6, 3, 9, 0, 0, 0, time.Local)
+			b.AddHandler(func(query *models.GetDueReportsQuery) error {
+				query.Result = []*models.Report{report}
+				return nil
+			})
+
+			var scheduled *models.SetReportNextRunCommand
+			b.AddHandler(func(cmd *models.SetReportNextRunCommand) error {
+				scheduled = cmd
+				return nil
+			})
+
+			service.sendDueReports(context.Background(), now)
+
+			Convey("Should schedule the next run and send the report", func() {
+				So(scheduled.NextRun, ShouldEqual, time.Date(2019, 6, 4, 8, 0, 0, 0, time.Local))
+				So(len(emails), ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func TestValidateSettings(t *testing.T) {
+	Convey("Should validate report settings", t, func() {
+		settings := models.ReportSettings{Name: "Weekly", Recipients: "ops@example.com", Schedule: "0 8 * * 1"}
+		So(ValidateSettings(&settings), ShouldBeNil)
+		So(settings.Layout, ShouldEqual, models.ReportLayoutDashboard)
+		So(settings.Format, ShouldEqual, models.ReportFormatPDF)
+
+		settings.Schedule = "every monday"
+		So(ValidateSettings(&settings), ShouldEqual, models.ErrReportInvalidSchedule)
+
+		settings.Schedule = "@daily"
+		settings.Recipients = "ops@example.com, not an address"
+		So(ValidateSettings(&settings), ShouldEqual, models.ErrReportInvalidRecipient)
+
+		settings.Recipients = " ; "
+		So(ValidateSettings(&settings), ShouldEqual, models.ErrReportNoRecipients)
+	})
+}
+
+func TestWritePDF(t *testing.T) {
+	Convey("Should write a PDF with valid cross references", t, func() {
+		images := []image.Image{
+			image.NewRGBA(image.Rect(0, 0, 10, 5)),
+			image.NewRGBA(image.Rect(0, 0, 3, 3)),
+		}
+
+		var buf bytes.Buffer
+		So(writePDF(&buf, images), ShouldBeNil)
+		pdf := buf.String()
+
+		So(pdf, ShouldContainSubstring, "/MediaBox [0 0 10 5]")
+		So(pdf, ShouldContainSubstring, "/MediaBox [0 0 3 3]")
+		So(pdf, ShouldEndWith, "%%EOF\n")
+
+		offsets := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(pdf, -1)
+		So(len(offsets), ShouldEqual, 8)
+		for i, match := range offsets {
+			offset, err := strconv.Atoi(match[1])
+			So(err, ShouldBeNil)
+			So(pdf[offset:], ShouldStartWith, fmt.Sprintf("%d 0 obj", i+1))
+		}
+	})
+}
@@ -0,0 +1,324 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+	"github.com/Seasheller/grafana/pkg/services/rendering"
+	"github.com/Seasheller/grafana/pkg/setting"
+	"github.com/Seasheller/grafana/pkg/tsdb"
+)
+
+const (
+	panelWidth     = 1000
+	panelHeight    = 500
+	dashboardWidth = 1500
+
+	// gridCellHeight is the height of a row of the dashboard grid,
+	// including the margin.
+	gridCellHeight = 38
+)
+
+// SendReport renders the dashboard of the report with the permissions of
+// the owner of the report and sends it to the recipients. The delivery is
+// recorded whether it succeeds or not.
+func (s *ReportService) SendReport(ctx context.Context, cmd *models.SendReportCommand) error {
+	report := cmd.Report
+
+	delivery := &models.ReportDelivery{
+		OrgId:      report.OrgId,
+		ReportId:   report.Id,
+		Recipients: report.Recipients,
+		Scheduled:  cmd.Scheduled,
+		State:      models.ReportDeliverySent,
+	}
+
+	sendErr := s.sendReport(ctx, report)
+	if sendErr != nil {
+		delivery.State = models.ReportDeliveryFailed
+		delivery.Error = sendErr.Error()
+	}
+
+	if err := s.Bus.Dispatch(&models.CreateReportDeliveryCommand{Delivery: delivery}); err != nil {
+		return err
+	}
+
+	cmd.Result = delivery
+	return sendErr
+}
+
+func (s *ReportService) sendReport(ctx context.Context, report *models.Report) error {
+	userQuery := models.GetSignedInUserQuery{UserId: report.UserId, OrgId: report.OrgId}
+	if err := s.Bus.Dispatch(&userQuery); err != nil {
+		return err
+	}
+	user := userQuery.Result
+
+	dashQuery := models.GetDashboardQuery{OrgId: report.OrgId, Uid: report.DashboardUid}
+	if err := s.Bus.Dispatch(&dashQuery); err != nil {
+		if err == models.ErrDashboardNotFound {
+			return models.ErrReportDashboardNotFound
+		}
+		return err
+	}
+	dash := dashQuery.Result
+
+	canView, err := guardian.New(dash.Id, report.OrgId, user).CanView()
+	if err != nil {
+		return err
+	}
+	if !canView {
+		return models.ErrReportAccessDenied
+	}
+
+	timeRange, err := getTimeRange(report, dash)
+	if err != nil {
+		return err
+	}
+
+	params := getUrlParams(report, timeRange)
+
+	images, err := s.render(ctx, report, user, dash, params)
+	if err != nil {
+		return err
+	}
+
+	dir, err := ioutil.TempDir("", "report")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	attachments, err := createAttachments(dir, report, dash, images)
+	if err != nil {
+		return err
+	}
+
+	return s.Bus.DispatchCtx(ctx, &models.SendEmailCommandSync{
+		SendEmailCommand: models.SendEmailCommand{
+			To:       report.RecipientList(),
+			Template: "report.html",
+			Data: map[string]interface{}{
+				"ReportName":     report.Name,
+				"DashboardTitle": dash.Title,
+				"DashboardUrl":   models.GetFullDashboardUrl(dash.Uid, dash.Slug) + "?" + params.Encode(),
+				"TimeFrom":       timeRange.GetFromAsTimeUTC().Format("2006-01-02 15:04 MST"),
+				"TimeTo":         timeRange.GetToAsTimeUTC().Format("2006-01-02 15:04 MST"),
+				"Message":        report.Message,
+			},
+			AttachedFiles: attachments,
+		},
+	})
+}
+
+// renderedImage is an image of the dashboard or of one of its panels.
+type renderedImage struct {
+	panelId  int64
+	filePath string
+}
+
+func (s *ReportService) render(ctx context.Context, report *models.Report, user *models.SignedInUser, dash *models.Dashboard, params url.Values) ([]renderedImage, error) {
+	opts := rendering.Opts{
+		Timeout:         setting.ReportsRenderTimeout,
+		OrgId:           report.OrgId,
+		UserId:          user.UserId,
+		OrgRole:         user.OrgRole,
+		ConcurrentLimit: setting.ReportsRenderLimit,
+	}
+
+	if report.Layout == models.ReportLayoutDashboard {
+		opts.Width = dashboardWidth
+		opts.Height = getDashboardHeight(dash.Data)
+		opts.Path = fmt.Sprintf("d/%s/%s?%s&kiosk=true", dash.Uid, dash.Slug, params.Encode())
+
+		result, err := s.RenderService.Render(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return []renderedImage{{filePath: result.FilePath}}, nil
+	}
+
+	panelIds := report.PanelIds
+	if len(panelIds) == 0 {
+		panelIds = getPanelIds(dash.Data)
+	}
+
+	images := make([]renderedImage, 0, len(panelIds))
+	for _, panelId := range panelIds {
+		opts.Width = panelWidth
+		opts.Height = panelHeight
+		opts.Path = fmt.Sprintf("d-solo/%s/%s?%s&panelId=%d", dash.Uid, dash.Slug, params.Encode(), panelId)
+
+		result, err := s.RenderService.Render(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, renderedImage{panelId: panelId, filePath: result.FilePath})
+	}
+
+	return images, nil
+}
+
+// createAttachments stores the rendered images in dir, as one PDF document
+// or as PNG images named after the dashboard.
+func createAttachments(dir string, report *models.Report, dash *models.Dashboard, images []renderedImage) ([]string, error) {
+	if report.Format == models.ReportFormatPDF {
+		decoded := make([]image.Image, 0, len(images))
+		for _, img := range images {
+			d, err := decodePNG(img.filePath)
+			if err != nil {
+				return nil, err
+			}
+			decoded = append(decoded, d)
+		}
+
+		path := filepath.Join(dir, dash.Slug+".pdf")
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		if err := writePDF(f, decoded); err != nil {
+			return nil, err
+		}
+		return []string{path}, f.Close()
+	}
+
+	attachments := make([]string, 0, len(images))
+	for _, img := range images {
+		name := dash.Slug + ".png"
+		if img.panelId != 0 {
+			name = fmt.Sprintf("%s-panel-%d.png", dash.Slug, img.panelId)
+		}
+
+		path := filepath.Join(dir, name)
+		if err := copyFile(img.filePath, path); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, path)
+	}
+	return attachments, nil
+}
+
+// getTimeRange returns the time range of the report, which defaults to the
+// time range of the dashboard.
+func getTimeRange(report *models.Report, dash *models.Dashboard) (*tsdb.TimeRange, error) {
+	from, to := report.TimeFrom, report.TimeTo
+	if from == "" {
+		from = dash.Data.Get("time").Get("from").MustString("now-6h")
+	}
+	if to == "" {
+		to = dash.Data.Get("time").Get("to").MustString("now")
+	}
+
+	timeRange := tsdb.NewTimeRange(from, to)
+	if _, err := timeRange.ParseFrom(); err != nil {
+		return nil, err
+	}
+	if _, err := timeRange.ParseTo(); err != nil {
+		return nil, err
+	}
+	return timeRange, nil
+}
+
+// getUrlParams returns the query parameters of the dashboard url for the
+// report, with the time range resolved so all images show the same data.
+func getUrlParams(report *models.Report, timeRange *tsdb.TimeRange) url.Values {
+	params := url.Values{}
+	params.Set("orgId", strconv.FormatInt(report.OrgId, 10))
+	params.Set("from", strconv.FormatInt(timeRange.GetFromAsMsEpoch(), 10))
+	params.Set("to", strconv.FormatInt(timeRange.GetToAsMsEpoch(), 10))
+
+	names := make([]string, 0, len(report.Variables))
+	for name := range report.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range report.Variables[name] {
+			params.Add("var-"+name, value)
+		}
+	}
+
+	return params
+}
+
+// getPanelIds returns the ids of all panels of the dashboard, including
+// panels in collapsed rows, but not the rows.
+func getPanelIds(data *simplejson.Json) []int64 {
+	ids := make([]int64, 0)
+
+	var walk func(panels []interface{})
+	walk = func(panels []interface{}) {
+		for _, p := range panels {
+			panel := simplejson.NewFromAny(p)
+			if panel.Get("type").MustString() != "row" {
+				ids = append(ids, panel.Get("id").MustInt64())
+			}
+			walk(panel.Get("panels").MustArray())
+		}
+	}
+
+	walk(data.Get("panels").MustArray())
+	return ids
+}
+
+// getDashboardHeight returns the height in pixels the panels of the
+// dashboard need.
+func getDashboardHeight(data *simplejson.Json) int {
+	rows := 0
+	for _, p := range data.Get("panels").MustArray() {
+		gridPos := simplejson.NewFromAny(p).Get("gridPos")
+		if bottom := gridPos.Get("y").MustInt() + gridPos.Get("h").MustInt(); bottom > rows {
+			rows = bottom
+		}
+	}
+
+	if height := rows * gridCellHeight; height > panelHeight {
+		return height
+	}
+	return panelHeight
+}
+
+func decodePNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return png.Decode(f)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,421 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	crewjam "github.com/crewjam/saml"
+)
+
+const (
+	sessionKeyPrefix = "saml_session_"
+	nameIDKeyPrefix  = "saml_nameid_"
+
+	sigAlgRSASHA1   = "http://www.w3.org/2000/09/xmldsig#rsa-sha1"
+	sigAlgRSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+
+	maxMessageSize = 1 << 20
+)
+
+var (
+	// ErrInvalidSignature is returned when a logout message isn't signed by the IdP.
+	ErrInvalidSignature = errors.New("SAML message signature is missing or invalid")
+
+	// ErrInvalidLogoutMessage is returned when a logout message can't be accepted.
+	ErrInvalidLogoutMessage = errors.New("invalid SAML logout message")
+)
+
+// Session identifies a user's session at the IdP. It is needed to
+// ask the IdP to end it, or to find the user when the IdP asks us to.
+type Session struct {
+	UserId       int64
+	NameID       string
+	NameIDFormat string
+	SessionIndex string
+}
+
+type logoutRequest struct {
+	XMLName      xml.Name        `xml:"urn:oasis:names:tc:SAML:2.0:protocol LogoutRequest"`
+	ID           string          `xml:",attr"`
+	Version      string          `xml:",attr"`
+	IssueInstant time.Time       `xml:",attr"`
+	Destination  string          `xml:",attr,omitempty"`
+	Issuer       *crewjam.Issuer `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+	NameID       *crewjam.NameID `xml:"urn:oasis:names:tc:SAML:2.0:assertion NameID"`
+	SessionIndex string          `xml:"urn:oasis:names:tc:SAML:2.0:protocol SessionIndex,omitempty"`
+}
+
+type logoutResponse struct {
+	XMLName      xml.Name        `xml:"urn:oasis:names:tc:SAML:2.0:protocol LogoutResponse"`
+	ID           string          `xml:",attr"`
+	InResponseTo string          `xml:",attr,omitempty"`
+	Version      string          `xml:",attr"`
+	IssueInstant time.Time       `xml:",attr"`
+	Destination  string          `xml:",attr,omitempty"`
+	Issuer       *crewjam.Issuer `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+	Status       crewjam.Status
+}
+
+func sessionFromAssertion(assertion *crewjam.Assertion) Session {
+	session := Session{}
+	if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		session.NameID = assertion.Subject.NameID.Value
+		session.NameIDFormat = assertion.Subject.NameID.Format
+	}
+	for _, statement := range assertion.AuthnStatements {
+		if statement.SessionIndex != "" {
+			session.SessionIndex = statement.SessionIndex
+			break
+		}
+	}
+	return session
+}
+
+// SaveSession remembers the IdP session of a user that just logged in.
+func (s *SAMLService) SaveSession(userId int64, session Session) error {
+	if session.NameID == "" {
+		return nil
+	}
+
+	session.UserId = userId
+	ttl := time.Duration(s.Cfg.LoginMaxLifetimeDays) * 24 * time.Hour
+	if err := s.RemoteCache.Set(sessionKeyPrefix+strconv.FormatInt(userId, 10), session, ttl); err != nil {
+		return err
+	}
+
+	return s.RemoteCache.Set(nameIDKeyPrefix+session.NameID, session, ttl)
+}
+
+func (s *SAMLService) takeSession(key string) (Session, bool) {
+	value, err := s.RemoteCache.Get(key)
+	if err != nil {
+		return Session{}, false
+	}
+
+	session, ok := value.(Session)
+	if !ok {
+		return Session{}, false
+	}
+
+	if err := s.RemoteCache.Delete(sessionKeyPrefix + strconv.FormatInt(session.UserId, 10)); err != nil {
+		s.log.Warn("Failed to delete SAML session", "error", err)
+	}
+	if err := s.RemoteCache.Delete(nameIDKeyPrefix + session.NameID); err != nil {
+		s.log.Warn("Failed to delete SAML session", "error", err)
+	}
+
+	return session, true
+}
+
+// LogoutRedirectURL returns the IdP URL that ends the user's IdP session,
+// or an empty string if there is nothing to log out of.
+func (s *SAMLService) LogoutRedirectURL(userId int64) (string, error) {
+	if s.sp == nil || !s.Cfg.SAML.SingleLogout {
+		return "", nil
+	}
+
+	location, _ := s.idpLogoutLocation()
+	if location == "" {
+		return "", nil
+	}
+
+	session, ok := s.takeSession(sessionKeyPrefix + strconv.FormatInt(userId, 10))
+	if !ok {
+		return "", nil
+	}
+
+	msg := &logoutRequest{
+		ID:           newMessageID(),
+		Version:      "2.0",
+		IssueInstant: crewjam.TimeNow(),
+		Destination:  location,
+		Issuer:       s.issuer(),
+		NameID: &crewjam.NameID{
+			Format: session.NameIDFormat,
+			Value:  session.NameID,
+		},
+		SessionIndex: session.SessionIndex,
+	}
+
+	return s.redirectURL(location, "SAMLRequest", msg, "")
+}
+
+// HandleLogoutRequest processes an IdP-initiated logout sent with the
+// HTTP-Redirect binding. It returns the id of the user to log out, 0 if
+// the session is unknown, and the URL to send the logout response to.
+func (s *SAMLService) HandleLogoutRequest(req *http.Request) (int64, string, error) {
+	if s.sp == nil {
+		return 0, "", ErrNotEnabled
+	}
+
+	msg := &logoutRequest{}
+	if err := s.readRedirectMessage(req, "SAMLRequest", msg); err != nil {
+		return 0, "", err
+	}
+
+	if msg.Issuer == nil || msg.Issuer.Value != s.sp.IDPMetadata.EntityID || msg.NameID == nil {
+		return 0, "", ErrInvalidLogoutMessage
+	}
+	if msg.Destination != "" && msg.Destination != s.sloURL.String() {
+		return 0, "", ErrInvalidLogoutMessage
+	}
+
+	var userId int64
+	if session, ok := s.takeSession(nameIDKeyPrefix + msg.NameID.Value); ok {
+		userId = session.UserId
+	}
+
+	_, location := s.idpLogoutLocation()
+	if location == "" {
+		return 0, "", errors.New("IdP metadata has no single logout service")
+	}
+
+	resp := &logoutResponse{
+		ID:           newMessageID(),
+		InResponseTo: msg.ID,
+		Version:      "2.0",
+		IssueInstant: crewjam.TimeNow(),
+		Destination:  location,
+		Issuer:       s.issuer(),
+		Status: crewjam.Status{
+			StatusCode: crewjam.StatusCode{Value: crewjam.StatusSuccess},
+		},
+	}
+
+	redirectURL, err := s.redirectURL(location, "SAMLResponse", resp, req.URL.Query().Get("RelayState"))
+	return userId, redirectURL, err
+}
+
+// HandleLogoutResponse validates the IdP's answer to a logout started by Grafana.
+func (s *SAMLService) HandleLogoutResponse(req *http.Request) error {
+	if s.sp == nil {
+		return ErrNotEnabled
+	}
+
+	msg := &logoutResponse{}
+	if err := s.readRedirectMessage(req, "SAMLResponse", msg); err != nil {
+		return err
+	}
+
+	if msg.Issuer == nil || msg.Issuer.Value != s.sp.IDPMetadata.EntityID {
+		return ErrInvalidLogoutMessage
+	}
+	if msg.Status.StatusCode.Value != crewjam.StatusSuccess {
+		return fmt.Errorf("IdP logout failed with status %s", msg.Status.StatusCode.Value)
+	}
+
+	return nil
+}
+
+func (s *SAMLService) issuer() *crewjam.Issuer {
+	return &crewjam.Issuer{
+		Format: "urn:oasis:names:tc:SAML:2.0:nameid-format:entity",
+		Value:  s.sp.MetadataURL.String(),
+	}
+}
+
+// idpLogoutLocation returns where to send logout requests and responses.
+func (s *SAMLService) idpLogoutLocation() (string, string) {
+	for _, descriptor := range s.sp.IDPMetadata.IDPSSODescriptors {
+		for _, endpoint := range descriptor.SingleLogoutServices {
+			if endpoint.Binding != crewjam.HTTPRedirectBinding {
+				continue
+			}
+			if endpoint.ResponseLocation != "" {
+				return endpoint.Location, endpoint.ResponseLocation
+			}
+			return endpoint.Location, endpoint.Location
+		}
+	}
+
+	return "", ""
+}
+
+// redirectURL encodes and signs msg for the HTTP-Redirect binding.
+func (s *SAMLService) redirectURL(location, param string, msg interface{}, relayState string) (string, error) {
+	data, err := xml.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(data); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	query := param + "=" + url.QueryEscape(base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		query += "&RelayState=" + url.QueryEscape(relayState)
+	}
+	query += "&SigAlg=" + url.QueryEscape(sigAlgRSASHA256)
+
+	digest := sha256.Sum256([]byte(query))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.sp.Key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	query += "&Signature=" + url.QueryEscape(base64.StdEncoding.EncodeToString(signature))
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	if u.RawQuery != "" {
+		u.RawQuery += "&" + query
+	} else {
+		u.RawQuery = query
+	}
+
+	return u.String(), nil
+}
+
+// readRedirectMessage verifies the signature of an HTTP-Redirect binding
+// message and decodes it into msg.
+func (s *SAMLService) readRedirectMessage(req *http.Request, param string, msg interface{}) error {
+	if err := s.verifyRedirectSignature(req.URL.RawQuery, param); err != nil {
+		return err
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(req.URL.Query().Get(param))
+	if err != nil {
+		return ErrInvalidLogoutMessage
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(compressed)), maxMessageSize))
+	if err != nil {
+		return ErrInvalidLogoutMessage
+	}
+
+	if err := xml.Unmarshal(data, msg); err != nil {
+		return ErrInvalidLogoutMessage
+	}
+
+	return nil
+}
+
+// verifyRedirectSignature checks the signature over the query parameters
+// exactly as they were encoded by the IdP, see section 3.4.4.1 of the
+// SAML bindings specification.
+func (s *SAMLService) verifyRedirectSignature(rawQuery, param string) error {
+	raw := map[string]string{}
+	for _, part := range strings.Split(rawQuery, "&") {
+		if i := strings.Index(part, "="); i > 0 {
+			raw[part[:i]] = part[i+1:]
+		}
+	}
+
+	if raw[param] == "" || raw["SigAlg"] == "" || raw["Signature"] == "" {
+		return ErrInvalidSignature
+	}
+
+	signed := param + "=" + raw[param]
+	if relayState, ok := raw["RelayState"]; ok {
+		signed += "&RelayState=" + relayState
+	}
+	signed += "&SigAlg=" + raw["SigAlg"]
+
+	sigAlg, err := url.QueryUnescape(raw["SigAlg"])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	sigValue, err := url.QueryUnescape(raw["Signature"])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	signature, err := base64.StdEncoding.DecodeString(sigValue)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	var hash crypto.Hash
+	var digest []byte
+	switch sigAlg {
+	case sigAlgRSASHA256:
+		sum := sha256.Sum256([]byte(signed))
+		hash, digest = crypto.SHA256, sum[:]
+	case sigAlgRSASHA1:
+		sum := sha1.Sum([]byte(signed))
+		hash, digest = crypto.SHA1, sum[:]
+	default:
+		return ErrInvalidSignature
+	}
+
+	certs, err := s.idpSigningCerts()
+	if err != nil {
+		return err
+	}
+
+	for _, cert := range certs {
+		key, ok := cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+		if rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+func (s *SAMLService) idpSigningCerts() ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for _, descriptor := range s.sp.IDPMetadata.IDPSSODescriptors {
+		for _, keyDescriptor := range descriptor.KeyDescriptors {
+			if keyDescriptor.Use != "signing" && keyDescriptor.Use != "" {
+				continue
+			}
+
+			data, err := base64.StdEncoding.DecodeString(whitespace.ReplaceAllString(keyDescriptor.KeyInfo.Certificate, ""))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode IdP certificate: %v", err)
+			}
+			cert, err := x509.ParseCertificate(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse IdP certificate: %v", err)
+			}
+			certs = append(certs, cert)
+		}
+	}
+
+	if len(certs) == 0 {
+		return nil, errors.New("IdP metadata has no signing certificate")
+	}
+
+	return certs, nil
+}
+
+func newMessageID() string {
+	id := make([]byte, 20)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("id-%x", id)
+}
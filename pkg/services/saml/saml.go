@@ -0,0 +1,441 @@
+package saml
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	crewjam "github.com/crewjam/saml"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/infra/remotecache"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/registry"
+	"github.com/Seasheller/grafana/pkg/setting"
+	"github.com/Seasheller/grafana/pkg/util"
+)
+
+const (
+	requestStateKeyPrefix = "saml_request_"
+	requestStateTTL       = time.Minute * 10
+)
+
+var (
+	// ErrNotEnabled is returned when SAML is used without being enabled.
+	ErrNotEnabled = errors.New("SAML authentication is not enabled")
+
+	// ErrUnknownRequest is returned when a response does not belong to a
+	// request started by Grafana and IdP-initiated login is not allowed.
+	ErrUnknownRequest = errors.New("SAML response does not match any pending authentication request")
+
+	// ErrMissingAttribute is returned when the assertion lacks the user's login or email.
+	ErrMissingAttribute = errors.New("SAML assertion is missing the login or email attribute")
+)
+
+func init() {
+	registry.RegisterService(&SAMLService{})
+	remotecache.Register(requestState{})
+	remotecache.Register(Session{})
+}
+
+// SAMLService lets Grafana act as a SAML 2.0 service provider.
+type SAMLService struct {
+	Bus         bus.Bus                  `inject:""`
+	Cfg         *setting.Cfg             `inject:""`
+	RemoteCache *remotecache.RemoteCache `inject:""`
+
+	log    log.Logger
+	sp     *crewjam.ServiceProvider
+	sloURL url.URL
+}
+
+// requestState is kept between the authentication request and the
+// IdP's response. It can't live in a cookie since the IdP posts the
+// response cross-site and lax cookies aren't sent along.
+type requestState struct {
+	ID         string
+	RedirectTo string
+}
+
+// LoginResponse is the outcome of a successful SAML login.
+type LoginResponse struct {
+	ExternalUser *models.ExternalUserInfo
+	Session      Session
+	RedirectTo   string
+}
+
+func (s *SAMLService) Init() error {
+	s.log = log.New("saml.auth")
+
+	s.Bus.AddHandler(s.isSAMLEnabled)
+
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	sp, err := s.newServiceProvider()
+	if err != nil {
+		return fmt.Errorf("failed to configure SAML: %v", err)
+	}
+
+	s.sp = sp
+	return nil
+}
+
+// IsEnabled returns true if SAML authentication is turned on in the configuration.
+func (s *SAMLService) IsEnabled() bool {
+	return s != nil && s.Cfg != nil && s.Cfg.SAML.Enabled
+}
+
+func (s *SAMLService) isSAMLEnabled(cmd *models.IsSAMLEnabledCommand) error {
+	cmd.Result = s.IsEnabled()
+	return nil
+}
+
+func (s *SAMLService) newServiceProvider() (*crewjam.ServiceProvider, error) {
+	cfg := s.Cfg.SAML
+
+	certData, err := readBase64OrFile("certificate", cfg.Certificate, cfg.CertificatePath)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := parseCertificate(certData)
+	if err != nil {
+		return nil, err
+	}
+
+	keyData, err := readBase64OrFile("private_key", cfg.PrivateKey, cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parsePrivateKey(keyData)
+	if err != nil {
+		return nil, err
+	}
+
+	idpMetadata, err := s.readIdpMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	appURL, err := url.Parse(strings.TrimSuffix(setting.AppUrl, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	crewjam.MaxIssueDelay = cfg.MaxIssueDelay
+
+	s.sloURL = *appURL
+	s.sloURL.Path += "/saml/slo"
+
+	sp := &crewjam.ServiceProvider{
+		Key:                   key,
+		Certificate:           cert,
+		MetadataURL:           *appURL,
+		AcsURL:                *appURL,
+		IDPMetadata:           idpMetadata,
+		MetadataValidDuration: cfg.MetadataValidDuration,
+	}
+	sp.MetadataURL.Path += "/saml/metadata"
+	sp.AcsURL.Path += "/saml/acs"
+
+	return sp, nil
+}
+
+func (s *SAMLService) readIdpMetadata() (*crewjam.EntityDescriptor, error) {
+	cfg := s.Cfg.SAML
+
+	var data []byte
+	var err error
+
+	switch {
+	case cfg.IdpMetadataUrl != "" && (cfg.IdpMetadata != "" || cfg.IdpMetadataPath != ""):
+		return nil, errors.New("only one of idp_metadata, idp_metadata_path and idp_metadata_url can be set")
+	case cfg.IdpMetadataUrl != "":
+		data, err = fetchMetadata(cfg.IdpMetadataUrl)
+	default:
+		data, err = readBase64OrFile("idp_metadata", cfg.IdpMetadata, cfg.IdpMetadataPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &crewjam.EntityDescriptor{}
+	if err := xml.Unmarshal(data, metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse IdP metadata: %v", err)
+	}
+
+	return metadata, nil
+}
+
+func fetchMetadata(metadataURL string) ([]byte, error) {
+	client := &http.Client{Timeout: time.Second * 30}
+	resp, err := client.Get(metadataURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch IdP metadata: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch IdP metadata: %s", resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// readBase64OrFile returns the decoded value, or the contents of path.
+// Setting both is a configuration error.
+func readBase64OrFile(name, value, path string) ([]byte, error) {
+	switch {
+	case value != "" && path != "":
+		return nil, fmt.Errorf("only one of %s and %s_path can be set", name, name)
+	case value != "":
+		data, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %v", name, err)
+		}
+		return data, nil
+	case path != "":
+		return ioutil.ReadFile(path)
+	default:
+		return nil, fmt.Errorf("%s or %s_path must be set", name, name)
+	}
+}
+
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+
+	cert, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %v", err)
+	}
+
+	return cert, nil
+}
+
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(data); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %v", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key must be an RSA key")
+	}
+
+	return key, nil
+}
+
+// MetadataXML returns the service provider metadata for the IdP.
+func (s *SAMLService) MetadataXML() ([]byte, error) {
+	if s.sp == nil {
+		return nil, ErrNotEnabled
+	}
+
+	metadata := s.sp.Metadata()
+	metadata.SPSSODescriptors[0].SingleLogoutServices = []crewjam.Endpoint{
+		{
+			Binding:  crewjam.HTTPRedirectBinding,
+			Location: s.sloURL.String(),
+		},
+	}
+
+	return xml.MarshalIndent(metadata, "", "  ")
+}
+
+// LoginRedirectURL starts an SP-initiated login and returns the IdP URL
+// the user should be sent to.
+func (s *SAMLService) LoginRedirectURL(redirectTo string) (string, error) {
+	if s.sp == nil {
+		return "", ErrNotEnabled
+	}
+
+	authnRequest, err := s.sp.MakeAuthenticationRequest(s.sp.GetSSOBindingLocation(crewjam.HTTPRedirectBinding))
+	if err != nil {
+		return "", err
+	}
+
+	relayState := util.GetRandomString(32)
+	state := requestState{ID: authnRequest.ID, RedirectTo: redirectTo}
+	if err := s.RemoteCache.Set(requestStateKeyPrefix+relayState, state, requestStateTTL); err != nil {
+		return "", err
+	}
+
+	return authnRequest.Redirect(relayState).String(), nil
+}
+
+// ParseLoginResponse validates the IdP's response posted to the ACS
+// endpoint and maps the assertion to an external user.
+func (s *SAMLService) ParseLoginResponse(req *http.Request) (*LoginResponse, error) {
+	if s.sp == nil {
+		return nil, ErrNotEnabled
+	}
+
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	relayState := req.PostForm.Get("RelayState")
+	resp := &LoginResponse{}
+	var possibleRequestIDs []string
+
+	if state, ok := s.takeRequestState(relayState); ok {
+		possibleRequestIDs = append(possibleRequestIDs, state.ID)
+		resp.RedirectTo = state.RedirectTo
+	} else if s.Cfg.SAML.AllowIdpInitiated {
+		possibleRequestIDs = append(possibleRequestIDs, "")
+		if isLocalPath(relayState) {
+			resp.RedirectTo = relayState
+		}
+	} else {
+		return nil, ErrUnknownRequest
+	}
+
+	assertion, err := s.sp.ParseResponse(req, possibleRequestIDs)
+	if err != nil {
+		if invalid, ok := err.(*crewjam.InvalidResponseError); ok {
+			s.log.Debug("Invalid SAML response", "error", invalid.PrivateErr)
+		}
+		return nil, err
+	}
+
+	extUser, err := s.externalUserInfo(assertion)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.ExternalUser = extUser
+	resp.Session = sessionFromAssertion(assertion)
+	return resp, nil
+}
+
+func (s *SAMLService) takeRequestState(relayState string) (requestState, bool) {
+	if relayState == "" {
+		return requestState{}, false
+	}
+
+	key := requestStateKeyPrefix + relayState
+	value, err := s.RemoteCache.Get(key)
+	if err != nil {
+		return requestState{}, false
+	}
+
+	// the state may only be used once
+	if err := s.RemoteCache.Delete(key); err != nil {
+		s.log.Warn("Failed to delete SAML request state", "error", err)
+	}
+
+	state, ok := value.(requestState)
+	return state, ok
+}
+
+func (s *SAMLService) externalUserInfo(assertion *crewjam.Assertion) (*models.ExternalUserInfo, error) {
+	cfg := s.Cfg.SAML
+	attrs := assertionAttributes(assertion)
+
+	extUser := &models.ExternalUserInfo{
+		AuthModule: models.AuthModuleSAML,
+		Name:       attrs.first(cfg.AssertionAttributeName),
+		Login:      attrs.first(cfg.AssertionAttributeLogin),
+		Email:      attrs.first(cfg.AssertionAttributeEmail),
+		Groups:     attrs.all(cfg.AssertionAttributeGroups),
+		OrgRoles:   map[int64]models.RoleType{},
+	}
+
+	if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		extUser.AuthId = assertion.Subject.NameID.Value
+	}
+
+	if extUser.Login == "" {
+		extUser.Login = extUser.Email
+	}
+
+	if extUser.Login == "" || extUser.Email == "" {
+		return nil, ErrMissingAttribute
+	}
+
+	// the role applies to the org new users are assigned to, like the roles
+	// of the other external auth modules
+	if role := models.RoleType(attrs.first(cfg.AssertionAttributeRole)); role != "" {
+		if role.IsValid() {
+			extUser.OrgRoles[int64(setting.AutoAssignOrgId)] = role
+		} else {
+			s.log.Warn("Ignoring invalid role in SAML assertion", "role", role, "login", extUser.Login)
+		}
+	}
+
+	return extUser, nil
+}
+
+// isLocalPath reports whether the IdP supplied relay state can be used as
+// a redirect target without sending the user off-site.
+func isLocalPath(path string) bool {
+	return strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "//") && !strings.Contains(path, "\\")
+}
+
+type attributes map[string][]string
+
+func assertionAttributes(assertion *crewjam.Assertion) attributes {
+	attrs := attributes{}
+	for _, statement := range assertion.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			var values []string
+			for _, value := range attr.Values {
+				values = append(values, value.Value)
+			}
+
+			if attr.Name != "" {
+				attrs[attr.Name] = append(attrs[attr.Name], values...)
+			}
+			if attr.FriendlyName != "" && attr.FriendlyName != attr.Name {
+				attrs[attr.FriendlyName] = append(attrs[attr.FriendlyName], values...)
+			}
+		}
+	}
+
+	return attrs
+}
+
+func (a attributes) first(name string) string {
+	if values := a.all(name); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func (a attributes) all(name string) []string {
+	if name == "" {
+		return nil
+	}
+
+	var values []string
+	for _, value := range a[name] {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
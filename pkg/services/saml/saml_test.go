@@ -0,0 +1,457 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"html"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	crewjam "github.com/crewjam/saml"
+	"github.com/crewjam/saml/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/infra/remotecache"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/setting"
+)
+
+// testIdP is a minimal identity provider used to issue real, signed
+// and encrypted responses to the service under test.
+type testIdP struct {
+	*crewjam.IdentityProvider
+	key     *rsa.PrivateKey
+	session *crewjam.Session
+}
+
+type testSessionProvider struct {
+	idp *testIdP
+}
+
+func (p testSessionProvider) GetSession(w http.ResponseWriter, r *http.Request, req *crewjam.IdpAuthnRequest) *crewjam.Session {
+	return p.idp.session
+}
+
+type testServiceProviderProvider struct {
+	service *SAMLService
+}
+
+func (p testServiceProviderProvider) GetServiceProvider(r *http.Request, serviceProviderID string) (*crewjam.EntityDescriptor, error) {
+	if serviceProviderID != p.service.sp.MetadataURL.String() {
+		return nil, os.ErrNotExist
+	}
+	return p.service.sp.Metadata(), nil
+}
+
+// roleAssertionMaker adds a role attribute to the default assertion.
+type roleAssertionMaker struct {
+	role string
+}
+
+func (m roleAssertionMaker) MakeAssertion(req *crewjam.IdpAuthnRequest, session *crewjam.Session) error {
+	if err := (crewjam.DefaultAssertionMaker{}).MakeAssertion(req, session); err != nil {
+		return err
+	}
+
+	statement := &req.Assertion.AttributeStatements[0]
+	statement.Attributes = append(statement.Attributes, crewjam.Attribute{
+		Name:   "role",
+		Values: []crewjam.AttributeValue{{Type: "xs:string", Value: m.role}},
+	})
+	return nil
+}
+
+func newKeyPair(t *testing.T, commonName string) (*rsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return key, cert
+}
+
+func setupTestService(t *testing.T, configure func(*setting.SAMLSettings)) (*SAMLService, *testIdP) {
+	t.Helper()
+
+	setting.AppUrl = "http://grafana.example.com/"
+
+	idpKey, idpCert := newKeyPair(t, "idp.example.com")
+	idp := &testIdP{
+		key: idpKey,
+		IdentityProvider: &crewjam.IdentityProvider{
+			Key:            idpKey,
+			Certificate:    idpCert,
+			Logger:         logger.DefaultLogger,
+			AssertionMaker: roleAssertionMaker{role: "Editor"},
+		},
+		session: &crewjam.Session{
+			ID:             "session-1",
+			CreateTime:     time.Now(),
+			ExpireTime:     time.Now().Add(time.Hour),
+			Index:          "index-1",
+			NameID:         "name-id-1",
+			UserName:       "alice",
+			UserEmail:      "alice@example.com",
+			UserCommonName: "Alice Liddell",
+			Groups:         []string{"admins", "developers"},
+		},
+	}
+	idp.MetadataURL = mustParseURL(t, "http://idp.example.com/metadata")
+	idp.SSOURL = mustParseURL(t, "http://idp.example.com/sso")
+	idp.LogoutURL = mustParseURL(t, "http://idp.example.com/slo")
+	idp.SessionProvider = testSessionProvider{idp: idp}
+
+	idpMetadata, err := xml.Marshal(idp.Metadata())
+	require.NoError(t, err)
+
+	spKey, spCert := newKeyPair(t, "grafana.example.com")
+
+	cfg := setting.NewCfg()
+	cfg.LoginMaxLifetimeDays = 30
+	cfg.SAML = setting.SAMLSettings{
+		Enabled:                  true,
+		Certificate:              base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: spCert.Raw})),
+		PrivateKey:               base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(spKey)})),
+		IdpMetadata:              base64.StdEncoding.EncodeToString(idpMetadata),
+		MaxIssueDelay:            time.Second * 90,
+		MetadataValidDuration:    time.Hour * 48,
+		AssertionAttributeName:   "cn",
+		AssertionAttributeLogin:  "uid",
+		AssertionAttributeEmail:  "eduPersonPrincipalName",
+		AssertionAttributeGroups: "eduPersonAffiliation",
+		AssertionAttributeRole:   "role",
+		SingleLogout:             true,
+	}
+	if configure != nil {
+		configure(&cfg.SAML)
+	}
+
+	service := &SAMLService{
+		Bus:         bus.New(),
+		Cfg:         cfg,
+		RemoteCache: remotecache.NewFakeStore(t),
+	}
+	require.NoError(t, service.Init())
+
+	idp.ServiceProviderProvider = testServiceProviderProvider{service: service}
+
+	return service, idp
+}
+
+func mustParseURL(t *testing.T, rawURL string) url.URL {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	return *u
+}
+
+var formValueRegexp = regexp.MustCompile(`name="(SAMLResponse|RelayState)" value="([^"]*)"`)
+
+// acsRequest turns the auto-submitting form rendered by the IdP into the
+// POST the browser would send to the ACS endpoint.
+func acsRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+
+	form := url.Values{}
+	for _, match := range formValueRegexp.FindAllStringSubmatch(body, -1) {
+		form.Set(match[1], html.UnescapeString(match[2]))
+	}
+	require.NotEmpty(t, form.Get("SAMLResponse"), body)
+
+	req := httptest.NewRequest("POST", "http://grafana.example.com/saml/acs", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestSAMLLogin(t *testing.T) {
+	defer func(orgId int) { setting.AutoAssignOrgId = orgId }(setting.AutoAssignOrgId)
+	setting.AutoAssignOrgId = 1
+
+	t.Run("SP-initiated login maps the assertion to an external user", func(t *testing.T) {
+		service, idp := setupTestService(t, nil)
+
+		idpURL, err := service.LoginRedirectURL("/d/abc")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(idpURL, "http://idp.example.com/sso?"))
+
+		w := httptest.NewRecorder()
+		idp.ServeSSO(w, httptest.NewRequest("GET", idpURL, nil))
+		require.Equal(t, 200, w.Code, w.Body.String())
+
+		resp, err := service.ParseLoginResponse(acsRequest(t, w.Body.String()))
+		require.NoError(t, err)
+
+		assert.Equal(t, "/d/abc", resp.RedirectTo)
+		assert.Equal(t, &models.ExternalUserInfo{
+			AuthModule: models.AuthModuleSAML,
+			AuthId:     "name-id-1",
+			Name:       "Alice Liddell",
+			Login:      "alice",
+			Email:      "alice@example.com",
+			Groups:     []string{"admins", "developers"},
+			OrgRoles:   map[int64]models.RoleType{1: models.ROLE_EDITOR},
+		}, resp.ExternalUser)
+		assert.Equal(t, "name-id-1", resp.Session.NameID)
+		assert.Equal(t, "index-1", resp.Session.SessionIndex)
+	})
+
+	t.Run("responses can only be used once", func(t *testing.T) {
+		service, idp := setupTestService(t, nil)
+
+		idpURL, err := service.LoginRedirectURL("")
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		idp.ServeSSO(w, httptest.NewRequest("GET", idpURL, nil))
+
+		_, err = service.ParseLoginResponse(acsRequest(t, w.Body.String()))
+		require.NoError(t, err)
+
+		_, err = service.ParseLoginResponse(acsRequest(t, w.Body.String()))
+		assert.Equal(t, ErrUnknownRequest, err)
+	})
+
+	t.Run("responses signed by another IdP are rejected", func(t *testing.T) {
+		service, idp := setupTestService(t, nil)
+
+		idpURL, err := service.LoginRedirectURL("")
+		require.NoError(t, err)
+
+		otherKey, otherCert := newKeyPair(t, "evil.example.com")
+		idp.Key = otherKey
+		idp.Certificate = otherCert
+
+		w := httptest.NewRecorder()
+		idp.ServeSSO(w, httptest.NewRequest("GET", idpURL, nil))
+
+		_, err = service.ParseLoginResponse(acsRequest(t, w.Body.String()))
+		assert.Error(t, err)
+	})
+
+	t.Run("IdP-initiated login is rejected unless allowed", func(t *testing.T) {
+		service, idp := setupTestService(t, nil)
+
+		w := httptest.NewRecorder()
+		idp.ServeIDPInitiated(w, httptest.NewRequest("GET", "http://idp.example.com/sso", nil), service.sp.MetadataURL.String(), "")
+
+		_, err := service.ParseLoginResponse(acsRequest(t, w.Body.String()))
+		assert.Equal(t, ErrUnknownRequest, err)
+	})
+
+	t.Run("IdP-initiated login is accepted when allowed", func(t *testing.T) {
+		service, idp := setupTestService(t, func(cfg *setting.SAMLSettings) {
+			cfg.AllowIdpInitiated = true
+		})
+
+		w := httptest.NewRecorder()
+		idp.ServeIDPInitiated(w, httptest.NewRequest("GET", "http://idp.example.com/sso", nil), service.sp.MetadataURL.String(), "//evil.example.com")
+
+		resp, err := service.ParseLoginResponse(acsRequest(t, w.Body.String()))
+		require.NoError(t, err)
+		assert.Equal(t, "alice", resp.ExternalUser.Login)
+		assert.Empty(t, resp.RedirectTo)
+	})
+
+	t.Run("roles apply to the org users are assigned to", func(t *testing.T) {
+		setting.AutoAssignOrgId = 2
+		defer func() { setting.AutoAssignOrgId = 1 }()
+
+		service, idp := setupTestService(t, nil)
+
+		idpURL, err := service.LoginRedirectURL("")
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		idp.ServeSSO(w, httptest.NewRequest("GET", idpURL, nil))
+
+		resp, err := service.ParseLoginResponse(acsRequest(t, w.Body.String()))
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]models.RoleType{2: models.ROLE_EDITOR}, resp.ExternalUser.OrgRoles)
+	})
+
+	t.Run("invalid roles are ignored", func(t *testing.T) {
+		service, idp := setupTestService(t, nil)
+		idp.AssertionMaker = roleAssertionMaker{role: "Superuser"}
+
+		idpURL, err := service.LoginRedirectURL("")
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		idp.ServeSSO(w, httptest.NewRequest("GET", idpURL, nil))
+
+		resp, err := service.ParseLoginResponse(acsRequest(t, w.Body.String()))
+		require.NoError(t, err)
+		assert.Empty(t, resp.ExternalUser.OrgRoles)
+	})
+}
+
+func TestSAMLMetadata(t *testing.T) {
+	service, _ := setupTestService(t, nil)
+
+	data, err := service.MetadataXML()
+	require.NoError(t, err)
+
+	metadata := &crewjam.EntityDescriptor{}
+	require.NoError(t, xml.Unmarshal(data, metadata))
+
+	assert.Equal(t, "http://grafana.example.com/saml/metadata", metadata.EntityID)
+	descriptor := metadata.SPSSODescriptors[0]
+	assert.Equal(t, "http://grafana.example.com/saml/acs", descriptor.AssertionConsumerServices[0].Location)
+	assert.Equal(t, "http://grafana.example.com/saml/slo", descriptor.SingleLogoutServices[0].Location)
+}
+
+func TestIsSAMLEnabledCommand(t *testing.T) {
+	service, _ := setupTestService(t, nil)
+
+	cmd := &models.IsSAMLEnabledCommand{}
+	require.NoError(t, service.Bus.Dispatch(cmd))
+	assert.True(t, cmd.Result)
+}
+
+func TestSAMLSingleLogout(t *testing.T) {
+	t.Run("logout redirects to the IdP with the user's session", func(t *testing.T) {
+		service, _ := setupTestService(t, nil)
+		require.NoError(t, service.SaveSession(10, Session{NameID: "name-id-1", NameIDFormat: "transient", SessionIndex: "index-1"}))
+
+		logoutURL, err := service.LogoutRedirectURL(10)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(logoutURL, "http://idp.example.com/slo?"), logoutURL)
+
+		u, err := url.Parse(logoutURL)
+		require.NoError(t, err)
+		assert.Equal(t, sigAlgRSASHA256, u.Query().Get("SigAlg"))
+		assert.NotEmpty(t, u.Query().Get("Signature"))
+
+		msg := &logoutRequest{}
+		decodeRedirectMessage(t, u.Query().Get("SAMLRequest"), msg)
+		assert.Equal(t, "name-id-1", msg.NameID.Value)
+		assert.Equal(t, "index-1", msg.SessionIndex)
+		assert.Equal(t, "http://grafana.example.com/saml/metadata", msg.Issuer.Value)
+
+		// the session is gone once the user logged out
+		logoutURL, err = service.LogoutRedirectURL(10)
+		require.NoError(t, err)
+		assert.Empty(t, logoutURL)
+	})
+
+	t.Run("IdP logout request logs the user out", func(t *testing.T) {
+		service, idp := setupTestService(t, nil)
+		require.NoError(t, service.SaveSession(10, Session{NameID: "name-id-1"}))
+
+		requestURL := signedRedirectURL(t, idp.key, "http://grafana.example.com/saml/slo", "SAMLRequest", &logoutRequest{
+			ID:           "id-logout",
+			Version:      "2.0",
+			IssueInstant: time.Now(),
+			Issuer:       &crewjam.Issuer{Value: "http://idp.example.com/metadata"},
+			NameID:       &crewjam.NameID{Value: "name-id-1"},
+		})
+
+		userId, responseURL, err := service.HandleLogoutRequest(httptest.NewRequest("GET", requestURL, nil))
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), userId)
+
+		u, err := url.Parse(responseURL)
+		require.NoError(t, err)
+		assert.Equal(t, "idp.example.com", u.Host)
+
+		msg := &logoutResponse{}
+		decodeRedirectMessage(t, u.Query().Get("SAMLResponse"), msg)
+		assert.Equal(t, "id-logout", msg.InResponseTo)
+		assert.Equal(t, crewjam.StatusSuccess, msg.Status.StatusCode.Value)
+	})
+
+	t.Run("logout requests not signed by the IdP are rejected", func(t *testing.T) {
+		service, _ := setupTestService(t, nil)
+		otherKey, _ := newKeyPair(t, "evil.example.com")
+
+		requestURL := signedRedirectURL(t, otherKey, "http://grafana.example.com/saml/slo", "SAMLRequest", &logoutRequest{
+			ID:           "id-logout",
+			Version:      "2.0",
+			IssueInstant: time.Now(),
+			Issuer:       &crewjam.Issuer{Value: "http://idp.example.com/metadata"},
+			NameID:       &crewjam.NameID{Value: "name-id-1"},
+		})
+
+		_, _, err := service.HandleLogoutRequest(httptest.NewRequest("GET", requestURL, nil))
+		assert.Equal(t, ErrInvalidSignature, err)
+	})
+
+	t.Run("IdP logout response is verified", func(t *testing.T) {
+		service, idp := setupTestService(t, nil)
+
+		responseURL := signedRedirectURL(t, idp.key, "http://grafana.example.com/saml/slo", "SAMLResponse", &logoutResponse{
+			ID:           "id-response",
+			Version:      "2.0",
+			IssueInstant: time.Now(),
+			Issuer:       &crewjam.Issuer{Value: "http://idp.example.com/metadata"},
+			Status:       crewjam.Status{StatusCode: crewjam.StatusCode{Value: crewjam.StatusSuccess}},
+		})
+
+		assert.NoError(t, service.HandleLogoutResponse(httptest.NewRequest("GET", responseURL, nil)))
+	})
+}
+
+func signedRedirectURL(t *testing.T, key *rsa.PrivateKey, location, param string, msg interface{}) string {
+	t.Helper()
+
+	data, err := xml.Marshal(msg)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	query := param + "=" + url.QueryEscape(base64.StdEncoding.EncodeToString(buf.Bytes())) +
+		"&RelayState=state&SigAlg=" + url.QueryEscape(sigAlgRSASHA256)
+	digest := sha256.Sum256([]byte(query))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return location + "?" + query + "&Signature=" + url.QueryEscape(base64.StdEncoding.EncodeToString(signature))
+}
+
+func decodeRedirectMessage(t *testing.T, value string, msg interface{}) {
+	t.Helper()
+
+	compressed, err := base64.StdEncoding.DecodeString(value)
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	require.NoError(t, err)
+	require.NoError(t, xml.Unmarshal(data, msg))
+}
@@ -1,9 +1,11 @@
 package search
 
 import (
+	"context"
 	"sort"
 
 	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/infra/log"
 	m "github.com/Seasheller/grafana/pkg/models"
 	"github.com/Seasheller/grafana/pkg/registry"
 )
@@ -14,13 +16,29 @@ func init() {
 
 type SearchService struct {
 	Bus bus.Bus `inject:""`
+	log log.Logger
 }
 
 func (s *SearchService) Init() error {
+	s.log = log.New("search")
 	s.Bus.AddHandler(s.searchHandler)
 	return nil
 }
 
+// Run adds dashboards that were saved before the full-text search index
+// existed to the index.
+func (s *SearchService) Run(ctx context.Context) error {
+	cmd := IndexMissingDashboardsCommand{}
+	if err := s.Bus.Dispatch(&cmd); err != nil {
+		s.log.Error("Failed to build full-text search index", "error", err)
+	} else if cmd.Result > 0 {
+		s.log.Info("Added dashboards to full-text search index", "count", cmd.Result)
+	}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
 func (s *SearchService) searchHandler(query *Query) error {
 	dashQuery := FindPersistedDashboardsQuery{
 		Title:        query.Title,
@@ -33,6 +51,9 @@ func (s *SearchService) searchHandler(query *Query) error {
 		Limit:        query.Limit,
 		Page:         query.Page,
 		Permission:   query.Permission,
+		FullText:     query.FullText,
+		Datasource:   query.Datasource,
+		PanelType:    query.PanelType,
 	}
 
 	if err := bus.Dispatch(&dashQuery); err != nil {
@@ -43,7 +64,11 @@ func (s *SearchService) searchHandler(query *Query) error {
 	hits = append(hits, dashQuery.Result...)
 
 	// sort main result array
-	sort.Sort(hits)
+	if query.FullText && len(query.Title) > 0 {
+		sort.Sort(RankedHitList(hits))
+	} else {
+		sort.Sort(hits)
+	}
 
 	// sort tags
 	for _, hit := range hits {
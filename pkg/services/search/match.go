@@ -0,0 +1,124 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Parts of a dashboard that can match a full-text search.
+const (
+	MatchTitle            = "title"
+	MatchDescription      = "description"
+	MatchPanelTitle       = "panelTitle"
+	MatchPanelDescription = "panelDescription"
+	MatchDatasource       = "datasource"
+	MatchQuery            = "query"
+)
+
+// MatchWeights is how much a match in each part of a dashboard adds to the
+// score of a hit.
+var MatchWeights = map[string]int{
+	MatchTitle:            10,
+	MatchPanelTitle:       5,
+	MatchDescription:      3,
+	MatchPanelDescription: 2,
+	MatchQuery:            2,
+	MatchDatasource:       1,
+}
+
+// maxMatchTextLength is the longest text returned for a match, long query
+// expressions are cut down to the part around the first match.
+const maxMatchTextLength = 120
+
+// Match is a part of a dashboard that matched a full-text search.
+type Match struct {
+	Field      string      `json:"field"`
+	PanelId    int64       `json:"panelId,omitempty"`
+	Text       string      `json:"text"`
+	Highlights []Highlight `json:"highlights"`
+}
+
+// Highlight is the position of the search text in Match.Text, as byte offsets.
+type Highlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// NewMatch returns the match of searchText in value, or nil if value does
+// not contain searchText. Matching is case insensitive.
+func NewMatch(field string, panelId int64, value string, searchText string) *Match {
+	if searchText == "" {
+		return nil
+	}
+
+	text := value
+	highlights := findHighlights(text, searchText)
+	if len(highlights) == 0 {
+		return nil
+	}
+
+	if len(text) > maxMatchTextLength {
+		text = snippet(text, highlights[0], len(searchText))
+		highlights = findHighlights(text, searchText)
+	}
+
+	return &Match{
+		Field:      field,
+		PanelId:    panelId,
+		Text:       text,
+		Highlights: highlights,
+	}
+}
+
+func findHighlights(text string, searchText string) []Highlight {
+	lowerText := strings.ToLower(text)
+	lowerSearch := strings.ToLower(searchText)
+
+	// ToLower can change the byte length of some runes, in which case the
+	// offsets would not line up with the original text
+	if len(lowerText) != len(text) {
+		return nil
+	}
+
+	highlights := make([]Highlight, 0)
+	offset := 0
+	for {
+		i := strings.Index(lowerText[offset:], lowerSearch)
+		if i < 0 {
+			break
+		}
+
+		start := offset + i
+		highlights = append(highlights, Highlight{Start: start, End: start + len(lowerSearch)})
+		offset = start + len(lowerSearch)
+	}
+
+	return highlights
+}
+
+// snippet cuts text down to about maxMatchTextLength bytes around the first highlight.
+func snippet(text string, first Highlight, searchLength int) string {
+	start := first.Start - (maxMatchTextLength-searchLength)/2
+	if start < 0 {
+		start = 0
+	}
+
+	end := start + maxMatchTextLength
+	if end > len(text) {
+		end = len(text)
+		start = end - maxMatchTextLength
+		if start < 0 {
+			start = 0
+		}
+	}
+
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	return text[start:end]
+}
@@ -0,0 +1,44 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSearchMatch(t *testing.T) {
+	Convey("When matching search text", t, func() {
+		Convey("Should highlight every occurrence ignoring case", func() {
+			match := NewMatch(MatchPanelTitle, 4, "Errors and more errors", "ERRORS")
+			So(match, ShouldNotBeNil)
+			So(match.PanelId, ShouldEqual, 4)
+			So(match.Highlights, ShouldResemble, []Highlight{{Start: 0, End: 6}, {Start: 16, End: 22}})
+		})
+
+		Convey("Should return nil without a match", func() {
+			So(NewMatch(MatchTitle, 0, "Overview", "latency"), ShouldBeNil)
+		})
+
+		Convey("Should cut long text down to the part around the match", func() {
+			value := strings.Repeat("a", 200) + "needle" + strings.Repeat("b", 200)
+			match := NewMatch(MatchQuery, 1, value, "needle")
+			So(len(match.Text), ShouldEqual, maxMatchTextLength)
+			So(match.Text[match.Highlights[0].Start:match.Highlights[0].End], ShouldEqual, "needle")
+		})
+	})
+
+	Convey("When ranking hits", t, func() {
+		hits := RankedHitList{
+			&Hit{Title: "B", Type: DashHitDB, Score: 2},
+			&Hit{Title: "A", Type: DashHitDB, Score: 2},
+			&Hit{Title: "C", Type: DashHitDB, Score: 10},
+		}
+		sort.Sort(hits)
+
+		So(hits[0].Title, ShouldEqual, "C")
+		So(hits[1].Title, ShouldEqual, "A")
+		So(hits[2].Title, ShouldEqual, "B")
+	})
+}
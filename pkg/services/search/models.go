@@ -25,6 +25,13 @@ type Hit struct {
 	FolderUid   string   `json:"folderUid,omitempty"`
 	FolderTitle string   `json:"folderTitle,omitempty"`
 	FolderUrl   string   `json:"folderUrl,omitempty"`
+
+	// Breadcrumbs are the folders from the root down to the folder of the hit.
+	Breadcrumbs []*models.FolderRef `json:"breadcrumbs,omitempty"`
+
+	// Score and Matches are only set for full-text searches.
+	Score   int      `json:"score,omitempty"`
+	Matches []*Match `json:"matches,omitempty"`
 }
 
 type HitList []*Hit
@@ -43,6 +50,19 @@ func (s HitList) Less(i, j int) bool {
 	return strings.ToLower(s[i].Title) < strings.ToLower(s[j].Title)
 }
 
+// RankedHitList orders full-text search hits by score, best match first.
+type RankedHitList []*Hit
+
+func (s RankedHitList) Len() int      { return len(s) }
+func (s RankedHitList) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s RankedHitList) Less(i, j int) bool {
+	if s[i].Score != s[j].Score {
+		return s[i].Score > s[j].Score
+	}
+
+	return HitList(s).Less(i, j)
+}
+
 type Query struct {
 	Title        string
 	Tags         []string
@@ -55,6 +75,9 @@ type Query struct {
 	DashboardIds []int64
 	FolderIds    []int64
 	Permission   models.PermissionType
+	FullText     bool
+	Datasource   string
+	PanelType    string
 
 	Result HitList
 }
@@ -71,6 +94,15 @@ type FindPersistedDashboardsQuery struct {
 	Limit        int64
 	Page         int64
 	Permission   models.PermissionType
+	FullText     bool
+	Datasource   string
+	PanelType    string
 
 	Result HitList
 }
+
+// IndexMissingDashboardsCommand adds dashboards that are not yet in the
+// full-text search index, e.g. dashboards saved before the index existed.
+type IndexMissingDashboardsCommand struct {
+	Result int
+}
@@ -0,0 +1,336 @@
+// Package snapshots takes dashboard snapshots on the server. The queries of
+// the panels are run through the tsdb package and their results embedded in
+// the snapshot, the same way the browser embeds them when sharing a
+// snapshot.
+package snapshots
+
+import (
+	"context"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/registry"
+	"github.com/Seasheller/grafana/pkg/services/datasources"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+	"github.com/Seasheller/grafana/pkg/tsdb"
+	"github.com/Seasheller/grafana/pkg/util"
+)
+
+const (
+	mixedDatasource   = "-- Mixed --"
+	grafanaDatasource = "-- Grafana --"
+
+	defaultMaxDataPoints = 1000
+)
+
+func init() {
+	registry.RegisterService(&SnapshotService{})
+}
+
+// SnapshotService handles models.CreateServerSideSnapshotCommand, which is
+// dispatched with bus.DispatchCtx by the API and by scheduled jobs.
+type SnapshotService struct {
+	Bus             bus.Bus                  `inject:""`
+	DatasourceCache datasources.CacheService `inject:""`
+
+	log log.Logger
+}
+
+func (s *SnapshotService) Init() error {
+	s.log = log.New("snapshots")
+	s.Bus.AddHandlerCtx(s.CreateServerSideSnapshot)
+	return nil
+}
+
+// CreateServerSideSnapshot runs the queries of all panels of the dashboard
+// for the time range and variable values of the command and stores the
+// dashboard with the results as a snapshot.
+func (s *SnapshotService) CreateServerSideSnapshot(ctx context.Context, cmd *models.CreateServerSideSnapshotCommand) error {
+	dashQuery := models.GetDashboardQuery{OrgId: cmd.OrgId, Uid: cmd.DashboardUid}
+	if err := s.Bus.Dispatch(&dashQuery); err != nil {
+		return err
+	}
+	dash := dashQuery.Result
+
+	canView, err := guardian.New(dash.Id, cmd.OrgId, cmd.User).CanView()
+	if err != nil {
+		return err
+	}
+	if !canView {
+		return models.ErrDashboardSnapshotAccessDenied
+	}
+
+	from, to := cmd.From, cmd.To
+	if from == "" {
+		from = dash.Data.Get("time").Get("from").MustString("now-6h")
+	}
+	if to == "" {
+		to = dash.Data.Get("time").Get("to").MustString("now")
+	}
+	timeRange := tsdb.NewTimeRange(from, to)
+
+	if _, err := timeRange.ParseFrom(); err != nil {
+		return err
+	}
+	if _, err := timeRange.ParseTo(); err != nil {
+		return err
+	}
+
+	b := &snapshotBuilder{
+		service:   s,
+		orgId:     cmd.OrgId,
+		user:      cmd.User,
+		timeRange: timeRange,
+		variables: newVariables(dash.Data, cmd.Variables),
+	}
+
+	data := dash.Data
+	for _, panel := range GetPanels(data) {
+		if err := b.addSnapshotData(ctx, panel); err != nil {
+			return err
+		}
+	}
+
+	b.variables.setCurrent(data)
+	scrubDashboard(data, timeRange, models.GetFullDashboardUrl(dash.Uid, dash.Slug))
+
+	name := cmd.Name
+	if name == "" {
+		name = dash.Title
+	}
+
+	createCmd := models.CreateDashboardSnapshotCommand{
+		Dashboard: data,
+		Name:      name,
+		Expires:   cmd.Expires,
+		Key:       util.GetRandomString(32),
+		DeleteKey: util.GetRandomString(32),
+		OrgId:     cmd.OrgId,
+		UserId:    cmd.User.UserId,
+	}
+
+	if err := s.Bus.Dispatch(&createCmd); err != nil {
+		return err
+	}
+
+	cmd.Result = createCmd.Result
+	return nil
+}
+
+type snapshotBuilder struct {
+	service   *SnapshotService
+	orgId     int64
+	user      *models.SignedInUser
+	timeRange *tsdb.TimeRange
+	variables *variables
+}
+
+// addSnapshotData runs the queries of the panel and stores the results in
+// the snapshotData of the panel. Queries are grouped by data source, since
+// panels using the mixed data source can query several.
+func (b *snapshotBuilder) addSnapshotData(ctx context.Context, panel *simplejson.Json) error {
+	targets := panel.Get("targets").MustArray()
+	if len(targets) == 0 {
+		return nil
+	}
+
+	panelDatasource := b.variables.interpolateString(panel.Get("datasource").MustString(), "")
+	if panelDatasource == grafanaDatasource {
+		return nil
+	}
+
+	dsNames := make([]string, 0)
+	targetsByDs := make(map[string][]*simplejson.Json)
+	for _, t := range targets {
+		target := simplejson.NewFromAny(t)
+		if target.Get("hide").MustBool() {
+			continue
+		}
+
+		dsName := panelDatasource
+		if dsName == mixedDatasource {
+			dsName = b.variables.interpolateString(target.Get("datasource").MustString(), "")
+		}
+
+		if _, exists := targetsByDs[dsName]; !exists {
+			dsNames = append(dsNames, dsName)
+		}
+		targetsByDs[dsName] = append(targetsByDs[dsName], target)
+	}
+
+	snapshotData := make([]interface{}, 0)
+	for _, dsName := range dsNames {
+		if dsName == grafanaDatasource {
+			continue
+		}
+
+		ds, err := b.getDatasource(dsName)
+		if err != nil {
+			return err
+		}
+
+		resp, err := b.query(ctx, panel, ds, targetsByDs[dsName])
+		if err != nil {
+			b.service.log.Warn("Failed to query data for snapshot", "panelId", panel.Get("id").MustInt64(), "datasource", ds.Name, "error", err)
+			continue
+		}
+
+		for _, target := range targetsByDs[dsName] {
+			result, ok := resp.Results[target.Get("refId").MustString("A")]
+			if !ok {
+				continue
+			}
+
+			if result.Error != nil {
+				b.service.log.Warn("Query for snapshot failed", "panelId", panel.Get("id").MustInt64(), "refId", result.RefId, "error", result.Error)
+				continue
+			}
+
+			snapshotData = append(snapshotData, toSnapshotData(result)...)
+		}
+	}
+
+	panel.Set("snapshotData", snapshotData)
+	return nil
+}
+
+func (b *snapshotBuilder) query(ctx context.Context, panel *simplejson.Json, ds *models.DataSource, targets []*simplejson.Json) (*tsdb.Response, error) {
+	minInterval, err := tsdb.GetIntervalFrom(ds, panel, time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	interval := tsdb.NewIntervalCalculator(nil).Calculate(b.timeRange, minInterval)
+
+	request := &tsdb.TsdbQuery{TimeRange: b.timeRange}
+	for _, target := range targets {
+		model := simplejson.NewFromAny(b.variables.interpolate(target.Interface(), ds.Type))
+		model.Set("datasourceId", ds.Id)
+		model.Set("intervalMs", interval.Milliseconds())
+
+		request.Queries = append(request.Queries, &tsdb.Query{
+			RefId:         model.Get("refId").MustString("A"),
+			MaxDataPoints: panel.Get("maxDataPoints").MustInt64(defaultMaxDataPoints),
+			IntervalMs:    interval.Milliseconds(),
+			Model:         model,
+			DataSource:    ds,
+		})
+	}
+
+	return tsdb.HandleRequest(ctx, ds, request)
+}
+
+// getDatasource returns the data source with the name, or the default data
+// source when the name is empty, if the user may query it.
+func (b *snapshotBuilder) getDatasource(name string) (*models.DataSource, error) {
+	var ds *models.DataSource
+
+	if name == "" {
+		query := models.GetDataSourcesQuery{OrgId: b.orgId}
+		if err := b.service.Bus.Dispatch(&query); err != nil {
+			return nil, err
+		}
+
+		for _, d := range query.Result {
+			if d.IsDefault {
+				ds = d
+			}
+		}
+
+		if ds == nil {
+			return nil, models.ErrDataSourceNotFound
+		}
+	} else {
+		query := models.GetDataSourceByNameQuery{OrgId: b.orgId, Name: name}
+		if err := b.service.Bus.Dispatch(&query); err != nil {
+			return nil, err
+		}
+		ds = query.Result
+	}
+
+	return b.service.DatasourceCache.GetDatasource(ds.Id, b.user, false)
+}
+
+// toSnapshotData converts a query result to the format the panels use for
+// data embedded in snapshots.
+func toSnapshotData(result *tsdb.QueryResult) []interface{} {
+	data := make([]interface{}, 0)
+
+	for _, series := range result.Series {
+		s := map[string]interface{}{
+			"target":     series.Name,
+			"datapoints": series.Points,
+		}
+		if len(series.Tags) > 0 {
+			s["tags"] = series.Tags
+		}
+		data = append(data, s)
+	}
+
+	for _, table := range result.Tables {
+		data = append(data, map[string]interface{}{
+			"type":    "table",
+			"columns": table.Columns,
+			"rows":    table.Rows,
+		})
+	}
+
+	return data
+}
+
+// GetPanels returns all panels of the dashboard, including panels in
+// collapsed rows and in rows of dashboards using the old schema.
+func GetPanels(data *simplejson.Json) []*simplejson.Json {
+	result := make([]*simplejson.Json, 0)
+
+	var walk func(panels []interface{})
+	walk = func(panels []interface{}) {
+		for _, p := range panels {
+			panel := simplejson.NewFromAny(p)
+			result = append(result, panel)
+			walk(panel.Get("panels").MustArray())
+		}
+	}
+
+	walk(data.Get("panels").MustArray())
+	for _, row := range data.Get("rows").MustArray() {
+		walk(simplejson.NewFromAny(row).Get("panels").MustArray())
+	}
+
+	return result
+}
+
+// scrubDashboard removes everything from the dashboard that would query
+// data sources when the snapshot is viewed, like the browser does when
+// sharing a snapshot.
+func scrubDashboard(data *simplejson.Json, timeRange *tsdb.TimeRange, originalUrl string) {
+	data.Set("time", map[string]interface{}{
+		"from": timeRange.GetFromAsTimeUTC().Format(time.RFC3339),
+		"to":   timeRange.GetToAsTimeUTC().Format(time.RFC3339),
+		"raw":  map[string]interface{}{"from": timeRange.From, "to": timeRange.To},
+	})
+
+	data.Set("snapshot", map[string]interface{}{
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+		"originalUrl": originalUrl,
+	})
+
+	for _, panel := range GetPanels(data) {
+		panel.Set("targets", []interface{}{})
+		panel.Set("links", []interface{}{})
+		panel.Del("datasource")
+	}
+
+	for _, v := range data.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		variable.Set("query", "")
+		variable.Set("options", []interface{}{variable.Get("current").Interface()})
+		variable.Set("refresh", 0)
+	}
+
+	if annotations, ok := data.CheckGet("annotations"); ok {
+		annotations.Set("list", []interface{}{})
+	}
+}
@@ -0,0 +1,183 @@
+package snapshots
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/guardian"
+	"github.com/Seasheller/grafana/pkg/tsdb"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type fakeQueryEndpoint struct {
+	queries []*tsdb.Query
+}
+
+func (e *fakeQueryEndpoint) Query(ctx context.Context, ds *models.DataSource, query *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	resp := &tsdb.Response{Results: make(map[string]*tsdb.QueryResult)}
+	for _, q := range query.Queries {
+		e.queries = append(e.queries, q)
+
+		result := tsdb.NewQueryResult()
+		result.RefId = q.RefId
+		result.Series = append(result.Series, tsdb.NewTimeSeries(ds.Name+" "+q.Model.Get("expr").MustString(), tsdb.NewTimeSeriesPointsFromArgs(1, 1000)))
+		resp.Results[q.RefId] = result
+	}
+	return resp, nil
+}
+
+type fakeDatasourceCache struct{}
+
+func (c *fakeDatasourceCache) GetDatasource(datasourceID int64, user *models.SignedInUser, skipCache bool) (*models.DataSource, error) {
+	for _, ds := range testDatasources {
+		if ds.Id == datasourceID {
+			return ds, nil
+		}
+	}
+	return nil, models.ErrDataSourceNotFound
+}
+
+var testDatasources = []*models.DataSource{
+	{Id: 1, OrgId: 1, Name: "prom", Type: "snapshot-test-prometheus", IsDefault: true},
+	{Id: 2, OrgId: 1, Name: "other", Type: "snapshot-test-prometheus"},
+}
+
+func TestServerSideSnapshots(t *testing.T) {
+	Convey("Given a dashboard with panels querying data sources", t, func() {
+		endpoint := &fakeQueryEndpoint{}
+		tsdb.RegisterTsdbQueryEndpoint("snapshot-test-prometheus", func(ds *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+			return endpoint, nil
+		})
+
+		data := simplejson.NewFromAny(map[string]interface{}{
+			"title": "Hosts",
+			"time":  map[string]interface{}{"from": "now-1h", "to": "now"},
+			"templating": map[string]interface{}{
+				"list": []interface{}{
+					map[string]interface{}{
+						"name":    "host",
+						"query":   "label_values(host)",
+						"current": map[string]interface{}{"text": "a", "value": "a"},
+					},
+				},
+			},
+			"panels": []interface{}{
+				map[string]interface{}{
+					"id": 1,
+					"targets": []interface{}{
+						map[string]interface{}{"refId": "A", "expr": `up{host="$host"}`},
+						map[string]interface{}{"refId": "B", "expr": "hidden", "hide": true},
+					},
+				},
+				map[string]interface{}{
+					"id":         2,
+					"datasource": mixedDatasource,
+					"targets": []interface{}{
+						map[string]interface{}{"refId": "A", "expr": "one", "datasource": "prom"},
+						map[string]interface{}{"refId": "B", "expr": "two", "datasource": "other"},
+					},
+				},
+				map[string]interface{}{"id": 3, "type": "text"},
+			},
+		})
+
+		b := bus.New()
+		b.AddHandler(func(query *models.GetDashboardQuery) error {
+			query.Result = &models.Dashboard{Id: 1, OrgId: 1, Uid: query.Uid, Title: "Hosts", Data: data}
+			return nil
+		})
+		b.AddHandler(func(query *models.GetDataSourcesQuery) error {
+			query.Result = testDatasources
+			return nil
+		})
+		b.AddHandler(func(query *models.GetDataSourceByNameQuery) error {
+			for _, ds := range testDatasources {
+				if ds.Name == query.Name {
+					query.Result = ds
+					return nil
+				}
+			}
+			return models.ErrDataSourceNotFound
+		})
+
+		var stored *models.CreateDashboardSnapshotCommand
+		b.AddHandler(func(cmd *models.CreateDashboardSnapshotCommand) error {
+			stored = cmd
+			cmd.Result = &models.DashboardSnapshot{Key: cmd.Key, Dashboard: cmd.Dashboard}
+			return nil
+		})
+
+		service := &SnapshotService{Bus: b, DatasourceCache: &fakeDatasourceCache{}, log: log.New("snapshots.test")}
+
+		origNewGuardian := guardian.New
+		defer func() {
+			guardian.New = origNewGuardian
+		}()
+
+		cmd := &models.CreateServerSideSnapshotCommand{
+			DashboardUid: "hosts",
+			OrgId:        1,
+			User:         &models.SignedInUser{UserId: 1, OrgId: 1},
+		}
+
+		Convey("When the user cannot view the dashboard", func() {
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: false})
+
+			err := service.CreateServerSideSnapshot(context.Background(), cmd)
+			So(err, ShouldEqual, models.ErrDashboardSnapshotAccessDenied)
+			So(stored, ShouldBeNil)
+		})
+
+		Convey("When taking a snapshot", func() {
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+
+			cmd.Variables = map[string][]string{"host": {"b", "c"}}
+			So(service.CreateServerSideSnapshot(context.Background(), cmd), ShouldBeNil)
+			So(cmd.Result, ShouldNotBeNil)
+
+			Convey("Should run the visible queries with the variables replaced", func() {
+				So(len(endpoint.queries), ShouldEqual, 3)
+				So(endpoint.queries[0].Model.Get("expr").MustString(), ShouldEqual, `up{host="{b,c}"}`)
+				So(endpoint.queries[0].DataSource.Name, ShouldEqual, "prom")
+				So(endpoint.queries[2].DataSource.Name, ShouldEqual, "other")
+			})
+
+			Convey("Should embed the results in the panels and remove the queries", func() {
+				So(stored.Name, ShouldEqual, "Hosts")
+
+				panels := stored.Dashboard.Get("panels")
+				first := panels.GetIndex(0)
+				So(len(first.Get("targets").MustArray()), ShouldEqual, 0)
+				So(len(first.Get("snapshotData").MustArray()), ShouldEqual, 1)
+				So(first.Get("snapshotData").GetIndex(0).Get("target").MustString(), ShouldEqual, `prom up{host="{b,c}"}`)
+
+				So(len(panels.GetIndex(1).Get("snapshotData").MustArray()), ShouldEqual, 2)
+
+				_, hasData := panels.GetIndex(2).CheckGet("snapshotData")
+				So(hasData, ShouldBeFalse)
+			})
+
+			Convey("Should store the variable values and an absolute time range", func() {
+				variable := stored.Dashboard.Get("templating").Get("list").GetIndex(0)
+				So(variable.Get("query").MustString(), ShouldEqual, "")
+				So(variable.Get("current").Get("text").MustString(), ShouldEqual, "b + c")
+
+				So(stored.Dashboard.Get("time").Get("raw").Get("from").MustString(), ShouldEqual, "now-1h")
+				So(stored.Dashboard.Get("time").Get("from").MustString(), ShouldNotEqual, "now-1h")
+			})
+		})
+	})
+}
+
+func TestFormatVariableValues(t *testing.T) {
+	Convey("Should format multiple values for the data source", t, func() {
+		So(formatValues([]string{"a.b", "c"}, "prometheus"), ShouldEqual, `(a\.b|c)`)
+		So(formatValues([]string{"a", "o'b"}, "postgres"), ShouldEqual, `'a','o''b'`)
+		So(formatValues([]string{"a", "b"}, "graphite"), ShouldEqual, "{a,b}")
+		So(formatValues([]string{"a"}, "prometheus"), ShouldEqual, "a")
+	})
+}
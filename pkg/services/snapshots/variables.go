@@ -0,0 +1,174 @@
+package snapshots
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+)
+
+const allValue = "$__all"
+
+// variableRegex matches $var, [[var]] and ${var}, with an optional format in
+// the last two forms.
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+)(?::\w+)?\]\]|\$\{(\w+)(?::\w+)?\}`)
+
+// variables holds the values of the template variables of a dashboard.
+type variables struct {
+	values map[string][]string
+}
+
+// newVariables collects the current values of the template variables of the
+// dashboard, replaced by the values given for the snapshot.
+func newVariables(data *simplejson.Json, overrides map[string][]string) *variables {
+	v := &variables{values: make(map[string][]string)}
+
+	for _, item := range data.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(item)
+		name := variable.Get("name").MustString()
+		if name == "" {
+			continue
+		}
+
+		values, ok := overrides[name]
+		if !ok {
+			values = toStrings(variable.Get("current").Get("value").Interface())
+		}
+
+		if len(values) == 1 && values[0] == allValue {
+			values = allValues(variable)
+		}
+
+		v.values[name] = values
+	}
+
+	return v
+}
+
+// Interpolate returns a copy of the query model with the template variables
+// replaced by their current values in the dashboard, formatted for the data
+// source type.
+func Interpolate(data *simplejson.Json, model interface{}, dsType string) interface{} {
+	return newVariables(data, nil).interpolate(model, dsType)
+}
+
+// allValues returns what the All option of the variable stands for.
+func allValues(variable *simplejson.Json) []string {
+	if custom := variable.Get("allValue").MustString(); custom != "" {
+		return []string{custom}
+	}
+
+	values := make([]string, 0)
+	for _, o := range variable.Get("options").MustArray() {
+		option := simplejson.NewFromAny(o)
+		if value := option.Get("value").MustString(); value != allValue {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// setCurrent stores the values used for the snapshot as the current values
+// of the variables.
+func (v *variables) setCurrent(data *simplejson.Json) {
+	for _, item := range data.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(item)
+		values, ok := v.values[variable.Get("name").MustString()]
+		if !ok {
+			continue
+		}
+
+		var value interface{} = values
+		if len(values) == 1 && !variable.Get("multi").MustBool() {
+			value = values[0]
+		}
+
+		variable.Set("current", map[string]interface{}{
+			"text":  strings.Join(values, " + "),
+			"value": value,
+		})
+	}
+}
+
+// interpolate returns a copy of the query model with the variables replaced
+// in all strings, formatted for the data source type.
+func (v *variables) interpolate(value interface{}, dsType string) interface{} {
+	switch val := value.(type) {
+	case string:
+		return v.interpolateString(val, dsType)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		for key, item := range val {
+			result[key] = v.interpolate(item, dsType)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, item := range val {
+			result[i] = v.interpolate(item, dsType)
+		}
+		return result
+	}
+	return value
+}
+
+// interpolateString replaces the variables in the string. Unknown variables,
+// like the built-in $__interval, are left for the data source to replace.
+func (v *variables) interpolateString(s string, dsType string) string {
+	return variableRegex.ReplaceAllStringFunc(s, func(match string) string {
+		groups := variableRegex.FindStringSubmatch(match)
+		name := groups[1] + groups[2] + groups[3]
+
+		values, ok := v.values[name]
+		if !ok {
+			return match
+		}
+
+		return formatValues(values, dsType)
+	})
+}
+
+// formatValues formats the values of a variable the way the data source
+// expects them.
+func formatValues(values []string, dsType string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	if len(values) == 1 {
+		return values[0]
+	}
+
+	switch dsType {
+	case "prometheus", "influxdb", "loki":
+		escaped := make([]string, len(values))
+		for i, value := range values {
+			escaped[i] = regexp.QuoteMeta(value)
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "mysql", "postgres", "mssql":
+		quoted := make([]string, len(values))
+		for i, value := range values {
+			quoted[i] = "'" + strings.Replace(value, "'", "''", -1) + "'"
+		}
+		return strings.Join(quoted, ",")
+	}
+
+	return "{" + strings.Join(values, ",") + "}"
+}
+
+func toStrings(value interface{}) []string {
+	switch val := value.(type) {
+	case nil:
+		return []string{}
+	case string:
+		return []string{val}
+	case []interface{}:
+		result := make([]string, 0, len(val))
+		for _, item := range val {
+			result = append(result, fmt.Sprint(item))
+		}
+		return result
+	}
+	return []string{fmt.Sprint(value)}
+}
@@ -0,0 +1,131 @@
+package sqlstore
+
+import (
+	"strings"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", CreateAuditEntry)
+	bus.AddHandler("sql", DeleteOldAuditEntries)
+	bus.AddHandler("sql", SearchAuditEntries)
+}
+
+func CreateAuditEntry(cmd *models.CreateAuditEntryCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		entry := &models.AuditEntry{
+			OrgId:     cmd.OrgId,
+			UserId:    cmd.UserId,
+			Login:     cmd.Login,
+			Action:    cmd.Action,
+			Resource:  cmd.Resource,
+			Target:    cmd.Target,
+			Method:    cmd.Method,
+			Status:    cmd.Status,
+			IpAddress: cmd.IpAddress,
+			RequestId: cmd.RequestId,
+			Created:   timeNow(),
+		}
+
+		if _, err := sess.Insert(entry); err != nil {
+			return err
+		}
+
+		cmd.Result = entry
+		return nil
+	})
+}
+
+func DeleteOldAuditEntries(cmd *models.DeleteOldAuditEntriesCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		result, err := sess.Exec("DELETE FROM audit_entry WHERE created < ?", cmd.OlderThan)
+		if err != nil {
+			return err
+		}
+
+		cmd.DeletedRows, err = result.RowsAffected()
+		return err
+	})
+}
+
+func SearchAuditEntries(query *models.SearchAuditEntriesQuery) error {
+	query.Result = models.SearchAuditEntriesQueryResult{
+		Entries: make([]*models.AuditEntry, 0),
+	}
+
+	whereConditions := make([]string, 0)
+	whereParams := make([]interface{}, 0)
+
+	if query.OrgId > 0 {
+		whereConditions = append(whereConditions, "org_id = ?")
+		whereParams = append(whereParams, query.OrgId)
+	}
+
+	if query.UserId > 0 {
+		whereConditions = append(whereConditions, "user_id = ?")
+		whereParams = append(whereParams, query.UserId)
+	}
+
+	if query.Login != "" {
+		whereConditions = append(whereConditions, "login = ?")
+		whereParams = append(whereParams, query.Login)
+	}
+
+	if query.Action != "" {
+		whereConditions = append(whereConditions, "action = ?")
+		whereParams = append(whereParams, query.Action)
+	}
+
+	if query.Resource != "" {
+		whereConditions = append(whereConditions, "resource = ?")
+		whereParams = append(whereParams, query.Resource)
+	}
+
+	if !query.From.IsZero() {
+		whereConditions = append(whereConditions, "created >= ?")
+		whereParams = append(whereParams, query.From)
+	}
+
+	if !query.To.IsZero() {
+		whereConditions = append(whereConditions, "created <= ?")
+		whereParams = append(whereParams, query.To)
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	sess := x.Table("audit_entry")
+	if len(whereConditions) > 0 {
+		sess.Where(strings.Join(whereConditions, " AND "), whereParams...)
+	}
+
+	offset := query.Limit * (query.Page - 1)
+	sess.Limit(query.Limit, offset)
+	sess.OrderBy("id DESC")
+	if err := sess.Find(&query.Result.Entries); err != nil {
+		return err
+	}
+
+	countSess := x.Table("audit_entry")
+	if len(whereConditions) > 0 {
+		countSess.Where(strings.Join(whereConditions, " AND "), whereParams...)
+	}
+
+	count, err := countSess.Count(&models.AuditEntry{})
+	if err != nil {
+		return err
+	}
+
+	query.Result.TotalCount = count
+	query.Result.Page = query.Page
+	query.Result.PerPage = query.Limit
+
+	return nil
+}
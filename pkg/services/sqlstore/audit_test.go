@@ -0,0 +1,81 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditDataAccess(t *testing.T) {
+	t.Run("Testing audit data access", func(t *testing.T) {
+		InitTestDB(t)
+
+		now := time.Date(2019, 8, 1, 12, 0, 0, 0, time.UTC)
+		timeNow = func() time.Time { return now }
+		defer resetTimeNow()
+
+		entries := []models.CreateAuditEntryCommand{
+			{OrgId: 1, UserId: 1, Login: "admin", Action: "create", Resource: "datasources", Target: "/api/datasources", Method: "POST", Status: 200},
+			{OrgId: 1, UserId: 2, Login: "editor", Action: "delete", Resource: "dashboards", Target: "/api/dashboards/uid/abc", Method: "DELETE", Status: 200},
+			{OrgId: 2, UserId: 1, Login: "admin", Action: "update", Resource: "org", Target: "/api/org", Method: "PUT", Status: 403},
+		}
+
+		for i := range entries {
+			err := CreateAuditEntry(&entries[i])
+			assert.Nil(t, err)
+			assert.NotZero(t, entries[i].Result.Id)
+			now = now.Add(time.Hour)
+		}
+
+		t.Run("Should return all entries newest first", func(t *testing.T) {
+			query := models.SearchAuditEntriesQuery{}
+			err := SearchAuditEntries(&query)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(3), query.Result.TotalCount)
+			assert.Len(t, query.Result.Entries, 3)
+			assert.Equal(t, "org", query.Result.Entries[0].Resource)
+		})
+
+		t.Run("Should filter by org and action", func(t *testing.T) {
+			query := models.SearchAuditEntriesQuery{OrgId: 1, Action: "delete"}
+			err := SearchAuditEntries(&query)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(1), query.Result.TotalCount)
+			assert.Equal(t, "editor", query.Result.Entries[0].Login)
+		})
+
+		t.Run("Should filter by login and time range", func(t *testing.T) {
+			query := models.SearchAuditEntriesQuery{
+				Login: "admin",
+				From:  time.Date(2019, 8, 1, 13, 0, 0, 0, time.UTC),
+			}
+			err := SearchAuditEntries(&query)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(1), query.Result.TotalCount)
+			assert.Equal(t, int64(2), query.Result.Entries[0].OrgId)
+		})
+
+		t.Run("Should page results", func(t *testing.T) {
+			query := models.SearchAuditEntriesQuery{Page: 2, Limit: 2}
+			err := SearchAuditEntries(&query)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(3), query.Result.TotalCount)
+			assert.Len(t, query.Result.Entries, 1)
+			assert.Equal(t, "datasources", query.Result.Entries[0].Resource)
+		})
+
+		t.Run("Should delete entries older than the given time", func(t *testing.T) {
+			cmd := models.DeleteOldAuditEntriesCommand{OlderThan: time.Date(2019, 8, 1, 13, 30, 0, 0, time.UTC)}
+			err := DeleteOldAuditEntries(&cmd)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(2), cmd.DeletedRows)
+
+			query := models.SearchAuditEntriesQuery{}
+			err = SearchAuditEntries(&query)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(1), query.Result.TotalCount)
+		})
+	})
+}
@@ -5,9 +5,11 @@ import (
 	"time"
 
 	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/events"
 	"github.com/Seasheller/grafana/pkg/infra/metrics"
 	m "github.com/Seasheller/grafana/pkg/models"
 	"github.com/Seasheller/grafana/pkg/services/search"
+	"github.com/Seasheller/grafana/pkg/setting"
 	"github.com/Seasheller/grafana/pkg/util"
 )
 
@@ -44,6 +46,8 @@ func saveDashboard(sess *DBSession, cmd *m.SaveDashboardCommand) error {
 		userId = -1
 	}
 
+	folderParentChanged := dash.IsFolder
+
 	if dash.Id > 0 {
 		var existing m.Dashboard
 		dashWithIdExists, err := sess.Where("id=? AND org_id=?", dash.Id, dash.OrgId).Get(&existing)
@@ -67,6 +71,14 @@ func saveDashboard(sess *DBSession, cmd *m.SaveDashboardCommand) error {
 		if existing.PluginId != "" && !cmd.Overwrite {
 			return m.UpdatePluginDashboardError{PluginId: existing.PluginId}
 		}
+
+		folderParentChanged = dash.IsFolder && dash.FolderId != existing.FolderId
+	}
+
+	if folderParentChanged {
+		if err := validateFolderParent(sess, dash); err != nil {
+			return err
+		}
 	}
 
 	if dash.Uid == "" {
@@ -111,6 +123,12 @@ func saveDashboard(sess *DBSession, cmd *m.SaveDashboardCommand) error {
 		return m.ErrDashboardNotFound
 	}
 
+	if folderParentChanged {
+		if err := updateFolderAncestors(sess, dash); err != nil {
+			return err
+		}
+	}
+
 	dashVersion := &m.DashboardVersion{
 		DashboardId:   dash.Id,
 		ParentVersion: parentVersion,
@@ -145,8 +163,27 @@ func saveDashboard(sess *DBSession, cmd *m.SaveDashboardCommand) error {
 		}
 	}
 
+	if err := updateDashboardSearchTerms(sess, dash); err != nil {
+		return err
+	}
+
+	if err := updateLibraryPanelConnections(sess, dash); err != nil {
+		return err
+	}
+
 	cmd.Result = dash
 
+	sess.publishAfterCommit(&events.DashboardSaved{
+		Timestamp: dash.Updated,
+		Id:        dash.Id,
+		Uid:       dash.Uid,
+		OrgId:     dash.OrgId,
+		Title:     dash.Title,
+		Version:   dash.Version,
+		UserId:    cmd.UserId,
+		IsFolder:  dash.IsFolder,
+	})
+
 	return err
 }
 
@@ -179,6 +216,11 @@ func GetDashboard(query *m.GetDashboardQuery) error {
 
 	dashboard.SetId(dashboard.Id)
 	dashboard.SetUid(dashboard.Uid)
+
+	if err := expandDashboardLibraryPanels(&dashboard); err != nil {
+		return err
+	}
+
 	query.Result = &dashboard
 	return nil
 }
@@ -217,6 +259,18 @@ func findDashboards(query *search.FindPersistedDashboardsQuery) ([]DashboardSear
 		sb.WithFolderIds(query.FolderIds)
 	}
 
+	if query.FullText {
+		sb.WithFullText()
+	}
+
+	if len(query.Datasource) > 0 {
+		sb.WithDatasource(query.Datasource)
+	}
+
+	if len(query.PanelType) > 0 {
+		sb.WithPanelType(query.PanelType)
+	}
+
 	var res []DashboardSearchProjection
 
 	sql, params := sb.ToSql()
@@ -236,6 +290,32 @@ func SearchDashboards(query *search.FindPersistedDashboardsQuery) error {
 
 	makeQueryResult(query, res)
 
+	if err := setSearchHitMatches(query); err != nil {
+		return err
+	}
+
+	return setSearchHitBreadcrumbs(query)
+}
+
+func setSearchHitBreadcrumbs(query *search.FindPersistedDashboardsQuery) error {
+	folderIds := make([]int64, 0)
+	seen := make(map[int64]bool)
+	for _, hit := range query.Result {
+		if hit.FolderId > 0 && !seen[hit.FolderId] {
+			seen[hit.FolderId] = true
+			folderIds = append(folderIds, hit.FolderId)
+		}
+	}
+
+	paths, err := getFolderPaths(query.SignedInUser.OrgId, folderIds)
+	if err != nil {
+		return err
+	}
+
+	for _, hit := range query.Result {
+		hit.Breadcrumbs = paths[hit.FolderId]
+	}
+
 	return nil
 }
 
@@ -309,24 +389,47 @@ func DeleteDashboard(cmd *m.DeleteDashboardCommand) error {
 			return m.ErrDashboardNotFound
 		}
 
+		if setting.DashboardTrashRetentionDays > 0 {
+			return moveDashboardToTrash(sess, &dashboard)
+		}
+
 		deletes := []string{
 			"DELETE FROM dashboard_tag WHERE dashboard_id = ? ",
+			"DELETE FROM dashboard_search_term WHERE dashboard_id = ? ",
+			"DELETE FROM library_panel_dashboard WHERE dashboard_id = ? ",
 			"DELETE FROM star WHERE dashboard_id = ? ",
 			"DELETE FROM dashboard WHERE id = ?",
 			"DELETE FROM playlist_item WHERE type = 'dashboard_by_id' AND value = ?",
 			"DELETE FROM dashboard_version WHERE dashboard_id = ?",
 			"DELETE FROM annotation WHERE dashboard_id = ?",
 			"DELETE FROM dashboard_provisioning WHERE dashboard_id = ?",
+			"DELETE FROM public_dashboard WHERE dashboard_id = ?",
+			"DELETE FROM dashboard_version_retention WHERE folder_id = ?",
 		}
 
 		if dashboard.IsFolder {
-			deletes = append(deletes, "DELETE FROM dashboard_provisioning WHERE dashboard_id in (select id from dashboard where folder_id = ?)")
-			deletes = append(deletes, "DELETE FROM dashboard WHERE folder_id = ?")
+			// deleting a folder also deletes its subfolders and everything in them
+			folderIds, err := getFolderSubtreeIds(sess, dashboard.Id)
+			if err != nil {
+				return err
+			}
+
+			inFolders := "(?" + strings.Repeat(",?", len(folderIds)-1) + ")"
+			params := make([]interface{}, 0, len(folderIds))
+			for _, id := range folderIds {
+				params = append(params, id)
+			}
+
+			if err := checkFolderLibraryPanelsNotInUse(sess, inFolders, params); err != nil {
+				return err
+			}
 
 			dashIds := []struct {
-				Id int64
+				Id       int64
+				Uid      string
+				IsFolder bool
 			}{}
-			err := sess.SQL("select id from dashboard where folder_id = ?", dashboard.Id).Find(&dashIds)
+			err = sess.SQL("select id, uid, is_folder from dashboard where folder_id IN "+inFolders, params...).Find(&dashIds)
 			if err != nil {
 				return err
 			}
@@ -335,6 +438,26 @@ func DeleteDashboard(cmd *m.DeleteDashboardCommand) error {
 				if err := deleteAlertDefinition(id.Id, sess); err != nil {
 					return nil
 				}
+
+				publishDashboardDeleted(sess, &m.Dashboard{Id: id.Id, Uid: id.Uid, OrgId: dashboard.OrgId, IsFolder: id.IsFolder})
+			}
+
+			folderDeletes := []string{
+				"DELETE FROM dashboard_provisioning WHERE dashboard_id in (select id from dashboard where folder_id IN " + inFolders + ")",
+				"DELETE FROM dashboard_search_term WHERE dashboard_id in (select id from dashboard where folder_id IN " + inFolders + ")",
+				"DELETE FROM library_panel_dashboard WHERE dashboard_id in (select id from dashboard where folder_id IN " + inFolders + ")",
+				"DELETE FROM public_dashboard WHERE dashboard_id in (select id from dashboard where folder_id IN " + inFolders + ")",
+				"DELETE FROM library_panel_version WHERE library_panel_id in (select id from library_panel where folder_id IN " + inFolders + ")",
+				"DELETE FROM library_panel WHERE folder_id IN " + inFolders,
+				"DELETE FROM dashboard WHERE folder_id IN " + inFolders,
+				"DELETE FROM folder_ancestor WHERE folder_id IN " + inFolders,
+				"DELETE FROM dashboard_version_retention WHERE folder_id IN " + inFolders,
+			}
+
+			for _, sql := range folderDeletes {
+				if _, err := sess.Exec(append([]interface{}{sql}, params...)...); err != nil {
+					return err
+				}
 			}
 		}
 
@@ -350,10 +473,24 @@ func DeleteDashboard(cmd *m.DeleteDashboardCommand) error {
 			}
 		}
 
+		publishDashboardDeleted(sess, &dashboard)
+
 		return nil
 	})
 }
 
+// publishDashboardDeleted notifies the listeners that the dashboard is gone
+// once the transaction deleting it is committed.
+func publishDashboardDeleted(sess *DBSession, dash *m.Dashboard) {
+	sess.publishAfterCommit(&events.DashboardDeleted{
+		Timestamp: time.Now(),
+		Id:        dash.Id,
+		Uid:       dash.Uid,
+		OrgId:     dash.OrgId,
+		IsFolder:  dash.IsFolder,
+	})
+}
+
 func GetDashboards(query *m.GetDashboardsQuery) error {
 	if len(query.DashboardIds) == 0 {
 		return m.ErrCommandValidationFailed
@@ -392,7 +529,7 @@ func GetDashboardPermissionsForUser(query *m.GetDashboardPermissionsForUserQuery
 	// check dashboards that have ACLs via user id, team id or role
 	sql := `SELECT d.id AS dashboard_id, MAX(COALESCE(da.permission, pt.permission)) AS permission
 	FROM dashboard AS d
-		LEFT JOIN dashboard_acl as da on d.folder_id = da.dashboard_id or d.id = da.dashboard_id
+		LEFT JOIN dashboard_acl as da on d.id = da.dashboard_id or da.dashboard_id IN (` + folderAncestorIdsSQL("d.folder_id") + `)
 		LEFT JOIN team_member as ugm on ugm.team_id =  da.team_id
 		LEFT JOIN org_user ou ON ou.role = da.role AND ou.user_id = ?
 	`
@@ -413,19 +550,20 @@ func GetDashboardPermissionsForUser(query *m.GetDashboardPermissionsForUserQuery
 		params = append(params, id)
 	}
 
+	// permissions set on any of the parent folders apply to the dashboard as well
+	hasAcl := `(d.has_acl = ` + dialect.BooleanStr(true) + ` OR ` + folderChainHasAclSQL("d.folder_id") + `)`
+
 	sql += ` AND
 	d.org_id = ? AND
 	  (
-		(d.has_acl = ?  AND (da.user_id = ? OR ugm.user_id = ? OR ou.id IS NOT NULL))
-		OR (d.has_acl = ? AND ouRole.id IS NOT NULL)
+		(` + hasAcl + ` AND (da.user_id = ? OR ugm.user_id = ? OR ou.id IS NOT NULL))
+		OR (NOT ` + hasAcl + ` AND ouRole.id IS NOT NULL)
 	)
 	group by d.id
 	order by d.id asc`
 	params = append(params, query.OrgId)
-	params = append(params, dialect.BooleanStr(true))
 	params = append(params, query.UserId)
 	params = append(params, query.UserId)
-	params = append(params, dialect.BooleanStr(false))
 
 	err := x.SQL(sql, params...).Find(&query.Result)
 
@@ -561,7 +699,7 @@ func getExistingDashboardByIdOrUidForUpdate(sess *DBSession, cmd *m.ValidateDash
 		return m.ErrDashboardTypeMismatch
 	}
 
-	if !dash.IsFolder && dash.FolderId != existing.FolderId {
+	if dash.FolderId != existing.FolderId {
 		cmd.Result.IsParentFolderChanged = true
 	}
 
@@ -586,7 +724,15 @@ func getExistingDashboardByTitleAndFolder(sess *DBSession, cmd *m.ValidateDashbo
 	dash := cmd.Dashboard
 	var existing m.Dashboard
 
-	exists, err := sess.Where("org_id=? AND slug=? AND (is_folder=? OR folder_id=?)", dash.OrgId, dash.Slug, dialect.BooleanStr(true), dash.FolderId).Get(&existing)
+	var exists bool
+	var err error
+
+	// subfolders only need a unique name within their parent folder
+	if dash.IsFolder && dash.FolderId > 0 {
+		exists, err = sess.Where("org_id=? AND slug=? AND folder_id=?", dash.OrgId, dash.Slug, dash.FolderId).Get(&existing)
+	} else {
+		exists, err = sess.Where("org_id=? AND slug=? AND ((is_folder=? AND folder_id=0) OR folder_id=?)", dash.OrgId, dash.Slug, dialect.BooleanStr(true), dash.FolderId).Get(&existing)
+	}
 	if err != nil {
 		return err
 	}
@@ -623,6 +769,10 @@ func ValidateDashboardBeforeSave(cmd *m.ValidateDashboardBeforeSaveCommand) (err
 			return err
 		}
 
+		if err = validateFolderParent(sess, cmd.Dashboard); err != nil {
+			return err
+		}
+
 		if err = getExistingDashboardByTitleAndFolder(sess, cmd); err != nil {
 			return err
 		}
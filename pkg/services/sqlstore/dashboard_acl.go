@@ -43,8 +43,8 @@ func UpdateDashboardAcl(cmd *m.UpdateDashboardAclCommand) error {
 // GetDashboardAclInfoList returns a list of permissions for a dashboard. They can be fetched from three
 // different places.
 // 1) Permissions for the dashboard
-// 2) permissions for its parent folder
-// 3) if no specific permissions have been set for the dashboard or its parent folder then get the default permissions
+// 2) permissions for its parent folder and the folders above it
+// 3) if no specific permissions have been set for the dashboard or its parent folders then get the default permissions
 func GetDashboardAclInfoList(query *m.GetDashboardAclInfoListQuery) error {
 	var err error
 
@@ -96,16 +96,16 @@ func GetDashboardAclInfoList(query *m.GetDashboardAclInfoListQuery) error {
 				d.slug,
 				d.uid,
 				d.is_folder,
-				CASE WHEN (da.dashboard_id = -1 AND d.folder_id > 0) OR da.dashboard_id = d.folder_id THEN ` + dialect.BooleanStr(true) + ` ELSE ` + falseStr + ` END AS inherited
+				CASE WHEN (da.dashboard_id = -1 AND d.folder_id > 0) OR (da.dashboard_id > 0 AND da.dashboard_id <> d.id) THEN ` + dialect.BooleanStr(true) + ` ELSE ` + falseStr + ` END AS inherited
 			FROM dashboard as d
 				LEFT JOIN dashboard folder on folder.id = d.folder_id
 				LEFT JOIN dashboard_acl AS da ON
 				da.dashboard_id = d.id OR
-				da.dashboard_id = d.folder_id OR
+				da.dashboard_id IN (` + folderAncestorIdsSQL("d.folder_id") + `) OR
 				(
 					-- include default permissions -->
 					da.org_id = -1 AND (
-					  (folder.id IS NOT NULL AND folder.has_acl = ` + falseStr + `) OR
+					  (folder.id IS NOT NULL AND NOT ` + folderChainHasAclSQL("d.folder_id") + `) OR
 					  (folder.id IS NULL AND d.has_acl = ` + falseStr + `)
 					)
 				)
@@ -0,0 +1,207 @@
+package sqlstore
+
+import (
+	"strings"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", GetFolderPaths)
+}
+
+type folderAncestor struct {
+	Id         int64
+	OrgId      int64
+	FolderId   int64
+	AncestorId int64
+	Depth      int
+}
+
+func (folderAncestor) TableName() string {
+	return "folder_ancestor"
+}
+
+// folderAncestorIdsSQL selects the id of the folder in folderIdColumn and the
+// ids of all its parent folders.
+func folderAncestorIdsSQL(folderIdColumn string) string {
+	return `SELECT ancestor_id FROM folder_ancestor WHERE folder_id = ` + folderIdColumn
+}
+
+// folderChainHasAclSQL is true when the folder in folderIdColumn or any of
+// its parent folders has its own permissions.
+func folderChainHasAclSQL(folderIdColumn string) string {
+	return `EXISTS (
+		SELECT 1 FROM folder_ancestor AS fa
+			INNER JOIN dashboard AS af ON af.id = fa.ancestor_id
+		WHERE fa.folder_id = ` + folderIdColumn + ` AND af.has_acl = ` + dialect.BooleanStr(true) + `)`
+}
+
+// validateFolderParent checks that moving the folder below its new parent
+// does not create a cycle and stays within MaxFolderDepth.
+func validateFolderParent(sess *DBSession, dash *m.Dashboard) error {
+	if !dash.IsFolder || dash.FolderId == 0 {
+		return nil
+	}
+
+	parentAncestors := make([]*folderAncestor, 0)
+	if err := sess.Where("folder_id=?", dash.FolderId).Find(&parentAncestors); err != nil {
+		return err
+	}
+
+	subtreeDepth := 0
+	if dash.Id > 0 {
+		descendants := make([]*folderAncestor, 0)
+		if err := sess.Where("ancestor_id=?", dash.Id).Find(&descendants); err != nil {
+			return err
+		}
+
+		// the folder itself is its own descendant at depth 0
+		for _, d := range descendants {
+			if d.FolderId == dash.FolderId {
+				return m.ErrDashboardFolderCannotBeOwnParent
+			}
+			if d.Depth > subtreeDepth {
+				subtreeDepth = d.Depth
+			}
+		}
+	}
+
+	if len(parentAncestors)+1+subtreeDepth > m.MaxFolderDepth {
+		return m.ErrDashboardFolderMaxDepthExceeded
+	}
+
+	return nil
+}
+
+// updateFolderAncestors places the saved folder, and every folder below it,
+// under the ancestors of its parent folder.
+func updateFolderAncestors(sess *DBSession, dash *m.Dashboard) error {
+	descendants := make([]*folderAncestor, 0)
+	if err := sess.Where("ancestor_id=?", dash.Id).Find(&descendants); err != nil {
+		return err
+	}
+
+	if len(descendants) == 0 {
+		self := &folderAncestor{OrgId: dash.OrgId, FolderId: dash.Id, AncestorId: dash.Id}
+		if _, err := sess.Insert(self); err != nil {
+			return err
+		}
+		descendants = append(descendants, self)
+	}
+
+	subtree := make([]interface{}, 0, len(descendants))
+	for _, d := range descendants {
+		subtree = append(subtree, d.FolderId)
+	}
+
+	placeholders := "?" + strings.Repeat(",?", len(subtree)-1)
+	rawSQL := "DELETE FROM folder_ancestor WHERE folder_id IN (" + placeholders + ") AND ancestor_id NOT IN (" + placeholders + ")"
+	params := append([]interface{}{rawSQL}, subtree...)
+	params = append(params, subtree...)
+	if _, err := sess.Exec(params...); err != nil {
+		return err
+	}
+
+	if dash.FolderId == 0 {
+		return nil
+	}
+
+	parentAncestors := make([]*folderAncestor, 0)
+	if err := sess.Where("folder_id=?", dash.FolderId).Find(&parentAncestors); err != nil {
+		return err
+	}
+
+	for _, d := range descendants {
+		for _, a := range parentAncestors {
+			row := &folderAncestor{
+				OrgId:      dash.OrgId,
+				FolderId:   d.FolderId,
+				AncestorId: a.AncestorId,
+				Depth:      d.Depth + 1 + a.Depth,
+			}
+			if _, err := sess.Insert(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// getFolderSubtreeIds returns the id of the folder and of every folder below it.
+func getFolderSubtreeIds(sess *DBSession, folderId int64) ([]int64, error) {
+	descendants := make([]*folderAncestor, 0)
+	if err := sess.Where("ancestor_id=?", folderId).Find(&descendants); err != nil {
+		return nil, err
+	}
+
+	ids := []int64{folderId}
+	for _, d := range descendants {
+		if d.FolderId != folderId {
+			ids = append(ids, d.FolderId)
+		}
+	}
+
+	return ids, nil
+}
+
+type folderPathProjection struct {
+	FolderId int64
+	Depth    int
+	Id       int64
+	Uid      string
+	Title    string
+	Slug     string
+}
+
+func getFolderPaths(orgId int64, folderIds []int64) (map[int64][]*m.FolderRef, error) {
+	result := make(map[int64][]*m.FolderRef)
+	if len(folderIds) == 0 {
+		return result, nil
+	}
+
+	rawSQL := `SELECT
+			fa.folder_id,
+			fa.depth,
+			f.id,
+			f.uid,
+			f.title,
+			f.slug
+		FROM folder_ancestor AS fa
+			INNER JOIN dashboard AS f ON f.id = fa.ancestor_id
+		WHERE fa.org_id = ? AND fa.folder_id IN (?` + strings.Repeat(",?", len(folderIds)-1) + `)
+		ORDER BY fa.folder_id ASC, fa.depth DESC`
+
+	params := []interface{}{orgId}
+	for _, id := range folderIds {
+		params = append(params, id)
+	}
+
+	rows := make([]*folderPathProjection, 0)
+	if err := x.SQL(rawSQL, params...).Find(&rows); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.FolderId] = append(result[row.FolderId], &m.FolderRef{
+			Id:    row.Id,
+			Uid:   row.Uid,
+			Title: row.Title,
+			Url:   m.GetFolderUrl(row.Uid, row.Slug),
+		})
+	}
+
+	return result, nil
+}
+
+func GetFolderPaths(query *m.GetFolderPathsQuery) error {
+	paths, err := getFolderPaths(query.OrgId, query.FolderIds)
+	if err != nil {
+		return err
+	}
+
+	query.Result = paths
+	return nil
+}
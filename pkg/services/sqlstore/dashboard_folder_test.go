@@ -1,10 +1,13 @@
 package sqlstore
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
 	m "github.com/Seasheller/grafana/pkg/models"
 	"github.com/Seasheller/grafana/pkg/services/search"
 )
@@ -408,3 +411,131 @@ func TestDashboardFolderDataAccess(t *testing.T) {
 		})
 	})
 }
+
+func TestNestedDashboardFolderDataAccess(t *testing.T) {
+	Convey("Testing nested folders", t, func() {
+		InitTestDB(t)
+
+		Convey("Given a folder tree team > service > prod with a dashboard in prod", func() {
+			team := insertTestDashboard("team", 1, 0, true)
+			service := insertTestDashboard("service", 1, team.Id, true)
+			prod := insertTestDashboard("prod", 1, service.Id, true)
+			dash := insertTestDashboard("latency", 1, prod.Id, false)
+			other := insertTestDashboard("other team", 1, 0, true)
+
+			viewer := createUser("viewer", "Viewer", false)
+			signedInViewer := &m.SignedInUser{UserId: viewer.Id, OrgId: 1, OrgRole: m.ROLE_VIEWER}
+
+			searchIds := func(user *m.SignedInUser, permission m.PermissionType) []int64 {
+				query := &search.FindPersistedDashboardsQuery{
+					SignedInUser: user,
+					OrgId:        1,
+					DashboardIds: []int64{dash.Id},
+					Permission:   permission,
+				}
+				So(SearchDashboards(query), ShouldBeNil)
+
+				ids := make([]int64, 0)
+				for _, hit := range query.Result {
+					ids = append(ids, hit.Id)
+				}
+				return ids
+			}
+
+			Convey("search hits have breadcrumbs from the root", func() {
+				query := &search.FindPersistedDashboardsQuery{
+					SignedInUser: &m.SignedInUser{OrgId: 1, OrgRole: m.ROLE_ADMIN},
+					OrgId:        1,
+					DashboardIds: []int64{dash.Id},
+				}
+				So(SearchDashboards(query), ShouldBeNil)
+				So(len(query.Result), ShouldEqual, 1)
+
+				crumbs := query.Result[0].Breadcrumbs
+				So(len(crumbs), ShouldEqual, 3)
+				So(crumbs[0].Uid, ShouldEqual, team.Uid)
+				So(crumbs[1].Uid, ShouldEqual, service.Uid)
+				So(crumbs[2].Uid, ShouldEqual, prod.Uid)
+			})
+
+			Convey("permissions on the top folder are inherited", func() {
+				So(testHelperUpdateDashboardAcl(team.Id, m.DashboardAcl{DashboardId: team.Id, OrgId: 1, UserId: 999, Permission: m.PERMISSION_EDIT}), ShouldBeNil)
+				So(searchIds(signedInViewer, m.PERMISSION_VIEW), ShouldBeEmpty)
+
+				So(UpdateDashboardAcl(&m.UpdateDashboardAclCommand{
+					DashboardId: team.Id,
+					Items: []*m.DashboardAcl{
+						{DashboardId: team.Id, OrgId: 1, UserId: 999, Permission: m.PERMISSION_EDIT, Created: time.Now(), Updated: time.Now()},
+						{DashboardId: team.Id, OrgId: 1, UserId: viewer.Id, Permission: m.PERMISSION_EDIT, Created: time.Now(), Updated: time.Now()},
+					},
+				}), ShouldBeNil)
+				So(searchIds(signedInViewer, m.PERMISSION_EDIT), ShouldResemble, []int64{dash.Id})
+
+				aclQuery := m.GetDashboardAclInfoListQuery{OrgId: 1, DashboardId: dash.Id}
+				So(GetDashboardAclInfoList(&aclQuery), ShouldBeNil)
+				So(len(aclQuery.Result), ShouldEqual, 2)
+				for _, item := range aclQuery.Result {
+					So(item.Inherited, ShouldBeTrue)
+				}
+
+				permQuery := m.GetDashboardPermissionsForUserQuery{OrgId: 1, UserId: viewer.Id, OrgRole: m.ROLE_VIEWER, DashboardIds: []int64{dash.Id}}
+				So(GetDashboardPermissionsForUser(&permQuery), ShouldBeNil)
+				So(len(permQuery.Result), ShouldEqual, 1)
+				So(permQuery.Result[0].Permission, ShouldEqual, m.PERMISSION_EDIT)
+			})
+
+			Convey("without permissions the default role permissions apply", func() {
+				So(searchIds(signedInViewer, m.PERMISSION_VIEW), ShouldResemble, []int64{dash.Id})
+				So(searchIds(signedInViewer, m.PERMISSION_EDIT), ShouldBeEmpty)
+			})
+
+			Convey("moving a subtree updates its paths", func() {
+				cmd := m.SaveDashboardCommand{OrgId: 1, FolderId: other.Id, IsFolder: true, Dashboard: service.Data, Overwrite: true}
+				So(SaveDashboard(&cmd), ShouldBeNil)
+
+				paths, err := getFolderPaths(1, []int64{prod.Id})
+				So(err, ShouldBeNil)
+				So(len(paths[prod.Id]), ShouldEqual, 3)
+				So(paths[prod.Id][0].Id, ShouldEqual, other.Id)
+				So(paths[prod.Id][1].Id, ShouldEqual, service.Id)
+			})
+
+			Convey("a folder cannot be moved into its own subfolder", func() {
+				cmd := m.SaveDashboardCommand{OrgId: 1, FolderId: prod.Id, IsFolder: true, Dashboard: team.Data, Overwrite: true}
+				So(SaveDashboard(&cmd), ShouldEqual, m.ErrDashboardFolderCannotBeOwnParent)
+			})
+
+			Convey("folders cannot be nested deeper than the maximum depth", func() {
+				parent := prod
+				for i := 3; i < m.MaxFolderDepth; i++ {
+					parent = insertTestDashboard(fmt.Sprintf("level %d", i+1), 1, parent.Id, true)
+				}
+
+				cmd := m.SaveDashboardCommand{
+					OrgId:     1,
+					FolderId:  parent.Id,
+					IsFolder:  true,
+					Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "too deep"}),
+				}
+				So(SaveDashboard(&cmd), ShouldEqual, m.ErrDashboardFolderMaxDepthExceeded)
+			})
+
+			Convey("subfolders can reuse names used in other folders", func() {
+				insertTestDashboard("prod", 1, other.Id, true)
+			})
+
+			Convey("deleting a folder deletes its subfolders and their dashboards", func() {
+				So(DeleteDashboard(&m.DeleteDashboardCommand{OrgId: 1, Id: team.Id}), ShouldBeNil)
+
+				for _, id := range []int64{service.Id, prod.Id, dash.Id} {
+					err := GetDashboard(&m.GetDashboardQuery{OrgId: 1, Id: id})
+					So(err, ShouldEqual, m.ErrDashboardNotFound)
+				}
+
+				paths, err := getFolderPaths(1, []int64{prod.Id})
+				So(err, ShouldBeNil)
+				So(paths, ShouldBeEmpty)
+			})
+		})
+	})
+}
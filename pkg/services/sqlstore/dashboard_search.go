@@ -0,0 +1,211 @@
+package sqlstore
+
+import (
+	"strings"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/search"
+)
+
+func init() {
+	bus.AddHandler("sql", IndexMissingDashboards)
+}
+
+// searchTermPanelType is only used to filter by panel type, it is not
+// matched against the search text.
+const searchTermPanelType = "panelType"
+
+// searchMatchKinds are the kinds of search terms matched against the search text.
+var searchMatchKinds = []string{
+	search.MatchTitle,
+	search.MatchDescription,
+	search.MatchPanelTitle,
+	search.MatchPanelDescription,
+	search.MatchDatasource,
+	search.MatchQuery,
+}
+
+// searchTargetQueryFields are the fields of a panel target that hold the
+// query expression for the common datasources.
+var searchTargetQueryFields = []string{"expr", "target", "query", "rawSql", "expression"}
+
+type dashboardSearchTerm struct {
+	Id          int64
+	OrgId       int64
+	DashboardId int64
+	PanelId     int64
+	Kind        string
+	Value       string
+}
+
+func (dashboardSearchTerm) TableName() string {
+	return "dashboard_search_term"
+}
+
+type searchTermExtractor struct {
+	dash  *m.Dashboard
+	terms []*dashboardSearchTerm
+	seen  map[dashboardSearchTerm]bool
+}
+
+func (e *searchTermExtractor) add(panelId int64, kind string, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+
+	key := dashboardSearchTerm{PanelId: panelId, Kind: kind, Value: value}
+	if e.seen[key] {
+		return
+	}
+	e.seen[key] = true
+
+	e.terms = append(e.terms, &dashboardSearchTerm{
+		OrgId:       e.dash.OrgId,
+		DashboardId: e.dash.Id,
+		PanelId:     panelId,
+		Kind:        kind,
+		Value:       value,
+	})
+}
+
+func (e *searchTermExtractor) addPanels(panels []interface{}) {
+	for _, p := range panels {
+		panel := simplejson.NewFromAny(p)
+		panelId := panel.Get("id").MustInt64()
+
+		e.add(panelId, search.MatchPanelTitle, panel.Get("title").MustString())
+		e.add(panelId, search.MatchPanelDescription, panel.Get("description").MustString())
+		e.add(panelId, searchTermPanelType, panel.Get("type").MustString())
+		e.add(panelId, search.MatchDatasource, panel.Get("datasource").MustString())
+
+		for _, t := range panel.Get("targets").MustArray() {
+			target := simplejson.NewFromAny(t)
+			e.add(panelId, search.MatchDatasource, target.Get("datasource").MustString())
+			for _, field := range searchTargetQueryFields {
+				e.add(panelId, search.MatchQuery, target.Get(field).MustString())
+			}
+		}
+
+		// panels inside collapsed rows
+		e.addPanels(panel.Get("panels").MustArray())
+	}
+}
+
+// extractDashboardSearchTerms returns the text of the dashboard that full-text
+// search looks at.
+func extractDashboardSearchTerms(dash *m.Dashboard) []*dashboardSearchTerm {
+	e := &searchTermExtractor{dash: dash, seen: make(map[dashboardSearchTerm]bool)}
+
+	e.add(0, search.MatchTitle, dash.Title)
+	if dash.Data == nil {
+		return e.terms
+	}
+
+	e.add(0, search.MatchDescription, dash.Data.Get("description").MustString())
+
+	e.addPanels(dash.Data.Get("panels").MustArray())
+
+	// dashboards from before schema version 16 keep their panels in rows
+	for _, row := range dash.Data.Get("rows").MustArray() {
+		e.addPanels(simplejson.NewFromAny(row).Get("panels").MustArray())
+	}
+
+	for _, v := range dash.Data.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		e.add(0, search.MatchDatasource, variable.Get("datasource").MustString())
+		e.add(0, search.MatchQuery, variable.Get("query").MustString())
+	}
+
+	return e.terms
+}
+
+func updateDashboardSearchTerms(sess *DBSession, dash *m.Dashboard) error {
+	if _, err := sess.Exec("DELETE FROM dashboard_search_term WHERE dashboard_id=?", dash.Id); err != nil {
+		return err
+	}
+
+	for _, term := range extractDashboardSearchTerms(dash) {
+		if _, err := sess.Insert(term); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IndexMissingDashboards adds the search terms of dashboards that have none,
+// which are the dashboards saved before full-text search was added.
+func IndexMissingDashboards(cmd *search.IndexMissingDashboardsCommand) error {
+	var lastId int64
+
+	for {
+		dashboards := make([]*m.Dashboard, 0)
+		err := x.Where("id > ?", lastId).
+			And("NOT EXISTS (SELECT 1 FROM dashboard_search_term WHERE dashboard_search_term.dashboard_id = dashboard.id)").
+			Asc("id").
+			Limit(100).
+			Find(&dashboards)
+		if err != nil {
+			return err
+		}
+
+		if len(dashboards) == 0 {
+			return nil
+		}
+
+		for _, dash := range dashboards {
+			err := inTransaction(func(sess *DBSession) error {
+				return updateDashboardSearchTerms(sess, dash)
+			})
+			if err != nil {
+				return err
+			}
+
+			lastId = dash.Id
+			cmd.Result++
+		}
+	}
+}
+
+// setSearchHitMatches scores the hits of a full-text search and lists where
+// in each dashboard the search text was found.
+func setSearchHitMatches(query *search.FindPersistedDashboardsQuery) error {
+	if !query.FullText || len(query.Title) == 0 || len(query.Result) == 0 {
+		return nil
+	}
+
+	params := make([]interface{}, 0, len(query.Result)+1)
+	for _, hit := range query.Result {
+		params = append(params, hit.Id)
+	}
+	params = append(params, "%"+query.Title+"%")
+
+	terms := make([]*dashboardSearchTerm, 0)
+	err := x.Where("dashboard_id IN (?"+strings.Repeat(",?", len(query.Result)-1)+") AND kind <> '"+searchTermPanelType+"' AND value "+dialect.LikeStr()+" ?", params...).
+		Asc("dashboard_id", "panel_id", "id").
+		Find(&terms)
+	if err != nil {
+		return err
+	}
+
+	hits := make(map[int64]*search.Hit)
+	for _, hit := range query.Result {
+		hits[hit.Id] = hit
+	}
+
+	for _, term := range terms {
+		hit := hits[term.DashboardId]
+		match := search.NewMatch(term.Kind, term.PanelId, term.Value, query.Title)
+		if match == nil {
+			continue
+		}
+
+		hit.Matches = append(hit.Matches, match)
+		hit.Score += search.MatchWeights[term.Kind]
+	}
+
+	return nil
+}
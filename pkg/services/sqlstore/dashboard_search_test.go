@@ -0,0 +1,211 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/search"
+)
+
+func TestDashboardFullTextSearch(t *testing.T) {
+	Convey("Testing full-text dashboard search", t, func() {
+		InitTestDB(t)
+
+		folder := insertTestDashboard("Services", 1, 0, true)
+		overview := insertTestDashboardWithData(1, folder.Id, map[string]interface{}{
+			"title":       "Service overview",
+			"description": "Traffic of the public API",
+			"panels": []interface{}{
+				map[string]interface{}{
+					"id":         1,
+					"type":       "graph",
+					"title":      "Request rate",
+					"datasource": "Prometheus",
+					"targets": []interface{}{
+						map[string]interface{}{"expr": "sum(rate(http_requests_total[5m]))"},
+					},
+				},
+				map[string]interface{}{
+					"id":        2,
+					"type":      "row",
+					"title":     "Logs",
+					"collapsed": true,
+					"panels": []interface{}{
+						map[string]interface{}{
+							"id":         3,
+							"type":       "singlestat",
+							"title":      "Errors",
+							"datasource": "Loki",
+							"targets": []interface{}{
+								map[string]interface{}{"expr": `count_over_time({job="api"} |= "error" [5m])`},
+							},
+						},
+					},
+				},
+			},
+		})
+		explained := insertTestDashboardWithData(1, 0, map[string]interface{}{
+			"title": "http_requests_total explained",
+		})
+		legacy := insertTestDashboardWithData(1, 0, map[string]interface{}{
+			"title": "Legacy",
+			"rows": []interface{}{
+				map[string]interface{}{
+					"panels": []interface{}{
+						map[string]interface{}{
+							"id":    1,
+							"type":  "graph",
+							"title": "Old requests",
+							"targets": []interface{}{
+								map[string]interface{}{"target": "stats.http_requests_total.count"},
+							},
+						},
+					},
+				},
+			},
+		})
+
+		admin := &m.SignedInUser{UserId: 1, OrgId: 1, OrgRole: m.ROLE_ADMIN}
+
+		searchFullText := func(user *m.SignedInUser, text string) search.HitList {
+			query := &search.FindPersistedDashboardsQuery{
+				SignedInUser: user,
+				OrgId:        1,
+				Title:        text,
+				FullText:     true,
+			}
+			So(SearchDashboards(query), ShouldBeNil)
+			return query.Result
+		}
+
+		Convey("Should find dashboards by their panel queries", func() {
+			hits := searchFullText(admin, "HTTP_requests_total")
+			So(len(hits), ShouldEqual, 3)
+
+			byId := map[int64]*search.Hit{}
+			for _, hit := range hits {
+				byId[hit.Id] = hit
+			}
+
+			So(byId[explained.Id].Score, ShouldEqual, search.MatchWeights[search.MatchTitle])
+			So(byId[overview.Id].Score, ShouldEqual, search.MatchWeights[search.MatchQuery])
+
+			matches := byId[overview.Id].Matches
+			So(len(matches), ShouldEqual, 1)
+			So(matches[0].Field, ShouldEqual, search.MatchQuery)
+			So(matches[0].PanelId, ShouldEqual, 1)
+			So(matches[0].Text, ShouldEqual, "sum(rate(http_requests_total[5m]))")
+			So(matches[0].Highlights, ShouldResemble, []search.Highlight{{Start: 9, End: 28}})
+
+			So(byId[legacy.Id].Matches[0].Field, ShouldEqual, search.MatchQuery)
+		})
+
+		Convey("Should find panels inside collapsed rows", func() {
+			hits := searchFullText(admin, "errors")
+			So(len(hits), ShouldEqual, 1)
+			So(hits[0].Id, ShouldEqual, overview.Id)
+			So(hits[0].Matches[0].Field, ShouldEqual, search.MatchPanelTitle)
+			So(hits[0].Matches[0].PanelId, ShouldEqual, 3)
+		})
+
+		Convey("Should only match titles when full-text is not requested", func() {
+			query := &search.FindPersistedDashboardsQuery{SignedInUser: admin, OrgId: 1, Title: "http_requests_total"}
+			So(SearchDashboards(query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 1)
+			So(query.Result[0].Id, ShouldEqual, explained.Id)
+			So(query.Result[0].Matches, ShouldBeEmpty)
+		})
+
+		Convey("Should filter by datasource and panel type", func() {
+			query := &search.FindPersistedDashboardsQuery{SignedInUser: admin, OrgId: 1, Datasource: "Loki"}
+			So(SearchDashboards(query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 1)
+			So(query.Result[0].Id, ShouldEqual, overview.Id)
+
+			query = &search.FindPersistedDashboardsQuery{SignedInUser: admin, OrgId: 1, PanelType: "graph"}
+			So(SearchDashboards(query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 2)
+
+			query = &search.FindPersistedDashboardsQuery{SignedInUser: admin, OrgId: 1, PanelType: "graph", Title: "requests", FullText: true}
+			So(SearchDashboards(query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 2)
+		})
+
+		Convey("Should not match panel types as text", func() {
+			So(searchFullText(admin, "singlestat"), ShouldBeEmpty)
+		})
+
+		Convey("Should keep filtering by permission", func() {
+			editorRole := m.ROLE_EDITOR
+			So(UpdateDashboardAcl(&m.UpdateDashboardAclCommand{
+				DashboardId: folder.Id,
+				Items: []*m.DashboardAcl{
+					{DashboardId: folder.Id, OrgId: 1, Role: &editorRole, Permission: m.PERMISSION_VIEW, Created: time.Now(), Updated: time.Now()},
+				},
+			}), ShouldBeNil)
+
+			viewer := &m.SignedInUser{UserId: 2, OrgId: 1, OrgRole: m.ROLE_VIEWER}
+			hits := searchFullText(viewer, "http_requests_total")
+			So(len(hits), ShouldEqual, 2)
+			for _, hit := range hits {
+				So(hit.Id, ShouldNotEqual, overview.Id)
+			}
+		})
+
+		Convey("Should update the index when a dashboard is saved", func() {
+			cmd := m.SaveDashboardCommand{
+				OrgId:     1,
+				FolderId:  folder.Id,
+				Overwrite: true,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"id":    overview.Id,
+					"uid":   overview.Uid,
+					"title": "Service overview",
+				}),
+			}
+			So(SaveDashboard(&cmd), ShouldBeNil)
+
+			So(len(searchFullText(admin, "http_requests_total")), ShouldEqual, 2)
+			So(searchFullText(admin, "errors"), ShouldBeEmpty)
+		})
+
+		Convey("Should remove the index of deleted dashboards", func() {
+			So(DeleteDashboard(&m.DeleteDashboardCommand{Id: folder.Id, OrgId: 1}), ShouldBeNil)
+
+			count, err := x.Where("dashboard_id=?", overview.Id).Count(&dashboardSearchTerm{})
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 0)
+		})
+
+		Convey("Should index dashboards missing from the index", func() {
+			_, err := x.Exec("DELETE FROM dashboard_search_term")
+			So(err, ShouldBeNil)
+			So(searchFullText(admin, "errors"), ShouldBeEmpty)
+
+			cmd := search.IndexMissingDashboardsCommand{}
+			So(IndexMissingDashboards(&cmd), ShouldBeNil)
+			So(cmd.Result, ShouldEqual, 4)
+			So(len(searchFullText(admin, "errors")), ShouldEqual, 1)
+
+			cmd = search.IndexMissingDashboardsCommand{}
+			So(IndexMissingDashboards(&cmd), ShouldBeNil)
+			So(cmd.Result, ShouldEqual, 0)
+		})
+	})
+}
+
+func insertTestDashboardWithData(orgId int64, folderId int64, data map[string]interface{}) *m.Dashboard {
+	data["id"] = nil
+	cmd := m.SaveDashboardCommand{
+		OrgId:     orgId,
+		FolderId:  folderId,
+		Dashboard: simplejson.NewFromAny(data),
+	}
+
+	So(SaveDashboard(&cmd), ShouldBeNil)
+	return cmd.Result
+}
@@ -6,7 +6,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Seasheller/grafana/pkg/bus"
 	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	"github.com/Seasheller/grafana/pkg/events"
 	m "github.com/Seasheller/grafana/pkg/models"
 	"github.com/Seasheller/grafana/pkg/services/search"
 	"github.com/Seasheller/grafana/pkg/setting"
@@ -91,12 +93,53 @@ func TestDashboardDataAccess(t *testing.T) {
 			Convey("Should be able to delete dashboard", func() {
 				dash := insertTestDashboard("delete me", 1, 0, false, "delete this")
 
+				var deleted *events.DashboardDeleted
+				bus.AddEventListener(func(e *events.DashboardDeleted) error {
+					deleted = e
+					return nil
+				})
+
 				err := DeleteDashboard(&m.DeleteDashboardCommand{
 					Id:    dash.Id,
 					OrgId: 1,
 				})
 
 				So(err, ShouldBeNil)
+
+				Convey("Should publish that the dashboard was deleted", func() {
+					So(deleted, ShouldNotBeNil)
+					So(deleted.Id, ShouldEqual, dash.Id)
+					So(deleted.Uid, ShouldEqual, dash.Uid)
+					So(deleted.OrgId, ShouldEqual, 1)
+				})
+			})
+
+			Convey("Should publish that the dashboard was saved", func() {
+				var saved *events.DashboardSaved
+				bus.AddEventListener(func(e *events.DashboardSaved) error {
+					saved = e
+					return nil
+				})
+
+				cmd := m.SaveDashboardCommand{
+					OrgId:  1,
+					UserId: 100,
+					Dashboard: simplejson.NewFromAny(map[string]interface{}{
+						"id":      savedDash.Id,
+						"uid":     savedDash.Uid,
+						"title":   "test dash 23",
+						"version": savedDash.Version,
+					}),
+				}
+
+				err := SaveDashboard(&cmd)
+				So(err, ShouldBeNil)
+
+				So(saved, ShouldNotBeNil)
+				So(saved.Id, ShouldEqual, savedDash.Id)
+				So(saved.Uid, ShouldEqual, savedDash.Uid)
+				So(saved.Version, ShouldEqual, savedDash.Version+1)
+				So(saved.UserId, ShouldEqual, 100)
 			})
 
 			Convey("Should retry generation of uid once if it fails.", func() {
@@ -0,0 +1,334 @@
+package sqlstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", GetDashboardTrash)
+	bus.AddHandler("sql", RestoreDashboard)
+	bus.AddHandler("sql", PurgeDashboardTrash)
+	bus.AddHandler("sql", DeleteExpiredDashboardTrash)
+}
+
+// moveDashboardToTrash deletes the dashboard, or the folder with everything
+// in it, so that it can still be restored. The dashboards are stored in the
+// trash, while their versions, permissions, stars, annotations and library
+// panel connections stay in place until the trash item is purged. Alerts are
+// deleted and extracted from the dashboard again when it is restored.
+func moveDashboardToTrash(sess *DBSession, dashboard *m.Dashboard) error {
+	dashboards := []*m.Dashboard{dashboard}
+
+	if dashboard.IsFolder {
+		folderIds, err := getFolderSubtreeIds(sess, dashboard.Id)
+		if err != nil {
+			return err
+		}
+
+		contents := make([]*m.Dashboard, 0)
+		if err := sess.In("folder_id", folderIds).Asc("id").Find(&contents); err != nil {
+			return err
+		}
+
+		dashboards = append(dashboards, contents...)
+	}
+
+	deletes := []string{
+		"DELETE FROM dashboard_tag WHERE dashboard_id = ?",
+		"DELETE FROM dashboard_search_term WHERE dashboard_id = ?",
+		"DELETE FROM dashboard_provisioning WHERE dashboard_id = ?",
+		"DELETE FROM folder_ancestor WHERE folder_id = ?",
+		"DELETE FROM dashboard WHERE id = ?",
+	}
+
+	deleted := time.Now()
+	var parentId int64
+
+	for _, dash := range dashboards {
+		data, err := json.Marshal(dash)
+		if err != nil {
+			return err
+		}
+
+		item := &m.DashboardTrashItem{
+			OrgId:       dash.OrgId,
+			ParentId:    parentId,
+			DashboardId: dash.Id,
+			Uid:         dash.Uid,
+			Title:       dash.Title,
+			FolderId:    dash.FolderId,
+			IsFolder:    dash.IsFolder,
+			Data:        string(data),
+			Deleted:     deleted,
+		}
+
+		if _, err := sess.Insert(item); err != nil {
+			return err
+		}
+
+		if parentId == 0 {
+			parentId = item.Id
+		}
+
+		if err := deleteAlertDefinition(dash.Id, sess); err != nil {
+			return err
+		}
+
+		for _, sql := range deletes {
+			if _, err := sess.Exec(sql, dash.Id); err != nil {
+				return err
+			}
+		}
+
+		publishDashboardDeleted(sess, dash)
+	}
+
+	return nil
+}
+
+func GetDashboardTrash(query *m.GetDashboardTrashQuery) error {
+	query.Result = make([]*m.DashboardTrashItem, 0)
+	return x.Where("org_id=? AND parent_id=0", query.OrgId).Desc("deleted").Find(&query.Result)
+}
+
+func RestoreDashboard(cmd *m.RestoreDashboardCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		items, err := getDashboardTrashItems(sess, cmd.OrgId, cmd.TrashId)
+		if err != nil {
+			return err
+		}
+
+		dashboards := make([]*m.Dashboard, 0, len(items))
+		for _, item := range items {
+			dash := &m.Dashboard{}
+			if err := json.Unmarshal([]byte(item.Data), dash); err != nil {
+				return err
+			}
+			dashboards = append(dashboards, dash)
+		}
+
+		// restore into the General folder when the parent folder is gone
+		restored := dashboards[0]
+		if restored.FolderId > 0 {
+			parentExists, err := sess.Where("id=? AND org_id=? AND is_folder="+dialect.BooleanStr(true), restored.FolderId, restored.OrgId).Get(&m.Dashboard{})
+			if err != nil {
+				return err
+			}
+			if !parentExists {
+				restored.FolderId = 0
+			}
+		}
+
+		validateCmd := m.ValidateDashboardBeforeSaveCommand{
+			OrgId:     restored.OrgId,
+			Dashboard: restored,
+			Result:    &m.ValidateDashboardBeforeSaveResult{},
+		}
+		if err := getExistingDashboardByTitleAndFolder(sess, &validateCmd); err != nil {
+			return err
+		}
+
+		if err := validateFolderParent(sess, restored); err != nil {
+			return err
+		}
+
+		for _, dash := range dashboards {
+			uidExists, err := sess.Where("org_id=? AND uid=?", dash.OrgId, dash.Uid).Get(&m.Dashboard{})
+			if err != nil {
+				return err
+			}
+			if uidExists {
+				return m.ErrDashboardWithSameUIDExists
+			}
+		}
+
+		for _, dash := range sortParentFoldersFirst(dashboards) {
+			if _, err := sess.Insert(dash); err != nil {
+				return err
+			}
+
+			for _, tag := range dash.GetTags() {
+				if _, err := sess.Insert(&DashboardTag{DashboardId: dash.Id, Term: tag}); err != nil {
+					return err
+				}
+			}
+
+			if err := updateDashboardSearchTerms(sess, dash); err != nil {
+				return err
+			}
+
+			if dash.IsFolder {
+				if err := updateFolderAncestors(sess, dash); err != nil {
+					return err
+				}
+			}
+		}
+
+		if _, err := sess.Exec("DELETE FROM dashboard_trash_item WHERE id = ? OR parent_id = ?", items[0].Id, items[0].Id); err != nil {
+			return err
+		}
+
+		cmd.Result = dashboards
+		return nil
+	})
+}
+
+func PurgeDashboardTrash(cmd *m.PurgeDashboardTrashCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		items, err := getDashboardTrashItems(sess, cmd.OrgId, cmd.TrashId)
+		if err != nil {
+			return err
+		}
+
+		return purgeDashboardTrashItems(sess, items)
+	})
+}
+
+func DeleteExpiredDashboardTrash(cmd *m.DeleteExpiredDashboardTrashCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		expired := make([]*m.DashboardTrashItem, 0)
+		if err := sess.Where("parent_id=0 AND deleted < ?", cmd.OlderThan).Find(&expired); err != nil {
+			return err
+		}
+
+		for _, item := range expired {
+			items, err := getDashboardTrashItems(sess, item.OrgId, item.Id)
+			if err != nil {
+				return err
+			}
+
+			if err := purgeDashboardTrashItems(sess, items); err != nil {
+				return err
+			}
+
+			cmd.DeletedRows += int64(len(items))
+		}
+
+		return nil
+	})
+}
+
+// getDashboardTrashItems returns the trash item followed by the items
+// deleted together with it.
+func getDashboardTrashItems(sess *DBSession, orgId int64, trashId int64) ([]*m.DashboardTrashItem, error) {
+	item := &m.DashboardTrashItem{}
+	has, err := sess.Where("id=? AND org_id=? AND parent_id=0", trashId, orgId).Get(item)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, m.ErrDashboardTrashItemNotFound
+	}
+
+	children := make([]*m.DashboardTrashItem, 0)
+	if err := sess.Where("parent_id=?", item.Id).Asc("id").Find(&children); err != nil {
+		return nil, err
+	}
+
+	return append([]*m.DashboardTrashItem{item}, children...), nil
+}
+
+// purgeDashboardTrashItems permanently deletes everything that was kept to
+// restore the trash items.
+func purgeDashboardTrashItems(sess *DBSession, items []*m.DashboardTrashItem) error {
+	deletes := []string{
+		"DELETE FROM star WHERE dashboard_id = ?",
+		"DELETE FROM playlist_item WHERE type = 'dashboard_by_id' AND value = ?",
+		"DELETE FROM dashboard_version WHERE dashboard_id = ?",
+		"DELETE FROM annotation WHERE dashboard_id = ?",
+		"DELETE FROM dashboard_acl WHERE dashboard_id = ?",
+		"DELETE FROM library_panel_dashboard WHERE dashboard_id = ?",
+		"DELETE FROM public_dashboard WHERE dashboard_id = ?",
+		"DELETE FROM dashboard_version_retention WHERE folder_id = ?",
+	}
+
+	for _, item := range items {
+		for _, sql := range deletes {
+			if _, err := sess.Exec(sql, item.DashboardId); err != nil {
+				return err
+			}
+		}
+
+		if item.IsFolder {
+			if err := purgeFolderLibraryPanels(sess, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := sess.Exec("DELETE FROM dashboard_trash_item WHERE id = ? OR parent_id = ?", items[0].Id, items[0].Id)
+	return err
+}
+
+// purgeFolderLibraryPanels deletes the library panels of a purged folder.
+// Library panels still used by dashboards are moved to the General folder.
+func purgeFolderLibraryPanels(sess *DBSession, folder *m.DashboardTrashItem) error {
+	libraryPanels := make([]*m.LibraryPanel, 0)
+	if err := sess.Where("org_id=? AND folder_id=?", folder.OrgId, folder.DashboardId).Find(&libraryPanels); err != nil {
+		return err
+	}
+
+	for _, lp := range libraryPanels {
+		inUse, err := sess.Table("library_panel_dashboard").Where("library_panel_id=?", lp.Id).Exist()
+		if err != nil {
+			return err
+		}
+
+		if !inUse {
+			if _, err := sess.Exec("DELETE FROM library_panel_version WHERE library_panel_id = ?", lp.Id); err != nil {
+				return err
+			}
+			if _, err := sess.Exec("DELETE FROM library_panel WHERE id = ?", lp.Id); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := lp.Name
+		nameTaken, err := sess.Where("org_id=? AND folder_id=0 AND name=?", lp.OrgId, name).Get(&m.LibraryPanel{})
+		if err != nil {
+			return err
+		}
+		if nameTaken {
+			name = fmt.Sprintf("%s (%s)", lp.Name, lp.Uid)
+		}
+
+		if _, err := sess.Exec("UPDATE library_panel SET folder_id = 0, name = ? WHERE id = ?", name, lp.Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortParentFoldersFirst orders the dashboards so that every folder comes
+// before the dashboards and folders in it.
+func sortParentFoldersFirst(dashboards []*m.Dashboard) []*m.Dashboard {
+	sorted := []*m.Dashboard{dashboards[0]}
+	placed := map[int64]bool{dashboards[0].Id: true}
+	remaining := dashboards[1:]
+
+	for len(remaining) > 0 {
+		next := make([]*m.Dashboard, 0, len(remaining))
+		for _, dash := range remaining {
+			if placed[dash.FolderId] {
+				sorted = append(sorted, dash)
+				placed[dash.Id] = true
+			} else {
+				next = append(next, dash)
+			}
+		}
+
+		if len(next) == len(remaining) {
+			return append(sorted, next...)
+		}
+
+		remaining = next
+	}
+
+	return sorted
+}
@@ -0,0 +1,187 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/setting"
+)
+
+func TestDashboardTrashDataAccess(t *testing.T) {
+	Convey("Testing dashboard trash data access", t, func() {
+		InitTestDB(t)
+
+		origRetentionDays := setting.DashboardTrashRetentionDays
+		setting.DashboardTrashRetentionDays = 30
+		defer func() {
+			setting.DashboardTrashRetentionDays = origRetentionDays
+		}()
+
+		folder := insertTestDashboard("Team folder", 1, 0, true)
+		subfolder := insertTestDashboard("Team subfolder", 1, folder.Id, true)
+		dashInFolder := insertTestDashboard("In folder", 1, folder.Id, false, "prod")
+		dashInSubfolder := insertTestDashboard("In subfolder", 1, subfolder.Id, false)
+		dash := insertTestDashboard("Standalone", 1, 0, false, "prod")
+
+		err := UpdateDashboardAcl(&m.UpdateDashboardAclCommand{
+			DashboardId: dash.Id,
+			Items: []*m.DashboardAcl{
+				{OrgId: 1, DashboardId: dash.Id, UserId: 1, Permission: m.PERMISSION_EDIT, Created: time.Now(), Updated: time.Now()},
+			},
+		})
+		So(err, ShouldBeNil)
+
+		getTrash := func() []*m.DashboardTrashItem {
+			query := m.GetDashboardTrashQuery{OrgId: 1}
+			So(GetDashboardTrash(&query), ShouldBeNil)
+			return query.Result
+		}
+
+		dashboardExists := func(id int64) bool {
+			err := GetDashboard(&m.GetDashboardQuery{OrgId: 1, Id: id})
+			if err == m.ErrDashboardNotFound {
+				return false
+			}
+			So(err, ShouldBeNil)
+			return true
+		}
+
+		Convey("When deleting a dashboard", func() {
+			So(DeleteDashboard(&m.DeleteDashboardCommand{Id: dash.Id, OrgId: 1}), ShouldBeNil)
+
+			Convey("Should move it to the trash", func() {
+				So(dashboardExists(dash.Id), ShouldBeFalse)
+
+				trash := getTrash()
+				So(len(trash), ShouldEqual, 1)
+				So(trash[0].DashboardId, ShouldEqual, dash.Id)
+				So(trash[0].Title, ShouldEqual, "Standalone")
+			})
+
+			Convey("Should restore it with its versions, permissions and tags", func() {
+				cmd := m.RestoreDashboardCommand{OrgId: 1, TrashId: getTrash()[0].Id}
+				So(RestoreDashboard(&cmd), ShouldBeNil)
+				So(cmd.Result[0].Id, ShouldEqual, dash.Id)
+
+				So(dashboardExists(dash.Id), ShouldBeTrue)
+				So(len(getTrash()), ShouldEqual, 0)
+
+				versions := m.GetDashboardVersionsQuery{OrgId: 1, DashboardId: dash.Id}
+				So(GetDashboardVersions(&versions), ShouldBeNil)
+				So(len(versions.Result), ShouldEqual, 1)
+
+				acl := m.GetDashboardAclInfoListQuery{OrgId: 1, DashboardId: dash.Id}
+				So(GetDashboardAclInfoList(&acl), ShouldBeNil)
+				So(len(acl.Result), ShouldEqual, 1)
+				So(acl.Result[0].UserId, ShouldEqual, 1)
+
+				tags := m.GetDashboardTagsQuery{OrgId: 1}
+				So(GetDashboardTags(&tags), ShouldBeNil)
+				So(len(tags.Result), ShouldEqual, 1)
+				So(tags.Result[0].Count, ShouldEqual, 2)
+			})
+
+			Convey("Should not restore it when a dashboard with the same uid exists", func() {
+				insertTestDashboardWithData(1, 0, map[string]interface{}{"title": "Other", "uid": dash.Uid})
+
+				cmd := m.RestoreDashboardCommand{OrgId: 1, TrashId: getTrash()[0].Id}
+				So(RestoreDashboard(&cmd), ShouldEqual, m.ErrDashboardWithSameUIDExists)
+			})
+
+			Convey("Should not restore it when a dashboard with the same title exists", func() {
+				insertTestDashboard("Standalone", 1, 0, false)
+
+				cmd := m.RestoreDashboardCommand{OrgId: 1, TrashId: getTrash()[0].Id}
+				So(RestoreDashboard(&cmd), ShouldEqual, m.ErrDashboardWithSameNameInFolderExists)
+			})
+
+			Convey("Should purge it with its versions and permissions", func() {
+				So(PurgeDashboardTrash(&m.PurgeDashboardTrashCommand{OrgId: 1, TrashId: getTrash()[0].Id}), ShouldBeNil)
+				So(len(getTrash()), ShouldEqual, 0)
+
+				versions, err := x.Where("dashboard_id=?", dash.Id).Count(&m.DashboardVersion{})
+				So(err, ShouldBeNil)
+				So(versions, ShouldEqual, 0)
+
+				acl, err := x.Where("dashboard_id=?", dash.Id).Count(&m.DashboardAcl{})
+				So(err, ShouldBeNil)
+				So(acl, ShouldEqual, 0)
+			})
+
+			Convey("Should not purge it in another org", func() {
+				err := PurgeDashboardTrash(&m.PurgeDashboardTrashCommand{OrgId: 2, TrashId: getTrash()[0].Id})
+				So(err, ShouldEqual, m.ErrDashboardTrashItemNotFound)
+			})
+
+			Convey("Should only purge it once expired", func() {
+				cmd := m.DeleteExpiredDashboardTrashCommand{OlderThan: time.Now().AddDate(0, 0, -1)}
+				So(DeleteExpiredDashboardTrash(&cmd), ShouldBeNil)
+				So(cmd.DeletedRows, ShouldEqual, 0)
+
+				cmd = m.DeleteExpiredDashboardTrashCommand{OlderThan: time.Now().Add(time.Minute)}
+				So(DeleteExpiredDashboardTrash(&cmd), ShouldBeNil)
+				So(cmd.DeletedRows, ShouldEqual, 1)
+				So(len(getTrash()), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When deleting a folder", func() {
+			So(DeleteDashboard(&m.DeleteDashboardCommand{Id: folder.Id, OrgId: 1}), ShouldBeNil)
+
+			Convey("Should move it to the trash with everything in it", func() {
+				for _, id := range []int64{folder.Id, subfolder.Id, dashInFolder.Id, dashInSubfolder.Id} {
+					So(dashboardExists(id), ShouldBeFalse)
+				}
+
+				trash := getTrash()
+				So(len(trash), ShouldEqual, 1)
+				So(trash[0].IsFolder, ShouldBeTrue)
+
+				count, err := x.Count(&m.DashboardTrashItem{})
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 4)
+			})
+
+			Convey("Should restore it with everything in it", func() {
+				cmd := m.RestoreDashboardCommand{OrgId: 1, TrashId: getTrash()[0].Id}
+				So(RestoreDashboard(&cmd), ShouldBeNil)
+				So(len(cmd.Result), ShouldEqual, 4)
+
+				for _, id := range []int64{folder.Id, subfolder.Id, dashInFolder.Id, dashInSubfolder.Id} {
+					So(dashboardExists(id), ShouldBeTrue)
+				}
+
+				count, err := x.Count(&m.DashboardTrashItem{})
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 0)
+
+				ancestors, err := x.Where("folder_id=? AND ancestor_id=?", subfolder.Id, folder.Id).Count(&folderAncestor{})
+				So(err, ShouldBeNil)
+				So(ancestors, ShouldEqual, 1)
+			})
+
+			Convey("Should purge it with everything in it", func() {
+				So(PurgeDashboardTrash(&m.PurgeDashboardTrashCommand{OrgId: 1, TrashId: getTrash()[0].Id}), ShouldBeNil)
+
+				count, err := x.Count(&m.DashboardTrashItem{})
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When deleting a dashboard in a folder that is deleted afterwards", func() {
+			So(DeleteDashboard(&m.DeleteDashboardCommand{Id: dashInFolder.Id, OrgId: 1}), ShouldBeNil)
+			trashId := getTrash()[0].Id
+			So(DeleteDashboard(&m.DeleteDashboardCommand{Id: folder.Id, OrgId: 1}), ShouldBeNil)
+
+			Convey("Should restore it to the General folder", func() {
+				cmd := m.RestoreDashboardCommand{OrgId: 1, TrashId: trashId}
+				So(RestoreDashboard(&cmd), ShouldBeNil)
+				So(cmd.Result[0].FolderId, ShouldEqual, 0)
+			})
+		})
+	})
+}
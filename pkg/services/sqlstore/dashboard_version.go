@@ -12,6 +12,8 @@ func init() {
 	bus.AddHandler("sql", GetDashboardVersion)
 	bus.AddHandler("sql", GetDashboardVersions)
 	bus.AddHandler("sql", DeleteExpiredVersions)
+	bus.AddHandler("sql", DeleteDashboardVersions)
+	bus.AddHandler("sql", SetDashboardVersionLabel)
 }
 
 // GetDashboardVersion gets the dashboard version for the given dashboard ID and version number.
@@ -49,6 +51,7 @@ func GetDashboardVersions(query *m.GetDashboardVersionsQuery) error {
 				dashboard_version.created,
 				dashboard_version.created_by as created_by_id,
 				dashboard_version.message,
+				dashboard_version.label,
 				dashboard_version.data,`+
 			dialect.Quote("user")+`.login as created_by`).
 		Join("LEFT", dialect.Quote("user"), `dashboard_version.created_by = `+dialect.Quote("user")+`.id`).
@@ -69,6 +72,16 @@ func GetDashboardVersions(query *m.GetDashboardVersionsQuery) error {
 
 const MAX_VERSIONS_TO_DELETE = 100
 
+// unlabeledVersionFilter matches the versions that may be deleted.
+const unlabeledVersionFilter = "(dashboard_version.label IS NULL OR dashboard_version.label = '')"
+
+// retainedDashboardIdsSql selects the dashboards in folders with a retention,
+// which are cleaned up by DeleteDashboardVersions instead.
+const retainedDashboardIdsSql = `SELECT dashboard.id FROM dashboard
+	INNER JOIN folder_ancestor ON folder_ancestor.folder_id = dashboard.folder_id
+	INNER JOIN dashboard_version_retention ON dashboard_version_retention.org_id = dashboard.org_id
+		AND dashboard_version_retention.folder_id = folder_ancestor.ancestor_id`
+
 func DeleteExpiredVersions(cmd *m.DeleteExpiredVersionsCommand) error {
 	return inTransaction(func(sess *DBSession) error {
 		versionsToKeep := setting.DashboardVersionsToKeep
@@ -87,7 +100,9 @@ func DeleteExpiredVersions(cmd *m.DeleteExpiredVersionsCommand) error {
 				GROUP BY dashboard_id
 			) AS vtd
 			WHERE dashboard_version.dashboard_id=vtd.dashboard_id
-			AND version < vtd.min + vtd.count - ?`
+			AND version < vtd.min + vtd.count - ?
+			AND ` + unlabeledVersionFilter + `
+			AND dashboard_version.dashboard_id NOT IN (` + retainedDashboardIdsSql + `)`
 
 		var versionIdsToDelete []interface{}
 		err := sess.SQL(versionIdsToDeleteQuery, versionsToKeep).Find(&versionIdsToDelete)
@@ -113,3 +128,46 @@ func DeleteExpiredVersions(cmd *m.DeleteExpiredVersionsCommand) error {
 		return nil
 	})
 }
+
+// DeleteDashboardVersions deletes the versions with the given ids, except for
+// labeled ones.
+func DeleteDashboardVersions(cmd *m.DeleteDashboardVersionsCommand) error {
+	if len(cmd.Ids) == 0 {
+		return nil
+	}
+
+	return inTransaction(func(sess *DBSession) error {
+		params := make([]interface{}, 0, len(cmd.Ids)+1)
+		params = append(params, `DELETE FROM dashboard_version WHERE id IN (?`+strings.Repeat(",?", len(cmd.Ids)-1)+`) AND `+unlabeledVersionFilter)
+		for _, id := range cmd.Ids {
+			params = append(params, id)
+		}
+
+		res, err := sess.Exec(params...)
+		if err != nil {
+			return err
+		}
+
+		cmd.DeletedRows, _ = res.RowsAffected()
+		return nil
+	})
+}
+
+func SetDashboardVersionLabel(cmd *m.SetDashboardVersionLabelCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		version := m.DashboardVersion{}
+		has, err := sess.Where("dashboard_version.dashboard_id=? AND dashboard_version.version=? AND dashboard.org_id=?", cmd.DashboardId, cmd.Version, cmd.OrgId).
+			Join("INNER", "dashboard", `dashboard.id = dashboard_version.dashboard_id`).
+			Cols("dashboard_version.id").
+			Get(&version)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return m.ErrDashboardVersionNotFound
+		}
+
+		_, err = sess.Exec("UPDATE dashboard_version SET label = ? WHERE id = ?", cmd.Label, version.Id)
+		return err
+	})
+}
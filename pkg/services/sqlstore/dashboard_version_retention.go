@@ -0,0 +1,134 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", SaveDashboardVersionRetention)
+	bus.AddHandler("sql", DeleteDashboardVersionRetention)
+	bus.AddHandler("sql", GetDashboardVersionRetention)
+	bus.AddHandler("sql", GetDashboardVersionRetentions)
+	bus.AddHandler("sql", GetDashboardVersionRetentionItems)
+}
+
+func SaveDashboardVersionRetention(cmd *m.SaveDashboardVersionRetentionCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		retention := &m.DashboardVersionRetention{}
+		exists, err := sess.Where("org_id=? AND folder_id=?", cmd.OrgId, cmd.FolderId).Get(retention)
+		if err != nil {
+			return err
+		}
+
+		retention.OrgId = cmd.OrgId
+		retention.FolderId = cmd.FolderId
+		retention.KeepAllDays = cmd.KeepAllDays
+		retention.KeepDailyDays = cmd.KeepDailyDays
+		retention.KeepWeeklyDays = cmd.KeepWeeklyDays
+		retention.Updated = time.Now()
+
+		if exists {
+			_, err = sess.ID(retention.Id).AllCols().Update(retention)
+		} else {
+			retention.Created = time.Now()
+			_, err = sess.Insert(retention)
+		}
+		if err != nil {
+			return err
+		}
+
+		cmd.Result = retention
+		return nil
+	})
+}
+
+func DeleteDashboardVersionRetention(cmd *m.DeleteDashboardVersionRetentionCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM dashboard_version_retention WHERE org_id = ? AND folder_id = ?", cmd.OrgId, cmd.FolderId)
+		if err != nil {
+			return err
+		}
+
+		if affected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return m.ErrDashboardVersionRetentionNotFound
+		}
+
+		return nil
+	})
+}
+
+func GetDashboardVersionRetention(query *m.GetDashboardVersionRetentionQuery) error {
+	retention := &m.DashboardVersionRetention{}
+	exists, err := x.Where("org_id=? AND folder_id=?", query.OrgId, query.FolderId).Get(retention)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return m.ErrDashboardVersionRetentionNotFound
+	}
+
+	query.Result = retention
+	return nil
+}
+
+func GetDashboardVersionRetentions(query *m.GetDashboardVersionRetentionsQuery) error {
+	query.Result = make([]*m.DashboardVersionRetention, 0)
+	return x.Asc("org_id", "folder_id").Find(&query.Result)
+}
+
+type dashboardRetentionProjection struct {
+	DashboardId int64
+	RetentionId int64
+	Depth       int
+}
+
+func GetDashboardVersionRetentionItems(query *m.GetDashboardVersionRetentionItemsQuery) error {
+	// a dashboard uses the retention of the closest folder that has one
+	rawSql := `SELECT
+		dashboard.id AS dashboard_id,
+		dashboard_version_retention.id AS retention_id,
+		folder_ancestor.depth AS depth
+		FROM dashboard
+		INNER JOIN folder_ancestor ON folder_ancestor.folder_id = dashboard.folder_id
+		INNER JOIN dashboard_version_retention ON dashboard_version_retention.org_id = dashboard.org_id
+			AND dashboard_version_retention.folder_id = folder_ancestor.ancestor_id`
+
+	candidates := make([]*dashboardRetentionProjection, 0)
+	if err := x.SQL(rawSql).Find(&candidates); err != nil {
+		return err
+	}
+
+	closest := make(map[int64]*dashboardRetentionProjection)
+	for _, c := range candidates {
+		if current, ok := closest[c.DashboardId]; !ok || c.Depth < current.Depth {
+			closest[c.DashboardId] = c
+		}
+	}
+
+	query.Result = make([]*m.DashboardVersionRetentionItem, 0)
+	if len(closest) == 0 {
+		return nil
+	}
+
+	versionsSql := `SELECT id, dashboard_id, version, created, label
+		FROM dashboard_version
+		WHERE dashboard_id IN (` + retainedDashboardIdsSql + `)
+		ORDER BY dashboard_id ASC, version DESC`
+
+	if err := x.SQL(versionsSql).Find(&query.Result); err != nil {
+		return err
+	}
+
+	for _, item := range query.Result {
+		if c, ok := closest[item.DashboardId]; ok {
+			item.RetentionId = c.RetentionId
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package sqlstore
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/setting"
+)
+
+func TestDashboardVersionRetention(t *testing.T) {
+	Convey("Testing dashboard version retention", t, func() {
+		InitTestDB(t)
+		setting.DashboardVersionsToKeep = 2
+
+		critical := insertTestDashboard("critical", 1, 0, true)
+		nested := insertTestDashboard("nested", 1, critical.Id, true)
+		other := insertTestDashboard("other", 1, 0, true)
+
+		inCritical := insertTestDashboard("in critical", 1, critical.Id, false)
+		inNested := insertTestDashboard("in nested", 1, nested.Id, false)
+		inOther := insertTestDashboard("in other", 1, other.Id, false)
+		for _, dash := range []*m.Dashboard{inCritical, inNested, inOther} {
+			for i := 0; i < 4; i++ {
+				saveCmd := m.SaveDashboardCommand{
+					OrgId:     1,
+					FolderId:  dash.FolderId,
+					Overwrite: true,
+					Dashboard: simplejson.NewFromAny(map[string]interface{}{"id": dash.Id, "title": dash.Title}),
+				}
+				So(SaveDashboard(&saveCmd), ShouldBeNil)
+			}
+		}
+
+		saveCmd := m.SaveDashboardVersionRetentionCommand{OrgId: 1, FolderId: critical.Id, KeepAllDays: 30}
+		So(SaveDashboardVersionRetention(&saveCmd), ShouldBeNil)
+		retention := saveCmd.Result
+
+		versionCount := func(dash *m.Dashboard) int {
+			query := m.GetDashboardVersionsQuery{DashboardId: dash.Id, OrgId: 1}
+			So(GetDashboardVersions(&query), ShouldBeNil)
+			return len(query.Result)
+		}
+
+		Convey("Should update the retention of a folder", func() {
+			cmd := m.SaveDashboardVersionRetentionCommand{OrgId: 1, FolderId: critical.Id, KeepAllDays: 7, KeepWeeklyDays: 365}
+			So(SaveDashboardVersionRetention(&cmd), ShouldBeNil)
+			So(cmd.Result.Id, ShouldEqual, retention.Id)
+
+			query := m.GetDashboardVersionRetentionQuery{OrgId: 1, FolderId: critical.Id}
+			So(GetDashboardVersionRetention(&query), ShouldBeNil)
+			So(query.Result.KeepAllDays, ShouldEqual, 7)
+			So(query.Result.KeepWeeklyDays, ShouldEqual, 365)
+		})
+
+		Convey("Should delete the retention of a folder", func() {
+			So(DeleteDashboardVersionRetention(&m.DeleteDashboardVersionRetentionCommand{OrgId: 1, FolderId: critical.Id}), ShouldBeNil)
+
+			query := m.GetDashboardVersionRetentionQuery{OrgId: 1, FolderId: critical.Id}
+			So(GetDashboardVersionRetention(&query), ShouldEqual, m.ErrDashboardVersionRetentionNotFound)
+		})
+
+		Convey("Should not apply the global number of versions to keep to folders with a retention", func() {
+			So(DeleteExpiredVersions(&m.DeleteExpiredVersionsCommand{}), ShouldBeNil)
+
+			So(versionCount(inCritical), ShouldEqual, 5)
+			So(versionCount(inNested), ShouldEqual, 5)
+			So(versionCount(inOther), ShouldEqual, 2)
+		})
+
+		Convey("Should use the retention of the closest folder", func() {
+			nestedCmd := m.SaveDashboardVersionRetentionCommand{OrgId: 1, FolderId: nested.Id, KeepAllDays: 1}
+			So(SaveDashboardVersionRetention(&nestedCmd), ShouldBeNil)
+
+			query := m.GetDashboardVersionRetentionItemsQuery{}
+			So(GetDashboardVersionRetentionItems(&query), ShouldBeNil)
+			// the versions of the nested folder itself are kept by the
+			// retention of its parent folder
+			So(len(query.Result), ShouldEqual, 11)
+
+			for _, item := range query.Result {
+				switch item.DashboardId {
+				case inCritical.Id, nested.Id:
+					So(item.RetentionId, ShouldEqual, retention.Id)
+				case inNested.Id:
+					So(item.RetentionId, ShouldEqual, nestedCmd.Result.Id)
+				default:
+					So(item.DashboardId, ShouldBeIn, []int64{inCritical.Id, inNested.Id, nested.Id})
+				}
+			}
+
+			last := len(query.Result) - 1
+			So(query.Result[last-1].DashboardId, ShouldEqual, query.Result[last].DashboardId)
+			So(query.Result[last-1].Version, ShouldBeGreaterThan, query.Result[last].Version)
+		})
+
+		Convey("Should never delete labeled versions", func() {
+			labelCmd := m.SetDashboardVersionLabelCommand{DashboardId: inOther.Id, OrgId: 1, Version: 1, Label: "release 2026-Q3"}
+			So(SetDashboardVersionLabel(&labelCmd), ShouldBeNil)
+
+			So(DeleteExpiredVersions(&m.DeleteExpiredVersionsCommand{}), ShouldBeNil)
+			So(versionCount(inOther), ShouldEqual, 3)
+
+			versionQuery := m.GetDashboardVersionQuery{DashboardId: inOther.Id, OrgId: 1, Version: 1}
+			So(GetDashboardVersion(&versionQuery), ShouldBeNil)
+			So(versionQuery.Result.Label, ShouldEqual, "release 2026-Q3")
+
+			deleteCmd := m.DeleteDashboardVersionsCommand{Ids: []int64{versionQuery.Result.Id}}
+			So(DeleteDashboardVersions(&deleteCmd), ShouldBeNil)
+			So(deleteCmd.DeletedRows, ShouldEqual, 0)
+		})
+
+		Convey("Should not label versions of other orgs", func() {
+			labelCmd := m.SetDashboardVersionLabelCommand{DashboardId: inOther.Id, OrgId: 2, Version: 1, Label: "release"}
+			So(SetDashboardVersionLabel(&labelCmd), ShouldEqual, m.ErrDashboardVersionNotFound)
+		})
+	})
+}
@@ -70,6 +70,10 @@ func GetAllDataSources(query *m.GetAllDataSourcesQuery) error {
 
 func DeleteDataSourceById(cmd *m.DeleteDataSourceByIdCommand) error {
 	return inTransaction(func(sess *DBSession) error {
+		if _, err := sess.Exec("DELETE FROM data_source_acl WHERE datasource_id=? and org_id=?", cmd.Id, cmd.OrgId); err != nil {
+			return err
+		}
+
 		var rawSql = "DELETE FROM data_source WHERE id=? and org_id=?"
 		result, err := sess.Exec(rawSql, cmd.Id, cmd.OrgId)
 		affected, _ := result.RowsAffected()
@@ -80,6 +84,11 @@ func DeleteDataSourceById(cmd *m.DeleteDataSourceByIdCommand) error {
 
 func DeleteDataSourceByName(cmd *m.DeleteDataSourceByNameCommand) error {
 	return inTransaction(func(sess *DBSession) error {
+		rawAclSql := "DELETE FROM data_source_acl WHERE org_id=? and datasource_id IN (SELECT id FROM data_source WHERE name=? and org_id=?)"
+		if _, err := sess.Exec(rawAclSql, cmd.OrgId, cmd.Name, cmd.OrgId); err != nil {
+			return err
+		}
+
 		var rawSql = "DELETE FROM data_source WHERE name=? and org_id=?"
 		result, err := sess.Exec(rawSql, cmd.Name, cmd.OrgId)
 		affected, _ := result.RowsAffected()
@@ -0,0 +1,320 @@
+package sqlstore
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", FilterDatasourcesByPermission)
+	bus.AddHandler("sql", GetDataSourcePermissions)
+	bus.AddHandler("sql", EnableDataSourcePermissions)
+	bus.AddHandler("sql", DisableDataSourcePermissions)
+	bus.AddHandler("sql", AddDataSourcePermission)
+	bus.AddHandler("sql", RemoveDataSourcePermission)
+	bus.AddHandler("sql", UpdateDataSourcePermissions)
+}
+
+// FilterDatasourcesByPermission keeps the data sources the user can use.
+// Org admins can use every data source. Data sources without permissions
+// enabled can be queried by everyone in the org, but only org admins can
+// administer them.
+func FilterDatasourcesByPermission(query *m.DatasourcesPermissionFilterQuery) error {
+	required := query.Permission
+	if required == m.DsPermissionNoAccess {
+		required = m.DsPermissionQuery
+	}
+
+	query.Result = make([]*m.DataSource, 0, len(query.Datasources))
+
+	user := query.User
+	if user == nil || user.OrgRole == m.ROLE_ADMIN {
+		query.Result = append(query.Result, query.Datasources...)
+		return nil
+	}
+
+	restricted := make([]int64, 0)
+	for _, ds := range query.Datasources {
+		if ds.PermissionsEnabled {
+			restricted = append(restricted, ds.Id)
+		}
+	}
+
+	granted, err := getDataSourcePermissionsForUser(user, restricted)
+	if err != nil {
+		return err
+	}
+
+	for _, ds := range query.Datasources {
+		if !ds.PermissionsEnabled {
+			if required == m.DsPermissionQuery {
+				query.Result = append(query.Result, ds)
+			}
+			continue
+		}
+
+		if granted[ds.Id] >= required {
+			query.Result = append(query.Result, ds)
+		}
+	}
+
+	return nil
+}
+
+// getDataSourcePermissionsForUser returns the highest permission the user
+// has on each of the data sources through the user, their teams or their role.
+func getDataSourcePermissionsForUser(user *m.SignedInUser, datasourceIds []int64) (map[int64]m.DsPermissionType, error) {
+	granted := make(map[int64]m.DsPermissionType)
+	if len(datasourceIds) == 0 {
+		return granted, nil
+	}
+
+	var sql strings.Builder
+	params := make([]interface{}, 0)
+
+	sql.WriteString(`SELECT datasource_id, permission FROM data_source_acl WHERE org_id = ? AND datasource_id IN (?` + strings.Repeat(",?", len(datasourceIds)-1) + `)`)
+	params = append(params, user.OrgId)
+	for _, id := range datasourceIds {
+		params = append(params, id)
+	}
+
+	conditions := make([]string, 0)
+	if user.UserId > 0 {
+		conditions = append(conditions, "user_id = ?", "team_id IN (SELECT team_id FROM team_member WHERE user_id = ?)")
+		params = append(params, user.UserId, user.UserId)
+	}
+
+	for _, role := range []m.RoleType{m.ROLE_VIEWER, m.ROLE_EDITOR, m.ROLE_ADMIN} {
+		if user.OrgRole.Includes(role) {
+			conditions = append(conditions, "role = ?")
+			params = append(params, role)
+		}
+	}
+
+	if len(conditions) == 0 {
+		return granted, nil
+	}
+
+	sql.WriteString(" AND (" + strings.Join(conditions, " OR ") + ")")
+
+	rows := make([]*m.DataSourceAcl, 0)
+	if err := x.SQL(sql.String(), params...).Find(&rows); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if row.Permission > granted[row.DatasourceId] {
+			granted[row.DatasourceId] = row.Permission
+		}
+	}
+
+	return granted, nil
+}
+
+func GetDataSourcePermissions(query *m.GetDataSourcePermissionsQuery) error {
+	rawSQL := `
+		SELECT
+			da.id,
+			da.org_id,
+			da.datasource_id,
+			da.user_id,
+			da.team_id,
+			da.role,
+			da.permission,
+			da.created,
+			da.updated,
+			u.login AS user_login,
+			u.email AS user_email,
+			t.name AS team,
+			t.email AS team_email
+		FROM data_source_acl AS da
+			LEFT JOIN ` + dialect.Quote("user") + ` AS u ON u.id = da.user_id
+			LEFT JOIN team t ON t.id = da.team_id
+		WHERE da.org_id = ? AND da.datasource_id = ?
+		ORDER BY da.id ASC`
+
+	query.Result = make([]*m.DataSourceAclInfoDTO, 0)
+	if err := x.SQL(rawSQL, query.OrgId, query.DatasourceId).Find(&query.Result); err != nil {
+		return err
+	}
+
+	for _, p := range query.Result {
+		p.PermissionName = p.Permission.String()
+	}
+
+	return nil
+}
+
+func EnableDataSourcePermissions(cmd *m.EnableDataSourcePermissionsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		return setDataSourcePermissionsEnabled(sess, cmd.OrgId, cmd.DatasourceId, true)
+	})
+}
+
+func DisableDataSourcePermissions(cmd *m.DisableDataSourcePermissionsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if err := setDataSourcePermissionsEnabled(sess, cmd.OrgId, cmd.DatasourceId, false); err != nil {
+			return err
+		}
+
+		_, err := sess.Exec("DELETE FROM data_source_acl WHERE org_id=? AND datasource_id=?", cmd.OrgId, cmd.DatasourceId)
+		return err
+	})
+}
+
+func AddDataSourcePermission(cmd *m.AddDataSourcePermissionCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		ds := m.DataSource{Id: cmd.DatasourceId, OrgId: cmd.OrgId}
+		has, err := sess.Get(&ds)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return m.ErrDataSourceNotFound
+		}
+		if !ds.PermissionsEnabled {
+			return m.ErrDataSourcePermissionsDisabled
+		}
+
+		item := &m.DataSourceAcl{
+			OrgId:        cmd.OrgId,
+			DatasourceId: cmd.DatasourceId,
+			UserId:       cmd.UserId,
+			TeamId:       cmd.TeamId,
+			Role:         cmd.Role,
+			Permission:   cmd.Permission,
+			Created:      time.Now(),
+			Updated:      time.Now(),
+		}
+
+		if err := validateDataSourceAcl(item); err != nil {
+			return err
+		}
+
+		existing := make([]*m.DataSourceAcl, 0)
+		if err := sess.Where("org_id=? AND datasource_id=?", cmd.OrgId, cmd.DatasourceId).Find(&existing); err != nil {
+			return err
+		}
+
+		for _, other := range existing {
+			if isSameDataSourceAclSubject(item, other) {
+				return m.ErrDataSourcePermissionExists
+			}
+		}
+
+		sess.Nullable("user_id", "team_id")
+		if _, err := sess.Insert(item); err != nil {
+			return err
+		}
+
+		cmd.Result = *item
+		return nil
+	})
+}
+
+func RemoveDataSourcePermission(cmd *m.RemoveDataSourcePermissionCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		rawSQL := "DELETE FROM data_source_acl WHERE id=? AND org_id=? AND datasource_id=?"
+		result, err := sess.Exec(rawSQL, cmd.Id, cmd.OrgId, cmd.DatasourceId)
+		if err != nil {
+			return err
+		}
+
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return m.ErrDataSourcePermissionNotFound
+		}
+
+		return nil
+	})
+}
+
+func UpdateDataSourcePermissions(cmd *m.UpdateDataSourcePermissionsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if err := setDataSourcePermissionsEnabled(sess, cmd.OrgId, cmd.DatasourceId, true); err != nil {
+			return err
+		}
+
+		_, err := sess.Exec("DELETE FROM data_source_acl WHERE org_id=? AND datasource_id=?", cmd.OrgId, cmd.DatasourceId)
+		if err != nil {
+			return err
+		}
+
+		for i, item := range cmd.Items {
+			item.OrgId = cmd.OrgId
+			item.DatasourceId = cmd.DatasourceId
+			item.Created = time.Now()
+			item.Updated = time.Now()
+
+			if err := validateDataSourceAcl(item); err != nil {
+				return err
+			}
+
+			for _, other := range cmd.Items[:i] {
+				if isSameDataSourceAclSubject(item, other) {
+					return m.ErrDataSourcePermissionExists
+				}
+			}
+
+			sess.Nullable("user_id", "team_id")
+			if _, err := sess.Insert(item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func setDataSourcePermissionsEnabled(sess *DBSession, orgId int64, datasourceId int64, enabled bool) error {
+	has, err := sess.Table("data_source").Where("id=? AND org_id=?", datasourceId, orgId).Exist()
+	if err != nil {
+		return err
+	}
+	if !has {
+		return m.ErrDataSourceNotFound
+	}
+
+	rawSQL := "UPDATE data_source SET permissions_enabled=? WHERE id=? AND org_id=?"
+	_, err = sess.Exec(rawSQL, enabled, datasourceId, orgId)
+	return err
+}
+
+func validateDataSourceAcl(item *m.DataSourceAcl) error {
+	subjects := 0
+	if item.UserId > 0 {
+		subjects++
+	}
+	if item.TeamId > 0 {
+		subjects++
+	}
+	if item.Role != nil {
+		if !item.Role.IsValid() {
+			return m.ErrDataSourcePermissionInvalid
+		}
+		subjects++
+	}
+
+	if subjects != 1 {
+		return m.ErrDataSourcePermissionInfoMissing
+	}
+
+	if !item.Permission.IsValid() {
+		return m.ErrDataSourcePermissionInvalid
+	}
+
+	return nil
+}
+
+func isSameDataSourceAclSubject(a, b *m.DataSourceAcl) bool {
+	switch {
+	case a.UserId > 0:
+		return a.UserId == b.UserId
+	case a.TeamId > 0:
+		return a.TeamId == b.TeamId
+	default:
+		return a.Role != nil && b.Role != nil && *a.Role == *b.Role
+	}
+}
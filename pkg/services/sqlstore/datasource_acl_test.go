@@ -0,0 +1,156 @@
+package sqlstore
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+func TestDataSourceAclDataAccess(t *testing.T) {
+	Convey("Testing data source ACL data access", t, func() {
+		InitTestDB(t)
+
+		addCmd := m.AddDataSourceCommand{OrgId: 1, Name: "restricted", Type: m.DS_GRAPHITE, Access: m.DS_ACCESS_PROXY, Url: "http://test"}
+		So(AddDataSource(&addCmd), ShouldBeNil)
+		restricted := addCmd.Result
+
+		openCmd := m.AddDataSourceCommand{OrgId: 1, Name: "open", Type: m.DS_GRAPHITE, Access: m.DS_ACCESS_PROXY, Url: "http://test"}
+		So(AddDataSource(&openCmd), ShouldBeNil)
+
+		viewer := createUser("viewer", "Viewer", false)
+		editor := createUser("editor", "Editor", false)
+
+		teamCmd := m.CreateTeamCommand{OrgId: 1, Name: "ops"}
+		So(CreateTeam(&teamCmd), ShouldBeNil)
+		So(AddTeamMember(&m.AddTeamMemberCommand{OrgId: 1, TeamId: teamCmd.Result.Id, UserId: viewer.Id}), ShouldBeNil)
+
+		filter := func(user m.User, role m.RoleType, permission m.DsPermissionType) []string {
+			dsQuery := m.GetDataSourcesQuery{OrgId: 1}
+			So(GetDataSources(&dsQuery), ShouldBeNil)
+
+			query := m.DatasourcesPermissionFilterQuery{
+				User:        &m.SignedInUser{UserId: user.Id, OrgId: 1, OrgRole: role},
+				Datasources: dsQuery.Result,
+				Permission:  permission,
+			}
+			So(FilterDatasourcesByPermission(&query), ShouldBeNil)
+
+			names := make([]string, 0)
+			for _, ds := range query.Result {
+				names = append(names, ds.Name)
+			}
+			return names
+		}
+
+		Convey("Without permissions enabled everyone can query but only admins administer", func() {
+			So(filter(viewer, m.ROLE_VIEWER, m.DsPermissionQuery), ShouldResemble, []string{"open", "restricted"})
+			So(filter(viewer, m.ROLE_VIEWER, m.DsPermissionAdmin), ShouldBeEmpty)
+			So(filter(m.User{}, m.ROLE_ADMIN, m.DsPermissionAdmin), ShouldResemble, []string{"open", "restricted"})
+		})
+
+		Convey("Cannot add permissions before enabling them", func() {
+			err := AddDataSourcePermission(&m.AddDataSourcePermissionCommand{OrgId: 1, DatasourceId: restricted.Id, UserId: viewer.Id, Permission: m.DsPermissionQuery})
+			So(err, ShouldEqual, m.ErrDataSourcePermissionsDisabled)
+		})
+
+		Convey("Given permissions are enabled", func() {
+			So(EnableDataSourcePermissions(&m.EnableDataSourcePermissionsCommand{OrgId: 1, DatasourceId: restricted.Id}), ShouldBeNil)
+
+			Convey("Only org admins can use it", func() {
+				So(filter(viewer, m.ROLE_VIEWER, m.DsPermissionQuery), ShouldResemble, []string{"open"})
+				So(filter(m.User{}, m.ROLE_ADMIN, m.DsPermissionQuery), ShouldResemble, []string{"open", "restricted"})
+			})
+
+			Convey("Team members get the team permission", func() {
+				err := AddDataSourcePermission(&m.AddDataSourcePermissionCommand{OrgId: 1, DatasourceId: restricted.Id, TeamId: teamCmd.Result.Id, Permission: m.DsPermissionQuery})
+				So(err, ShouldBeNil)
+
+				So(filter(viewer, m.ROLE_VIEWER, m.DsPermissionQuery), ShouldResemble, []string{"open", "restricted"})
+				So(filter(viewer, m.ROLE_VIEWER, m.DsPermissionAdmin), ShouldBeEmpty)
+				So(filter(editor, m.ROLE_EDITOR, m.DsPermissionQuery), ShouldResemble, []string{"open"})
+			})
+
+			Convey("Role permissions apply to higher roles", func() {
+				viewerRole := m.ROLE_VIEWER
+				err := AddDataSourcePermission(&m.AddDataSourcePermissionCommand{OrgId: 1, DatasourceId: restricted.Id, Role: &viewerRole, Permission: m.DsPermissionQuery})
+				So(err, ShouldBeNil)
+
+				So(filter(editor, m.ROLE_EDITOR, m.DsPermissionQuery), ShouldResemble, []string{"open", "restricted"})
+				So(filter(m.User{}, m.ROLE_VIEWER, m.DsPermissionQuery), ShouldResemble, []string{"open", "restricted"})
+			})
+
+			Convey("User admin permission", func() {
+				cmd := m.AddDataSourcePermissionCommand{OrgId: 1, DatasourceId: restricted.Id, UserId: editor.Id, Permission: m.DsPermissionAdmin}
+				So(AddDataSourcePermission(&cmd), ShouldBeNil)
+
+				So(filter(editor, m.ROLE_EDITOR, m.DsPermissionAdmin), ShouldResemble, []string{"restricted"})
+
+				Convey("Cannot be added twice", func() {
+					err := AddDataSourcePermission(&m.AddDataSourcePermissionCommand{OrgId: 1, DatasourceId: restricted.Id, UserId: editor.Id, Permission: m.DsPermissionQuery})
+					So(err, ShouldEqual, m.ErrDataSourcePermissionExists)
+				})
+
+				Convey("Is listed with user info", func() {
+					query := m.GetDataSourcePermissionsQuery{OrgId: 1, DatasourceId: restricted.Id}
+					So(GetDataSourcePermissions(&query), ShouldBeNil)
+					So(len(query.Result), ShouldEqual, 1)
+					So(query.Result[0].UserLogin, ShouldEqual, "editor")
+					So(query.Result[0].PermissionName, ShouldEqual, "Admin")
+				})
+
+				Convey("Can be removed", func() {
+					err := RemoveDataSourcePermission(&m.RemoveDataSourcePermissionCommand{OrgId: 1, DatasourceId: restricted.Id, Id: cmd.Result.Id})
+					So(err, ShouldBeNil)
+					So(filter(editor, m.ROLE_EDITOR, m.DsPermissionQuery), ShouldResemble, []string{"open"})
+
+					err = RemoveDataSourcePermission(&m.RemoveDataSourcePermissionCommand{OrgId: 1, DatasourceId: restricted.Id, Id: cmd.Result.Id})
+					So(err, ShouldEqual, m.ErrDataSourcePermissionNotFound)
+				})
+
+				Convey("Disabling permissions removes them", func() {
+					So(DisableDataSourcePermissions(&m.DisableDataSourcePermissionsCommand{OrgId: 1, DatasourceId: restricted.Id}), ShouldBeNil)
+
+					query := m.GetDataSourcePermissionsQuery{OrgId: 1, DatasourceId: restricted.Id}
+					So(GetDataSourcePermissions(&query), ShouldBeNil)
+					So(query.Result, ShouldBeEmpty)
+					So(filter(viewer, m.ROLE_VIEWER, m.DsPermissionQuery), ShouldResemble, []string{"open", "restricted"})
+				})
+			})
+
+			Convey("Rejects permissions without exactly one subject", func() {
+				err := AddDataSourcePermission(&m.AddDataSourcePermissionCommand{OrgId: 1, DatasourceId: restricted.Id, Permission: m.DsPermissionQuery})
+				So(err, ShouldEqual, m.ErrDataSourcePermissionInfoMissing)
+
+				err = AddDataSourcePermission(&m.AddDataSourcePermissionCommand{OrgId: 1, DatasourceId: restricted.Id, UserId: viewer.Id, TeamId: teamCmd.Result.Id, Permission: m.DsPermissionQuery})
+				So(err, ShouldEqual, m.ErrDataSourcePermissionInfoMissing)
+			})
+		})
+
+		Convey("Updating permissions replaces the ACL and enables permissions", func() {
+			editorRole := m.ROLE_EDITOR
+			err := UpdateDataSourcePermissions(&m.UpdateDataSourcePermissionsCommand{
+				OrgId:        1,
+				DatasourceId: restricted.Id,
+				Items: []*m.DataSourceAcl{
+					{Role: &editorRole, Permission: m.DsPermissionQuery},
+				},
+			})
+			So(err, ShouldBeNil)
+
+			So(filter(viewer, m.ROLE_VIEWER, m.DsPermissionQuery), ShouldResemble, []string{"open"})
+			So(filter(editor, m.ROLE_EDITOR, m.DsPermissionQuery), ShouldResemble, []string{"open", "restricted"})
+		})
+
+		Convey("Deleting the datasource removes its ACL", func() {
+			So(EnableDataSourcePermissions(&m.EnableDataSourcePermissionsCommand{OrgId: 1, DatasourceId: restricted.Id}), ShouldBeNil)
+			So(AddDataSourcePermission(&m.AddDataSourcePermissionCommand{OrgId: 1, DatasourceId: restricted.Id, UserId: viewer.Id, Permission: m.DsPermissionQuery}), ShouldBeNil)
+			So(DeleteDataSourceById(&m.DeleteDataSourceByIdCommand{OrgId: 1, Id: restricted.Id}), ShouldBeNil)
+
+			query := m.GetDataSourcePermissionsQuery{OrgId: 1, DatasourceId: restricted.Id}
+			So(GetDataSourcePermissions(&query), ShouldBeNil)
+			So(query.Result, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,386 @@
+package sqlstore
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/util"
+)
+
+func init() {
+	bus.AddHandler("sql", CreateLibraryPanel)
+	bus.AddHandler("sql", UpdateLibraryPanel)
+	bus.AddHandler("sql", DeleteLibraryPanel)
+	bus.AddHandler("sql", GetLibraryPanel)
+	bus.AddHandler("sql", GetLibraryPanels)
+	bus.AddHandler("sql", GetLibraryPanelVersions)
+	bus.AddHandler("sql", GetLibraryPanelDashboardIds)
+}
+
+func CreateLibraryPanel(cmd *m.CreateLibraryPanelCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if err := validateLibraryPanel(sess, cmd.OrgId, cmd.FolderId, cmd.Name, cmd.Model); err != nil {
+			return err
+		}
+
+		uid := strings.TrimSpace(cmd.Uid)
+		if uid == "" {
+			var err error
+			if uid, err = generateNewLibraryPanelUid(sess, cmd.OrgId); err != nil {
+				return err
+			}
+		} else if !util.IsValidShortUID(uid) {
+			return m.ErrLibraryPanelInvalidUid
+		} else if len(uid) > 40 {
+			return m.ErrLibraryPanelUidTooLong
+		}
+
+		if exists, err := sess.Where("org_id=? AND uid=?", cmd.OrgId, uid).Get(&m.LibraryPanel{}); err != nil {
+			return err
+		} else if exists {
+			return m.ErrLibraryPanelWithSameUIDExists
+		}
+
+		if err := checkLibraryPanelNameIsUnique(sess, cmd.OrgId, cmd.FolderId, cmd.Name, 0); err != nil {
+			return err
+		}
+
+		userId := cmd.UserId
+		if userId == 0 {
+			userId = -1
+		}
+
+		lp := &m.LibraryPanel{
+			OrgId:     cmd.OrgId,
+			FolderId:  cmd.FolderId,
+			Uid:       uid,
+			Name:      strings.TrimSpace(cmd.Name),
+			Model:     cmd.Model,
+			Version:   1,
+			Created:   time.Now(),
+			Updated:   time.Now(),
+			CreatedBy: userId,
+			UpdatedBy: userId,
+		}
+		setLibraryPanelModelFields(lp)
+
+		if _, err := sess.Insert(lp); err != nil {
+			return err
+		}
+
+		if err := insertLibraryPanelVersion(sess, lp); err != nil {
+			return err
+		}
+
+		cmd.Result = lp
+		return nil
+	})
+}
+
+func UpdateLibraryPanel(cmd *m.UpdateLibraryPanelCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		lp, err := getLibraryPanelByUid(sess, cmd.OrgId, cmd.Uid)
+		if err != nil {
+			return err
+		}
+
+		if lp.Version != cmd.Version {
+			return m.ErrLibraryPanelVersionMismatch
+		}
+
+		if err := validateLibraryPanel(sess, cmd.OrgId, cmd.FolderId, cmd.Name, cmd.Model); err != nil {
+			return err
+		}
+
+		if err := checkLibraryPanelNameIsUnique(sess, cmd.OrgId, cmd.FolderId, cmd.Name, lp.Id); err != nil {
+			return err
+		}
+
+		userId := cmd.UserId
+		if userId == 0 {
+			userId = -1
+		}
+
+		lp.FolderId = cmd.FolderId
+		lp.Name = strings.TrimSpace(cmd.Name)
+		lp.Model = cmd.Model
+		lp.Version++
+		lp.Updated = time.Now()
+		lp.UpdatedBy = userId
+		setLibraryPanelModelFields(lp)
+
+		affectedRows, err := sess.ID(lp.Id).Where("version=?", cmd.Version).AllCols().Update(lp)
+		if err != nil {
+			return err
+		} else if affectedRows == 0 {
+			return m.ErrLibraryPanelVersionMismatch
+		}
+
+		if err := insertLibraryPanelVersion(sess, lp); err != nil {
+			return err
+		}
+
+		cmd.Result = lp
+		return nil
+	})
+}
+
+func DeleteLibraryPanel(cmd *m.DeleteLibraryPanelCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		lp, err := getLibraryPanelByUid(sess, cmd.OrgId, cmd.Uid)
+		if err != nil {
+			return err
+		}
+
+		inUse, err := sess.Table("library_panel_dashboard").Where("library_panel_id=?", lp.Id).Exist()
+		if err != nil {
+			return err
+		} else if inUse {
+			return m.ErrLibraryPanelInUse
+		}
+
+		deletes := []string{
+			"DELETE FROM library_panel_version WHERE library_panel_id = ?",
+			"DELETE FROM library_panel WHERE id = ?",
+		}
+
+		for _, sql := range deletes {
+			if _, err := sess.Exec(sql, lp.Id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func GetLibraryPanel(query *m.GetLibraryPanelQuery) error {
+	lp, err := getLibraryPanelByUid(newSession(), query.OrgId, query.Uid)
+	if err != nil {
+		return err
+	}
+
+	query.Result = lp
+	return nil
+}
+
+func GetLibraryPanels(query *m.GetLibraryPanelsQuery) error {
+	sess := x.Where("org_id=?", query.OrgId)
+
+	if len(query.FolderIds) > 0 {
+		sess.In("folder_id", query.FolderIds)
+	}
+
+	if query.Name != "" {
+		sess.And("name "+dialect.LikeStr()+" ?", "%"+query.Name+"%")
+	}
+
+	if query.Limit > 0 {
+		sess.Limit(query.Limit)
+	}
+
+	query.Result = make([]*m.LibraryPanel, 0)
+	return sess.Asc("name").Find(&query.Result)
+}
+
+func GetLibraryPanelVersions(query *m.GetLibraryPanelVersionsQuery) error {
+	lp, err := getLibraryPanelByUid(newSession(), query.OrgId, query.Uid)
+	if err != nil {
+		return err
+	}
+
+	query.Result = make([]*m.LibraryPanelVersion, 0)
+	return x.Where("library_panel_id=?", lp.Id).Desc("version").Find(&query.Result)
+}
+
+func GetLibraryPanelDashboardIds(query *m.GetLibraryPanelDashboardIdsQuery) error {
+	lp, err := getLibraryPanelByUid(newSession(), query.OrgId, query.Uid)
+	if err != nil {
+		return err
+	}
+
+	connections := make([]*m.LibraryPanelDashboard, 0)
+	if err := x.Where("library_panel_id=?", lp.Id).Asc("dashboard_id").Find(&connections); err != nil {
+		return err
+	}
+
+	query.Result = make([]int64, 0, len(connections))
+	for _, c := range connections {
+		query.Result = append(query.Result, c.DashboardId)
+	}
+
+	return nil
+}
+
+func getLibraryPanelByUid(sess *DBSession, orgId int64, uid string) (*m.LibraryPanel, error) {
+	lp := &m.LibraryPanel{}
+	has, err := sess.Where("org_id=? AND uid=?", orgId, uid).Get(lp)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, m.ErrLibraryPanelNotFound
+	}
+
+	return lp, nil
+}
+
+func validateLibraryPanel(sess *DBSession, orgId int64, folderId int64, name string, model *simplejson.Json) error {
+	if strings.TrimSpace(name) == "" {
+		return m.ErrLibraryPanelNameEmpty
+	}
+
+	if model == nil {
+		return m.ErrLibraryPanelModelEmpty
+	}
+
+	if folderId == 0 {
+		return nil
+	}
+
+	folder := m.Dashboard{}
+	has, err := sess.Where("id=? AND org_id=?", folderId, orgId).Get(&folder)
+	if err != nil {
+		return err
+	} else if !has {
+		return m.ErrFolderNotFound
+	} else if !folder.IsFolder {
+		return m.ErrLibraryPanelFolderMustBeAFolder
+	}
+
+	return nil
+}
+
+func checkLibraryPanelNameIsUnique(sess *DBSession, orgId int64, folderId int64, name string, id int64) error {
+	existing := m.LibraryPanel{}
+	has, err := sess.Where("org_id=? AND folder_id=? AND name=?", orgId, folderId, strings.TrimSpace(name)).Get(&existing)
+	if err != nil {
+		return err
+	} else if has && existing.Id != id {
+		return m.ErrLibraryPanelWithSameNameExists
+	}
+
+	return nil
+}
+
+// setLibraryPanelModelFields copies the fields of the model that are stored
+// in their own columns.
+func setLibraryPanelModelFields(lp *m.LibraryPanel) {
+	lp.Type = lp.Model.Get("type").MustString()
+	lp.Description = lp.Model.Get("description").MustString()
+
+	// the connection to the library panel is made by dashboards, not by the model
+	lp.Model.Del("libraryPanel")
+	lp.Model.Del("id")
+	lp.Model.Del("gridPos")
+}
+
+func insertLibraryPanelVersion(sess *DBSession, lp *m.LibraryPanel) error {
+	_, err := sess.Insert(&m.LibraryPanelVersion{
+		LibraryPanelId: lp.Id,
+		Version:        lp.Version,
+		Name:           lp.Name,
+		Model:          lp.Model,
+		Created:        lp.Updated,
+		CreatedBy:      lp.UpdatedBy,
+	})
+	return err
+}
+
+func generateNewLibraryPanelUid(sess *DBSession, orgId int64) (string, error) {
+	for i := 0; i < 3; i++ {
+		uid := generateNewUid()
+
+		exists, err := sess.Where("org_id=? AND uid=?", orgId, uid).Get(&m.LibraryPanel{})
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return uid, nil
+		}
+	}
+
+	return "", m.ErrLibraryPanelFailedGenerateUid
+}
+
+// updateLibraryPanelConnections records which library panels the saved
+// dashboard uses, so library panels in use cannot be deleted.
+func updateLibraryPanelConnections(sess *DBSession, dash *m.Dashboard) error {
+	if _, err := sess.Exec("DELETE FROM library_panel_dashboard WHERE dashboard_id=?", dash.Id); err != nil {
+		return err
+	}
+
+	if dash.IsFolder || dash.Data == nil {
+		return nil
+	}
+
+	uids, err := m.GetLibraryPanelUids(dash.Data)
+	if err != nil || len(uids) == 0 {
+		return err
+	}
+
+	libraryPanels := make([]*m.LibraryPanel, 0)
+	if err := sess.Where("org_id=?", dash.OrgId).In("uid", uids).Find(&libraryPanels); err != nil {
+		return err
+	}
+
+	if len(libraryPanels) != len(uids) {
+		return m.ErrDashboardLibraryPanelNotFound
+	}
+
+	for _, lp := range libraryPanels {
+		if _, err := sess.Insert(&m.LibraryPanelDashboard{LibraryPanelId: lp.Id, DashboardId: dash.Id}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expandDashboardLibraryPanels replaces the library panel references of the
+// dashboard with the current library panel models.
+func expandDashboardLibraryPanels(dash *m.Dashboard) error {
+	if dash.IsFolder || dash.Data == nil {
+		return nil
+	}
+
+	uids, err := m.GetLibraryPanelUids(dash.Data)
+	if err != nil || len(uids) == 0 {
+		return err
+	}
+
+	libraryPanels := make([]*m.LibraryPanel, 0)
+	if err := x.Where("org_id=?", dash.OrgId).In("uid", uids).Find(&libraryPanels); err != nil {
+		return err
+	}
+
+	m.ExpandLibraryPanels(dash.Data, libraryPanels)
+	return nil
+}
+
+// checkFolderLibraryPanelsNotInUse fails when library panels in the folders
+// are used by dashboards that are not in the folders.
+func checkFolderLibraryPanelsNotInUse(sess *DBSession, inFolders string, params []interface{}) error {
+	rawSQL := `SELECT COUNT(*) FROM library_panel_dashboard AS lpd
+		INNER JOIN library_panel AS lp ON lp.id = lpd.library_panel_id
+		WHERE lp.folder_id IN ` + inFolders + `
+		AND lpd.dashboard_id NOT IN (SELECT id FROM dashboard WHERE folder_id IN ` + inFolders + `)`
+
+	sqlParams := make([]interface{}, 0, 2*len(params))
+	sqlParams = append(sqlParams, params...)
+	sqlParams = append(sqlParams, params...)
+
+	var count int64
+	if _, err := sess.SQL(rawSQL, sqlParams...).Get(&count); err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return m.ErrFolderContainsLibraryPanelsInUse
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package sqlstore
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+func TestLibraryPanelDataAccess(t *testing.T) {
+	Convey("Testing library panel data access", t, func() {
+		InitTestDB(t)
+
+		folder := insertTestDashboard("Shared", 1, 0, true)
+
+		createCmd := m.CreateLibraryPanelCommand{
+			OrgId:    1,
+			UserId:   1,
+			FolderId: folder.Id,
+			Name:     "Service health",
+			Model: simplejson.NewFromAny(map[string]interface{}{
+				"id":    4,
+				"type":  "graph",
+				"title": "Service health",
+				"targets": []interface{}{
+					map[string]interface{}{"expr": "up"},
+				},
+			}),
+		}
+		So(CreateLibraryPanel(&createCmd), ShouldBeNil)
+		lp := createCmd.Result
+
+		saveDashboardUsingLibraryPanel := func(title string, folderId int64, uid string) *m.Dashboard {
+			return insertTestDashboardWithData(1, folderId, map[string]interface{}{
+				"title": title,
+				"panels": []interface{}{
+					map[string]interface{}{
+						"id":           1,
+						"gridPos":      map[string]interface{}{"x": 0, "y": 0, "w": 12, "h": 8},
+						"libraryPanel": map[string]interface{}{"uid": uid, "name": "Service health"},
+					},
+				},
+			})
+		}
+
+		Convey("Should create the library panel", func() {
+			So(lp.Uid, ShouldNotBeEmpty)
+			So(lp.Version, ShouldEqual, 1)
+			So(lp.Type, ShouldEqual, "graph")
+
+			_, hasId := lp.Model.CheckGet("id")
+			So(hasId, ShouldBeFalse)
+
+			query := m.GetLibraryPanelVersionsQuery{OrgId: 1, Uid: lp.Uid}
+			So(GetLibraryPanelVersions(&query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 1)
+		})
+
+		Convey("Should not create a library panel with the same name in the folder", func() {
+			cmd := m.CreateLibraryPanelCommand{OrgId: 1, FolderId: folder.Id, Name: "Service health", Model: simplejson.New()}
+			So(CreateLibraryPanel(&cmd), ShouldEqual, m.ErrLibraryPanelWithSameNameExists)
+		})
+
+		Convey("Should not create a library panel in a dashboard", func() {
+			dash := insertTestDashboard("Not a folder", 1, 0, false)
+			cmd := m.CreateLibraryPanelCommand{OrgId: 1, FolderId: dash.Id, Name: "Other", Model: simplejson.New()}
+			So(CreateLibraryPanel(&cmd), ShouldEqual, m.ErrLibraryPanelFolderMustBeAFolder)
+		})
+
+		Convey("Should update the library panel and keep its versions", func() {
+			updateCmd := m.UpdateLibraryPanelCommand{
+				OrgId:    1,
+				Uid:      lp.Uid,
+				FolderId: folder.Id,
+				Name:     "Service health v2",
+				Model:    simplejson.NewFromAny(map[string]interface{}{"type": "singlestat"}),
+				Version:  1,
+			}
+			So(UpdateLibraryPanel(&updateCmd), ShouldBeNil)
+			So(updateCmd.Result.Version, ShouldEqual, 2)
+			So(updateCmd.Result.Type, ShouldEqual, "singlestat")
+
+			query := m.GetLibraryPanelVersionsQuery{OrgId: 1, Uid: lp.Uid}
+			So(GetLibraryPanelVersions(&query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 2)
+			So(query.Result[0].Version, ShouldEqual, 2)
+
+			Convey("Should not update with an old version", func() {
+				So(UpdateLibraryPanel(&updateCmd), ShouldEqual, m.ErrLibraryPanelVersionMismatch)
+			})
+		})
+
+		Convey("Should list library panels by name", func() {
+			query := m.GetLibraryPanelsQuery{OrgId: 1, Name: "health"}
+			So(GetLibraryPanels(&query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 1)
+
+			query = m.GetLibraryPanelsQuery{OrgId: 1, FolderIds: []int64{0}}
+			So(GetLibraryPanels(&query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 0)
+		})
+
+		Convey("Should not save a dashboard using an unknown library panel", func() {
+			cmd := m.SaveDashboardCommand{
+				OrgId: 1,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"title": "Broken",
+					"panels": []interface{}{
+						map[string]interface{}{"id": 1, "libraryPanel": map[string]interface{}{"uid": "unknown"}},
+					},
+				}),
+			}
+			So(SaveDashboard(&cmd), ShouldEqual, m.ErrDashboardLibraryPanelNotFound)
+		})
+
+		Convey("Given a dashboard using the library panel", func() {
+			dash := saveDashboardUsingLibraryPanel("Using library panel", 0, lp.Uid)
+
+			Convey("Should expand the library panel when getting the dashboard", func() {
+				query := m.GetDashboardQuery{OrgId: 1, Uid: dash.Uid}
+				So(GetDashboard(&query), ShouldBeNil)
+
+				panel := query.Result.Data.Get("panels").GetIndex(0)
+				So(panel.Get("id").MustInt64(), ShouldEqual, 1)
+				So(panel.Get("type").MustString(), ShouldEqual, "graph")
+				So(panel.Get("gridPos").Get("w").MustInt(), ShouldEqual, 12)
+				So(panel.Get("targets").GetIndex(0).Get("expr").MustString(), ShouldEqual, "up")
+				So(panel.Get("libraryPanel").Get("version").MustInt(), ShouldEqual, 1)
+			})
+
+			Convey("Should show changes of the library panel in the dashboard", func() {
+				updateCmd := m.UpdateLibraryPanelCommand{
+					OrgId:    1,
+					Uid:      lp.Uid,
+					FolderId: folder.Id,
+					Name:     "Service health",
+					Model:    simplejson.NewFromAny(map[string]interface{}{"type": "table"}),
+					Version:  1,
+				}
+				So(UpdateLibraryPanel(&updateCmd), ShouldBeNil)
+
+				query := m.GetDashboardQuery{OrgId: 1, Uid: dash.Uid}
+				So(GetDashboard(&query), ShouldBeNil)
+				So(query.Result.Data.Get("panels").GetIndex(0).Get("type").MustString(), ShouldEqual, "table")
+			})
+
+			Convey("Should list the dashboard as using the library panel", func() {
+				query := m.GetLibraryPanelDashboardIdsQuery{OrgId: 1, Uid: lp.Uid}
+				So(GetLibraryPanelDashboardIds(&query), ShouldBeNil)
+				So(query.Result, ShouldResemble, []int64{dash.Id})
+			})
+
+			Convey("Should not delete the library panel", func() {
+				So(DeleteLibraryPanel(&m.DeleteLibraryPanelCommand{OrgId: 1, Uid: lp.Uid}), ShouldEqual, m.ErrLibraryPanelInUse)
+			})
+
+			Convey("Should not delete the folder of the library panel", func() {
+				So(DeleteDashboard(&m.DeleteDashboardCommand{Id: folder.Id, OrgId: 1}), ShouldEqual, m.ErrFolderContainsLibraryPanelsInUse)
+			})
+
+			Convey("Should delete the library panel once the dashboard is deleted", func() {
+				So(DeleteDashboard(&m.DeleteDashboardCommand{Id: dash.Id, OrgId: 1}), ShouldBeNil)
+				So(DeleteLibraryPanel(&m.DeleteLibraryPanelCommand{OrgId: 1, Uid: lp.Uid}), ShouldBeNil)
+
+				query := m.GetLibraryPanelQuery{OrgId: 1, Uid: lp.Uid}
+				So(GetLibraryPanel(&query), ShouldEqual, m.ErrLibraryPanelNotFound)
+			})
+		})
+
+		Convey("Should delete the folder with its library panels when only dashboards in it use them", func() {
+			saveDashboardUsingLibraryPanel("Inside the folder", folder.Id, lp.Uid)
+
+			So(DeleteDashboard(&m.DeleteDashboardCommand{Id: folder.Id, OrgId: 1}), ShouldBeNil)
+
+			query := m.GetLibraryPanelQuery{OrgId: 1, Uid: lp.Uid}
+			So(GetLibraryPanel(&query), ShouldEqual, m.ErrLibraryPanelNotFound)
+
+			count, err := x.Count(&m.LibraryPanelDashboard{})
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import . "github.com/Seasheller/grafana/pkg/services/sqlstore/migrator"
+
+func addAuditMigrations(mg *Migrator) {
+	auditEntryV1 := Table{
+		Name: "audit_entry",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "target", Type: DB_NVarchar, Length: 2048, Nullable: false},
+			{Name: "method", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "status", Type: DB_Int, Nullable: false},
+			{Name: "ip_address", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "request_id", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "created"}},
+			{Cols: []string{"user_id"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create audit_entry table", NewAddTableMigration(auditEntryV1))
+	addTableIndicesMigrations(mg, "v1", auditEntryV1)
+}
@@ -215,4 +215,77 @@ func addDashboardMigration(mg *Migrator) {
 	mg.AddMigration("Add check_sum column", NewAddColumnMigration(dashboardExtrasTableV2, &Column{
 		Name: "check_sum", Type: DB_NVarchar, Length: 32, Nullable: true,
 	}))
+
+	// folder_ancestor holds one row for every folder and each of its parent folders,
+	// including the folder itself at depth 0, so nested folders can be resolved
+	// without recursive queries.
+	folderAncestorV1 := Table{
+		Name: "folder_ancestor",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "folder_id", Type: DB_BigInt, Nullable: false},
+			{Name: "ancestor_id", Type: DB_BigInt, Nullable: false},
+			{Name: "depth", Type: DB_Int, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"folder_id", "ancestor_id"}, Type: UniqueIndex},
+			{Cols: []string{"ancestor_id"}},
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create folder_ancestor table", NewAddTableMigration(folderAncestorV1))
+	addTableIndicesMigrations(mg, "v1", folderAncestorV1)
+
+	mg.AddMigration("Add existing folders to folder_ancestor", NewRawSqlMigration(`
+		INSERT INTO folder_ancestor (org_id, folder_id, ancestor_id, depth)
+		SELECT org_id, id, id, 0 FROM dashboard WHERE is_folder = `+mg.Dialect.BooleanStr(true)))
+
+	// dashboard_search_term holds the text extracted from each dashboard (title,
+	// panel titles, descriptions, datasources and query expressions) so that
+	// search can look inside dashboards and not only at their titles.
+	dashboardSearchTermV1 := Table{
+		Name: "dashboard_search_term",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+			{Name: "panel_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "value", Type: DB_Text, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"dashboard_id"}},
+			{Cols: []string{"org_id", "kind"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_search_term table", NewAddTableMigration(dashboardSearchTermV1))
+	addTableIndicesMigrations(mg, "v1", dashboardSearchTermV1)
+
+	// dashboard_trash_item holds deleted dashboards and folders until they are
+	// restored or purged.
+	dashboardTrashV1 := Table{
+		Name: "dashboard_trash_item",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "parent_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "title", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "folder_id", Type: DB_BigInt, Nullable: false},
+			{Name: "is_folder", Type: DB_Bool, Nullable: false},
+			{Name: "data", Type: DB_MediumText, Nullable: false},
+			{Name: "deleted", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "parent_id"}},
+			{Cols: []string{"deleted"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_trash_item table", NewAddTableMigration(dashboardTrashV1))
+	addTableIndicesMigrations(mg, "v1", dashboardTrashV1)
 }
@@ -56,4 +56,28 @@ FROM dashboard;`
 	// change column type of dashboard_version.data
 	mg.AddMigration("alter dashboard_version.data to mediumtext v1", NewRawSqlMigration("").
 		Mysql("ALTER TABLE dashboard_version MODIFY data MEDIUMTEXT;"))
+
+	mg.AddMigration("Add column label in dashboard_version", NewAddColumnMigration(dashboardVersionV1, &Column{
+		Name: "label", Type: DB_NVarchar, Length: 190, Nullable: true,
+	}))
+
+	dashboardVersionRetentionV1 := Table{
+		Name: "dashboard_version_retention",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "folder_id", Type: DB_BigInt, Nullable: false},
+			{Name: "keep_all_days", Type: DB_Int, Nullable: false},
+			{Name: "keep_daily_days", Type: DB_Int, Nullable: false},
+			{Name: "keep_weekly_days", Type: DB_Int, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "folder_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard_version_retention table", NewAddTableMigration(dashboardVersionRetentionV1))
+	addTableIndicesMigrations(mg, "v1", dashboardVersionRetentionV1)
 }
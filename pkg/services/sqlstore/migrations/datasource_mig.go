@@ -133,4 +133,31 @@ func addDataSourceMigration(mg *Migrator) {
 
 	const setEmptyJSONWhereNullJSON = `UPDATE data_source SET json_data = '{}' WHERE json_data is null`
 	mg.AddMigration("Update json_data with nulls", NewRawSqlMigration(setEmptyJSONWhereNullJSON))
+
+	mg.AddMigration("Add permissions_enabled column to data_source", NewAddColumnMigration(tableV2, &Column{
+		Name: "permissions_enabled", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	dataSourceAclV1 := Table{
+		Name: "data_source_acl",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "datasource_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: true},
+			{Name: "team_id", Type: DB_BigInt, Nullable: true},
+			{Name: "role", Type: DB_Varchar, Length: 20, Nullable: true},
+			{Name: "permission", Type: DB_SmallInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"datasource_id"}},
+			{Cols: []string{"org_id", "user_id"}},
+			{Cols: []string{"org_id", "team_id"}},
+		},
+	}
+
+	mg.AddMigration("create data_source_acl table", NewAddTableMigration(dataSourceAclV1))
+	addTableIndicesMigrations(mg, "v1", dataSourceAclV1)
 }
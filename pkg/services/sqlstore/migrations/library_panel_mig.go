@@ -0,0 +1,66 @@
+package migrations
+
+import . "github.com/Seasheller/grafana/pkg/services/sqlstore/migrator"
+
+func addLibraryPanelMigrations(mg *Migrator) {
+	libraryPanelV1 := Table{
+		Name: "library_panel",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "folder_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 150, Nullable: false},
+			{Name: "type", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "description", Type: DB_Text, Nullable: false},
+			{Name: "model", Type: DB_MediumText, Nullable: false},
+			{Name: "version", Type: DB_Int, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "updated_by", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "folder_id", "name"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create library_panel table", NewAddTableMigration(libraryPanelV1))
+	addTableIndicesMigrations(mg, "v1", libraryPanelV1)
+
+	libraryPanelVersionV1 := Table{
+		Name: "library_panel_version",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "library_panel_id", Type: DB_BigInt, Nullable: false},
+			{Name: "version", Type: DB_Int, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 150, Nullable: false},
+			{Name: "model", Type: DB_MediumText, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"library_panel_id", "version"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create library_panel_version table", NewAddTableMigration(libraryPanelVersionV1))
+	addTableIndicesMigrations(mg, "v1", libraryPanelVersionV1)
+
+	libraryPanelDashboardV1 := Table{
+		Name: "library_panel_dashboard",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "library_panel_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"library_panel_id", "dashboard_id"}, Type: UniqueIndex},
+			{Cols: []string{"dashboard_id"}},
+		},
+	}
+
+	mg.AddMigration("create library_panel_dashboard table", NewAddTableMigration(libraryPanelDashboardV1))
+	addTableIndicesMigrations(mg, "v1", libraryPanelDashboardV1)
+}
@@ -34,6 +34,10 @@ func AddMigrations(mg *Migrator) {
 	addServerlockMigrations(mg)
 	addUserAuthTokenMigrations(mg)
 	addCacheMigration(mg)
+	addAuditMigrations(mg)
+	addLibraryPanelMigrations(mg)
+	addReportMigrations(mg)
+	addPublicDashboardMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
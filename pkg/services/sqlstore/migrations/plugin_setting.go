@@ -38,4 +38,9 @@ func addAppSettingsMigration(mg *Migrator) {
 		{Name: "secure_json_data", Type: DB_Text, Nullable: true},
 		{Name: "plugin_version", Type: DB_NVarchar, Nullable: true, Length: 50},
 	}))
+
+	// add column to mark the settings of provisioned plugins
+	mg.AddMigration("Add column read_only to plugin_setting", NewAddColumnMigration(pluginSettingTable, &Column{
+		Name: "read_only", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
 }
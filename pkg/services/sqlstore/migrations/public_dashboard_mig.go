@@ -0,0 +1,31 @@
+package migrations
+
+import . "github.com/Seasheller/grafana/pkg/services/sqlstore/migrator"
+
+func addPublicDashboardMigrations(mg *Migrator) {
+	publicDashboardV1 := Table{
+		Name: "public_dashboard",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+			{Name: "access_token", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "revoked", Type: DB_Bool, Nullable: false},
+			{Name: "expires", Type: DB_DateTime, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false},
+			{Name: "queries", Type: DB_BigInt, Nullable: false},
+			{Name: "denied_queries", Type: DB_BigInt, Nullable: false},
+			{Name: "last_used", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"access_token"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "dashboard_id"}},
+		},
+	}
+
+	mg.AddMigration("create public_dashboard table", NewAddTableMigration(publicDashboardV1))
+	addTableIndicesMigrations(mg, "v1", publicDashboardV1)
+}
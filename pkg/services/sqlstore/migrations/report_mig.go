@@ -0,0 +1,56 @@
+package migrations
+
+import . "github.com/Seasheller/grafana/pkg/services/sqlstore/migrator"
+
+func addReportMigrations(mg *Migrator) {
+	reportV1 := Table{
+		Name: "report",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "layout", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "format", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "panel_ids", Type: DB_Text, Nullable: true},
+			{Name: "variables", Type: DB_Text, Nullable: true},
+			{Name: "time_from", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "time_to", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "recipients", Type: DB_Text, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: false},
+			{Name: "schedule", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "next_run", Type: DB_DateTime, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}},
+			{Cols: []string{"enabled", "next_run"}},
+		},
+	}
+
+	mg.AddMigration("create report table", NewAddTableMigration(reportV1))
+	addTableIndicesMigrations(mg, "v1", reportV1)
+
+	reportDeliveryV1 := Table{
+		Name: "report_delivery",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "report_id", Type: DB_BigInt, Nullable: false},
+			{Name: "state", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "error", Type: DB_Text, Nullable: false},
+			{Name: "recipients", Type: DB_Text, Nullable: false},
+			{Name: "scheduled", Type: DB_Bool, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"report_id", "created"}},
+		},
+	}
+
+	mg.AddMigration("create report_delivery table", NewAddTableMigration(reportDeliveryV1))
+	addTableIndicesMigrations(mg, "v1", reportDeliveryV1)
+}
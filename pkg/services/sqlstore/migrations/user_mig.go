@@ -122,6 +122,30 @@ func addUserMigrations(mg *Migrator) {
 	mg.AddMigration("Add is_disabled column to user", NewAddColumnMigration(userV2, &Column{
 		Name: "is_disabled", Type: DB_Bool, Nullable: false, Default: "0",
 	}))
+
+	// password_changed_at is used by the password policy to expire old passwords.
+	mg.AddMigration("Add password_changed_at column to user", NewAddColumnMigration(userV2, &Column{
+		Name: "password_changed_at", Type: DB_DateTime, Nullable: true,
+	}))
+
+	mg.AddMigration("Set password_changed_at for existing users", NewRawSqlMigration("").
+		Default("UPDATE "+mg.Dialect.Quote("user")+" SET password_changed_at = updated WHERE password_changed_at IS NULL"))
+
+	userPasswordHistoryV1 := Table{
+		Name: "user_password_history",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "password", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}},
+		},
+	}
+
+	mg.AddMigration("create user_password_history table", NewAddTableMigration(userPasswordHistoryV1))
+	addTableIndicesMigrations(mg, "v1", userPasswordHistoryV1)
 }
 
 type AddMissingUserSaltAndRandsMigration struct {
@@ -191,9 +191,20 @@ func DeleteOrg(cmd *m.DeleteOrgCommand) error {
 		deletes := []string{
 			"DELETE FROM star WHERE EXISTS (SELECT 1 FROM dashboard WHERE org_id = ? AND star.dashboard_id = dashboard.id)",
 			"DELETE FROM dashboard_tag WHERE EXISTS (SELECT 1 FROM dashboard WHERE org_id = ? AND dashboard_tag.dashboard_id = dashboard.id)",
+			"DELETE FROM dashboard_search_term WHERE org_id = ?",
+			"DELETE FROM dashboard_trash_item WHERE org_id = ?",
+			"DELETE FROM library_panel_dashboard WHERE EXISTS (SELECT 1 FROM library_panel WHERE org_id = ? AND library_panel_dashboard.library_panel_id = library_panel.id)",
+			"DELETE FROM library_panel_version WHERE EXISTS (SELECT 1 FROM library_panel WHERE org_id = ? AND library_panel_version.library_panel_id = library_panel.id)",
+			"DELETE FROM library_panel WHERE org_id = ?",
+			"DELETE FROM report_delivery WHERE org_id = ?",
+			"DELETE FROM report WHERE org_id = ?",
+			"DELETE FROM public_dashboard WHERE org_id = ?",
+			"DELETE FROM dashboard_version_retention WHERE org_id = ?",
 			"DELETE FROM dashboard WHERE org_id = ?",
+			"DELETE FROM folder_ancestor WHERE org_id = ?",
 			"DELETE FROM api_key WHERE org_id = ?",
 			"DELETE FROM data_source WHERE org_id = ?",
+			"DELETE FROM data_source_acl WHERE org_id = ?",
 			"DELETE FROM org_user WHERE org_id = ?",
 			"DELETE FROM org WHERE id = ?",
 			"DELETE FROM temp_user WHERE org_id = ?",
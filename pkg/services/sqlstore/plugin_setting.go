@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/Seasheller/grafana/pkg/bus"
+	"github.com/Seasheller/grafana/pkg/components/securejsondata"
 	m "github.com/Seasheller/grafana/pkg/models"
 	"github.com/Seasheller/grafana/pkg/setting"
 	"github.com/Seasheller/grafana/pkg/util"
@@ -14,10 +15,11 @@ func init() {
 	bus.AddHandler("sql", GetPluginSettingById)
 	bus.AddHandler("sql", UpdatePluginSetting)
 	bus.AddHandler("sql", UpdatePluginSettingVersion)
+	bus.AddHandler("sql", UpdatePluginSettingReadOnly)
 }
 
 func GetPluginSettings(query *m.GetPluginSettingsQuery) error {
-	sql := `SELECT org_id, plugin_id, enabled, pinned, plugin_version
+	sql := `SELECT org_id, plugin_id, enabled, pinned, plugin_version, read_only
 					FROM plugin_setting `
 	params := make([]interface{}, 0)
 
@@ -53,6 +55,7 @@ func UpdatePluginSetting(cmd *m.UpdatePluginSettingCmd) error {
 		}
 		sess.UseBool("enabled")
 		sess.UseBool("pinned")
+		sess.UseBool("read_only")
 		if !exists {
 			pluginSetting = m.PluginSetting{
 				PluginId:       cmd.PluginId,
@@ -62,6 +65,7 @@ func UpdatePluginSetting(cmd *m.UpdatePluginSettingCmd) error {
 				JsonData:       cmd.JsonData,
 				PluginVersion:  cmd.PluginVersion,
 				SecureJsonData: cmd.GetEncryptedJsonData(),
+				ReadOnly:       cmd.ReadOnly,
 				Created:        time.Now(),
 				Updated:        time.Now(),
 			}
@@ -76,6 +80,9 @@ func UpdatePluginSetting(cmd *m.UpdatePluginSettingCmd) error {
 			_, err = sess.Insert(&pluginSetting)
 			return err
 		}
+		if pluginSetting.SecureJsonData == nil {
+			pluginSetting.SecureJsonData = make(securejsondata.SecureJsonData)
+		}
 		for key, data := range cmd.SecureJsonData {
 			encryptedData, err := util.Encrypt([]byte(data), setting.SecretKey)
 			if err != nil {
@@ -99,6 +106,7 @@ func UpdatePluginSetting(cmd *m.UpdatePluginSettingCmd) error {
 		pluginSetting.JsonData = cmd.JsonData
 		pluginSetting.Pinned = cmd.Pinned
 		pluginSetting.PluginVersion = cmd.PluginVersion
+		pluginSetting.ReadOnly = cmd.ReadOnly
 
 		_, err = sess.ID(pluginSetting.Id).Update(&pluginSetting)
 		return err
@@ -113,3 +121,10 @@ func UpdatePluginSettingVersion(cmd *m.UpdatePluginSettingVersionCmd) error {
 
 	})
 }
+
+func UpdatePluginSettingReadOnly(cmd *m.UpdatePluginSettingReadOnlyCmd) error {
+	return inTransaction(func(sess *DBSession) error {
+		_, err := sess.Where("org_id=? AND plugin_id=?", cmd.OrgId, cmd.PluginId).UseBool("read_only").Update(&m.PluginSetting{ReadOnly: cmd.ReadOnly})
+		return err
+	})
+}
@@ -0,0 +1,35 @@
+package sqlstore
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+func TestPluginSettingDataAccess(t *testing.T) {
+	Convey("Testing plugin setting data access", t, func() {
+		InitTestDB(t)
+
+		err := UpdatePluginSetting(&m.UpdatePluginSettingCmd{
+			OrgId:    1,
+			PluginId: "test-app",
+			Enabled:  true,
+			JsonData: map[string]interface{}{"apiUrl": "https://api.example.com"},
+			ReadOnly: true,
+		})
+		So(err, ShouldBeNil)
+
+		Convey("Should make the settings editable without changing them", func() {
+			err := UpdatePluginSettingReadOnly(&m.UpdatePluginSettingReadOnlyCmd{OrgId: 1, PluginId: "test-app", ReadOnly: false})
+			So(err, ShouldBeNil)
+
+			query := &m.GetPluginSettingByIdQuery{OrgId: 1, PluginId: "test-app"}
+			So(GetPluginSettingById(query), ShouldBeNil)
+			So(query.Result.ReadOnly, ShouldBeFalse)
+			So(query.Result.Enabled, ShouldBeTrue)
+			So(query.Result.JsonData["apiUrl"], ShouldEqual, "https://api.example.com")
+		})
+	})
+}
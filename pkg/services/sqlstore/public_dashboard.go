@@ -0,0 +1,98 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/util"
+)
+
+func init() {
+	bus.AddHandler("sql", CreatePublicDashboard)
+	bus.AddHandler("sql", RevokePublicDashboard)
+	bus.AddHandler("sql", RecordPublicDashboardUsage)
+	bus.AddHandler("sql", GetPublicDashboardByToken)
+	bus.AddHandler("sql", GetPublicDashboards)
+}
+
+func CreatePublicDashboard(cmd *m.CreatePublicDashboardCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		var expires = time.Now().Add(time.Hour * 24 * 365 * 50)
+		if cmd.Expires > 0 {
+			expires = time.Now().Add(time.Second * time.Duration(cmd.Expires))
+		}
+
+		publicDashboard := &m.PublicDashboard{
+			OrgId:       cmd.OrgId,
+			DashboardId: cmd.DashboardId,
+			AccessToken: util.GetRandomString(32),
+			CreatedBy:   cmd.UserId,
+			Expires:     expires,
+			Created:     time.Now(),
+			Updated:     time.Now(),
+		}
+
+		if _, err := sess.Insert(publicDashboard); err != nil {
+			return err
+		}
+
+		cmd.Result = publicDashboard
+		return nil
+	})
+}
+
+func RevokePublicDashboard(cmd *m.RevokePublicDashboardCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		rawSql := "UPDATE public_dashboard SET revoked = " + dialect.BooleanStr(true) + ", updated = ? WHERE id = ? AND org_id = ? AND dashboard_id = ?"
+		res, err := sess.Exec(rawSql, time.Now(), cmd.Id, cmd.OrgId, cmd.DashboardId)
+		if err != nil {
+			return err
+		}
+
+		if affected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return m.ErrPublicDashboardNotFound
+		}
+
+		return nil
+	})
+}
+
+func RecordPublicDashboardUsage(cmd *m.RecordPublicDashboardUsageCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		rawSql := `UPDATE public_dashboard SET
+			views = views + ?,
+			queries = queries + ?,
+			denied_queries = denied_queries + ?,
+			last_used = ?
+			WHERE id = ?`
+
+		_, err := sess.Exec(rawSql, cmd.Views, cmd.Queries, cmd.DeniedQueries, cmd.Time, cmd.Id)
+		return err
+	})
+}
+
+func GetPublicDashboardByToken(query *m.GetPublicDashboardByTokenQuery) error {
+	if query.AccessToken == "" {
+		return m.ErrPublicDashboardNotFound
+	}
+
+	publicDashboard := &m.PublicDashboard{}
+	exists, err := x.Where("access_token=?", query.AccessToken).Get(publicDashboard)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return m.ErrPublicDashboardNotFound
+	}
+
+	query.Result = publicDashboard
+	return nil
+}
+
+func GetPublicDashboards(query *m.GetPublicDashboardsQuery) error {
+	query.Result = make([]*m.PublicDashboard, 0)
+	return x.Where("org_id=? AND dashboard_id=?", query.OrgId, query.DashboardId).Desc("created").Find(&query.Result)
+}
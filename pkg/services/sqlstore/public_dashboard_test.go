@@ -0,0 +1,72 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+func TestPublicDashboardDataAccess(t *testing.T) {
+	Convey("Testing public dashboard data access", t, func() {
+		InitTestDB(t)
+
+		createCmd := m.CreatePublicDashboardCommand{OrgId: 1, DashboardId: 3, UserId: 2, Expires: 3600}
+		So(CreatePublicDashboard(&createCmd), ShouldBeNil)
+		publicDashboard := createCmd.Result
+		So(publicDashboard.AccessToken, ShouldHaveLength, 32)
+		So(publicDashboard.IsActive(time.Now()), ShouldBeTrue)
+		So(publicDashboard.IsActive(time.Now().Add(2*time.Hour)), ShouldBeFalse)
+
+		Convey("Should get it by its access token", func() {
+			query := m.GetPublicDashboardByTokenQuery{AccessToken: publicDashboard.AccessToken}
+			So(GetPublicDashboardByToken(&query), ShouldBeNil)
+			So(query.Result.Id, ShouldEqual, publicDashboard.Id)
+			So(query.Result.DashboardId, ShouldEqual, 3)
+
+			query = m.GetPublicDashboardByTokenQuery{AccessToken: "unknown"}
+			So(GetPublicDashboardByToken(&query), ShouldEqual, m.ErrPublicDashboardNotFound)
+		})
+
+		Convey("Should never expire without an expiry", func() {
+			cmd := m.CreatePublicDashboardCommand{OrgId: 1, DashboardId: 3, UserId: 2}
+			So(CreatePublicDashboard(&cmd), ShouldBeNil)
+			So(cmd.Result.IsActive(time.Now().Add(24*365*time.Hour)), ShouldBeTrue)
+			So(cmd.Result.AccessToken, ShouldNotEqual, publicDashboard.AccessToken)
+
+			query := m.GetPublicDashboardsQuery{OrgId: 1, DashboardId: 3}
+			So(GetPublicDashboards(&query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 2)
+		})
+
+		Convey("Should keep revoked tokens", func() {
+			cmd := m.RevokePublicDashboardCommand{Id: publicDashboard.Id, OrgId: 1, DashboardId: 3}
+			So(RevokePublicDashboard(&cmd), ShouldBeNil)
+
+			query := m.GetPublicDashboardByTokenQuery{AccessToken: publicDashboard.AccessToken}
+			So(GetPublicDashboardByToken(&query), ShouldBeNil)
+			So(query.Result.Revoked, ShouldBeTrue)
+			So(query.Result.IsActive(time.Now()), ShouldBeFalse)
+		})
+
+		Convey("Should not revoke tokens of other dashboards", func() {
+			cmd := m.RevokePublicDashboardCommand{Id: publicDashboard.Id, OrgId: 1, DashboardId: 4}
+			So(RevokePublicDashboard(&cmd), ShouldEqual, m.ErrPublicDashboardNotFound)
+		})
+
+		Convey("Should add up usage", func() {
+			So(RecordPublicDashboardUsage(&m.RecordPublicDashboardUsageCommand{Id: publicDashboard.Id, Views: 1, Time: time.Now()}), ShouldBeNil)
+			So(RecordPublicDashboardUsage(&m.RecordPublicDashboardUsageCommand{Id: publicDashboard.Id, Queries: 1, Time: time.Now()}), ShouldBeNil)
+			So(RecordPublicDashboardUsage(&m.RecordPublicDashboardUsageCommand{Id: publicDashboard.Id, DeniedQueries: 1, Time: time.Now()}), ShouldBeNil)
+
+			query := m.GetPublicDashboardsQuery{OrgId: 1, DashboardId: 3}
+			So(GetPublicDashboards(&query), ShouldBeNil)
+			So(query.Result[0].Views, ShouldEqual, 1)
+			So(query.Result[0].Queries, ShouldEqual, 1)
+			So(query.Result[0].DeniedQueries, ShouldEqual, 1)
+			So(query.Result[0].LastUsed.IsZero(), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,137 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/bus"
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", CreateReport)
+	bus.AddHandler("sql", UpdateReport)
+	bus.AddHandler("sql", DeleteReport)
+	bus.AddHandler("sql", GetReport)
+	bus.AddHandler("sql", GetReports)
+	bus.AddHandler("sql", GetDueReports)
+	bus.AddHandler("sql", SetReportNextRun)
+	bus.AddHandler("sql", CreateReportDelivery)
+	bus.AddHandler("sql", GetReportDeliveries)
+}
+
+func CreateReport(cmd *m.CreateReportCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		report := &m.Report{
+			OrgId:          cmd.OrgId,
+			UserId:         cmd.UserId,
+			ReportSettings: cmd.ReportSettings,
+			NextRun:        cmd.NextRun,
+			Created:        time.Now(),
+			Updated:        time.Now(),
+		}
+
+		if _, err := sess.Insert(report); err != nil {
+			return err
+		}
+
+		cmd.Result = report
+		return nil
+	})
+}
+
+func UpdateReport(cmd *m.UpdateReportCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		report, err := getReport(sess, cmd.Id, cmd.OrgId)
+		if err != nil {
+			return err
+		}
+
+		report.ReportSettings = cmd.ReportSettings
+		report.UserId = cmd.UserId
+		report.NextRun = cmd.NextRun
+		report.Updated = time.Now()
+
+		if _, err := sess.ID(report.Id).AllCols().Update(report); err != nil {
+			return err
+		}
+
+		cmd.Result = report
+		return nil
+	})
+}
+
+func DeleteReport(cmd *m.DeleteReportCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := getReport(sess, cmd.Id, cmd.OrgId); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("DELETE FROM report_delivery WHERE report_id = ?", cmd.Id); err != nil {
+			return err
+		}
+
+		_, err := sess.Exec("DELETE FROM report WHERE id = ?", cmd.Id)
+		return err
+	})
+}
+
+func GetReport(query *m.GetReportQuery) error {
+	report, err := getReport(newSession(), query.Id, query.OrgId)
+	if err != nil {
+		return err
+	}
+
+	query.Result = report
+	return nil
+}
+
+func GetReports(query *m.GetReportsQuery) error {
+	query.Result = make([]*m.Report, 0)
+	return x.Where("org_id=?", query.OrgId).Asc("name").Find(&query.Result)
+}
+
+func GetDueReports(query *m.GetDueReportsQuery) error {
+	query.Result = make([]*m.Report, 0)
+	return x.Where("enabled="+dialect.BooleanStr(true)+" AND next_run<=?", query.Now).Asc("next_run").Find(&query.Result)
+}
+
+func SetReportNextRun(cmd *m.SetReportNextRunCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		_, err := sess.Exec("UPDATE report SET next_run = ? WHERE id = ?", cmd.NextRun, cmd.Id)
+		return err
+	})
+}
+
+func CreateReportDelivery(cmd *m.CreateReportDeliveryCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if cmd.Delivery.Created.IsZero() {
+			cmd.Delivery.Created = time.Now()
+		}
+
+		_, err := sess.Insert(cmd.Delivery)
+		return err
+	})
+}
+
+func GetReportDeliveries(query *m.GetReportDeliveriesQuery) error {
+	sess := x.Where("org_id=? AND report_id=?", query.OrgId, query.ReportId)
+
+	if query.Limit > 0 {
+		sess.Limit(query.Limit)
+	}
+
+	query.Result = make([]*m.ReportDelivery, 0)
+	return sess.Desc("created").Desc("id").Find(&query.Result)
+}
+
+func getReport(sess *DBSession, id int64, orgId int64) (*m.Report, error) {
+	report := &m.Report{}
+	exists, err := sess.Where("id=? AND org_id=?", id, orgId).Get(report)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, m.ErrReportNotFound
+	}
+	return report, nil
+}
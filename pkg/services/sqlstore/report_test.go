@@ -0,0 +1,86 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	m "github.com/Seasheller/grafana/pkg/models"
+)
+
+func TestReportDataAccess(t *testing.T) {
+	Convey("Testing report data access", t, func() {
+		InitTestDB(t)
+
+		now := time.Now()
+
+		createCmd := m.CreateReportCommand{
+			OrgId:  1,
+			UserId: 2,
+			ReportSettings: m.ReportSettings{
+				Name:         "Weekly",
+				DashboardUid: "abc",
+				Layout:       m.ReportLayoutPanels,
+				Format:       m.ReportFormatPDF,
+				PanelIds:     []int64{1, 3},
+				Variables:    map[string][]string{"host": {"a", "b"}},
+				Recipients:   "ops@example.com",
+				Schedule:     "0 8 * * 1",
+				Enabled:      true,
+			},
+			NextRun: now.Add(-time.Minute),
+		}
+		So(CreateReport(&createCmd), ShouldBeNil)
+		report := createCmd.Result
+		So(report.Id, ShouldNotEqual, 0)
+
+		Convey("Should get the report with its panels and variables", func() {
+			query := m.GetReportQuery{Id: report.Id, OrgId: 1}
+			So(GetReport(&query), ShouldBeNil)
+			So(query.Result.Name, ShouldEqual, "Weekly")
+			So(query.Result.PanelIds, ShouldResemble, []int64{1, 3})
+			So(query.Result.Variables["host"], ShouldResemble, []string{"a", "b"})
+
+			query = m.GetReportQuery{Id: report.Id, OrgId: 2}
+			So(GetReport(&query), ShouldEqual, m.ErrReportNotFound)
+		})
+
+		Convey("Should return enabled reports that are due", func() {
+			query := m.GetDueReportsQuery{Now: now}
+			So(GetDueReports(&query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 1)
+
+			So(SetReportNextRun(&m.SetReportNextRunCommand{Id: report.Id, NextRun: now.Add(time.Hour)}), ShouldBeNil)
+			So(GetDueReports(&query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 0)
+		})
+
+		Convey("Should not return disabled reports as due", func() {
+			settings := report.ReportSettings
+			settings.Enabled = false
+			updateCmd := m.UpdateReportCommand{Id: report.Id, OrgId: 1, UserId: 2, ReportSettings: settings, NextRun: report.NextRun}
+			So(UpdateReport(&updateCmd), ShouldBeNil)
+
+			query := m.GetDueReportsQuery{Now: now}
+			So(GetDueReports(&query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 0)
+		})
+
+		Convey("Should list deliveries newest first and delete them with the report", func() {
+			for _, state := range []m.ReportDeliveryState{m.ReportDeliveryFailed, m.ReportDeliverySent} {
+				cmd := m.CreateReportDeliveryCommand{Delivery: &m.ReportDelivery{OrgId: 1, ReportId: report.Id, State: state}}
+				So(CreateReportDelivery(&cmd), ShouldBeNil)
+			}
+
+			query := m.GetReportDeliveriesQuery{OrgId: 1, ReportId: report.Id}
+			So(GetReportDeliveries(&query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 2)
+			So(query.Result[0].State, ShouldEqual, m.ReportDeliverySent)
+
+			So(DeleteReport(&m.DeleteReportCommand{Id: report.Id, OrgId: 1}), ShouldBeNil)
+			So(GetReportDeliveries(&query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 0)
+		})
+	})
+}
@@ -1,9 +1,11 @@
 package sqlstore
 
 import (
+	"strconv"
 	"strings"
 
 	m "github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/services/search"
 )
 
 // SearchBuilder is a builder/object mother that builds a dashboard search query
@@ -19,6 +21,9 @@ type SearchBuilder struct {
 	whereTypeFolder     bool
 	whereTypeDash       bool
 	whereFolderIds      []int64
+	whereDatasource     string
+	wherePanelType      string
+	fullText            bool
 	permission          m.PermissionType
 }
 
@@ -71,6 +76,26 @@ func (sb *SearchBuilder) WithTitle(title string) *SearchBuilder {
 	return sb
 }
 
+// WithFullText matches the title against everything in the full-text search
+// index, not only the dashboard title, and orders the hits by score.
+func (sb *SearchBuilder) WithFullText() *SearchBuilder {
+	sb.fullText = true
+
+	return sb
+}
+
+func (sb *SearchBuilder) WithDatasource(datasource string) *SearchBuilder {
+	sb.whereDatasource = datasource
+
+	return sb
+}
+
+func (sb *SearchBuilder) WithPanelType(panelType string) *SearchBuilder {
+	sb.wherePanelType = panelType
+
+	return sb
+}
+
 func (sb *SearchBuilder) WithType(queryType string) *SearchBuilder {
 	if len(queryType) > 0 && queryType == "dash-folder" {
 		sb.whereTypeFolder = true
@@ -100,8 +125,12 @@ func (sb *SearchBuilder) ToSql() (string, []interface{}) {
 		sb.buildMainQuery()
 	}
 
-	sb.sql.WriteString(`
-		ORDER BY dashboard.id ` + dialect.LimitOffset(sb.limit, (sb.page-1)*sb.limit) + `) as ids
+	sb.sql.WriteString(` ORDER BY `)
+	if sb.isFullTextSearch() {
+		sb.buildScoreOrder()
+	}
+
+	sb.sql.WriteString(`dashboard.id ` + dialect.LimitOffset(sb.limit, (sb.page-1)*sb.limit) + `) as ids
 		INNER JOIN dashboard on ids.id = dashboard.id
 	`)
 
@@ -183,11 +212,22 @@ func (sb *SearchBuilder) buildSearchWhereClause() {
 
 	sb.writeDashboardPermissionFilter(sb.signedInUser, sb.permission)
 
-	if len(sb.whereTitle) > 0 {
+	if sb.isFullTextSearch() {
+		sb.sql.WriteString(" AND (dashboard.title " + dialect.LikeStr() + " ? OR dashboard.id IN (SELECT dashboard_id FROM dashboard_search_term WHERE org_id = ? AND kind <> '" + searchTermPanelType + "' AND value " + dialect.LikeStr() + " ?))")
+		sb.params = append(sb.params, "%"+sb.whereTitle+"%", sb.signedInUser.OrgId, "%"+sb.whereTitle+"%")
+	} else if len(sb.whereTitle) > 0 {
 		sb.sql.WriteString(" AND dashboard.title " + dialect.LikeStr() + " ?")
 		sb.params = append(sb.params, "%"+sb.whereTitle+"%")
 	}
 
+	if len(sb.whereDatasource) > 0 {
+		sb.writeSearchTermFilter(search.MatchDatasource, sb.whereDatasource)
+	}
+
+	if len(sb.wherePanelType) > 0 {
+		sb.writeSearchTermFilter(searchTermPanelType, sb.wherePanelType)
+	}
+
 	if sb.whereTypeFolder {
 		sb.sql.WriteString(" AND dashboard.is_folder = " + dialect.BooleanStr(true))
 	}
@@ -203,3 +243,23 @@ func (sb *SearchBuilder) buildSearchWhereClause() {
 		}
 	}
 }
+
+func (sb *SearchBuilder) isFullTextSearch() bool {
+	return sb.fullText && len(sb.whereTitle) > 0
+}
+
+func (sb *SearchBuilder) writeSearchTermFilter(kind string, value string) {
+	sb.sql.WriteString(" AND dashboard.id IN (SELECT dashboard_id FROM dashboard_search_term WHERE org_id = ? AND kind = ? AND value = ?)")
+	sb.params = append(sb.params, sb.signedInUser.OrgId, kind, value)
+}
+
+// buildScoreOrder orders the dashboards by the summed weight of their
+// matching search terms, so the best matches are on the first page.
+func (sb *SearchBuilder) buildScoreOrder() {
+	sb.sql.WriteString(`COALESCE((SELECT SUM(CASE st.kind`)
+	for _, kind := range searchMatchKinds {
+		sb.sql.WriteString(` WHEN '` + kind + `' THEN ` + strconv.Itoa(search.MatchWeights[kind]))
+	}
+	sb.sql.WriteString(` ELSE 0 END) FROM dashboard_search_term AS st WHERE st.dashboard_id = dashboard.id AND st.value ` + dialect.LikeStr() + ` ?), 0) DESC, `)
+	sb.params = append(sb.params, "%"+sb.whereTitle+"%")
+}
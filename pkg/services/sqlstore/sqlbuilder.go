@@ -51,7 +51,7 @@ func (sb *SqlBuilder) writeDashboardPermissionFilter(user *m.SignedInUser, permi
 					LEFT JOIN dashboard AS folder on folder.id = d.folder_id
 					LEFT JOIN dashboard_acl AS da ON
 						da.dashboard_id = d.id OR
-						da.dashboard_id = d.folder_id
+						da.dashboard_id IN (` + folderAncestorIdsSQL("d.folder_id") + `)
 					LEFT JOIN team_member as ugm on ugm.team_id = da.team_id
 					WHERE
 						d.org_id = ? AND
@@ -69,7 +69,7 @@ func (sb *SqlBuilder) writeDashboardPermissionFilter(user *m.SignedInUser, permi
 						(
 							-- include default permissions -->
 							da.org_id = -1 AND (
-							  (folder.id IS NOT NULL AND folder.has_acl = ` + falseStr + `) OR
+							  (folder.id IS NOT NULL AND NOT ` + folderChainHasAclSQL("d.folder_id") + `) OR
 							  (folder.id IS NULL AND d.has_acl = ` + falseStr + `)
 							)
 						)
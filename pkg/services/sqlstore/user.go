@@ -20,6 +20,7 @@ func (ss *SqlStore) addUserQueryAndCommandHandlers() {
 	bus.AddHandler("sql", GetUserById)
 	bus.AddHandler("sql", UpdateUser)
 	bus.AddHandler("sql", ChangeUserPassword)
+	bus.AddHandler("sql", GetUserPasswordHistory)
 	bus.AddHandler("sql", GetUserByLogin)
 	bus.AddHandler("sql", GetUserByEmail)
 	bus.AddHandler("sql", SetUsingOrg)
@@ -119,6 +120,7 @@ func CreateUser(ctx context.Context, cmd *models.CreateUserCommand) error {
 
 		if len(cmd.Password) > 0 {
 			user.Password = util.EncodePassword(cmd.Password, user.Salt)
+			user.PasswordChangedAt = time.Now()
 		}
 
 		sess.UseBool("is_admin")
@@ -127,6 +129,12 @@ func CreateUser(ctx context.Context, cmd *models.CreateUserCommand) error {
 			return err
 		}
 
+		if len(user.Password) > 0 {
+			if err := addUserPasswordHistory(sess, user.Id, user.Password); err != nil {
+				return err
+			}
+		}
+
 		sess.publishAfterCommit(&events.UserCreated{
 			Timestamp: user.Created,
 			Id:        user.Id,
@@ -261,15 +269,55 @@ func ChangeUserPassword(cmd *models.ChangeUserPasswordCommand) error {
 	return inTransaction(func(sess *DBSession) error {
 
 		user := models.User{
-			Password: cmd.NewPassword,
-			Updated:  time.Now(),
+			Password:          cmd.NewPassword,
+			Updated:           time.Now(),
+			PasswordChangedAt: time.Now(),
 		}
 
-		_, err := sess.ID(cmd.UserId).Update(&user)
-		return err
+		if _, err := sess.ID(cmd.UserId).Update(&user); err != nil {
+			return err
+		}
+
+		return addUserPasswordHistory(sess, cmd.UserId, cmd.NewPassword)
 	})
 }
 
+// addUserPasswordHistory remembers a password hash and forgets the
+// ones older than the largest history the password policy can use.
+func addUserPasswordHistory(sess *DBSession, userID int64, password string) error {
+	entry := models.UserPasswordHistory{
+		UserId:   userID,
+		Password: password,
+		Created:  time.Now(),
+	}
+
+	if _, err := sess.Insert(&entry); err != nil {
+		return err
+	}
+
+	var ids []int64
+	err := sess.Table("user_password_history").Cols("id").Where("user_id = ?", userID).Desc("id").Find(&ids)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) <= setting.MaxPasswordHistory {
+		return nil
+	}
+
+	_, err = sess.In("id", ids[setting.MaxPasswordHistory:]).Delete(&models.UserPasswordHistory{})
+	return err
+}
+
+func GetUserPasswordHistory(query *models.GetUserPasswordHistoryQuery) error {
+	query.Result = make([]*models.UserPasswordHistory, 0)
+	if query.Limit <= 0 {
+		return nil
+	}
+
+	return x.Where("user_id = ?", query.UserId).Desc("id").Limit(query.Limit).Find(&query.Result)
+}
+
 func UpdateUserLastSeenAt(cmd *models.UpdateUserLastSeenAtCommand) error {
 	return inTransaction(func(sess *DBSession) error {
 		user := models.User{
@@ -551,11 +599,13 @@ func deleteUserInTransaction(sess *DBSession, cmd *models.DeleteUserCommand) err
 		"DELETE FROM " + dialect.Quote("user") + " WHERE id = ?",
 		"DELETE FROM org_user WHERE user_id = ?",
 		"DELETE FROM dashboard_acl WHERE user_id = ?",
+		"DELETE FROM data_source_acl WHERE user_id = ?",
 		"DELETE FROM preferences WHERE user_id = ?",
 		"DELETE FROM team_member WHERE user_id = ?",
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM user_password_history WHERE user_id = ?",
 	}
 
 	for _, sql := range deletes {
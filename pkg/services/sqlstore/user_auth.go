@@ -16,6 +16,7 @@ func init() {
 	bus.AddHandler("sql", GetUserByAuthInfo)
 	bus.AddHandler("sql", GetExternalUserInfoByLogin)
 	bus.AddHandler("sql", GetAuthInfo)
+	bus.AddHandler("sql", GetUsersByAuthModule)
 	bus.AddHandler("sql", SetAuthInfo)
 	bus.AddHandler("sql", UpdateAuthInfo)
 	bus.AddHandler("sql", DeleteAuthInfo)
@@ -174,6 +175,13 @@ func GetAuthInfo(query *models.GetAuthInfoQuery) error {
 	return nil
 }
 
+func GetUsersByAuthModule(query *models.GetUsersByAuthModuleQuery) error {
+	query.Result = make([]*models.User, 0)
+	return x.Where("id IN (SELECT user_id FROM user_auth WHERE auth_module = ?)", query.AuthModule).
+		Asc("id").
+		Find(&query.Result)
+}
+
 func SetAuthInfo(cmd *models.SetAuthInfoCommand) error {
 	return inTransaction(func(sess *DBSession) error {
 		authUser := &models.UserAuth{
@@ -221,5 +221,35 @@ func TestUserAuth(t *testing.T) {
 			So(err, ShouldBeNil)
 			So(getAuthQuery.Result.AuthModule, ShouldEqual, "test1")
 		})
+
+		Convey("Can list users by AuthModule", func() {
+			for _, login := range []string{"loginuser1", "loginuser3"} {
+				query := &m.GetUserByAuthInfoQuery{Login: login}
+				err = GetUserByAuthInfo(query)
+				So(err, ShouldBeNil)
+
+				cmd := &m.SetAuthInfoCommand{UserId: query.Result.Id, AuthModule: "ldap", AuthId: login}
+				err = SetAuthInfo(cmd)
+				So(err, ShouldBeNil)
+
+				// a second auth entry must not list the user twice
+				err = SetAuthInfo(cmd)
+				So(err, ShouldBeNil)
+			}
+
+			query := &m.GetUsersByAuthModuleQuery{AuthModule: "ldap"}
+			err = GetUsersByAuthModule(query)
+
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 2)
+			So(query.Result[0].Login, ShouldEqual, "loginuser1")
+			So(query.Result[1].Login, ShouldEqual, "loginuser3")
+
+			query = &m.GetUsersByAuthModuleQuery{AuthModule: "oauth_github"}
+			err = GetUsersByAuthModule(query)
+
+			So(err, ShouldBeNil)
+			So(query.Result, ShouldBeEmpty)
+		})
 	})
 }
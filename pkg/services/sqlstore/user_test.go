@@ -9,6 +9,7 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 
 	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/setting"
 )
 
 func TestUserDataAccess(t *testing.T) {
@@ -443,6 +444,48 @@ func TestUserDataAccess(t *testing.T) {
 			})
 		})
 
+		Convey("Given a user with a password", func() {
+			cmd := &models.CreateUserCommand{
+				Email:    "pwtest@test.com",
+				Login:    "pw_test_login",
+				Password: "first",
+			}
+			err := CreateUser(context.Background(), cmd)
+			So(err, ShouldBeNil)
+			So(cmd.Result.PasswordChangedAt.IsZero(), ShouldBeFalse)
+
+			Convey("Changing the password remembers previous hashes newest first", func() {
+				for _, hash := range []string{"second", "third"} {
+					err := ChangeUserPassword(&models.ChangeUserPasswordCommand{UserId: cmd.Result.Id, NewPassword: hash})
+					So(err, ShouldBeNil)
+				}
+
+				query := models.GetUserPasswordHistoryQuery{UserId: cmd.Result.Id, Limit: 2}
+				err := GetUserPasswordHistory(&query)
+				So(err, ShouldBeNil)
+				So(query.Result, ShouldHaveLength, 2)
+				So(query.Result[0].Password, ShouldEqual, "third")
+				So(query.Result[1].Password, ShouldEqual, "second")
+
+				userQuery := models.GetUserByIdQuery{Id: cmd.Result.Id}
+				So(GetUserById(&userQuery), ShouldBeNil)
+				So(userQuery.Result.Password, ShouldEqual, "third")
+				So(userQuery.Result.PasswordChangedAt.IsZero(), ShouldBeFalse)
+			})
+
+			Convey("History is capped", func() {
+				for i := 0; i < setting.MaxPasswordHistory+3; i++ {
+					err := ChangeUserPassword(&models.ChangeUserPasswordCommand{UserId: cmd.Result.Id, NewPassword: fmt.Sprint("hash", i)})
+					So(err, ShouldBeNil)
+				}
+
+				query := models.GetUserPasswordHistoryQuery{UserId: cmd.Result.Id, Limit: 100}
+				err := GetUserPasswordHistory(&query)
+				So(err, ShouldBeNil)
+				So(query.Result, ShouldHaveLength, setting.MaxPasswordHistory)
+			})
+		})
+
 		Convey("Given one grafana admin user", func() {
 			var err error
 			createUserCmd := &models.CreateUserCommand{
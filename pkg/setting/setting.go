@@ -111,6 +111,9 @@ var (
 	// Dashboard history
 	DashboardVersionsToKeep int
 
+	// Days deleted dashboards are kept in the trash, 0 deletes them at once.
+	DashboardTrashRetentionDays int
+
 	// User settings
 	AllowUserSignUp         bool
 	AllowUserOrgCreate      bool
@@ -192,9 +195,22 @@ var (
 	AlertingNotificationTimeout time.Duration
 	AlertingMaxAttempts         int
 
+	// Reports
+	ReportsEnabled       bool
+	ReportsRenderLimit   int
+	ReportsRenderTimeout time.Duration
+
+	// Public dashboards
+	PublicDashboardsEnabled bool
+
 	// Explore UI
 	ExploreEnabled bool
 
+	// Plugin signatures
+	PluginSignatureKeys   []string
+	PluginSignaturePolicy string
+	PluginsAllowUnsigned  []string
+
 	// Grafana.NET URL
 	GrafanaComUrl string
 
@@ -254,13 +270,27 @@ type Cfg struct {
 
 	// SAML Auth
 	SAMLEnabled bool
+	SAML        SAMLSettings
+
+	// Password policy for built-in accounts
+	PasswordPolicy PasswordPolicySettings
 
 	// Dataproxy
 	SendUserHeader bool
 
+	// Audit
+	Audit AuditSettings
+
+	// Data source health checks
+	DataSourceHealth DataSourceHealthSettings
+
 	// DistributedCache
 	RemoteCacheOptions *RemoteCacheOptions
 
+	// Live
+	LiveHAEngine        string
+	LiveHAEngineConnStr string
+
 	EditorsCanAdmin bool
 
 	ApiKeyMaxSecondsToLive int64
@@ -732,6 +762,7 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	// read dashboard settings
 	dashboards := iniFile.Section("dashboards")
 	DashboardVersionsToKeep = dashboards.Key("versions_to_keep").MustInt(20)
+	DashboardTrashRetentionDays = dashboards.Key("trash_retention_days").MustInt(30)
 
 	//  read data source proxy white list
 	DataProxyWhiteList = make(map[string]bool)
@@ -816,7 +847,11 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	}
 
 	// SAML auth
-	cfg.SAMLEnabled = iniFile.Section("auth.saml").Key("enabled").MustBool(false)
+	cfg.readSAMLSettings()
+
+	if err := cfg.readPasswordPolicySettings(); err != nil {
+		return err
+	}
 
 	// anonymous access
 	AnonymousEnabled = iniFile.Section("auth.anonymous").Key("enabled").MustBool(false)
@@ -923,6 +958,13 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	AlertingNotificationTimeout = time.Second * time.Duration(notificationTimeoutSeconds)
 	AlertingMaxAttempts = alerting.Key("max_attempts").MustInt(3)
 
+	reports := iniFile.Section("reports")
+	ReportsEnabled = reports.Key("enabled").MustBool(true)
+	ReportsRenderLimit = reports.Key("concurrent_render_limit").MustInt(5)
+	ReportsRenderTimeout = time.Second * time.Duration(reports.Key("render_timeout_seconds").MustInt64(60))
+
+	PublicDashboardsEnabled = iniFile.Section("public_dashboards").Key("enabled").MustBool(false)
+
 	explore := iniFile.Section("explore")
 	ExploreEnabled = explore.Key("enabled").MustBool(true)
 
@@ -932,6 +974,9 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	pluginsSection := iniFile.Section("plugins")
 	cfg.PluginsEnableAlpha = pluginsSection.Key("enable_alpha").MustBool(false)
 	cfg.PluginsAppsSkipVerifyTLS = pluginsSection.Key("app_tls_skip_verify_insecure").MustBool(false)
+	PluginSignatureKeys = util.SplitString(pluginsSection.Key("signature_keys").String())
+	PluginSignaturePolicy = pluginsSection.Key("signature_policy").In("warn", []string{"warn", "refuse"})
+	PluginsAllowUnsigned = util.SplitString(pluginsSection.Key("allow_unsigned_plugins").String())
 
 	// check old location for this option
 	if panelsSection.Key("enable_alpha").MustBool(false) {
@@ -942,6 +987,8 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	cfg.readSessionConfig()
 	cfg.readSmtpSettings()
 	cfg.readQuotaSettings()
+	cfg.readAuditSettings()
+	cfg.readDataSourceHealthSettings()
 
 	if VerifyEmailEnabled && !cfg.Smtp.Enabled {
 		log.Warn("require_email_validation is enabled but smtp is disabled")
@@ -985,6 +1032,19 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 		ConnStr: connStr,
 	}
 
+	live := iniFile.Section("live")
+	cfg.LiveHAEngine, err = valueAsString(live, "ha_engine", "local")
+	if err != nil {
+		return err
+	}
+	cfg.LiveHAEngineConnStr, err = valueAsString(live, "ha_engine_connstr", "")
+	if err != nil {
+		return err
+	}
+	if cfg.LiveHAEngineConnStr == "" && dbName == "redis" {
+		cfg.LiveHAEngineConnStr = connStr
+	}
+
 	return nil
 }
 
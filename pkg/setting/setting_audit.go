@@ -0,0 +1,23 @@
+package setting
+
+import (
+	"path/filepath"
+)
+
+type AuditSettings struct {
+	Enabled    bool
+	LogPath    string
+	MaxAgeDays int
+}
+
+func (cfg *Cfg) readAuditSettings() {
+	sec := cfg.Raw.Section("audit")
+	cfg.Audit.Enabled = sec.Key("enabled").MustBool(false)
+	cfg.Audit.MaxAgeDays = sec.Key("max_age_days").MustInt(90)
+
+	logPath := sec.Key("log_path").String()
+	if logPath != "" && !filepath.IsAbs(logPath) {
+		logPath = filepath.Join(cfg.LogsPath, logPath)
+	}
+	cfg.Audit.LogPath = logPath
+}
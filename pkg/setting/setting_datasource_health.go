@@ -0,0 +1,25 @@
+package setting
+
+import (
+	"time"
+)
+
+type DataSourceHealthSettings struct {
+	Enabled  bool
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+func (cfg *Cfg) readDataSourceHealthSettings() {
+	sec := cfg.Raw.Section("datasource_health")
+	cfg.DataSourceHealth.Enabled = sec.Key("enabled").MustBool(false)
+	cfg.DataSourceHealth.Interval = sec.Key("interval").MustDuration(time.Minute)
+	cfg.DataSourceHealth.Timeout = sec.Key("timeout").MustDuration(time.Second * 10)
+
+	if cfg.DataSourceHealth.Interval <= 0 {
+		cfg.DataSourceHealth.Interval = time.Minute
+	}
+	if cfg.DataSourceHealth.Timeout <= 0 {
+		cfg.DataSourceHealth.Timeout = time.Second * 10
+	}
+}
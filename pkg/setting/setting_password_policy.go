@@ -0,0 +1,77 @@
+package setting
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/Seasheller/grafana/pkg/util"
+	"github.com/Seasheller/grafana/pkg/util/errutil"
+)
+
+// MaxPasswordHistory is the largest number of previous passwords
+// that can be remembered for each user.
+const MaxPasswordHistory = 24
+
+type PasswordPolicySettings struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	DenyList         []string
+	HistorySize      int
+	MaxAgeDays       int
+}
+
+func (cfg *Cfg) readPasswordPolicySettings() error {
+	sec := cfg.Raw.Section("password_policy")
+	cfg.PasswordPolicy.MinLength = sec.Key("min_length").MustInt(5)
+	cfg.PasswordPolicy.RequireUppercase = sec.Key("require_uppercase").MustBool(false)
+	cfg.PasswordPolicy.RequireLowercase = sec.Key("require_lowercase").MustBool(false)
+	cfg.PasswordPolicy.RequireDigit = sec.Key("require_digit").MustBool(false)
+	cfg.PasswordPolicy.RequireSymbol = sec.Key("require_symbol").MustBool(false)
+	cfg.PasswordPolicy.MaxAgeDays = sec.Key("max_age_days").MustInt(0)
+
+	historySize := sec.Key("history_size").MustInt(0)
+	if historySize > MaxPasswordHistory {
+		cfg.Logger.Warn("password_policy history_size is too large, using maximum", "max", MaxPasswordHistory)
+		historySize = MaxPasswordHistory
+	}
+	cfg.PasswordPolicy.HistorySize = historySize
+
+	denyList := util.SplitString(sec.Key("deny_list").String())
+
+	if denyListPath := sec.Key("deny_list_path").String(); denyListPath != "" {
+		fromFile, err := readPasswordDenyList(makeAbsolute(denyListPath, HomePath))
+		if err != nil {
+			return errutil.Wrap("Failed to read password_policy deny_list_path", err)
+		}
+		denyList = append(denyList, fromFile...)
+	}
+
+	cfg.PasswordPolicy.DenyList = denyList
+	return nil
+}
+
+// readPasswordDenyList reads one password per line, skipping
+// empty lines and lines starting with #.
+func readPasswordDenyList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var passwords []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords = append(passwords, line)
+	}
+
+	return passwords, scanner.Err()
+}
@@ -0,0 +1,54 @@
+package setting
+
+import (
+	"time"
+)
+
+type SAMLSettings struct {
+	Enabled               bool
+	Certificate           string
+	CertificatePath       string
+	PrivateKey            string
+	PrivateKeyPath        string
+	IdpMetadata           string
+	IdpMetadataPath       string
+	IdpMetadataUrl        string
+	MaxIssueDelay         time.Duration
+	MetadataValidDuration time.Duration
+
+	AssertionAttributeName   string
+	AssertionAttributeLogin  string
+	AssertionAttributeEmail  string
+	AssertionAttributeGroups string
+	AssertionAttributeRole   string
+
+	AllowIdpInitiated bool
+	AllowSignUp       bool
+	SingleLogout      bool
+}
+
+func (cfg *Cfg) readSAMLSettings() {
+	sec := cfg.Raw.Section("auth.saml")
+	cfg.SAML.Enabled = sec.Key("enabled").MustBool(false)
+	cfg.SAML.Certificate = sec.Key("certificate").String()
+	cfg.SAML.CertificatePath = sec.Key("certificate_path").String()
+	cfg.SAML.PrivateKey = sec.Key("private_key").String()
+	cfg.SAML.PrivateKeyPath = sec.Key("private_key_path").String()
+	cfg.SAML.IdpMetadata = sec.Key("idp_metadata").String()
+	cfg.SAML.IdpMetadataPath = sec.Key("idp_metadata_path").String()
+	cfg.SAML.IdpMetadataUrl = sec.Key("idp_metadata_url").String()
+	cfg.SAML.MaxIssueDelay = sec.Key("max_issue_delay").MustDuration(time.Second * 90)
+	cfg.SAML.MetadataValidDuration = sec.Key("metadata_valid_duration").MustDuration(time.Hour * 48)
+
+	cfg.SAML.AssertionAttributeName = sec.Key("assertion_attribute_name").MustString("displayName")
+	cfg.SAML.AssertionAttributeLogin = sec.Key("assertion_attribute_login").MustString("mail")
+	cfg.SAML.AssertionAttributeEmail = sec.Key("assertion_attribute_email").MustString("mail")
+	cfg.SAML.AssertionAttributeGroups = sec.Key("assertion_attribute_groups").String()
+	cfg.SAML.AssertionAttributeRole = sec.Key("assertion_attribute_role").String()
+
+	cfg.SAML.AllowIdpInitiated = sec.Key("allow_idp_initiated").MustBool(false)
+	cfg.SAML.AllowSignUp = sec.Key("allow_sign_up").MustBool(true)
+	cfg.SAML.SingleLogout = sec.Key("single_logout").MustBool(false)
+
+	cfg.SAMLEnabled = cfg.SAML.Enabled
+}
@@ -8,9 +8,12 @@ import (
 	"strings"
 
 	"github.com/Seasheller/grafana/pkg/infra/log"
+	"github.com/Seasheller/grafana/pkg/infra/metrics"
 	"github.com/Seasheller/grafana/pkg/models"
 	"github.com/Seasheller/grafana/pkg/tsdb"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
 	"golang.org/x/sync/errgroup"
@@ -76,6 +79,27 @@ func (e *CloudWatchExecutor) Query(ctx context.Context, dsInfo *models.DataSourc
 	return result, err
 }
 
+// HealthCheck checks that the metrics of the default region can be listed
+// with the credentials of the datasource
+func (e *CloudWatchExecutor) HealthCheck(ctx context.Context, dsInfo *models.DataSource) error {
+	e.DataSource = dsInfo
+
+	svc, err := e.getClient("default")
+	if err != nil {
+		return err
+	}
+
+	metrics.MAwsCloudWatchListMetrics.Inc()
+	_, err = svc.ListMetricsWithContext(ctx, &cloudwatch.ListMetricsInput{
+		Namespace: aws.String("AWS/Billing"),
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to call cloudwatch:ListMetrics, %v", err)
+	}
+
+	return nil
+}
+
 func (e *CloudWatchExecutor) executeTimeSeriesQuery(ctx context.Context, queryContext *tsdb.TsdbQuery) (*tsdb.Response, error) {
 	results := &tsdb.Response{
 		Results: make(map[string]*tsdb.QueryResult),
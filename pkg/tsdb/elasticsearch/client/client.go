@@ -38,6 +38,7 @@ type Client interface {
 	GetMinInterval(queryInterval string) (time.Duration, error)
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
+	CheckHealth() error
 	EnableDebug()
 }
 
@@ -314,6 +315,74 @@ func (c *baseClientImpl) MultiSearch() *MultiSearchRequestBuilder {
 	return NewMultiSearchRequestBuilder(c.GetVersion())
 }
 
+// CheckHealth checks that the indices of the time range exist and have the
+// time field.
+func (c *baseClientImpl) CheckHealth() error {
+	res, err := c.executeRequest(http.MethodGet, strings.Join(c.indices, ",")+"/_mapping", "", nil)
+	if err != nil {
+		return err
+	}
+
+	httpRes := res.httpResponse
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode/100 != 2 {
+		return fmt.Errorf("Elasticsearch returned invalid status code: %v", httpRes.Status)
+	}
+
+	var indices map[string]struct {
+		Mappings map[string]interface{} `json:"mappings"`
+	}
+	if err := json.NewDecoder(httpRes.Body).Decode(&indices); err != nil {
+		return err
+	}
+
+	for _, index := range indices {
+		if mappingsHaveField(index.Mappings, c.timeField) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("No date field named %s found", c.timeField)
+}
+
+// mappingsHaveField looks for the field in the mappings of an index, which
+// are grouped by document type before Elasticsearch 7.
+func mappingsHaveField(mappings map[string]interface{}, field string) bool {
+	path := strings.Split(field, ".")
+
+	if properties, ok := mappings["properties"].(map[string]interface{}); ok {
+		return propertiesHaveField(properties, path)
+	}
+
+	for _, mapping := range mappings {
+		docType, ok := mapping.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if properties, ok := docType["properties"].(map[string]interface{}); ok && propertiesHaveField(properties, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func propertiesHaveField(properties map[string]interface{}, path []string) bool {
+	property, ok := properties[path[0]].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	if len(path) == 1 {
+		return true
+	}
+
+	nested, ok := property["properties"].(map[string]interface{})
+	return ok && propertiesHaveField(nested, path[1:])
+}
+
 func (c *baseClientImpl) EnableDebug() {
 	c.debugEnabled = true
 }
@@ -438,3 +438,30 @@ func httpClientScenario(t *testing.T, desc string, ds *models.DataSource, fn sce
 		fn(sc)
 	})
 }
+
+func TestMappingsHaveField(t *testing.T) {
+	Convey("When looking for the time field in the mappings of an index", t, func() {
+		Convey("Should find it in the mappings of Elasticsearch 7", func() {
+			mappings := parseMappings(`{"properties": {"@timestamp": {"type": "date"}}}`)
+			So(mappingsHaveField(mappings, "@timestamp"), ShouldBeTrue)
+			So(mappingsHaveField(mappings, "timestamp"), ShouldBeFalse)
+		})
+
+		Convey("Should find it in the mappings of document types", func() {
+			mappings := parseMappings(`{"doc": {"properties": {"@timestamp": {"type": "date"}}}}`)
+			So(mappingsHaveField(mappings, "@timestamp"), ShouldBeTrue)
+		})
+
+		Convey("Should find nested fields", func() {
+			mappings := parseMappings(`{"properties": {"event": {"properties": {"created": {"type": "date"}}}}}`)
+			So(mappingsHaveField(mappings, "event.created"), ShouldBeTrue)
+			So(mappingsHaveField(mappings, "event.ingested"), ShouldBeFalse)
+		})
+	})
+}
+
+func parseMappings(body string) map[string]interface{} {
+	mappings, err := simplejson.NewJson([]byte(body))
+	So(err, ShouldBeNil)
+	return mappings.MustMap()
+}
@@ -47,3 +47,13 @@ func (e *ElasticsearchExecutor) Query(ctx context.Context, dsInfo *models.DataSo
 	query := newTimeSeriesQuery(client, tsdbQuery, intervalCalculator)
 	return query.execute()
 }
+
+// HealthCheck checks that the indices of the last five minutes can be read
+func (e *ElasticsearchExecutor) HealthCheck(ctx context.Context, dsInfo *models.DataSource) error {
+	client, err := es.NewClient(ctx, dsInfo, tsdb.NewTimeRange("now-5m", "now"))
+	if err != nil {
+		return err
+	}
+
+	return client.CheckHealth()
+}
@@ -637,6 +637,10 @@ func newFakeClient(version int) *fakeClient {
 
 func (c *fakeClient) EnableDebug() {}
 
+func (c *fakeClient) CheckHealth() error {
+	return nil
+}
+
 func (c *fakeClient) GetVersion() int {
 	return c.version
 }
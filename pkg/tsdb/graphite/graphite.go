@@ -115,6 +115,24 @@ func (e *GraphiteExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 	return result, nil
 }
 
+// HealthCheck checks that Graphite can find the metrics at the root
+func (e *GraphiteExecutor) HealthCheck(ctx context.Context, dsInfo *models.DataSource) error {
+	u, _ := url.Parse(dsInfo.Url)
+	u.Path = path.Join(u.Path, "metrics/find")
+	u.RawQuery = url.Values{"query": []string{"*"}}.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	if dsInfo.BasicAuth {
+		req.SetBasicAuth(dsInfo.BasicAuthUser, dsInfo.DecryptedBasicAuthPassword())
+	}
+
+	return tsdb.CheckHttpHealth(ctx, dsInfo, req)
+}
+
 func (e *GraphiteExecutor) parseResponse(res *http.Response) ([]TargetResponseDTO, error) {
 	body, err := ioutil.ReadAll(res.Body)
 	defer res.Body.Close()
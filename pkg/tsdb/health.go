@@ -0,0 +1,91 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context/ctxhttp"
+
+	"github.com/Seasheller/grafana/pkg/models"
+)
+
+var ErrHealthCheckNotSupported = errors.New("Data source does not support health checks")
+
+// HealthCheckingTsdbQueryEndpoint is implemented by the query endpoints of
+// datasources that can check they are reachable and usable. HealthCheck
+// returns an error telling why the datasource is not healthy.
+type HealthCheckingTsdbQueryEndpoint interface {
+	HealthCheck(ctx context.Context, ds *models.DataSource) error
+}
+
+// HealthCheckResult is the outcome of the health check of a datasource.
+type HealthCheckResult struct {
+	Healthy bool
+	// Message tells why the datasource is not healthy.
+	Message string
+	Latency time.Duration
+}
+
+type HandleHealthCheckFunc func(ctx context.Context, dsInfo *models.DataSource) (*HealthCheckResult, error)
+
+// HandleHealthCheck checks the health of the datasource. It returns
+// ErrHealthCheckNotSupported when the datasource has no query endpoint that
+// can check its health, failed checks are reported in the result.
+func HandleHealthCheck(ctx context.Context, dsInfo *models.DataSource) (*HealthCheckResult, error) {
+	if _, exists := registry[dsInfo.Type]; !exists {
+		return nil, ErrHealthCheckNotSupported
+	}
+
+	start := time.Now()
+
+	endpoint, err := getTsdbQueryEndpointFor(dsInfo)
+	if err == nil {
+		checking, ok := endpoint.(HealthCheckingTsdbQueryEndpoint)
+		if !ok {
+			return nil, ErrHealthCheckNotSupported
+		}
+
+		err = checking.HealthCheck(ctx, dsInfo)
+	}
+
+	result := &HealthCheckResult{
+		Healthy: err == nil,
+		Latency: time.Since(start),
+	}
+	if err != nil {
+		result.Message = err.Error()
+	}
+
+	return result, nil
+}
+
+// CheckHttpHealth sends the request with the http client of the datasource
+// and returns an error when the response status is not 2xx.
+func CheckHttpHealth(ctx context.Context, dsInfo *models.DataSource, req *http.Request) error {
+	httpClient, err := dsInfo.GetHttpClient()
+	if err != nil {
+		return err
+	}
+
+	res, err := ctxhttp.Do(ctx, httpClient, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// drain the body so the connection can be reused
+	if _, err := io.Copy(ioutil.Discard, res.Body); err != nil {
+		return err
+	}
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("Request failed status: %v", res.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Seasheller/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type fakeHealthCheckingExecutor struct {
+	FakeExecutor
+	err error
+}
+
+func (e *fakeHealthCheckingExecutor) HealthCheck(ctx context.Context, ds *models.DataSource) error {
+	return e.err
+}
+
+func TestHealthCheck(t *testing.T) {
+	Convey("When checking the health of a datasource", t, func() {
+		var checkErr error
+		RegisterTsdbQueryEndpoint("health-test", func(ds *models.DataSource) (TsdbQueryEndpoint, error) {
+			return &fakeHealthCheckingExecutor{err: checkErr}, nil
+		})
+		RegisterTsdbQueryEndpoint("health-test-broken", func(ds *models.DataSource) (TsdbQueryEndpoint, error) {
+			return nil, errors.New("invalid tls configuration")
+		})
+		registerFakeExecutor()
+
+		Convey("Should report a successful check", func() {
+			result, err := HandleHealthCheck(context.TODO(), &models.DataSource{Type: "health-test"})
+			So(err, ShouldBeNil)
+			So(result.Healthy, ShouldBeTrue)
+			So(result.Message, ShouldBeEmpty)
+		})
+
+		Convey("Should report a failed check", func() {
+			checkErr = errors.New("connection refused")
+
+			result, err := HandleHealthCheck(context.TODO(), &models.DataSource{Type: "health-test"})
+			So(err, ShouldBeNil)
+			So(result.Healthy, ShouldBeFalse)
+			So(result.Message, ShouldEqual, "connection refused")
+		})
+
+		Convey("Should report the query endpoint failing to start as a failed check", func() {
+			result, err := HandleHealthCheck(context.TODO(), &models.DataSource{Type: "health-test-broken"})
+			So(err, ShouldBeNil)
+			So(result.Healthy, ShouldBeFalse)
+			So(result.Message, ShouldEqual, "invalid tls configuration")
+		})
+
+		Convey("Should not check datasources without health checks", func() {
+			_, err := HandleHealthCheck(context.TODO(), &models.DataSource{Type: "test"})
+			So(err, ShouldEqual, ErrHealthCheckNotSupported)
+		})
+
+		Convey("Should not check datasources without query endpoint", func() {
+			_, err := HandleHealthCheck(context.TODO(), &models.DataSource{Type: "frontend-only"})
+			So(err, ShouldEqual, ErrHealthCheckNotSupported)
+		})
+	})
+
+	Convey("When checking the health of a datasource over http", t, func() {
+		status := http.StatusOK
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(status)
+		}))
+		defer server.Close()
+
+		ds := &models.DataSource{Id: 1, Url: server.URL}
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+
+		Convey("Should succeed on 2xx responses", func() {
+			So(CheckHttpHealth(context.TODO(), ds, req), ShouldBeNil)
+		})
+
+		Convey("Should fail on other responses", func() {
+			status = http.StatusUnauthorized
+
+			err := CheckHttpHealth(context.TODO(), ds, req)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "Request failed status: 401 Unauthorized")
+		})
+	})
+}
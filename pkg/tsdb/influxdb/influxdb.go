@@ -98,6 +98,51 @@ func (e *InfluxDBExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 	return result, nil
 }
 
+// HealthCheck checks that the database can be queried. InfluxDB reports
+// errors like a missing database with status 200, in the results.
+func (e *InfluxDBExecutor) HealthCheck(ctx context.Context, dsInfo *models.DataSource) error {
+	req, err := e.createRequest(dsInfo, "SHOW MEASUREMENTS LIMIT 1")
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := dsInfo.GetHttpClient()
+	if err != nil {
+		return err
+	}
+
+	resp, err := ctxhttp.Do(ctx, httpClient, req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("Influxdb returned statuscode invalid status code: %v", resp.Status)
+	}
+
+	var response struct {
+		Results []struct {
+			Error string `json:"error"`
+		} `json:"results"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return err
+	}
+
+	if response.Error != "" {
+		return errors.New(response.Error)
+	}
+	for _, result := range response.Results {
+		if result.Error != "" {
+			return errors.New(result.Error)
+		}
+	}
+
+	return nil
+}
+
 func (e *InfluxDBExecutor) getQuery(dsInfo *models.DataSource, queries []*tsdb.Query, context *tsdb.TsdbQuery) (*Query, error) {
 	// The model supports multiple queries, but right now this is only used from
 	// alerting so we only needed to support batch executing 1 query at a time.
@@ -78,6 +78,24 @@ func (e *OpenTsdbExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 	return result, nil
 }
 
+// HealthCheck checks that OpenTSDB can suggest metrics
+func (e *OpenTsdbExecutor) HealthCheck(ctx context.Context, dsInfo *models.DataSource) error {
+	u, _ := url.Parse(dsInfo.Url)
+	u.Path = path.Join(u.Path, "api/suggest")
+	u.RawQuery = url.Values{"type": []string{"metrics"}, "max": []string{"1"}}.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	if dsInfo.BasicAuth {
+		req.SetBasicAuth(dsInfo.BasicAuthUser, dsInfo.DecryptedBasicAuthPassword())
+	}
+
+	return tsdb.CheckHttpHealth(ctx, dsInfo, req)
+}
+
 func (e *OpenTsdbExecutor) createRequest(dsInfo *models.DataSource, data OpenTsdbQuery) (*http.Request, error) {
 	u, _ := url.Parse(dsInfo.Url)
 	u.Path = path.Join(u.Path, "api/query")
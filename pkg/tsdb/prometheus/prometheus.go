@@ -82,6 +82,17 @@ func (e *PrometheusExecutor) getClient(dsInfo *models.DataSource) (apiv1.API, er
 	return apiv1.NewAPI(client), nil
 }
 
+// HealthCheck checks that Prometheus can evaluate a query
+func (e *PrometheusExecutor) HealthCheck(ctx context.Context, dsInfo *models.DataSource) error {
+	client, err := e.getClient(dsInfo)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Query(ctx, "1+1", time.Now())
+	return err
+}
+
 func (e *PrometheusExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
 	result := &tsdb.Response{
 		Results: map[string]*tsdb.QueryResult{},
@@ -186,6 +186,11 @@ func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource,
 	return result, nil
 }
 
+// HealthCheck checks that a connection to the database can be made
+func (e *sqlQueryEndpoint) HealthCheck(ctx context.Context, dsInfo *models.DataSource) error {
+	return e.engine.DB().PingContext(ctx)
+}
+
 // global macros/substitutions for all sql datasources
 var Interpolate = func(query *Query, timeRange *TimeRange, sql string) (string, error) {
 	minInterval, err := GetIntervalFrom(query.DataSource, query.Model, time.Second*60)
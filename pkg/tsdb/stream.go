@@ -0,0 +1,45 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Seasheller/grafana/pkg/models"
+)
+
+var ErrStreamingNotSupported = errors.New("Data source does not support streaming")
+
+// StreamRequest is a request to stream data of a datasource to the
+// subscribers of a Grafana Live stream.
+type StreamRequest struct {
+	// Stream is the name of the stream the packets are pushed to.
+	Stream string
+	// Path is the part of the stream name that tells the datasource what to
+	// stream.
+	Path string
+	// User is the user who subscribed to the stream first.
+	User *models.SignedInUser
+}
+
+// StreamingTsdbQueryEndpoint is implemented by the query endpoints of
+// datasources that stream data. RunStream sends packets until the context is
+// done, which is when the last subscriber left, or the stream ends.
+type StreamingTsdbQueryEndpoint interface {
+	RunStream(ctx context.Context, ds *models.DataSource, req *StreamRequest, send func(*models.StreamPacket) error) error
+}
+
+type HandleStreamFunc func(ctx context.Context, dsInfo *models.DataSource, req *StreamRequest, send func(*models.StreamPacket) error) error
+
+func HandleStream(ctx context.Context, dsInfo *models.DataSource, req *StreamRequest, send func(*models.StreamPacket) error) error {
+	endpoint, err := getTsdbQueryEndpointFor(dsInfo)
+	if err != nil {
+		return err
+	}
+
+	streaming, ok := endpoint.(StreamingTsdbQueryEndpoint)
+	if !ok {
+		return ErrStreamingNotSupported
+	}
+
+	return streaming.RunStream(ctx, dsInfo, req, send)
+}
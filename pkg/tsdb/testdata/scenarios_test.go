@@ -1,10 +1,12 @@
 package testdata
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/Seasheller/grafana/pkg/components/simplejson"
+	"github.com/Seasheller/grafana/pkg/models"
 	"github.com/Seasheller/grafana/pkg/tsdb"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -92,3 +94,33 @@ func TestTestdataScenarios(t *testing.T) {
 		})
 	})
 }
+
+func TestTestdataStream(t *testing.T) {
+	Convey("random walk stream", t, func() {
+		executor := &TestDataExecutor{}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		Convey("Should send points until the context is done", func() {
+			packets := []*models.StreamPacket{}
+			err := executor.RunStream(ctx, nil, &tsdb.StreamRequest{Stream: "ds/1/random-walk/10ms", Path: "random-walk/10ms"}, func(packet *models.StreamPacket) error {
+				packets = append(packets, packet)
+				if len(packets) == 3 {
+					cancel()
+				}
+				return nil
+			})
+
+			So(err, ShouldBeNil)
+			So(packets, ShouldHaveLength, 3)
+			So(packets[0].Stream, ShouldEqual, "ds/1/random-walk/10ms")
+			So(packets[0].Series[0].Points, ShouldHaveLength, 1)
+		})
+
+		Convey("Should fail for unknown streams and too short intervals", func() {
+			send := func(packet *models.StreamPacket) error { return nil }
+			So(executor.RunStream(ctx, nil, &tsdb.StreamRequest{Path: "unknown"}, send), ShouldNotBeNil)
+			So(executor.RunStream(ctx, nil, &tsdb.StreamRequest{Path: "random-walk/1ms"}, send), ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,60 @@
+package testdata
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/Seasheller/grafana/pkg/components/null"
+	"github.com/Seasheller/grafana/pkg/models"
+	"github.com/Seasheller/grafana/pkg/tsdb"
+)
+
+const minStreamInterval = time.Millisecond * 10
+
+// RunStream streams a random walk with a point every second, or every
+// interval of the path random-walk/<interval>, like random-walk/100ms.
+func (e *TestDataExecutor) RunStream(ctx context.Context, dsInfo *models.DataSource, req *tsdb.StreamRequest, send func(*models.StreamPacket) error) error {
+	parts := strings.SplitN(req.Path, "/", 2)
+	if parts[0] != "random-walk" {
+		return fmt.Errorf("Unknown stream %s", req.Path)
+	}
+
+	interval := time.Second
+	if len(parts) == 2 {
+		parsed, err := time.ParseDuration(parts[1])
+		if err != nil || parsed < minStreamInterval {
+			return fmt.Errorf("Invalid interval %s, should be at least %v", parts[1], minStreamInterval)
+		}
+		interval = parsed
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	walker := rand.Float64() * 100
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			timestamp := float64(now.UnixNano() / int64(time.Millisecond))
+			err := send(&models.StreamPacket{
+				Stream: req.Stream,
+				Series: []models.StreamSeries{
+					{
+						Name:   "random-walk",
+						Points: models.TimeSeriesPoints{{null.FloatFrom(walker), null.FloatFrom(timestamp)}},
+					},
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			walker += rand.Float64() - 0.5
+		}
+	}
+}
@@ -24,6 +24,11 @@ func init() {
 	tsdb.RegisterTsdbQueryEndpoint("testdata", NewTestDataExecutor)
 }
 
+// HealthCheck always succeeds, the test data is generated by Grafana
+func (e *TestDataExecutor) HealthCheck(ctx context.Context, dsInfo *models.DataSource) error {
+	return nil
+}
+
 func (e *TestDataExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
 	result := &tsdb.Response{}
 	result.Results = make(map[string]*tsdb.QueryResult)
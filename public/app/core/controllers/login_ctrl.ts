@@ -114,6 +114,11 @@ export class LoginCtrl {
         .then((result: any) => {
           $scope.result = result;
 
+          if (result.passwordExpired) {
+            window.location.href = result.redirectUrl;
+            return;
+          }
+
           if ($scope.formModel.password !== 'admin' || $scope.ldapEnabled || $scope.authProxyEnabled) {
             $scope.toGrafana();
             return;
@@ -67,7 +67,13 @@ export class AppConfigCtrlWrapper extends PureComponent<Props, State> {
         <div ref={element => (this.element = element)} />
         <br />
         <br />
-        {model && (
+        {model && model.readOnly && (
+          <div className="grafana-info-box span8">
+            This app was configured by provisioning and cannot be modified using the UI. Please contact your server
+            admin to update this app.
+          </div>
+        )}
+        {model && !model.readOnly && (
           <div className="gf-form">
             {!model.enabled && (
               <Button variant="primary" onClick={this.enable} className={withRightMargin}>